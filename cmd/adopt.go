@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <project>",
+	Short: "Set up a project already in Docker storage in the current directory",
+	Long: `Create a local .vervids config for a project that is already in Docker storage but
+has no config on this machine, e.g. after restoring the volume from a backup, and make
+it the current project. Its whole history is kept: nothing in storage is changed and
+no project file is needed. Pull a version to get the project file, then commit as
+usual.
+
+The history comes from the config each commit publishes next to the versions, or is
+rebuilt from the versions' asset tracking for projects committed before that.
+
+Example:
+  vervids adopt promo
+  vervids pull 12`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if storage.IsInitialized() {
+			name := args[0]
+			if local, err := project.Load(); err == nil {
+				name = local.ProjectName
+			}
+			exitWithKind(errs.Conflict, i18n.T("This directory already has project %s", name),
+				i18n.T("Run 'vervids adopt' in an empty directory, or refresh this one with 'vervids fetch %s --metadata'.", name))
+		}
+
+		target := findStoredProject(args[0])
+		cwd, err := os.Getwd()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		proj, published, err := project.FetchMetadata(docker.Volume{}, target.DockerPath, cwd)
+		if err != nil {
+			exitWithError(err, i18n.T("Error reading %s from storage: %v", target.Name, err))
+		}
+		if !published {
+			fmt.Println(warningMsg(i18n.T("No published history found; rebuilt it from asset tracking (no labels, metadata or signatures)")))
+		}
+
+		if err := storage.Initialize(); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
+		}
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+		if err := setProjectContext(proj, storage.GetConfigPath()); err != nil {
+			fmt.Println(warningMsg(i18n.T("Warning: Could not save project context: %v", err)))
+		}
+
+		fmt.Println(successMsg(i18n.T("Adopted %s with %d version(s)", proj.ProjectName, len(proj.Versions))))
+		fmt.Println()
+		showProjectCommits(proj)
+		fmt.Println(infoMsg(i18n.T("Use 'vervids pull %d' to get the latest project file, then commit as usual.", len(proj.Versions)-1)))
+	},
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var allCmd = &cobra.Command{
+	Use:   "all",
+	Short: "Run maintenance across every project in storage",
+	Long: `Run a maintenance command on every project in Docker storage, and every project on
+this machine stored in an S3-compatible bucket, one after the other, and report the
+results in one table instead of project by project.
+
+  verify  check each project's storage like 'vervids fsck', without repairing
+  prune   remove each project's versions whose storage is missing, like 'vervids prune';
+          with --keep-last N, also purge the stored files that only versions older
+          than the newest N use. Their project files stay, so the history is kept,
+          and pinned files are never purged.
+  stats   versions, newest commit and space used per project, with totals
+
+Projects in Docker storage are found the way 'vervids switch' finds them, and those
+in a bucket by their config.json in the same folders. A project in storage whose
+config.json can't be found on this machine is listed as skipped; use 'vervids adopt'
+to set it up. One failing project doesn't stop the others; the command exits with an
+error if any project failed or has problems.
+
+Example:
+  vervids all verify
+  vervids all prune
+  vervids all prune --keep-last 10
+  vervids all stats`,
+}
+
+var allVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the storage of every project",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := 0
+		rows := [][]string{}
+		for _, p := range loadAllProjects() {
+			if p.err != nil {
+				rows = append(rows, []string{p.info.Name, "", "", "", "", "", ui.WarningStyle.Render(i18n.T("skipped: %v", p.err))})
+				failed++
+				continue
+			}
+			check, err := checkStorage(p.proj)
+			if err != nil {
+				rows = append(rows, []string{p.info.Name, strconv.Itoa(len(p.proj.Versions)), "", "", "", "", ui.ErrorStyle.Render(i18n.T("failed: %v", err))})
+				failed++
+				continue
+			}
+			status := ui.SuccessStyle.Render("ok")
+			if !check.ok() {
+				status = ui.WarningStyle.Render(i18n.T("run 'vervids fsck' in the project"))
+				failed++
+			}
+			rows = append(rows, []string{p.info.Name, strconv.Itoa(len(p.proj.Versions)),
+				strconv.Itoa(len(check.missing)), strconv.Itoa(len(check.missingPins)), strconv.Itoa(len(check.issues)), strconv.Itoa(len(check.manifests)), status})
+		}
+		fmt.Println(ui.Table([]string{"Project", "Versions", "Missing", "Pins missing", "Tracking", "Manifests", "Status"}, rows, 2))
+		finishAll(len(rows), failed, i18n.T("%d of %d project(s) need attention", failed, len(rows)))
+	},
+}
+
+var allPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove versions with missing storage from every project",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		keepLast, _ := cmd.Flags().GetInt("keep-last")
+		if cmd.Flags().Changed("keep-last") && keepLast < 1 {
+			exitWithError(nil, i18n.T("--keep-last must be at least 1"))
+		}
+		mb := func(n int64) string { return fmt.Sprintf("%.2f", float64(n)/(1024*1024)) }
+		failed, total, totalPurged := 0, 0, 0
+		var totalFreed int64
+		rows := [][]string{}
+		for _, p := range loadAllProjects() {
+			if p.err != nil {
+				rows = append(rows, []string{p.info.Name, "", "", "", "", ui.WarningStyle.Render(i18n.T("skipped: %v", p.err))})
+				failed++
+				continue
+			}
+			before := len(p.proj.Versions)
+			var removed, purged int
+			var freed int64
+			err := inProjectDirectory(p.configPath, func() error {
+				// Reload: another command may have written to the project since it was listed
+				proj, err := project.LoadFromPath(p.configPath)
+				if err != nil {
+					return err
+				}
+				before = len(proj.Versions)
+				if removed, err = proj.PruneMissingDockerVersions(); err != nil {
+					return err
+				}
+				if keepLast > 0 {
+					purged, freed, err = proj.PruneKeepLast(keepLast)
+				}
+				return err
+			})
+			row := []string{p.info.Name, strconv.Itoa(before), strconv.Itoa(removed), strconv.Itoa(purged), mb(freed)}
+			total += removed
+			totalPurged += purged
+			totalFreed += freed
+			if err != nil {
+				rows = append(rows, append(row, ui.ErrorStyle.Render(i18n.T("failed: %v", err))))
+				failed++
+				continue
+			}
+			status := ui.SuccessStyle.Render("ok")
+			if removed > 0 || purged > 0 {
+				status = ui.SuccessStyle.Render(i18n.T("pruned"))
+			}
+			rows = append(rows, append(row, status))
+		}
+		fmt.Println(ui.Table([]string{"Project", "Versions", "Pruned", "Purged", "Freed(MB)", "Status"}, rows, 5))
+		fmt.Println(infoMsg(i18n.T("Pruned %d missing version(s) across %d project(s)", total, len(rows))))
+		if keepLast > 0 {
+			fmt.Println(infoMsg(i18n.T("Purged %d object(s) (%s MB) used only by versions older than the newest %d", totalPurged, mb(totalFreed), keepLast)))
+		}
+		if total > 0 {
+			fmt.Println(infoMsg(i18n.T("Run 'vervids all verify' to check the tracking of the versions that remain")))
+		}
+		finishAll(len(rows), failed, i18n.T("%d of %d project(s) could not be pruned", failed, len(rows)))
+	},
+}
+
+var allStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show versions and storage used by every project",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		mb := func(n int64) string { return fmt.Sprintf("%.2f", float64(n)/(1024*1024)) }
+		var total project.Stats
+		failed := 0
+		rows := [][]string{}
+		for _, p := range loadAllProjects() {
+			if p.err != nil {
+				rows = append(rows, []string{p.info.Name, "", "", "", "", ui.WarningStyle.Render(i18n.T("skipped: %v", p.err))})
+				failed++
+				continue
+			}
+			stats := p.proj.Stats()
+			newest := "-"
+			if stats.Newest != nil {
+				newest = fmt.Sprintf("v%d  %s", stats.Newest.Number, stats.Newest.Timestamp.Format("2006-01-02 15:04"))
+			}
+			notes := ""
+			if stats.Missing > 0 {
+				notes = ui.WarningStyle.Render(i18n.T("%d missing asset(s)", stats.Missing))
+			}
+			if p.proj.OverQuota() {
+				notes = ui.ErrorStyle.Render(i18n.T("over quota"))
+			}
+			rows = append(rows, []string{p.info.Name, strconv.Itoa(stats.Versions), newest, mb(stats.StoredSize), mb(stats.Savings()), notes})
+			total.Versions += stats.Versions
+			total.StoredSize += stats.StoredSize
+			total.LogicalSize += stats.LogicalSize
+		}
+		rows = append(rows, []string{ui.InfoStyle.Render(i18n.T("Total")), strconv.Itoa(total.Versions), "", mb(total.StoredSize), mb(total.Savings()), ""})
+		fmt.Println(ui.Table([]string{"Project", "Versions", "Newest", "Stored(MB)", "Saved(MB)", "Notes"}, rows, 2))
+		finishAll(len(rows)-1, failed, i18n.T("%d of %d project(s) could not be read", failed, len(rows)-1))
+	},
+}
+
+// allProject is one project in storage for 'vervids all', with its config if found
+type allProject struct {
+	info       project.ProjectInfo
+	configPath string
+	proj       *project.Project
+	err        error // Why the project is skipped
+}
+
+// loadAllProjects finds the config of every project in Docker storage, and of every
+// project on this machine stored in an S3-compatible bucket. Without Docker, only the
+// latter are found.
+func loadAllProjects() []allProject {
+	var infos []project.ProjectInfo
+	remote := project.GetRemoteProjects()
+	if err := docker.EnsureDockerReady(); err != nil {
+		if len(remote) == 0 {
+			exitWithError(err, i18n.T("%v", err))
+		}
+		fmt.Println(warningMsg(i18n.T("Docker is not available; only the %d project(s) stored in a bucket are included", len(remote))))
+	} else {
+		if infos, err = project.GetAllProjects(); err != nil {
+			exitWithError(err, i18n.T("Error getting projects: %v", err))
+		}
+	}
+	infos = append(infos, remote...)
+	if len(infos) == 0 {
+		exitWithKind(errs.ProjectNotFound, i18n.T("No projects found in Docker storage or on this machine."),
+			i18n.T("Use 'vervids init <file.aepx>' to create a project."))
+	}
+
+	projects := make([]allProject, len(infos))
+	for i, info := range infos {
+		projects[i].info = info
+		if info.ConfigPath != "" {
+			projects[i].configPath = info.ConfigPath
+			projects[i].proj, projects[i].err = project.LoadFromPath(info.ConfigPath)
+			continue
+		}
+		configPath, err := findProjectConfigFile(info.Name)
+		if err != nil {
+			projects[i].err = fmt.Errorf("no config.json on this machine")
+			continue
+		}
+		if projects[i].configPath, err = filepath.Abs(configPath); err != nil {
+			projects[i].configPath = configPath
+		}
+		projects[i].proj, projects[i].err = project.LoadFromPath(projects[i].configPath)
+	}
+	return projects
+}
+
+// inProjectDirectory runs fn from the directory holding configPath's .vervids, where
+// the project saves its config.json, and changes back afterwards
+func inProjectDirectory(configPath string, fn func() error) error {
+	originalDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting current directory: %w", err)
+	}
+	if err := os.Chdir(filepath.Dir(filepath.Dir(configPath))); err != nil {
+		return fmt.Errorf("cannot access project directory: %w", err)
+	}
+	defer os.Chdir(originalDir)
+	return fn()
+}
+
+// finishAll reports the outcome of a 'vervids all' command, exiting with an error if
+// any project failed
+func finishAll(count int, failed int, failure string) {
+	if failed == 0 {
+		fmt.Println(successMsg(i18n.T("All %d project(s) done", count)))
+		return
+	}
+	exitWithError(nil, failure)
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/tracking"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var assetCmd = &cobra.Command{
+	Use:   "asset",
+	Short: "Inspect individual assets across versions",
+}
+
+var assetTimelineCmd = &cobra.Command{
+	Use:   "timeline <filename>",
+	Short: "Show when an asset appeared, changed and was removed",
+	Long: `Combine the asset tracking of every version into the history of one asset: the
+version it first appeared in, each version where its size or content changed, its
+footage interpretation changed, or it was renamed or moved, and when it was removed.
+
+The asset can be given by filename or by its path relative to the project.
+
+Example:
+  vervids asset timeline hero_shot.mov
+  vervids asset timeline footage/hero_shot.mov`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get project from context (already ensured by PersistentPreRunE)
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		if len(proj.Versions) == 0 {
+			fmt.Println(infoMsg(i18n.T("No commits yet. Use 'vervids commit \"message\" <file.aepx>' to create one.")))
+			return
+		}
+
+		target := args[0]
+		var last *tracking.AssetStatus // Asset as of the previous version that contained it
+		events := 0
+
+		for _, v := range proj.Versions {
+			track, err := proj.LoadVersionTracking(v.Number)
+			if err != nil {
+				fmt.Println(warningMsg(i18n.T("v%d: tracking unavailable (%v)", v.Number, err)))
+				continue
+			}
+
+			current := findTrackedAsset(track, target, last)
+			label := fmt.Sprintf("v%-3d %s", v.Number, v.Timestamp.Format("2006-01-02 15:04"))
+
+			switch {
+			case current == nil || current.Status == "removed":
+				if last != nil {
+					fmt.Printf("  %s  %s %s\n", label, ui.ErrorStyle.Render("removed "), v.Message)
+					last = nil
+					events++
+				}
+				continue
+			case last == nil:
+				fmt.Printf("  %s  %s %s (%.2f MB)\n", label, ui.SuccessStyle.Render("added   "), v.Message, float64(current.Size)/(1024*1024))
+			case current.Status == "renamed":
+				fmt.Printf("  %s  %s %s → %s\n", label, ui.WarningStyle.Render("renamed "), current.PreviousPath, trackedName(current))
+			case current.Hash != last.Hash || current.Size != last.Size:
+				fmt.Printf("  %s  %s %s (%+.2f MB)\n", label, ui.WarningStyle.Render("changed "), v.Message,
+					float64(current.Size-last.Size)/(1024*1024))
+			case current.Status == "reinterpreted":
+				fmt.Printf("  %s  %s %s (%s)\n", label, ui.WarningStyle.Render("settings"), v.Message, strings.Join(current.InterpretationChanged, ", "))
+			default:
+				last = current
+				continue
+			}
+			last = current
+			events++
+		}
+
+		if events == 0 {
+			fmt.Println(infoMsg(i18n.T("'%s' does not appear in any version", target)))
+		}
+	},
+}
+
+var assetPinCmd = &cobra.Command{
+	Use:   "pin <filename>",
+	Short: "Keep an asset's stored content even when no version uses it",
+	Long: `Pin an asset so its stored content is never garbage-collected or pruned, even after
+every version referencing it is gone (e.g. licensed stock footage that must be retained).
+The newest stored content of the asset is pinned; pins are kept in .vervids/config.json.
+
+Example:
+  vervids asset pin stock_city_aerial.mov
+  vervids asset pin --list
+  vervids asset unpin stock_city_aerial.mov`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		if list, _ := cmd.Flags().GetBool("list"); list || len(args) == 0 {
+			if len(proj.PinnedAssets) == 0 {
+				fmt.Println(infoMsg(i18n.T("No pinned assets")))
+				return
+			}
+			fmt.Println(infoMsg(i18n.T("Pinned assets (%d):", len(proj.PinnedAssets))))
+			for _, pin := range proj.PinnedAssets {
+				fmt.Printf(ui.Text("  📌 %s  (from v%d, pinned %s)\n"), pin.Filename, pin.Version, pin.PinnedAt.Format("2006-01-02"))
+			}
+			return
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		pin, err := proj.PinAsset(args[0])
+		if err != nil {
+			exitWithError(err, i18n.T("%v", err))
+		}
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("Pinned %s (content from v%d)", pin.Filename, pin.Version)))
+	},
+}
+
+var assetUnpinCmd = &cobra.Command{
+	Use:   "unpin <filename>",
+	Short: "Remove an asset's pin",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		if proj.UnpinAsset(args[0]) == 0 {
+			exitWithError(nil, i18n.T("'%s' is not pinned", args[0]))
+		}
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("Unpinned %s", args[0])))
+	},
+}
+
+var assetRestoreCmd = &cobra.Command{
+	Use:   "restore <version> <filename>",
+	Short: "Bring back one asset from a past version into the project file",
+	Long: `Copy an asset as it was stored in a past version next to the project file, under
+assets/vNNN/, and point the project file's reference to it at the copy. Nothing else in
+the project changes, so one piece of footage can be recovered without a full rollback.
+
+The asset can be given by filename or by its path relative to the project. The project
+file defaults to the last committed one; it must still reference the asset.
+
+Example:
+  vervids asset restore 3 logo_anim.mov
+  vervids asset restore 3 footage/logo_anim.mov --into "/path/to/exported.aepx"`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		versionNum := parseVersionArg(proj, args[0])
+
+		aepxFilePath, _ := cmd.Flags().GetString("into")
+		if aepxFilePath == "" {
+			aepxFilePath = proj.ProjectPath
+		}
+		aepxFilePath, err = filepath.Abs(aepxFilePath)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if _, err := os.Stat(aepxFilePath); err != nil {
+			exitWithError(nil, i18n.T("File '%s' does not exist", aepxFilePath),
+				i18n.T("Pass the project file to update with --into <file.aepx>."))
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		restored, err := proj.RestoreAsset(versionNum, args[1], aepxFilePath)
+		if err != nil {
+			exitWithError(err, i18n.T("Error restoring asset: %v", err))
+		}
+
+		fmt.Println(successMsg(i18n.T("Restored %s from v%d", restored.Asset.Filename, versionNum)))
+		fmt.Printf("  File: %s\n", restored.LocalPath)
+		fmt.Printf("  Project: %s\n", aepxFilePath)
+		fmt.Printf("  Reference: %s -> %s\n", restored.Reference, restored.NewPath)
+		fmt.Println(infoMsg(i18n.T("Reopen the project in After Effects to pick up the change.")))
+	},
+}
+
+// findTrackedAsset finds the asset matching target in a version's tracking. Once the
+// asset has been seen, a rename from its previous location is followed as well.
+func findTrackedAsset(track *tracking.AssetTracking, target string, last *tracking.AssetStatus) *tracking.AssetStatus {
+	for i := range track.Assets {
+		a := &track.Assets[i]
+		if last != nil && a.Status == "renamed" && a.PreviousPath == trackedName(last) {
+			return a
+		}
+	}
+	for i := range track.Assets {
+		if matchesAsset(&track.Assets[i], target) {
+			return &track.Assets[i]
+		}
+	}
+	return nil
+}
+
+// matchesAsset reports whether target names the asset, by relative path or filename
+func matchesAsset(a *tracking.AssetStatus, target string) bool {
+	target = strings.ReplaceAll(target, "\\", "/")
+	if a.RelativePath != "" && strings.EqualFold(trackedName(a), target) {
+		return true
+	}
+	return strings.EqualFold(a.Filename, target)
+}
+
+// trackedName returns the key tracking uses for an asset: its relative path or filename
+func trackedName(a *tracking.AssetStatus) string {
+	if a.RelativePath != "" {
+		return strings.ReplaceAll(a.RelativePath, "\\", "/")
+	}
+	return a.Filename
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var autosaveCmd = &cobra.Command{
+	Use:   "autosave",
+	Short: "Commit a project file automatically on a schedule",
+	Long: `Schedule automatic commits of the current project's file. 'vervids autosave run'
+checks the file at every interval and commits it when it changed since the newest
+version, labeled "autosave" so the versions can be left out of 'vervids list' and
+'vervids log' with --no-autosaves.
+
+Autosaves are pruned more aggressively than regular versions: beyond the newest
+--keep autosaves, stored files that only older autosaves use are purged. Their project
+files stay, so they remain in the history.
+
+Example:
+  vervids autosave enable --every 30m promo.aepx
+  vervids autosave run
+  vervids list 1 --no-autosaves`,
+}
+
+var autosaveEnableCmd = &cobra.Command{
+	Use:   "enable <file>",
+	Short: "Schedule automatic commits of a file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		every, _ := cmd.Flags().GetString("every")
+		keep, _ := cmd.Flags().GetInt("keep")
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if err := proj.EnableAutosave(args[0], every, keep); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		saveAutosaveProject(proj)
+		fmt.Println(successMsg(i18n.T("Autosaving %s every %s", proj.Autosave.File, proj.Autosave.Every)))
+		fmt.Println(infoMsg(i18n.T("Start the scheduler with 'vervids autosave run' (keep it running, e.g. as a login item)")))
+	},
+}
+
+var autosaveDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop automatic commits",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if proj.Autosave == nil {
+			fmt.Println(infoMsg(i18n.T("Autosave is not enabled")))
+			return
+		}
+		proj.Autosave = nil
+		saveAutosaveProject(proj)
+		fmt.Println(successMsg(i18n.T("Autosave disabled")))
+	},
+}
+
+var autosaveStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the autosave schedule",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if proj.Autosave == nil {
+			fmt.Println(infoMsg(i18n.T("Autosave is not enabled")))
+			return
+		}
+		count := 0
+		var last *project.Version
+		for i := range proj.Versions {
+			if proj.Versions[i].IsAutosave() {
+				count++
+				last = &proj.Versions[i]
+			}
+		}
+		fmt.Printf("File:       %s\n", proj.Autosave.File)
+		fmt.Printf("Every:      %s\n", proj.Autosave.Every)
+		fmt.Printf("Keep:       %d\n", proj.Autosave.KeepCount())
+		fmt.Printf("Autosaves:  %d\n", count)
+		if last != nil {
+			fmt.Printf("Last:       v%03d %s\n", last.Number, last.Timestamp.Format("2006-01-02 15:04"))
+		}
+	},
+}
+
+var autosaveRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the autosave scheduler until interrupted",
+	Long: `Run the autosave scheduler in the foreground: check the file at every interval and
+commit it when it changed. The schedule is re-read at every check, so 'vervids autosave
+enable' and 'disable' take effect without restarting it. Stop it with Ctrl-C.
+
+Autosaves take the project's write lock like every other write, so one that comes
+due while a commit, prune or edit from another terminal, the API or watch mode is
+writing to the project waits for it, and those wait for an autosave in progress.
+
+Example:
+  vervids autosave run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := ensureProjectContext(); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		projectContext, err := storage.LoadContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
+		}
+		defer cleanup()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		for {
+			proj, err := project.LoadFromPath(projectContext.ConfigPath)
+			if err != nil {
+				exitWithError(err, i18n.T("Error loading project: %v", err))
+			}
+			if proj.Autosave == nil {
+				exitWithError(nil, i18n.T("Autosave is not enabled"), i18n.T("Enable it with 'vervids autosave enable --every 30m <file>'."))
+			}
+			every, err := proj.Autosave.Interval()
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			// Keep the storage container up between commits too, so a container stopped
+			// while idle is fixed before the next autosave needs it
+			if proj.Remote == nil {
+				if _, err := docker.Heal(); err != nil {
+					fmt.Println(warningMsg(i18n.T("Storage container check failed: %v", err)))
+				}
+			}
+			runAutosave(proj)
+
+			fmt.Println(infoMsg(i18n.T("Next check at %s", time.Now().Add(every).Format("15:04"))))
+			select {
+			case <-ctx.Done():
+				fmt.Println(infoMsg(i18n.T("Autosave stopped")))
+				return
+			case <-time.After(every):
+			}
+		}
+	},
+}
+
+// runAutosave commits the autosaved file if it changed and prunes older autosaves.
+// Failures are reported and the scheduler carries on.
+func runAutosave(proj *project.Project) {
+	due, err := proj.AutosaveDue()
+	if err != nil {
+		fmt.Println(warningMsg(i18n.T("Autosave skipped: %v", err)))
+		return
+	}
+	if !due {
+		fmt.Println(infoMsg(i18n.T("%s unchanged, nothing to autosave", proj.Autosave.File)))
+		return
+	}
+	v, err := proj.CommitAutosave()
+	if err != nil {
+		fmt.Println(errorMsg(i18n.T("Autosave failed: %v", err)))
+		return
+	}
+	fmt.Println(successMsg(i18n.T("Autosaved version %d", v.Number)))
+	printCommitStorage(proj, v)
+
+	purged, freed, err := proj.PruneAutosaves(proj.Autosave.KeepCount())
+	if err != nil {
+		fmt.Println(warningMsg(i18n.T("Pruning old autosaves failed: %v", err)))
+	} else if purged > 0 {
+		fmt.Println(infoMsg(i18n.T("Purged %d object(s) (%.2f MB) used only by old autosaves", purged, float64(freed)/(1024*1024))))
+	}
+}
+
+// saveAutosaveProject saves the project after its schedule changed
+func saveAutosaveProject(proj *project.Project) {
+	cleanup, err := changeToProjectDirectory()
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
+	}
+	defer cleanup()
+	if err := proj.Save(); err != nil {
+		exitWithError(err, i18n.T("Error saving project: %v", err))
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Move versions between machines as a single file",
+	Long: `Bundles carry versions from one machine to another without shared storage, like
+git bundles: create a bundle file, copy it over on a USB drive, and apply it on the
+other side.
+
+A bundle holds the versions after the one given with --since and only the stored
+files those versions use that the earlier versions don't, so a bundle of last week's
+work doesn't carry the footage both machines already have. Without --since the whole
+history is bundled, which sets the project up on a machine that has never had it.
+
+Example:
+  vervids bundle create promo promo-v012.vvb                 # whole history
+  vervids bundle create promo promo-v015.vvb --since 12      # the other side has v012
+  vervids bundle apply /Volumes/USB/promo-v015.vvb`,
+}
+
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create <project> <file>",
+	Short: "Write versions the other machine lacks to a bundle file",
+	Long: `Write a project's versions to a bundle file. With --since N the bundle leaves out
+version N and earlier, and everything they store; the machine applying it must have
+version N. 'vervids bundle apply' prints the --since to use for a machine.
+
+Example:
+  vervids bundle create promo promo.vvb
+  vervids bundle create promo promo.vvb --since 12`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		since, _ := cmd.Flags().GetInt("since")
+		configPath, err := findProjectConfigFile(args[0])
+		if err != nil {
+			exitWithKind(errs.ProjectNotFound, i18n.T("Could not find config.json for project '%s'", args[0]), i18n.T("Tip: Navigate to the project directory, or ensure .vervids/config.json exists."))
+		}
+		proj, err := project.LoadFromPath(configPath)
+		if err != nil {
+			exitWithError(err, i18n.T("Error loading project: %v", err))
+		}
+
+		output := args[1]
+		file, err := os.Create(output)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		writer := bufio.NewWriter(file)
+		var header *project.BundleHeader
+		err = ui.RunStep(i18n.T("Writing %s", output), func() error {
+			var err error
+			header, err = proj.CreateBundle(writer, since)
+			if err == nil {
+				err = writer.Flush()
+			}
+			return err
+		})
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+			exitWithError(err, i18n.T("Error creating bundle: %v", err))
+		}
+
+		fmt.Println(successMsg(i18n.T("Bundled %d version(s) (%s) to %s", len(header.Versions()), versionRanges(header.Versions()), output)))
+		fmt.Println(infoMsg(i18n.T("%d stored object(s), %.2f MB", header.Objects, float64(header.Size)/(1024*1024))))
+		if header.Base != nil {
+			fmt.Println(infoMsg(i18n.T("The machine applying it needs v%03d", header.Base.Number)))
+		}
+	},
+}
+
+var bundleApplyCmd = &cobra.Command{
+	Use:   "apply <file>",
+	Short: "Add the versions in a bundle file to this machine",
+	Long: `Unpack a bundle made with 'vervids bundle create' into Docker storage and add its
+versions to the project. The project in the current directory is used, or else the
+current project; when neither is the bundle's project, a bundle of the whole history
+sets the project up in the current directory.
+
+Versions this machine already has are skipped, so applying a bundle twice is safe.
+A bundle made on top of a version this machine doesn't have, or whose versions differ
+from the ones here, is refused.
+
+Example:
+  vervids bundle apply /Volumes/USB/promo.vvb --dry-run
+  vervids bundle apply /Volumes/USB/promo.vvb`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		file, err := os.Open(args[0])
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer file.Close()
+		reader := bufio.NewReader(file)
+		header, err := project.ReadBundleHeader(reader)
+		if err != nil {
+			exitWithError(err, i18n.T("Error reading bundle: %v", err))
+		}
+
+		proj, isNew, cleanup := bundleTarget(header)
+		defer cleanup()
+		if !dryRun {
+			if err := proj.CheckWritable(); err != nil {
+				exitWithError(err, i18n.T("%v", err))
+			}
+		}
+		missing, err := proj.BundleVersions(header)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		rows := [][]string{}
+		for _, v := range header.Project.Versions {
+			status := i18n.T("already here")
+			for _, m := range missing {
+				if m.Number == v.Number {
+					status = i18n.T("new")
+				}
+			}
+			rows = append(rows, []string{fmt.Sprintf("v%03d", v.Number), v.Timestamp.Format("2006-01-02 15:04"), v.Message, status})
+		}
+		fmt.Println(ui.Table([]string{"Version", "Date", "Message", "Status"}, rows, 2))
+		if len(missing) == 0 {
+			fmt.Println(successMsg(i18n.T("Already up to date")))
+			return
+		}
+		if dryRun {
+			fmt.Println(infoMsg(i18n.T("%d version(s) would be added", len(missing))))
+			return
+		}
+
+		if isNew {
+			if err := storage.Initialize(); err != nil {
+				exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
+			}
+		}
+		added, err := proj.ApplyBundle(header, reader)
+		if err != nil {
+			exitWithError(err, i18n.T("Error applying bundle: %v", err))
+		}
+		if isNew {
+			if err := setProjectContext(proj, storage.GetConfigPath()); err != nil {
+				fmt.Println(warningMsg(i18n.T("Warning: Could not save project context: %v", err)))
+			}
+			fmt.Println(successMsg(i18n.T("Set up project %s in the current directory", proj.ProjectName)))
+		}
+		numbers := make([]int, len(added))
+		for i, v := range added {
+			numbers[i] = v.Number
+		}
+		fmt.Println(successMsg(i18n.T("Added %d version(s) (%s)", len(added), versionRanges(numbers))))
+		if latest := proj.GetLatestVersion(); latest != nil {
+			fmt.Println(infoMsg(i18n.T("Bundles for this machine can use --since %d", latest.Number)))
+		}
+	},
+}
+
+// bundleTarget picks the project a bundle is applied to: the one in the current
+// directory, else the current project, else a new project in the current directory.
+// The returned cleanup restores the working directory.
+func bundleTarget(header *project.BundleHeader) (*project.Project, bool, func()) {
+	if storage.IsInitialized() {
+		proj, err := project.Load()
+		if err != nil {
+			exitWithError(err, i18n.T("Error loading project: %v", err))
+		}
+		if !header.For(proj) {
+			exitWithKind(errs.Conflict, i18n.T("The bundle is for project %s, but this directory has %s", header.ProjectID, proj.ProjectName))
+		}
+		return proj, false, func() {}
+	}
+
+	if context, err := storage.LoadContext(); err == nil {
+		if proj, err := project.LoadFromPath(context.ConfigPath); err == nil && header.For(proj) {
+			cleanup, err := changeToProjectDirectory()
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
+			}
+			return proj, false, cleanup
+		}
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+	return project.NewProjectFromBundle(header, cwd), true, func() {}
+}
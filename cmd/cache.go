@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// staleTempAge is how old a temp file must be before 'cache clean' treats it as left
+// over from an interrupted run rather than in use by a running one
+const staleTempAge = time.Hour
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Show where temporary and cached data is kept",
+	Long: `Show the temp and cache directories and how much space vervids uses in each.
+
+Temporary files (transcodes, chunk staging, files copied out of Docker) go to the
+system temp directory unless temp_dir is set; cached data goes to ~/.vervids/cache
+unless cache_dir is set. VERVIDS_TMPDIR and VERVIDS_CACHE_DIR override both.
+
+Example:
+  vervids cache
+  vervids config set --global temp_dir /scratch/vervids
+  vervids cache clean`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		tempFiles := staleTempEntries(storage.TempDir(), 0)
+		cacheFiles := cacheEntries(storage.CacheDir())
+		fmt.Printf("%s %s  (%d item(s), %.2f MB)\n", ui.InfoStyle.Render("Temp: "), storage.TempDir(), len(tempFiles), float64(diskUsage(tempFiles...))/(1024*1024))
+		fmt.Printf("%s %s  (%d item(s), %.2f MB)\n", ui.InfoStyle.Render("Cache:"), storage.CacheDir(), len(cacheFiles), float64(diskUsage(cacheFiles...))/(1024*1024))
+		if staged, err := project.LoadStagedCommits(); err == nil && len(staged) > 0 {
+			fmt.Printf("%s %s  (%d version(s), %.2f MB; run 'vervids push')\n", ui.InfoStyle.Render("Staged:"), project.StagingDir(), len(staged), float64(diskUsage(project.StagingDir()))/(1024*1024))
+		}
+	},
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove leftover temp files and cached data",
+	Long: `Remove temp files left behind by interrupted runs, and everything in the cache
+directory. Temp files from the last hour are kept in case another vervids command is
+still using them; use --all to remove them too. Staged versions are kept until
+'vervids push' stores them.
+
+Example:
+  vervids cache clean
+  vervids cache clean --all`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		minAge := staleTempAge
+		if all {
+			minAge = 0
+		}
+
+		paths := append(staleTempEntries(storage.TempDir(), minAge), cacheEntries(storage.CacheDir())...)
+		removed := 0
+		var freed int64
+		for _, path := range paths {
+			size := diskUsage(path)
+			if err := os.RemoveAll(path); err != nil {
+				fmt.Println(warningMsg(i18n.T("Could not remove %s: %v", path, err)))
+				continue
+			}
+			removed++
+			freed += size
+		}
+
+		if removed == 0 {
+			fmt.Println(successMsg(i18n.T("Nothing to clean")))
+			return
+		}
+		fmt.Println(successMsg(i18n.T("Removed %d item(s), freed %.2f MB", removed, float64(freed)/(1024*1024))))
+	},
+}
+
+// staleTempEntries returns the vervids temp files and directories in dir last
+// modified at least minAge ago
+func staleTempEntries(dir string, minAge time.Duration) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	paths := []string{}
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), storage.TempPrefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < minAge {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+	return paths
+}
+
+// cacheEntries returns everything in the cache directory except versions staged by
+// 'vervids commit --offline', which are not yet stored anywhere else
+func cacheEntries(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	paths := []string{}
+	for _, e := range entries {
+		if e.Name() == project.StagingDirName {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+	return paths
+}
+
+// diskUsage returns the total size of the files at or under paths
+func diskUsage(paths ...string) int64 {
+	var total int64
+	for _, path := range paths {
+		filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if !d.IsDir() {
+				if info, err := d.Info(); err == nil {
+					total += info.Size()
+				}
+			}
+			return nil
+		})
+	}
+	return total
+}
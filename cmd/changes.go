@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/tracking"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var changesCmd = &cobra.Command{
+	Use:   "changes <version-number>",
+	Short: "Show which assets were added, kept or removed in a version",
+	Long: `Show the asset tracking recorded when a version was committed: which assets were
+new, modified (same location, different content), still present, renamed or removed compared to the previous version, with totals.
+Footage whose file is unchanged but whose interpretation (frame rate, alpha, color
+profile) was changed in the project is listed as reinterpreted, with the settings that changed.
+
+Example:
+  vervids changes 3`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get project from context (already ensured by PersistentPreRunE)
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		num := parseVersionArg(proj, args[0])
+		track, err := proj.LoadVersionTracking(num)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		fmt.Printf("%s v%d - %s\n", ui.InfoStyle.Render("Changes in"), track.Version, track.CommitMessage)
+		fmt.Println()
+
+		printTrackingGroups(track, true)
+		printTrackingTotals(track)
+	},
+}
+
+// printTrackingGroups lists tracked assets grouped by status, with a +/~/*/→/- marker,
+// leaving out unchanged assets unless withUnchanged
+func printTrackingGroups(track *tracking.AssetTracking, withUnchanged bool) {
+	groups := []struct {
+		status string
+		title  string
+		marker string
+	}{
+		{"new", "New", ui.SuccessStyle.Render("+")},
+		{"modified", "Modified", ui.WarningStyle.Render("~")},
+		{"reinterpreted", "Reinterpreted", ui.WarningStyle.Render("*")},
+		{"renamed", "Renamed/moved", ui.WarningStyle.Render("→")},
+		{"removed", "Removed", ui.ErrorStyle.Render("-")},
+		{"present", "Unchanged", " "},
+	}
+	for _, g := range groups {
+		assets := assetsWithStatus(track, g.status)
+		if len(assets) == 0 || (g.status == "present" && !withUnchanged) {
+			continue
+		}
+		fmt.Println(infoMsg(i18n.T("%s (%d):", g.title, len(assets))))
+		for _, a := range assets {
+			name := a.Filename
+			if a.RelativePath != "" {
+				name = a.RelativePath
+			}
+			if a.Status == "renamed" && a.PreviousPath != "" {
+				name = fmt.Sprintf("%s → %s", a.PreviousPath, name)
+			}
+			if a.Status == "modified" {
+				fmt.Printf("  %s %s  %.2f MB (%+.2f MB)\n", g.marker, name, float64(a.Size)/(1024*1024), float64(a.SizeDelta)/(1024*1024))
+				continue
+			}
+			if a.Status == "reinterpreted" {
+				fmt.Printf("  %s %s  %s\n", g.marker, name, strings.Join(a.InterpretationChanged, ", "))
+				continue
+			}
+			fmt.Printf("  %s %s  %.2f MB\n", g.marker, name, float64(a.Size)/(1024*1024))
+		}
+		fmt.Println()
+	}
+}
+
+// printTrackingTotals prints the asset counts of each status and the size change
+func printTrackingTotals(track *tracking.AssetTracking) {
+	var delta int64
+	for _, a := range track.Assets {
+		delta += a.SizeDelta
+	}
+	fmt.Printf("%s %d new, %d modified, %d reinterpreted, %d renamed, %d removed, %d present (%d tracked), %+.2f MB\n",
+		ui.InfoStyle.Render("Totals:"),
+		track.NewAssets, track.ModifiedAssets, track.ReinterpretedAssets, track.RenamedAssets, track.RemovedAssets, track.PresentAssets, track.TotalAssets,
+		float64(delta)/(1024*1024))
+}
+
+// assetsWithStatus returns the tracked assets with the given status
+func assetsWithStatus(track *tracking.AssetTracking, status string) []tracking.AssetStatus {
+	matches := []tracking.AssetStatus{}
+	for _, a := range track.Assets {
+		if a.Status == status {
+			matches = append(matches, a)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove files left behind by interrupted commits",
+	Long: `Find and remove what interrupted commits leave behind:
+  - temp files on this machine (tracking files, transcodes, copies out of Docker)
+  - offline commits that stopped while being staged (no staged.json)
+  - vXXX directories in storage that no version in config.json points to
+
+Everything found is listed first and only removed after confirmation. Anything from
+the last hour is kept in case another vervids command is still using it; use --all
+to include it. Storage isn't checked while a commit of the project is running, and
+only Docker storage is checked. Stored assets are never touched, and a commit journal
+is kept so 'vervids commit' can still resume.
+
+Example:
+  vervids clean
+  vervids clean --dry-run
+  vervids clean --all --yes`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		all, _ := cmd.Flags().GetBool("all")
+		yes, _ := cmd.Flags().GetBool("yes")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if !dryRun {
+			if err := proj.CheckWritable(); err != nil {
+				exitWithError(err, i18n.T("%v", err))
+			}
+		}
+		minAge := staleTempAge
+		if all {
+			minAge = 0
+		}
+
+		hostPaths := append(staleTempEntries(storage.TempDir(), minAge), project.IncompleteStagedCommits(minAge)...)
+
+		var orphans []project.OrphanDir
+		switch {
+		case proj.Remote != nil:
+			fmt.Println(infoMsg(i18n.T("Storage not checked: only Docker storage is scanned for incomplete versions")))
+		case !docker.IsContainerRunning():
+			fmt.Println(warningMsg(i18n.T("Storage not checked: the vervids container is not running")))
+		case commitInProgress():
+			fmt.Println(warningMsg(i18n.T("Storage not checked: a commit of this project is running")))
+		default:
+			if orphans, err = proj.IncompleteVersionDirs(); err != nil {
+				exitWithError(err, i18n.T("Error checking storage: %v", err))
+			}
+		}
+
+		if len(hostPaths) == 0 && len(orphans) == 0 {
+			fmt.Println(successMsg(i18n.T("Nothing to clean")))
+			return
+		}
+
+		var total int64
+		rows := make([][]string, 0, len(hostPaths)+len(orphans))
+		for _, path := range hostPaths {
+			size := diskUsage(path)
+			total += size
+			rows = append(rows, []string{"host", fmt.Sprintf("%.2f", float64(size)/(1024*1024)), path})
+		}
+		for _, o := range orphans {
+			total += o.Size
+			rows = append(rows, []string{"storage", fmt.Sprintf("%.2f", float64(o.Size)/(1024*1024)), o.DockerPath})
+		}
+		fmt.Println(ui.Table([]string{"Where", "Size(MB)", "Path"}, rows, 2))
+		fmt.Println(infoMsg(i18n.T("%d item(s), %.2f MB", len(rows), float64(total)/(1024*1024))))
+
+		if dryRun {
+			return
+		}
+		if !yes {
+			if !term.IsTerminal(os.Stdin.Fd()) {
+				exitWithError(nil, i18n.T("Refusing to remove without confirmation"), i18n.T("Run with --yes to remove without asking"))
+			}
+			fmt.Print(infoMsg(i18n.T("Remove these items? (y/N): ")))
+			input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if answer := strings.ToLower(strings.TrimSpace(input)); answer != "y" && answer != "yes" {
+				fmt.Println(infoMsg(i18n.T("Nothing removed")))
+				return
+			}
+		}
+
+		removed := 0
+		var freed int64
+		for _, path := range hostPaths {
+			size := diskUsage(path)
+			if err := os.RemoveAll(path); err != nil {
+				fmt.Println(warningMsg(i18n.T("Could not remove %s: %v", path, err)))
+				continue
+			}
+			removed++
+			freed += size
+		}
+		if len(orphans) > 0 && commitInProgress() {
+			// A commit started while we asked; it may be resuming into one of these folders
+			fmt.Println(warningMsg(i18n.T("A commit of this project started; storage left as it is")))
+			orphans = nil
+		}
+		for _, o := range orphans {
+			if err := docker.DeleteDirectory(o.DockerPath); err != nil {
+				fmt.Println(warningMsg(i18n.T("Could not remove %s: %v", o.DockerPath, err)))
+				continue
+			}
+			removed++
+			freed += o.Size
+		}
+		fmt.Println(successMsg(i18n.T("Removed %d item(s), freed %.2f MB", removed, float64(freed)/(1024*1024))))
+	},
+}
+
+// commitInProgress reports whether a commit of the current project holds its commit
+// lock, which it takes before writing anything to storage
+func commitInProgress() bool {
+	context, err := storage.LoadContext()
+	if err != nil {
+		return false
+	}
+	return storage.CommitRunning(filepath.Dir(context.ConfigPath))
+}
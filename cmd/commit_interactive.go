@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/charmbracelet/x/term"
+)
+
+// recentFilesShown is how many nearby .aepx files the guided commit offers
+const recentFilesShown = 15
+
+// guidedCommit walks through a commit for 'vervids commit -i': choosing the .aepx
+// (unless given), reviewing the pending asset changes, entering a message (unless
+// given) and confirming the upload. ok is false when the user backs out.
+func guidedCommit(proj *project.Project, message string, aepxFilePath string) (string, string, bool) {
+	if !term.IsTerminal(os.Stdin.Fd()) || !term.IsTerminal(os.Stdout.Fd()) {
+		exitWithError(nil, i18n.T("The guided commit needs an interactive terminal"),
+			i18n.T("Use 'vervids commit \"message\" <file.aepx>' in scripts."))
+	}
+	reader := bufio.NewReader(os.Stdin)
+
+	// 1. Project file
+	if aepxFilePath == "" {
+		path, ok := chooseProjectFile(proj)
+		if !ok {
+			return "", "", false
+		}
+		aepxFilePath = path
+	}
+	fmt.Println(infoMsg(i18n.T("Project file: %s", aepxFilePath)))
+	fmt.Println()
+
+	// 2. Pending changes
+	pending, err := proj.PendingChanges(aepxFilePath)
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+	printPendingCommit(pending)
+
+	// 3. Message
+	for message == "" {
+		fmt.Print(infoMsg(i18n.T("Commit message: ")))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return "", "", false
+		}
+		message = strings.TrimSpace(input)
+	}
+
+	// 4. Confirm
+	fmt.Println()
+	fmt.Print(infoMsg(i18n.T("Commit \"%s\" and upload about %.2f MB? [Y/n]: ", message, float64(pending.UploadSize)/(1024*1024))))
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return "", "", false
+	}
+	if answer := strings.ToLower(strings.TrimSpace(input)); answer != "" && answer != "y" && answer != "yes" {
+		return "", "", false
+	}
+	fmt.Println()
+	return message, aepxFilePath, true
+}
+
+// chooseProjectFile offers the .aepx files found near the project in a picker
+func chooseProjectFile(proj *project.Project) (string, bool) {
+	cwd, _ := os.Getwd()
+	files := proj.RecentProjectFiles(cwd, recentFilesShown)
+	if len(files) == 0 {
+		exitWithError(nil, i18n.T("No .aepx files found near the project"),
+			i18n.T("Export the project as .aepx from After Effects, or pass the file: vervids commit -i \"message\" <file.aepx>"))
+	}
+
+	items := make([]ui.PickItem, len(files))
+	for i, path := range files {
+		detail := filepath.Dir(path)
+		if info, err := os.Stat(path); err == nil {
+			detail = fmt.Sprintf("%s  ·  %s  ·  %.2f MB", detail, info.ModTime().Format("2006-01-02 15:04"), float64(info.Size())/(1024*1024))
+		}
+		if path == proj.ProjectPath {
+			detail += "  ·  " + i18n.T("last committed")
+		}
+		items[i] = ui.PickItem{Label: filepath.Base(path), Detail: detail}
+	}
+	index, ok, err := ui.Pick(i18n.T("Project file to commit:"), "", items)
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+	if !ok {
+		return "", false
+	}
+	return files[index], true
+}
+
+// detectProjectFile picks the most recently modified project file near the project
+// for a commit given only a message, and asks to confirm it unless yes is set. ok is
+// false when the user declines.
+func detectProjectFile(proj *project.Project, yes bool) (string, bool) {
+	cwd, _ := os.Getwd()
+	files := proj.RecentProjectFiles(cwd, 1)
+	if len(files) == 0 {
+		exitWithError(nil, i18n.T("No project file found near the project"),
+			i18n.T("Pass the file: vervids commit \"message\" <file.aepx>"))
+	}
+	path := files[0]
+	detail := ""
+	if info, err := os.Stat(path); err == nil {
+		detail = i18n.T(" (modified %s)", info.ModTime().Format("2006-01-02 15:04"))
+	}
+	if yes {
+		fmt.Println(infoMsg(i18n.T("Project file: %s%s", path, detail)))
+		return path, true
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		exitWithError(nil, i18n.T("No project file given"),
+			i18n.T("Pass the file, or add --yes to commit the newest one found: %s", path))
+	}
+
+	fmt.Print(infoMsg(i18n.T("Commit %s%s? [Y/n]: ", path, detail)))
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", false
+	}
+	if answer := strings.ToLower(strings.TrimSpace(input)); answer != "" && answer != "y" && answer != "yes" {
+		return "", false
+	}
+	return path, true
+}
+
+// printPendingCommit summarizes the asset changes and sizes a commit would store
+func printPendingCommit(pending *project.PendingCommit) {
+	fmt.Println(infoMsg(i18n.T("Pending changes:")))
+	printPendingAssets(pending)
+
+	upload := 0
+	for _, a := range pending.Assets {
+		if a.Upload {
+			upload++
+		}
+	}
+	fmt.Println()
+	fmt.Printf("%s %d new, %d modified, %d unchanged, %d removed, %d missing\n",
+		ui.InfoStyle.Render("Assets:"),
+		pending.Count("new"), pending.Count("modified"), pending.Count("unchanged"), len(pending.Removed), len(pending.Missing))
+	if pending.Excluded > 0 {
+		fmt.Printf("%s %d excluded by asset rules\n", ui.InfoStyle.Render("Skipped:"), pending.Excluded)
+	}
+	fmt.Printf("%s %.2f MB project file + %d asset(s) not stored yet, about %.2f MB in total\n",
+		ui.InfoStyle.Render("Upload:"), float64(pending.ProjectFileSize)/(1024*1024), upload, float64(pending.UploadSize)/(1024*1024))
+	fmt.Println()
+}
+
+// printPendingAssets lists new, modified, removed and missing assets, one per line
+func printPendingAssets(pending *project.PendingCommit) {
+	groups := []struct {
+		status string
+		marker string
+	}{
+		{"new", ui.SuccessStyle.Render("+")},
+		{"modified", ui.WarningStyle.Render("~")},
+	}
+	for _, g := range groups {
+		for _, a := range pending.Assets {
+			if a.Status == g.status {
+				fmt.Printf("  %s %s  %.2f MB\n", g.marker, filepath.Base(a.Path), float64(a.Size)/(1024*1024))
+			}
+		}
+	}
+	for _, path := range pending.Removed {
+		fmt.Printf("  %s %s\n", ui.ErrorStyle.Render("-"), filepath.Base(path))
+	}
+	for _, path := range pending.Missing {
+		fmt.Println(warningMsg(i18n.T("  Missing: %s", path)))
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/settings"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change project and global settings",
+	Long: `View and change settings for the current project. Settings are stored in .vervids/config.json
+and applied when parsing .aepx files on commit.
+
+With --global, view and change user-wide settings in ~/.vervids/settings.yaml instead:
+color theme, language, logging, update checks, the default 'serve' port, the author
+recorded on commits, and asset excludes applied to every project. Project settings
+override global ones where both exist (e.g. author). Keys that only exist globally
+work without --global.
+
+Set read_only to archive a project: commit, delete, prune and the other commands that
+change its config or storage are refused (exit code 8) until it is unset. 'vervids config set --global read_only true' does the same for every
+project, e.g. to give producers safe browsing access to a shared backend.
+
+List settings accept several values or a comma-separated list.
+
+Example:
+  vervids config list
+  vervids config set include_extensions .mov .png .wav
+  vervids config set exclude_folders Renders,Proxies
+  vervids config get exclude_folders
+  vervids config unset include_extensions
+  vervids config list --global
+  vervids config set --global author_name "Ana Ruiz"
+  vervids config set --global theme light
+  vervids config set read_only true`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all project (or global) settings",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if global, _ := cmd.Flags().GetBool("global"); global {
+			s := loadSettings()
+			fmt.Printf("%s: %s\n\n", ui.InfoStyle.Render("Settings"), settings.GetSettingsPath())
+			for _, key := range settings.ConfigKeys() {
+				value, _ := s.GetConfig(key)
+				printConfigValue(key, value, settings.ConfigDescription(key))
+			}
+			return
+		}
+
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		fmt.Printf("%s: %s\n\n", ui.InfoStyle.Render("Project"), proj.ProjectName)
+		for _, key := range project.ConfigKeys() {
+			value, _ := proj.GetConfig(key)
+			printConfigValue(key, value, project.ConfigDescription(key))
+		}
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a project (or global) setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if globalConfigKey(cmd, args[0]) {
+			value, err := loadSettings().GetConfig(args[0])
+			if err != nil {
+				exitWithError(err, i18n.T("%v", err))
+			}
+			fmt.Println(value)
+			return
+		}
+
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		value, err := proj.GetConfig(args[0])
+		if err != nil {
+			exitWithError(err, i18n.T("%v", err))
+		}
+		fmt.Println(value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> [value...]",
+	Short: "Change a project (or global) setting",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if globalConfigKey(cmd, args[0]) {
+			updateSettings(args[0], args[1:])
+			return
+		}
+		updateProjectConfig(args[0], args[1:])
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Clear a project (or global) setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if globalConfigKey(cmd, args[0]) {
+			updateSettings(args[0], nil)
+			return
+		}
+		updateProjectConfig(args[0], nil)
+	},
+}
+
+// globalConfigKey reports whether key refers to a global setting: --global was given,
+// or the key only exists globally
+func globalConfigKey(cmd *cobra.Command, key string) bool {
+	if global, _ := cmd.Flags().GetBool("global"); global {
+		return true
+	}
+	if _, err := (&project.Project{}).GetConfig(key); err == nil {
+		return false
+	}
+	return settings.IsConfigKey(key)
+}
+
+func printConfigValue(key string, value string, description string) {
+	if value == "" {
+		value = "(not set)"
+	}
+	fmt.Printf("  %-20s %s\n", key, value)
+	fmt.Printf("  %-20s %s\n", "", ui.InfoStyle.Render(description))
+}
+
+// loadSettings loads ~/.vervids/settings.yaml, exiting on error
+func loadSettings() *settings.Settings {
+	s, err := settings.Load()
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+	return s
+}
+
+// updateSettings sets a global setting and saves settings.yaml
+func updateSettings(key string, values []string) {
+	s := loadSettings()
+	if err := s.SetConfig(key, values); err != nil {
+		exitWithError(err, i18n.T("%v", err))
+	}
+	if err := s.Save(); err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+
+	value, _ := s.GetConfig(key)
+	if value == "" {
+		value = "(not set)"
+	}
+	fmt.Println(successMsg(i18n.T("%s = %s", key, value)))
+	if strings.HasPrefix(key, "container_") {
+		fmt.Println(infoMsg(i18n.T("Run 'vervids container recreate' to apply it to the storage container")))
+	}
+}
+
+// updateProjectConfig sets a project setting and saves the project config
+func updateProjectConfig(key string, values []string) {
+	proj, err := ensureProjectContext()
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+
+	cleanup, err := changeToProjectDirectory()
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+	defer cleanup()
+
+	if values == nil {
+		// Booleans reset to false, lists to empty
+		if current, err := proj.GetConfig(key); err == nil && (current == "true" || current == "false") {
+			values = []string{"false"}
+		}
+	}
+
+	if err := proj.SetConfig(key, values); err != nil {
+		exitWithError(err, i18n.T("%v", err))
+	}
+	if err := proj.Save(); err != nil {
+		exitWithError(err, i18n.T("Error saving project: %v", err))
+	}
+
+	value, _ := proj.GetConfig(key)
+	if value == "" {
+		value = "(not set)"
+	}
+	fmt.Println(successMsg(i18n.T("%s = %s", key, value)))
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var containerCmd = &cobra.Command{
+	Use:   "container",
+	Short: "Check or recreate the storage container",
+	Long: `The storage container is created with the resource limits and isolation options
+from ~/.vervids/settings.yaml:
+
+  container_cpus       CPU limit, e.g. 2
+  container_memory     Memory limit, e.g. 2g
+  container_image      Image, pinned by digest (alpine@sha256:...)
+  container_read_only  Read-only root filesystem
+
+Set them with 'vervids config set --global <key> <value>'. They apply when the
+container is created, so after changing them, recreate it. Stored versions live in
+the Docker volume and are kept.
+
+Example:
+  vervids config set --global container_memory 2g
+  vervids container status
+  vervids container recreate`,
+}
+
+var containerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the storage container matches the settings",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := docker.EnsureDockerReady(); err != nil {
+			exitWithError(err, i18n.T("%v", err))
+		}
+		drift, err := docker.ContainerDrift()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if len(drift) == 0 {
+			fmt.Println(successMsg(i18n.T("Storage container matches the settings")))
+			return
+		}
+		fmt.Println(warningMsg(i18n.T("Storage container differs from the settings:")))
+		for _, d := range drift {
+			fmt.Printf("  • %s\n", d)
+		}
+		fmt.Println(infoMsg(i18n.T("Run 'vervids container recreate' to apply the settings")))
+	},
+}
+
+var containerRecreateCmd = &cobra.Command{
+	Use:   "recreate",
+	Short: "Recreate the storage container with the current settings",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if commitInProgress() {
+			exitWithError(nil, i18n.T("A commit may still be running; try again when it has finished"))
+		}
+		if err := docker.EnsureDockerReady(); err != nil {
+			exitWithError(err, i18n.T("%v", err))
+		}
+		if err := ui.RunStep(i18n.T("Recreating storage container"), docker.RecreateContainer); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err),
+				i18n.T("Check the container settings with 'vervids config list --global'."))
+		}
+		fmt.Println(successMsg(i18n.T("Storage container recreated; stored versions are unchanged")))
+	},
+}
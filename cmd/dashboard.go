@@ -0,0 +1,800 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/tracking"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse projects and versions in an interactive dashboard",
+	Long: `Open a full-screen dashboard with panes for the projects in Docker storage, the
+selected project's commits, and the selected version's details with its asset and
+composition changes since the previous version. While the projects pane has the
+cursor, the details pane shows the highlighted project's notes.
+
+Keys:
+  ↑/↓ or k/j    Move within the pane
+  ←/→ or tab    Switch between the projects and commits panes
+  enter         Switch to the highlighted project
+  a             List every asset of the highlighted version, with a bar for the
+                share stored new rather than reused; ← or a goes back
+  p             Pull the highlighted version into the current directory
+  t             Tag the highlighted version, like 'vervids tag' (asks for the name)
+  d             Delete the highlighted project (asks for confirmation)
+  r             Reload projects from Docker storage
+  q or esc      Quit
+
+Example:
+  vervids ui`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !term.IsTerminal(os.Stdin.Fd()) || !term.IsTerminal(os.Stdout.Fd()) {
+			exitWithError(nil, i18n.T("The dashboard needs an interactive terminal"),
+				i18n.T("Use 'vervids list' and 'vervids show <version>' in scripts."))
+		}
+
+		projects, err := project.GetAllProjects()
+		if err != nil {
+			exitWithError(err, i18n.T("Error getting projects: %v", err))
+		}
+
+		d := newDashboard(projects)
+		if err := d.run(); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+	},
+}
+
+// Dashboard panes that take the cursor
+const (
+	paneProjects = iota
+	paneCommits
+	paneAssets // The highlighted version's assets, in the details pane
+)
+
+// dashboard is the state of the vervids ui screen, run as a Bubble Tea program
+type dashboard struct {
+	projects      []project.ProjectInfo
+	loaded        map[string]*dashboardProject // Local configs, by project name
+	changes       map[string]*dashboardChanges // Asset tracking, by project name and version
+	comps         map[string]*dashboardComps   // Compositions, by project name and version
+	history       bool                         // Only the commits and details panes, for one project
+	pane          int
+	projectCursor int
+	commitCursor  int
+	assetCursor   int
+	status        string
+	confirmDelete bool
+	width         int // Terminal size, from the last resize
+	height        int
+}
+
+// dashboardProject is a project's local config, or why it couldn't be loaded
+type dashboardProject struct {
+	proj       *project.Project
+	configPath string
+	err        error
+}
+
+// dashboardChanges is a version's asset tracking, or why it couldn't be loaded
+type dashboardChanges struct {
+	track *tracking.AssetTracking
+	err   error
+}
+
+// dashboardComps are the compositions in a version's .aepx, or why they couldn't be read
+type dashboardComps struct {
+	comps []aepx.Comp
+	err   error
+}
+
+func newDashboard(projects []project.ProjectInfo) *dashboard {
+	return &dashboard{
+		projects: projects,
+		loaded:   map[string]*dashboardProject{},
+		changes:  map[string]*dashboardChanges{},
+		comps:    map[string]*dashboardComps{},
+	}
+}
+
+// newHistoryBrowser returns a dashboard over a single project's versions
+func newHistoryBrowser(proj *project.Project) *dashboard {
+	d := newDashboard([]project.ProjectInfo{{Name: proj.ProjectName}})
+	d.loaded[proj.ProjectName] = &dashboardProject{proj: proj}
+	d.history = true
+	d.pane = paneCommits
+	return d
+}
+
+func (d *dashboard) run() error {
+	_, err := tea.NewProgram(d, tea.WithAltScreen()).Run()
+	return err
+}
+
+func (d *dashboard) Init() tea.Cmd {
+	return nil
+}
+
+func (d *dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width, d.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		return d, d.handle(dashboardKey(msg))
+	case resumedMsg:
+		if msg.err != nil {
+			d.status = errorMsg(msg.err.Error())
+		}
+		if msg.then != nil {
+			msg.then()
+		}
+	}
+	return d, nil
+}
+
+// resumedMsg is sent when the dashboard comes back after suspend, with then to run
+// once it has
+type resumedMsg struct {
+	err  error
+	then func()
+}
+
+// suspend leaves the dashboard so fn can print normal output and read answers, and
+// comes back, then runs then, once the user has read it
+func (d *dashboard) suspend(fn func(), then func()) tea.Cmd {
+	return tea.Exec(suspended(fn), func(err error) tea.Msg {
+		return resumedMsg{err: err, then: then}
+	})
+}
+
+// suspended runs a function on the normal screen while the dashboard is released
+type suspended func()
+
+func (s suspended) Run() error {
+	s()
+	fmt.Println()
+	fmt.Print(infoMsg(i18n.T("Press Enter to return to the dashboard")))
+	_, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	return err
+}
+
+// suspended's function uses the process's own stdin and stdout
+func (s suspended) SetStdin(io.Reader)  {}
+func (s suspended) SetStdout(io.Writer) {}
+func (s suspended) SetStderr(io.Writer) {}
+
+// dashboardKey names a key press, with vi-style letters for the arrows and q to quit
+func dashboardKey(msg tea.KeyMsg) string {
+	key := msg.String()
+	switch key {
+	case "k":
+		return "up"
+	case "j":
+		return "down"
+	case "l":
+		return "right"
+	case "h":
+		return "left"
+	case "esc", "ctrl+c", "q":
+		return "quit"
+	}
+	return key
+}
+
+// handle applies a key press and returns what the program should do next, e.g. quit
+func (d *dashboard) handle(key string) tea.Cmd {
+	if d.confirmDelete {
+		d.confirmDelete = false
+		d.status = ""
+		if key == "y" || key == "Y" {
+			return d.deleteProject()
+		}
+		d.status = infoMsg(i18n.T("Deletion cancelled"))
+		return nil
+	}
+
+	d.status = ""
+	if d.pane == paneAssets {
+		switch key {
+		case "a", "left", "tab":
+			d.pane = paneCommits
+			return nil
+		case "right", "enter", "d":
+			return nil
+		}
+	}
+	if d.history {
+		switch key {
+		case "tab", "left", "right", "enter", "d", "r":
+			return nil
+		}
+	}
+	switch key {
+	case "quit":
+		return tea.Quit
+	case "a":
+		if d.pane == paneCommits && d.currentVersion() != nil {
+			d.pane = paneAssets
+			d.assetCursor = 0
+		}
+	case "tab":
+		d.pane = 1 - d.pane
+	case "left":
+		d.pane = paneProjects
+	case "right":
+		d.pane = paneCommits
+	case "up":
+		d.move(-1)
+	case "down":
+		d.move(1)
+	case "enter":
+		if d.pane == paneProjects {
+			d.switchProject()
+		}
+	case "p":
+		return d.pull()
+	case "t":
+		return d.tag()
+	case "d":
+		if info := d.currentProject(); info != nil {
+			d.confirmDelete = true
+			d.status = warningMsg(i18n.T("Delete project '%s' and all its versions? This cannot be undone. (y/N)", info.Name))
+		}
+	case "r":
+		d.reload()
+	}
+	return nil
+}
+
+func (d *dashboard) move(delta int) {
+	if d.pane == paneAssets {
+		if v := d.currentVersion(); v != nil {
+			d.assetCursor = clampCursor(d.assetCursor+delta, len(v.Assets)+len(v.Missing))
+		}
+		return
+	}
+	if d.pane == paneProjects {
+		d.projectCursor = clampCursor(d.projectCursor+delta, len(d.projects))
+		d.commitCursor = 0
+		return
+	}
+	d.commitCursor = clampCursor(d.commitCursor+delta, len(d.versions()))
+}
+
+func clampCursor(cursor int, count int) int {
+	if cursor >= count {
+		cursor = count - 1
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	return cursor
+}
+
+func (d *dashboard) currentProject() *project.ProjectInfo {
+	if d.projectCursor >= len(d.projects) {
+		return nil
+	}
+	return &d.projects[d.projectCursor]
+}
+
+// project loads the highlighted project's local config, once per project
+func (d *dashboard) project() *dashboardProject {
+	info := d.currentProject()
+	if info == nil {
+		return nil
+	}
+	if loaded, ok := d.loaded[info.Name]; ok {
+		return loaded
+	}
+	loaded := &dashboardProject{}
+	loaded.configPath, loaded.err = findProjectConfigFile(info.Name)
+	if loaded.err == nil {
+		loaded.proj, loaded.err = project.LoadFromPath(loaded.configPath)
+	}
+	d.loaded[info.Name] = loaded
+	return loaded
+}
+
+// versions returns the highlighted project's versions, newest first
+func (d *dashboard) versions() []*project.Version {
+	loaded := d.project()
+	if loaded == nil || loaded.proj == nil {
+		return nil
+	}
+	versions := make([]*project.Version, 0, len(loaded.proj.Versions))
+	for i := len(loaded.proj.Versions) - 1; i >= 0; i-- {
+		versions = append(versions, &loaded.proj.Versions[i])
+	}
+	return versions
+}
+
+func (d *dashboard) currentVersion() *project.Version {
+	versions := d.versions()
+	if d.commitCursor >= len(versions) {
+		return nil
+	}
+	return versions[d.commitCursor]
+}
+
+// versionChanges loads the asset tracking for a version, once per version
+func (d *dashboard) versionChanges(proj *project.Project, v *project.Version) *dashboardChanges {
+	key := fmt.Sprintf("%s#%d", proj.ProjectName, v.Number)
+	if changes, ok := d.changes[key]; ok {
+		return changes
+	}
+	changes := &dashboardChanges{}
+	changes.track, changes.err = proj.LoadVersionTracking(v.Number)
+	d.changes[key] = changes
+	return changes
+}
+
+// versionComps reads the compositions of a version's .aepx, once per version
+func (d *dashboard) versionComps(proj *project.Project, number int) *dashboardComps {
+	key := fmt.Sprintf("%s#%d", proj.ProjectName, number)
+	if comps, ok := d.comps[key]; ok {
+		return comps
+	}
+	comps := &dashboardComps{}
+	path, cleanup, err := proj.FetchVersionFile(number)
+	if err == nil {
+		comps.comps, err = aepx.ExtractComps(path)
+		cleanup()
+	}
+	comps.err = err
+	d.comps[key] = comps
+	return comps
+}
+
+// previousVersion returns the version committed before v, or nil for the first one
+func previousVersion(proj *project.Project, v *project.Version) *project.Version {
+	for i := range proj.Versions {
+		if proj.Versions[i].Number == v.Number && i > 0 {
+			return &proj.Versions[i-1]
+		}
+	}
+	return nil
+}
+
+// switchProject makes the highlighted project the current one, as 'vervids list' does
+func (d *dashboard) switchProject() {
+	loaded := d.project()
+	if loaded == nil {
+		return
+	}
+	if loaded.err != nil {
+		d.status = errorMsg(i18n.T("Could not find config file for project: %s", d.currentProject().Name))
+		return
+	}
+	if err := setProjectContext(loaded.proj, loaded.configPath); err != nil {
+		d.status = errorMsg(i18n.T("Error saving context: %v", err))
+		return
+	}
+	d.pane = paneCommits
+	d.status = successMsg(i18n.T("Switched to project: %s", loaded.proj.ProjectName))
+}
+
+// pull restores the highlighted version into the current directory, like 'vervids pull'
+func (d *dashboard) pull() tea.Cmd {
+	loaded := d.project()
+	v := d.currentVersion()
+	if loaded == nil || loaded.proj == nil || v == nil {
+		d.status = warningMsg(i18n.T("Select a version to pull"))
+		return nil
+	}
+	outputDir, err := os.Getwd()
+	if err != nil {
+		d.status = errorMsg(i18n.T("Error getting absolute path: %v", err))
+		return nil
+	}
+	return d.suspend(func() {
+		fmt.Println(infoMsg(i18n.T("📦 Pulling version %d...", v.Number)))
+		restoredPath, err := loaded.proj.RestoreVersion(v.Number, outputDir, project.RestoreOptions{})
+		if err != nil {
+			fmt.Println(errorMsg(i18n.T("Error pulling version: %v", err)))
+			return
+		}
+		fmt.Println()
+		fmt.Println(successMsg(i18n.T("✓ Successfully pulled version %d", v.Number)))
+		fmt.Printf("  Project file: %s\n", restoredPath)
+	}, nil)
+}
+
+// tag asks for a name and tags the highlighted version with it, like 'vervids tag'
+func (d *dashboard) tag() tea.Cmd {
+	loaded := d.project()
+	v := d.currentVersion()
+	if loaded == nil || loaded.proj == nil || v == nil {
+		d.status = warningMsg(i18n.T("Select a version to tag"))
+		return nil
+	}
+	configPath := loaded.configPath
+	if configPath == "" {
+		// The history browser shows the current project
+		context, err := storage.LoadContext()
+		if err != nil {
+			d.status = errorMsg(i18n.T("Error loading project context: %v", err))
+			return nil
+		}
+		configPath = context.ConfigPath
+	}
+	number := v.Number
+	return d.suspend(func() {
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Print(infoMsg(i18n.T("Tag name for version %d: ", number)))
+		input, _ := reader.ReadString('\n')
+		name := strings.TrimSpace(input)
+		if name == "" {
+			fmt.Println(infoMsg(i18n.T("No tag added")))
+			return
+		}
+		force := false
+		if tagged := loaded.proj.FindTag(name); tagged != nil && tagged.Number != number {
+			fmt.Print(warningMsg(i18n.T("Tag %s is on version %d. Move it? (y/N): ", name, tagged.Number)))
+			answer, _ := reader.ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				fmt.Println(infoMsg(i18n.T("No tag added")))
+				return
+			}
+			force = true
+		}
+
+		var from int
+		err := inProjectDirectory(configPath, func() error {
+			// Reload: another command may have written to the project since it was shown
+			proj, err := project.LoadFromPath(configPath)
+			if err != nil {
+				return err
+			}
+			if from, err = tagVersion(proj, name, number, force); err != nil {
+				return err
+			}
+			loaded.proj = proj
+			return nil
+		})
+		if err != nil {
+			fmt.Println(errorMsg(i18n.T("Error: %v", err)))
+			return
+		}
+		fmt.Println(successMsg(tagResult(name, from, number)))
+	}, nil)
+}
+
+// deleteProject deletes the highlighted project, like 'vervids delete'
+func (d *dashboard) deleteProject() tea.Cmd {
+	info := d.currentProject()
+	if info == nil {
+		return nil
+	}
+	return d.suspend(func() {
+		if err := checkProjectWritable(info.Name); err != nil {
+			fmt.Println(errorMsg(i18n.T("%v", err)))
+			return
+		}
+		fmt.Println(infoMsg(i18n.T("🗑️  Deleting project...")))
+		if err := project.DeleteProjectByName(info.Name, info.DockerPath); err != nil {
+			fmt.Println(errorMsg(i18n.T("Error deleting project: %v", err)))
+			return
+		}
+		fmt.Println(successMsg(i18n.T("Project deleted successfully")))
+	}, d.reload)
+}
+
+// reload rereads the project list from Docker storage and drops cached configs
+func (d *dashboard) reload() {
+	projects, err := project.GetAllProjects()
+	if err != nil {
+		d.status = errorMsg(i18n.T("Error getting projects: %v", err))
+		return
+	}
+	d.projects = projects
+	d.loaded = map[string]*dashboardProject{}
+	d.changes = map[string]*dashboardChanges{}
+	d.comps = map[string]*dashboardComps{}
+	d.projectCursor = clampCursor(d.projectCursor, len(projects))
+	d.commitCursor = 0
+}
+
+func (d *dashboard) View() string {
+	width, height := d.width, d.height
+	if width < 40 || height < 10 {
+		width, height = 100, 30
+	}
+	// Borders take two rows, and the status and key lines one each
+	paneHeight := height - 4
+	keys := i18n.T("↑/↓ move  ←/→ switch pane  enter select project  a assets  p pull  t tag  d delete  r reload  q quit")
+
+	var panes string
+	if d.history {
+		commitsWidth := width * 2 / 5
+		panes = lipgloss.JoinHorizontal(lipgloss.Top,
+			d.renderPane(d.commitLines(), commitsWidth, paneHeight, d.pane == paneCommits),
+			d.renderPane(d.detailLines(), width-commitsWidth, paneHeight, d.pane == paneAssets),
+		)
+		keys = i18n.T("↑/↓ move  a assets  p pull  t tag  q quit")
+	} else {
+		projectsWidth := width / 4
+		commitsWidth := width * 3 / 8
+		panes = lipgloss.JoinHorizontal(lipgloss.Top,
+			d.renderPane(d.projectLines(), projectsWidth, paneHeight, d.pane == paneProjects),
+			d.renderPane(d.commitLines(), commitsWidth, paneHeight, d.pane == paneCommits),
+			d.renderPane(d.detailLines(), width-projectsWidth-commitsWidth, paneHeight, d.pane == paneAssets),
+		)
+	}
+	if d.pane == paneAssets {
+		keys = i18n.T("↑/↓ scroll  ← back  p pull  q quit")
+	}
+	return panes + "\n" + ui.Truncate(d.status, width) + "\n" + ui.InfoStyle.Render(ui.Truncate(keys, width))
+}
+
+// paneLines are a pane's title, the lines under it that don't scroll, and rows, with
+// the row the cursor is on (-1 for none)
+type paneLines struct {
+	title  string
+	header []string
+	rows   []string
+	cursor int
+}
+
+func (d *dashboard) renderPane(lines paneLines, width int, height int, focused bool) string {
+	inner := width - 4 // Border and padding
+	borderColor := lipgloss.TerminalColor(lipgloss.Color("240"))
+	if focused {
+		borderColor = ui.AccentStyle.GetForeground()
+	}
+
+	out := []string{lipgloss.NewStyle().Bold(true).Render(ui.Truncate(lines.title, inner))}
+	for _, line := range lines.header {
+		out = append(out, ui.Truncate(line, inner))
+	}
+	visible := height - len(out)
+	start := 0
+	if lines.cursor >= visible {
+		start = lines.cursor - visible + 1
+	}
+	for i := start; i < len(lines.rows) && i < start+visible; i++ {
+		row := ui.Truncate(lines.rows[i], inner-2)
+		if i == lines.cursor {
+			marker := "  "
+			if focused {
+				marker = "▸ "
+			}
+			out = append(out, ui.AccentStyle.Bold(focused).Render(marker+row))
+			continue
+		}
+		out = append(out, "  "+row)
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Padding(0, 1).
+		Width(width - 2).
+		Height(height).
+		MaxHeight(height + 2).
+		Render(strings.Join(out, "\n"))
+}
+
+func (d *dashboard) projectLines() paneLines {
+	lines := paneLines{title: i18n.T("Projects (%d)", len(d.projects)), cursor: d.projectCursor}
+	current := ""
+	if context, err := storage.LoadContext(); err == nil {
+		current = strings.ToLower(context.ProjectName)
+	}
+	for _, p := range d.projects {
+		name := p.Name
+		if current != "" && (strings.Contains(current, strings.ToLower(p.Name)) || strings.Contains(strings.ToLower(p.Name), current)) {
+			name = "→ " + name
+		}
+		lines.rows = append(lines.rows, name)
+	}
+	if len(d.projects) == 0 {
+		lines.rows = []string{i18n.T("No projects found in Docker storage.")}
+		lines.cursor = -1
+	}
+	return lines
+}
+
+func (d *dashboard) commitLines() paneLines {
+	lines := paneLines{title: i18n.T("Commits"), cursor: d.commitCursor}
+	loaded := d.project()
+	if loaded == nil {
+		lines.cursor = -1
+		return lines
+	}
+	if loaded.err != nil {
+		lines.rows = []string{i18n.T("Local config not found"), i18n.T("Open the project directory and run vervids from there.")}
+		lines.cursor = -1
+		return lines
+	}
+	versions := d.versions()
+	lines.title = i18n.T("Commits (%d)", len(versions))
+	for _, v := range versions {
+		lines.rows = append(lines.rows, fmt.Sprintf("v%03d  %s  %s", v.Number, v.Timestamp.Format("2006-01-02 15:04"), v.Message))
+	}
+	if len(versions) == 0 {
+		lines.rows = []string{i18n.T("No commits yet")}
+		lines.cursor = -1
+	}
+	return lines
+}
+
+func (d *dashboard) detailLines() paneLines {
+	if d.pane == paneProjects && !d.history {
+		return d.notesLines()
+	}
+	if d.pane == paneAssets {
+		return d.assetLines()
+	}
+	lines := paneLines{title: i18n.T("Version"), cursor: -1}
+	loaded := d.project()
+	v := d.currentVersion()
+	if loaded == nil || loaded.proj == nil || v == nil {
+		return lines
+	}
+
+	lines.title = fmt.Sprintf("v%03d  %s", v.Number, v.Message)
+	lines.rows = []string{
+		i18n.T("Time: %s", v.Timestamp.Format("2006-01-02 15:04:05")),
+		i18n.T("Project file: %.2f MB", float64(v.Size)/(1024*1024)),
+		i18n.T("Assets: %d files", v.AssetCount),
+		i18n.T("Total size: %.2f MB", float64(v.TotalSize)/(1024*1024)),
+	}
+	if v.Author != "" {
+		lines.rows = append(lines.rows, i18n.T("Author: %s", v.Author))
+	}
+	if v.AppVersion != nil {
+		lines.rows = append(lines.rows, i18n.T("Saved by: %s", v.AppVersion))
+	}
+	if len(v.Labels) > 0 {
+		lines.rows = append(lines.rows, i18n.T("Labels: %s", strings.Join(v.Labels, ", ")))
+	}
+	if len(v.Tags) > 0 {
+		lines.rows = append(lines.rows, i18n.T("Tags: %s", strings.Join(v.Tags, ", ")))
+	}
+	if len(v.Meta) > 0 {
+		lines.rows = append(lines.rows, i18n.T("Meta: %s", strings.Join(project.MetaPairs(v.Meta), ", ")))
+	}
+	if len(v.Missing) > 0 {
+		lines.rows = append(lines.rows, ui.WarningStyle.Render(i18n.T("Missing: %d asset(s) not stored", len(v.Missing))))
+	}
+
+	lines.rows = append(lines.rows, "", i18n.T("Asset changes:"))
+	lines.rows = append(lines.rows, d.assetChangeLines(loaded.proj, v)...)
+
+	prev := previousVersion(loaded.proj, v)
+	if prev == nil {
+		return lines
+	}
+	lines.rows = append(lines.rows, "", i18n.T("Composition changes since v%d:", prev.Number))
+	lines.rows = append(lines.rows, d.compChangeLines(loaded.proj, prev, v)...)
+	return lines
+}
+
+// notesLines shows the highlighted project's notes while the projects pane has the cursor
+func (d *dashboard) notesLines() paneLines {
+	lines := paneLines{title: i18n.T("Notes"), cursor: -1}
+	loaded := d.project()
+	if loaded == nil || loaded.proj == nil {
+		return lines
+	}
+	lines.title = i18n.T("Notes: %s", loaded.proj.ProjectName)
+	if loaded.proj.Notes == "" {
+		lines.rows = []string{i18n.T("No notes yet. Use 'vervids notes edit' to add some.")}
+		return lines
+	}
+	lines.rows = strings.Split(loaded.proj.Notes, "\n")
+	return lines
+}
+
+// assetLines lists every asset of the highlighted version, as 'vervids show --assets'
+// does, then the ones missing at commit
+func (d *dashboard) assetLines() paneLines {
+	lines := paneLines{title: i18n.T("Assets"), cursor: d.assetCursor}
+	loaded := d.project()
+	v := d.currentVersion()
+	if loaded == nil || loaded.proj == nil || v == nil {
+		lines.cursor = -1
+		return lines
+	}
+	lines.title = i18n.T("Assets in v%03d (%d)", v.Number, len(v.Assets))
+	var added int64
+	for _, a := range v.Assets {
+		if !a.Purged && loaded.proj.IsNewAsset(v.Number, a.Hash) {
+			added += a.Size
+		}
+	}
+	if v.TotalSize > 0 {
+		// How much of the version it stored itself rather than reused
+		bar := ui.NewProgressBar(24).ViewAs(float64(added) / float64(v.TotalSize))
+		lines.header = []string{i18n.T("New content: %s  %.2f of %.2f MB", bar, float64(added)/(1024*1024), float64(v.TotalSize)/(1024*1024)), ""}
+	}
+	for _, a := range v.Assets {
+		name := a.Filename
+		if a.RelativePath != "" {
+			name = a.RelativePath
+		}
+		row := fmt.Sprintf("%s  %.2f MB", name, float64(a.Size)/(1024*1024))
+		switch {
+		case a.Purged:
+			row += "  " + ui.WarningStyle.Render(i18n.T("purged"))
+		case a.ProxyOnly:
+			row += "  " + ui.InfoStyle.Render(i18n.T("proxy only"))
+		case !loaded.proj.IsNewAsset(v.Number, a.Hash):
+			row += "  " + ui.InfoStyle.Render(i18n.T("reused"))
+		}
+		lines.rows = append(lines.rows, row)
+	}
+	for _, m := range v.Missing {
+		lines.rows = append(lines.rows, ui.WarningStyle.Render(i18n.T("missing: %s", m)))
+	}
+	if len(lines.rows) == 0 {
+		lines.rows = []string{i18n.T("No assets in this version")}
+		lines.cursor = -1
+	}
+	return lines
+}
+
+// compChangeLines lists the compositions added, removed, renamed or changed from prev to v
+func (d *dashboard) compChangeLines(proj *project.Project, prev *project.Version, v *project.Version) []string {
+	if !assets.IsAEPX(prev.DockerPath) || !assets.IsAEPX(v.DockerPath) {
+		return []string{i18n.T("Not available: only .aepx projects have compositions")}
+	}
+	oldComps := d.versionComps(proj, prev.Number)
+	newComps := d.versionComps(proj, v.Number)
+	for _, c := range []*dashboardComps{oldComps, newComps} {
+		if c.err != nil {
+			return []string{i18n.T("Not available: %v", c.err)}
+		}
+	}
+	changes := aepx.DiffComps(oldComps.comps, newComps.comps)
+	if len(changes) == 0 {
+		return []string{i18n.T("No composition changes")}
+	}
+	return compChangeLines(changes)
+}
+
+// assetChangeLines lists the assets new, modified, reinterpreted, renamed or removed in v
+func (d *dashboard) assetChangeLines(proj *project.Project, v *project.Version) []string {
+	changes := d.versionChanges(proj, v)
+	if changes.err != nil {
+		return []string{i18n.T("Not available: %v", changes.err)}
+	}
+	markers := map[string]string{
+		"new":           ui.SuccessStyle.Render("+"),
+		"modified":      ui.WarningStyle.Render("~"),
+		"reinterpreted": ui.WarningStyle.Render("*"),
+		"renamed":       ui.WarningStyle.Render("→"),
+		"removed":       ui.ErrorStyle.Render("-"),
+	}
+	rows := []string{}
+	for _, status := range []string{"new", "modified", "reinterpreted", "renamed", "removed"} {
+		for _, a := range assetsWithStatus(changes.track, status) {
+			name := a.Filename
+			if a.RelativePath != "" {
+				name = a.RelativePath
+			}
+			rows = append(rows, fmt.Sprintf("%s %s  %.2f MB", markers[status], name, float64(a.Size)/(1024*1024)))
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, i18n.T("No asset changes (%d unchanged)", changes.track.PresentAssets))
+	}
+	return rows
+}
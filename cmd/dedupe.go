@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find and consolidate identical assets stored more than once",
+	Long: `Identical content is normally stored once, but the same file committed under another
+name or extension (clip.mov and clip_final.mp4), or copies from before the
+content-addressed pool existed, can end up stored several times.
+
+'vervids dedupe report' groups the current project's stored assets by SHA-256 and lists
+the content stored under more than one path, with the space the extra copies take.
+'vervids dedupe consolidate' points every version and pin at one copy of each and
+deletes the rest. Both copies are checked against the hash first, and a copy is only
+deleted once config.json no longer refers to it. Proxies and chunked assets are left
+alone.
+
+Example:
+  vervids dedupe report
+  vervids dedupe consolidate --dry-run
+  vervids dedupe consolidate`,
+}
+
+var dedupeReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "List content stored under more than one path",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, groups := dedupeGroups(cmd)
+		if len(groups) == 0 {
+			fmt.Println(successMsg(i18n.T("No duplicate assets in %s", proj.ProjectName)))
+			return
+		}
+		printDuplicateGroups(groups)
+		fmt.Println(infoMsg(i18n.T("Run 'vervids dedupe consolidate' to keep one copy of each")))
+	},
+}
+
+var dedupeConsolidateCmd = &cobra.Command{
+	Use:   "consolidate",
+	Short: "Keep one copy of each duplicated asset and update references",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, groups := dedupeGroups(cmd)
+		if len(groups) == 0 {
+			fmt.Println(successMsg(i18n.T("No duplicate assets in %s", proj.ProjectName)))
+			return
+		}
+		printDuplicateGroups(groups)
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
+		}
+		defer cleanup()
+
+		result, err := proj.Consolidate(groups)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		for _, failure := range result.Failed {
+			fmt.Println(warningMsg(failure))
+		}
+		fmt.Println(successMsg(i18n.T("Consolidated %d copy(ies), freed %.2f MB", result.Consolidated, float64(result.Freed)/(1024*1024))))
+		if len(result.Failed) > 0 {
+			exitWithError(nil, i18n.T("%d copy(ies) could not be consolidated", len(result.Failed)),
+				i18n.T("Run 'vervids fsck' to check the project's storage."))
+		}
+	},
+}
+
+// dedupeGroups loads the current project and finds its duplicated content
+func dedupeGroups(cmd *cobra.Command) (*project.Project, []project.DuplicateGroup) {
+	proj, err := ensureProjectContext()
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); cmd.Name() == "consolidate" && !dryRun {
+		// Refuse up front, before hashing anything in storage
+		if err := proj.CheckWritable(); err != nil {
+			exitWithError(err, i18n.T("%v", err))
+		}
+	}
+	hashMissing, _ := cmd.Flags().GetBool("hash-missing")
+	if hashMissing || cmd.Name() == "consolidate" {
+		if err := docker.EnsureDockerReady(); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+	}
+	groups, unhashed, err := proj.Duplicates(hashMissing)
+	if err != nil {
+		exitWithError(err, i18n.T("Error checking storage: %v", err))
+	}
+	if unhashed > 0 {
+		fmt.Println(warningMsg(i18n.T("%d stored object(s) have no recorded hash and weren't compared; add --hash-missing to hash them in storage", unhashed)))
+	}
+	return proj, groups
+}
+
+// printDuplicateGroups lists each duplicated content's kept copy and redundant copies
+func printDuplicateGroups(groups []project.DuplicateGroup) {
+	rows := [][]string{}
+	var wasted int64
+	copies := 0
+	for _, g := range groups {
+		rows = append(rows, []string{g.Hash[:12], fmt.Sprintf("%.2f", float64(g.Size)/(1024*1024)), "keep", storedName(g.Keep.DockerPath), versionRanges(g.Keep.Versions)})
+		for _, c := range g.Copies {
+			rows = append(rows, []string{"", "", "drop", storedName(c.DockerPath), versionRanges(c.Versions)})
+		}
+		wasted += g.Wasted()
+		copies += len(g.Copies)
+	}
+	fmt.Println(ui.Table([]string{"Hash", "Size(MB)", "Action", "Stored as", "Versions"}, rows, 2))
+	fmt.Println(warningMsg(i18n.T("%d duplicate copy(ies) of %d file(s) take up %.2f MB", copies, len(groups), float64(wasted)/(1024*1024))))
+}
+
+// storedName shortens an object path to its place in the project's storage
+func storedName(dockerPath string) string {
+	parts := strings.SplitN(strings.TrimPrefix(dockerPath, docker.StoragePath+"/"), "/", 2)
+	if len(parts) == 2 {
+		return parts[1]
+	}
+	return filepath.Base(dockerPath)
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// demoSandbox names the demo's storage container and volume
+const demoSandbox = "demo"
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Walk through init, commit, diff and pull in a throwaway sandbox",
+	Long: `Run the whole pipeline on a sample project without touching real ones: a separate
+storage container and volume (vervids-demo-storage, vervids-demo-data) are started,
+a sample .aepx referencing generated footage is written to a temporary folder, and
+the project is initialized, edited and committed, diffed and pulled back, checking
+each result along the way. Everything is removed afterwards.
+
+Settings, the project context and the cache are kept in the sandbox too, so the
+current project and any configured remote are left alone. Handy for showing an
+artist how vervids works, or checking an install end to end.
+
+With --keep, the sandbox is left in place to look around in; 'vervids demo --clean'
+removes it later.
+
+Example:
+  vervids demo
+  vervids demo --keep
+  vervids demo --clean`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		docker.UseSandbox(demoSandbox)
+
+		if clean, _ := cmd.Flags().GetBool("clean"); clean {
+			if err := removeDemo(""); err != nil {
+				exitWithError(err, i18n.T("Error removing the demo sandbox: %v", err))
+			}
+			fmt.Println(successMsg(i18n.T("Removed the demo sandbox")))
+			return
+		}
+
+		if err := docker.EnsureDockerReady(); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err), i18n.T("The demo stores its versions in Docker."))
+		}
+		dir, err := storage.MkdirTemp("demo-")
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		restore, err := isolateDemo(dir)
+		if err != nil {
+			os.RemoveAll(dir)
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		err = runDemo(dir)
+		restore()
+
+		fmt.Println()
+		if keep, _ := cmd.Flags().GetBool("keep"); keep {
+			fmt.Println(infoMsg(i18n.T("Sandbox kept: files in %s, storage in container %s", dir, docker.ContainerName)))
+			fmt.Println(infoMsg(i18n.T("Run 'vervids demo --clean' to remove it.")))
+		} else if rerr := removeDemo(dir); rerr != nil {
+			fmt.Println(warningMsg(i18n.T("Could not remove the demo sandbox: %v", rerr)))
+			fmt.Println(infoMsg(i18n.T("Run 'vervids demo --clean' to try again.")))
+		} else {
+			fmt.Println(successMsg(i18n.T("Removed the demo sandbox")))
+		}
+
+		if err != nil {
+			exitWithError(err, i18n.T("Demo failed: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("Demo finished: init, commit, diff and pull all work")))
+	},
+}
+
+// isolateDemo points the user directory, cache and working directory into dir for the
+// rest of the run. The returned function puts them back.
+func isolateDemo(dir string) (func(), error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	env := map[string]string{}
+	for _, key := range []string{"HOME", "USERPROFILE", storage.CacheDirEnv} {
+		env[key] = os.Getenv(key)
+	}
+
+	os.Setenv("HOME", dir)
+	os.Setenv("USERPROFILE", dir)
+	os.Unsetenv(storage.CacheDirEnv)
+	storage.SetCacheDir(filepath.Join(dir, "cache"))
+	return func() {
+		os.Chdir(cwd)
+		for key, value := range env {
+			if value == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, value)
+			}
+		}
+		storage.SetCacheDir(loadSettings().CacheDir)
+	}, nil
+}
+
+// removeDemo deletes the sandbox container and volume, and dir, or every leftover demo
+// folder when dir is empty
+func removeDemo(dir string) error {
+	if err := docker.RemoveSandbox(); err != nil {
+		return err
+	}
+	dirs := []string{dir}
+	if dir == "" {
+		dirs, _ = filepath.Glob(filepath.Join(storage.TempDir(), storage.TempPrefix+"demo-*"))
+	}
+	for _, d := range dirs {
+		if err := os.RemoveAll(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// demoStep prints the heading of one stage of the demo
+func demoStep(n int, title string) {
+	fmt.Println()
+	fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("[%d/5] %s", n, title)))
+}
+
+// demoCheck fails the demo unless ok, or prints what was confirmed
+func demoCheck(ok bool, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if !ok {
+		return fmt.Errorf("expected %s", msg)
+	}
+	fmt.Println(successMsg("  " + msg))
+	return nil
+}
+
+// runDemo walks through the pipeline in dir, stopping at the first step that doesn't
+// do what it should
+func runDemo(dir string) error {
+	projectDir := filepath.Join(dir, "project")
+	aepxPath := filepath.Join(projectDir, "demo.aepx")
+
+	demoStep(1, i18n.T("Generating a sample project"))
+	if err := writeDemoProject(projectDir, false); err != nil {
+		return err
+	}
+	fmt.Printf("  %s with 3 pieces of footage in %s\n", filepath.Base(aepxPath), filepath.Join(projectDir, "footage"))
+	if err := os.Chdir(projectDir); err != nil {
+		return err
+	}
+
+	demoStep(2, i18n.T("vervids init"))
+	proj, err := project.Initialize(aepxPath, project.InitOptions{})
+	if err != nil {
+		return fmt.Errorf("init: %w", err)
+	}
+	if err := demoCheck(len(proj.Versions) == 1 && proj.Versions[0].AssetCount == 3, "v0 stored with 3 assets"); err != nil {
+		return err
+	}
+
+	demoStep(3, i18n.T("vervids commit"))
+	if err := writeDemoProject(projectDir, true); err != nil {
+		return err
+	}
+	fmt.Println("  Regraded the logo, added a title card and its layer")
+	v, err := proj.CommitWithOptions("Add title card", aepxPath, project.CommitOptions{})
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	if err := demoCheck(v.Number == 1 && v.AssetCount == 4, "v1 stored with 4 assets"); err != nil {
+		return err
+	}
+	if cs, err := proj.CommitStorage(v.Number); err == nil {
+		if err := demoCheck(cs.ReusedSize > 0, "unchanged footage reused from v0 (%.2f MB)", float64(cs.ReusedSize)/(1024*1024)); err != nil {
+			return err
+		}
+	}
+
+	demoStep(4, i18n.T("vervids diff 0 1"))
+	diff, err := proj.DiffVersions(0, 1)
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	printTrackingGroups(diff.Assets, false)
+	if err := demoCheck(diff.Assets.NewAssets == 1 && diff.Assets.ModifiedAssets == 1, "1 asset added and 1 modified"); err != nil {
+		return err
+	}
+	if err := demoCheck(diff.Parsed && len(diff.Layers) == 1, "1 layer added to Main Comp"); err != nil {
+		return err
+	}
+
+	demoStep(5, i18n.T("vervids pull 0"))
+	// Without the footage on disk, the pull has to bring it back out of storage
+	if err := os.RemoveAll(filepath.Join(projectDir, "footage")); err != nil {
+		return err
+	}
+	pullDir := filepath.Join(dir, "pulled")
+	restored, err := proj.RestoreVersion(0, pullDir, project.RestoreOptions{})
+	if err != nil {
+		return fmt.Errorf("pull: %w", err)
+	}
+	_, err = os.Stat(restored)
+	if err := demoCheck(err == nil, "%s restored to %s", filepath.Base(restored), pullDir); err != nil {
+		return err
+	}
+	for _, a := range proj.Versions[0].Assets {
+		hash, err := storage.HashFile(filepath.Join(pullDir, "assets", a.Filename))
+		if err != nil || hash != a.Hash {
+			return fmt.Errorf("expected %s restored with its v0 content", a.Filename)
+		}
+	}
+	return demoCheck(true, "footage restored with its v0 content, the original logo included")
+}
+
+// writeDemoProject writes the sample .aepx and the footage it references into dir:
+// one comp over a plate, a logo and music, and after the edit a regraded logo and a
+// title card with a layer of its own. Footage is random bytes, seeded so it's the same
+// every run.
+func writeDemoProject(dir string, edited bool) error {
+	footageDir := filepath.Join(dir, "footage")
+	if err := os.MkdirAll(footageDir, 0755); err != nil {
+		return err
+	}
+
+	footage := []struct {
+		name string
+		size int
+		seed int64
+	}{
+		{"plate.mov", 2 << 20, 1},
+		{"logo.png", 64 << 10, 2},
+		{"music.wav", 512 << 10, 3},
+	}
+	layers := []string{"Plate", "Logo", "Music"}
+	if edited {
+		footage[1].seed = 4
+		footage = append(footage, struct {
+			name string
+			size int
+			seed int64
+		}{"title.psd", 128 << 10, 5})
+		layers = append(layers, "Title")
+	}
+
+	var items strings.Builder
+	for _, f := range footage {
+		path := filepath.Join(footageDir, f.name)
+		data := make([]byte, f.size)
+		rand.New(rand.NewSource(f.seed)).Read(data)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(&items, "    <Item>\n      <string>%s</string>\n      <Pin><fileReference fullpath=\"%s\"/></Pin>\n    </Item>\n",
+			html.EscapeString(f.name), html.EscapeString(path))
+	}
+
+	var comp strings.Builder
+	// idta: item type 4 (composition) and comp ID 1
+	comp.WriteString("    <Item>\n      <string>Main Comp</string>\n      <idta bdata=\"0004000000000000000000000000000000000001\"/>\n")
+	for _, name := range layers {
+		fmt.Fprintf(&comp, "      <Layr><string>%s</string></Layr>\n", name)
+	}
+	comp.WriteString("    </Item>\n")
+
+	aepx := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+		"<AfterEffectsProject xmlns=\"http://www.adobe.com/products/aftereffects\">\n  <Fold>\n" +
+		comp.String() + items.String() +
+		"  </Fold>\n</AfterEffectsProject>\n"
+	return os.WriteFile(filepath.Join(dir, "demo.aepx"), []byte(aepx), 0644)
+}
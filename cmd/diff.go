@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <v1> [v2]",
+	Short: "Compare two versions, or a working file against a version",
+	Long: `Compare two committed versions of the project.
+
+Without flags, everything that changed is listed: assets added, modified,
+reinterpreted, renamed or removed, then, for .aepx projects, compositions added,
+removed or changed, layers added, removed or modified in each comp, and effects
+added, removed or changed. Both versions' .aepx files are fetched from Docker
+storage and parsed for this. Versions can be given by number or by tag.
+
+With --comps, compositions are compared by their internal ID: comps added, removed
+or renamed are listed, along with changes to each comp's layer count, resolution,
+duration and frame rate.
+
+With --expressions, the expression on every layer property is extracted from both
+versions' .aepx files and the properties whose expressions were added, removed or
+edited are listed, with the old and new text.
+
+With --working <file.aepx>, an .aepx on disk is compared against one stored version
+instead, to see how far it has drifted from it: assets added, modified or removed
+since that version, then composition changes (or only what --comps/--expressions ask for).
+
+Example:
+  vervids diff 2 5
+  vervids diff client-review-1 final-v2
+  vervids diff --comps 2 5
+  vervids diff --expressions 2 5
+  vervids diff 4 --working "/path/to/exported.aepx"`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if working, _ := cmd.Flags().GetString("working"); working != "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get project from context (already ensured by PersistentPreRunE)
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		from := parseVersionArg(proj, args[0])
+		fromLabel := fmt.Sprintf("v%d", from)
+
+		expressions, _ := cmd.Flags().GetBool("expressions")
+		comps, _ := cmd.Flags().GetBool("comps")
+		working, _ := cmd.Flags().GetString("working")
+
+		var newPath, toLabel string
+		if working != "" {
+			newPath, err = filepath.Abs(working)
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			if _, err := os.Stat(newPath); err != nil {
+				exitWithError(nil, i18n.T("File '%s' does not exist", working))
+			}
+			toLabel = filepath.Base(newPath)
+			// Compositions are the default view of a working .aepx file's drift
+			comps = comps || (!expressions && assets.IsAEPX(newPath))
+
+			cleanup, err := changeToProjectDirectory()
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			pending, err := proj.PendingChangesSince(newPath, from)
+			cleanup()
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			printAssetDrift(pending, fromLabel, toLabel)
+			if !comps && !expressions {
+				return
+			}
+			fmt.Println()
+		} else if !expressions && !comps {
+			printVersionDiff(proj, from, parseVersionArg(proj, args[1]))
+			return
+		}
+
+		oldPath, oldCleanup, err := proj.FetchVersionFile(from)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer oldCleanup()
+		if working == "" {
+			to := parseVersionArg(proj, args[1])
+			toLabel = fmt.Sprintf("v%d", to)
+			var newCleanup func()
+			newPath, newCleanup, err = proj.FetchVersionFile(to)
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			defer newCleanup()
+		}
+		if !assets.IsAEPX(oldPath) || !assets.IsAEPX(newPath) {
+			exitWithError(nil, i18n.T("Comps and expressions can only be compared between .aepx files"))
+		}
+
+		if comps {
+			if err := printCompDiff(oldPath, newPath, fromLabel, toLabel); err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+		}
+		if comps && expressions {
+			fmt.Println()
+		}
+		if expressions {
+			if err := printExpressionDiff(oldPath, newPath, fromLabel, toLabel); err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+		}
+	},
+}
+
+// printVersionDiff prints everything that changed between two versions: assets, then
+// compositions, layers and effects when both project files are .aepx
+func printVersionDiff(proj *project.Project, from int, to int) {
+	diff, err := proj.DiffVersions(from, to)
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+	fromLabel, toLabel := fmt.Sprintf("v%d", from), fmt.Sprintf("v%d", to)
+
+	fmt.Printf("%s %s → %s\n", ui.InfoStyle.Render("Changes from"), fromLabel, toLabel)
+	fmt.Println()
+	printTrackingGroups(diff.Assets, false)
+
+	if !diff.Parsed {
+		printTrackingTotals(diff.Assets)
+		fmt.Println(infoMsg(i18n.T("Compositions, layers and effects are only compared between .aepx files")))
+		return
+	}
+
+	if len(diff.Comps) > 0 {
+		fmt.Println(infoMsg(i18n.T("Compositions (%d):", len(diff.Comps))))
+		for _, line := range compChangeLines(diff.Comps) {
+			fmt.Printf("  %s\n", line)
+		}
+		fmt.Println()
+	}
+	if len(diff.Layers) > 0 {
+		fmt.Println(infoMsg(i18n.T("Layers (%d):", len(diff.Layers))))
+		for _, l := range diff.Layers {
+			fmt.Printf("  %s %s › %s\n", changeMarker(l.Status), l.Comp, l.Layer)
+		}
+		fmt.Println()
+	}
+	if len(diff.Effects) > 0 {
+		fmt.Println(infoMsg(i18n.T("Effects (%d):", len(diff.Effects))))
+		for _, e := range diff.Effects {
+			fmt.Printf("  %s %s\n", changeMarker(e.Status), e.Key)
+		}
+		fmt.Println()
+	}
+
+	printTrackingTotals(diff.Assets)
+	fmt.Printf("%s %d comp(s), %d layer(s), %d effect(s) changed\n",
+		ui.InfoStyle.Render("Project:"), len(diff.Comps), len(diff.Layers), len(diff.Effects))
+}
+
+// changeMarker returns the +/-/~ marker for an "added", "removed" or "modified" status
+func changeMarker(status string) string {
+	switch status {
+	case "added":
+		return ui.SuccessStyle.Render("+")
+	case "removed":
+		return ui.ErrorStyle.Render("-")
+	default:
+		return ui.WarningStyle.Render("~")
+	}
+}
+
+// printAssetDrift lists the assets of a working file that differ from a version
+func printAssetDrift(pending *project.PendingCommit, from, to string) {
+	changed := len(pending.Assets) - pending.Count("unchanged") + len(pending.Removed)
+	if changed == 0 && len(pending.Missing) == 0 {
+		fmt.Println(successMsg(i18n.T("No asset changes between %s and %s", from, to)))
+		return
+	}
+
+	fmt.Println(infoMsg(i18n.T("Asset changes from %s to %s:", from, to)))
+	printPendingAssets(pending)
+	fmt.Printf("%s %d new, %d modified, %d unchanged, %d removed, %d missing\n",
+		ui.InfoStyle.Render("Assets:"),
+		pending.Count("new"), pending.Count("modified"), pending.Count("unchanged"), len(pending.Removed), len(pending.Missing))
+}
+
+// parseVersionArg parses a version argument, a number or one of the project's tags,
+// exiting on invalid input
+func parseVersionArg(proj *project.Project, arg string) int {
+	num, err := proj.ResolveVersion(arg)
+	if err != nil {
+		exitWithError(err, i18n.T("%v", err))
+	}
+	return num
+}
+
+// printCompDiff prints compositions added, removed, renamed or changed between two .aepx files
+func printCompDiff(oldPath, newPath string, from, to string) error {
+	oldComps, err := aepx.ExtractComps(oldPath)
+	if err != nil {
+		return err
+	}
+	newComps, err := aepx.ExtractComps(newPath)
+	if err != nil {
+		return err
+	}
+
+	changes := aepx.DiffComps(oldComps, newComps)
+	if len(changes) == 0 {
+		fmt.Println(successMsg(i18n.T("No composition changes between %s and %s", from, to)))
+		return nil
+	}
+
+	fmt.Println(infoMsg(i18n.T("Composition changes from %s to %s:", from, to)))
+	for _, line := range compChangeLines(changes) {
+		fmt.Printf("  %s\n", line)
+	}
+	return nil
+}
+
+// compChangeLines formats composition changes one per line with a +/-/→/~ marker,
+// followed by their indented details
+func compChangeLines(changes []aepx.CompChange) []string {
+	lines := []string{}
+	for _, c := range changes {
+		switch c.Status {
+		case "added":
+			lines = append(lines, fmt.Sprintf("%s %s", ui.SuccessStyle.Render("+"), c.Name))
+		case "removed":
+			lines = append(lines, fmt.Sprintf("%s %s", ui.ErrorStyle.Render("-"), c.Name))
+		case "renamed":
+			lines = append(lines, fmt.Sprintf("%s %s → %s", ui.WarningStyle.Render("→"), c.OldName, c.Name))
+		default:
+			lines = append(lines, fmt.Sprintf("%s %s", ui.WarningStyle.Render("~"), c.Name))
+		}
+		for _, d := range c.Details {
+			lines = append(lines, "    "+d)
+		}
+	}
+	return lines
+}
+
+// printExpressionDiff prints layer expressions added, removed or edited between two .aepx files
+func printExpressionDiff(oldPath, newPath string, from, to string) error {
+	oldExprs, err := aepx.ExtractExpressions(oldPath)
+	if err != nil {
+		return err
+	}
+	newExprs, err := aepx.ExtractExpressions(newPath)
+	if err != nil {
+		return err
+	}
+
+	changes := aepx.DiffExpressions(oldExprs, newExprs)
+	if len(changes) == 0 {
+		fmt.Println(successMsg(i18n.T("No expression changes between %s and %s", from, to)))
+		return nil
+	}
+
+	fmt.Println(infoMsg(i18n.T("Expression changes from %s to %s:", from, to)))
+	for _, c := range changes {
+		fmt.Println()
+		switch c.Status {
+		case "added":
+			fmt.Printf("%s %s\n", ui.SuccessStyle.Render("+"), c.Key)
+			printExpression("+", c.New)
+		case "removed":
+			fmt.Printf("%s %s\n", ui.ErrorStyle.Render("-"), c.Key)
+			printExpression("-", c.Old)
+		default:
+			fmt.Printf("%s %s\n", ui.WarningStyle.Render("~"), c.Key)
+			printExpression("-", c.Old)
+			printExpression("+", c.New)
+		}
+	}
+	fmt.Println()
+	fmt.Println(infoMsg(i18n.T("%d expression(s) changed", len(changes))))
+	return nil
+}
+
+// printExpression prints expression text indented, with a +/- marker on each line
+func printExpression(marker string, text string) {
+	style := ui.SuccessStyle
+	if marker == "-" {
+		style = ui.ErrorStyle
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Printf("    %s\n", style.Render(marker+" "+strings.TrimRight(line, "\r")))
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var docsCmd = &cobra.Command{
+	Use:    "docs",
+	Short:  "Generate reference documentation from the command tree",
+	Hidden: true,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate man pages (section 1), one per command",
+	Long: `Generate a man page for every command, e.g. vervids.1 and vervids-commit.1, for
+packagers to install under share/man/man1. Set SOURCE_DATE_EPOCH for a reproducible date.
+
+Example:
+  vervids docs man --dir ./man`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		count, err := generateDocs(rootCmd, dir, ".1", manPage)
+		if err != nil {
+			exitWithError(err, fmt.Sprintf("Error generating man pages: %v", err))
+		}
+		fmt.Println(successMsg(fmt.Sprintf("Wrote %d man page(s) to %s", count, dir)))
+	},
+}
+
+var docsMarkdownCmd = &cobra.Command{
+	Use:   "markdown",
+	Short: "Generate Markdown reference docs, one file per command",
+	Long: `Generate a Markdown page for every command, e.g. vervids.md and vervids_commit.md,
+linked to each other.
+
+Example:
+  vervids docs markdown --dir ./docs`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		count, err := generateDocs(rootCmd, dir, ".md", markdownPage)
+		if err != nil {
+			exitWithError(err, fmt.Sprintf("Error generating Markdown docs: %v", err))
+		}
+		fmt.Println(successMsg(fmt.Sprintf("Wrote %d Markdown page(s) to %s", count, dir)))
+	},
+}
+
+// generateDocs renders every documented command under root into dir and returns the
+// number of files written
+func generateDocs(root *cobra.Command, dir string, ext string, render func(*cobra.Command) string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, c := range documentedCommands(root) {
+		path := filepath.Join(dir, docBaseName(c, ext)+ext)
+		if err := os.WriteFile(path, []byte(render(c)), 0644); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// documentedCommands returns root and every visible command below it
+func documentedCommands(root *cobra.Command) []*cobra.Command {
+	commands := []*cobra.Command{root}
+	for _, c := range root.Commands() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		commands = append(commands, documentedCommands(c)...)
+	}
+	return commands
+}
+
+// docBaseName is the file name for a command's page: vervids-pathmap-add for man
+// pages, vervids_pathmap_add for Markdown
+func docBaseName(c *cobra.Command, ext string) string {
+	sep := "_"
+	if ext == ".1" {
+		sep = "-"
+	}
+	return strings.ReplaceAll(c.CommandPath(), " ", sep)
+}
+
+// visibleChildren returns the command's documented subcommands, sorted by name
+func visibleChildren(c *cobra.Command) []*cobra.Command {
+	children := []*cobra.Command{}
+	for _, child := range c.Commands() {
+		if child.IsAvailableCommand() && !child.IsAdditionalHelpTopicCommand() {
+			children = append(children, child)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name() < children[j].Name() })
+	return children
+}
+
+func markdownPage(c *cobra.Command) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "## %s\n\n%s\n\n", c.CommandPath(), c.Short)
+	if c.Long != "" {
+		fmt.Fprintf(&buf, "### Synopsis\n\n%s\n\n", c.Long)
+	}
+	if c.Runnable() {
+		fmt.Fprintf(&buf, "```\n%s\n```\n\n", c.UseLine())
+	}
+	if c.Example != "" {
+		fmt.Fprintf(&buf, "### Examples\n\n```\n%s\n```\n\n", c.Example)
+	}
+	if flags := c.NonInheritedFlags(); flags.HasAvailableFlags() {
+		fmt.Fprintf(&buf, "### Options\n\n```\n%s```\n\n", flags.FlagUsages())
+	}
+	if flags := c.InheritedFlags(); flags.HasAvailableFlags() {
+		fmt.Fprintf(&buf, "### Options inherited from parent commands\n\n```\n%s```\n\n", flags.FlagUsages())
+	}
+
+	buf.WriteString("### SEE ALSO\n\n")
+	if c.HasParent() {
+		parent := c.Parent()
+		fmt.Fprintf(&buf, "* [%s](%s.md)\t - %s\n", parent.CommandPath(), docBaseName(parent, ".md"), parent.Short)
+	}
+	for _, child := range visibleChildren(c) {
+		fmt.Fprintf(&buf, "* [%s](%s.md)\t - %s\n", child.CommandPath(), docBaseName(child, ".md"), child.Short)
+	}
+	return buf.String()
+}
+
+func manPage(c *cobra.Command) string {
+	var buf bytes.Buffer
+	title := strings.ToUpper(docBaseName(c, ".1"))
+	fmt.Fprintf(&buf, ".TH \"%s\" \"1\" \"%s\" \"vervids %s\" \"vervids Manual\"\n", title, docDate().Format("Jan 2006"), version)
+	fmt.Fprintf(&buf, ".SH NAME\n%s \\- %s\n", docBaseName(c, ".1"), roffEscape(c.Short))
+	fmt.Fprintf(&buf, ".SH SYNOPSIS\n\\fB%s\\fP\n", roffEscape(c.UseLine()))
+
+	description := c.Long
+	if description == "" {
+		description = c.Short
+	}
+	fmt.Fprintf(&buf, ".SH DESCRIPTION\n.nf\n%s\n.fi\n", roffEscape(description))
+
+	if flags := c.NonInheritedFlags(); flags.HasAvailableFlags() {
+		buf.WriteString(".SH OPTIONS\n")
+		writeManFlags(&buf, flags)
+	}
+	if flags := c.InheritedFlags(); flags.HasAvailableFlags() {
+		buf.WriteString(".SH OPTIONS INHERITED FROM PARENT COMMANDS\n")
+		writeManFlags(&buf, flags)
+	}
+	if c.Example != "" {
+		fmt.Fprintf(&buf, ".SH EXAMPLE\n.nf\n%s\n.fi\n", roffEscape(c.Example))
+	}
+
+	related := []string{}
+	if c.HasParent() {
+		related = append(related, fmt.Sprintf("\\fB%s\\fP(1)", docBaseName(c.Parent(), ".1")))
+	}
+	for _, child := range visibleChildren(c) {
+		related = append(related, fmt.Sprintf("\\fB%s\\fP(1)", docBaseName(child, ".1")))
+	}
+	if len(related) > 0 {
+		fmt.Fprintf(&buf, ".SH SEE ALSO\n%s\n", strings.Join(related, ", "))
+	}
+	return buf.String()
+}
+
+// writeManFlags writes one tagged paragraph per flag
+func writeManFlags(buf *bytes.Buffer, flags *pflag.FlagSet) {
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		name := "\\-\\-" + strings.ReplaceAll(f.Name, "-", "\\-")
+		if f.Shorthand != "" {
+			name = "\\-" + f.Shorthand + ", " + name
+		}
+		if f.Value.Type() != "bool" {
+			name += " <" + f.Value.Type() + ">"
+		}
+		usage := f.Usage
+		if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "[]" {
+			usage += fmt.Sprintf(" (default %s)", f.DefValue)
+		}
+		fmt.Fprintf(buf, ".TP\n\\fB%s\\fP\n%s\n", name, roffEscape(usage))
+	})
+}
+
+// roffEscape escapes text for roff: backslashes, and dots or quotes that would start a request
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = "\\&" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// docDate is the date printed in man pages: SOURCE_DATE_EPOCH when set, for reproducible builds
+func docDate() time.Time {
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+	return time.Now()
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Show the largest stored assets and clean them up",
+	Long: `List the biggest objects the current project keeps in storage, with the versions
+that reference them. Sizes come from config.json, so Docker isn't needed to list them.
+
+With -i, go through the list and decide for each object:
+  e  exclude the file from future commits (adds it to the exclude_files setting)
+  p  purge the stored copy; only for objects the newest version doesn't use. The
+     versions that referenced it are kept, but pulling them skips the purged asset.
+  s  skip it, q  stop
+
+Pinned objects, chunked assets and anything in the newest version can't be purged.
+
+Example:
+  vervids du
+  vervids du --largest 20
+  vervids du --largest 20 -i`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		largest, _ := cmd.Flags().GetInt("largest")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+
+		usage := proj.ObjectUsage()
+		if len(usage) == 0 {
+			fmt.Println(infoMsg(i18n.T("No stored assets")))
+			return
+		}
+		var total int64
+		for _, u := range usage {
+			total += u.Size
+		}
+		if largest > 0 && largest < len(usage) {
+			usage = usage[:largest]
+		}
+
+		rows := make([][]string, 0, len(usage))
+		for i, u := range usage {
+			rows = append(rows, []string{
+				fmt.Sprintf("%d", i+1),
+				fmt.Sprintf("%.2f", float64(u.Size)/(1024*1024)),
+				u.Filename,
+				versionRanges(u.Versions),
+				objectNotes(&u),
+			})
+		}
+		fmt.Printf("%s: %s\n", ui.InfoStyle.Render("Project"), proj.ProjectName)
+		fmt.Printf("%s: %.2f MB in %d object(s)\n\n", ui.InfoStyle.Render("Stored"), float64(total)/(1024*1024), len(proj.ObjectUsage()))
+		fmt.Println(ui.Table([]string{"#", "Size(MB)", "File", "Versions", "Notes"}, rows, 2))
+
+		if interactive {
+			cleanupObjects(proj, usage)
+		}
+	},
+}
+
+// cleanupObjects asks what to do with each listed object for 'vervids du -i'
+func cleanupObjects(proj *project.Project, usage []project.ObjectUsage) {
+	if !term.IsTerminal(os.Stdin.Fd()) || !term.IsTerminal(os.Stdout.Fd()) {
+		exitWithError(nil, i18n.T("Interactive cleanup needs an interactive terminal"),
+			i18n.T("Use 'vervids config set exclude_files <file>' in scripts."))
+	}
+	cleanup, err := changeToProjectDirectory()
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+	defer cleanup()
+
+	reader := bufio.NewReader(os.Stdin)
+	excluded, purged := 0, 0
+	var freed int64
+	storageReady := false
+	fmt.Println()
+loop:
+	for i := range usage {
+		u := &usage[i]
+		choices := "e/s/q"
+		if u.Purgeable() {
+			choices = "e/p/s/q"
+		}
+		fmt.Print(infoMsg(i18n.T("%s (%.2f MB, %s): [%s] ", u.Filename, float64(u.Size)/(1024*1024), versionRanges(u.Versions), choices)))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "e":
+			if proj.ExcludeAsset(u.OriginalPath) {
+				excluded++
+				fmt.Println(successMsg(i18n.T("Excluded %s from future commits", u.Filename)))
+			} else {
+				fmt.Println(infoMsg(i18n.T("%s is already excluded", u.Filename)))
+			}
+		case "p":
+			if !u.Purgeable() {
+				fmt.Println(warningMsg(i18n.T("%s can't be purged", u.Filename)))
+				continue
+			}
+			if !storageReady {
+				if err := proj.EnsureStorageReady(); err != nil {
+					exitWithError(err, i18n.T("%v", err))
+				}
+				storageReady = true
+			}
+			if _, err := proj.PurgeObject(u.DockerPath); err != nil {
+				fmt.Println(errorMsg(i18n.T("Error: %v", err)))
+				continue
+			}
+			purged++
+			freed += u.Size
+			fmt.Println(successMsg(i18n.T("Purged %s from %s", u.Filename, versionRanges(u.Versions))))
+		case "q":
+			break loop
+		}
+	}
+
+	if excluded == 0 && purged == 0 {
+		fmt.Println(infoMsg(i18n.T("Nothing changed")))
+		return
+	}
+	if err := proj.Save(); err != nil {
+		exitWithError(err, i18n.T("Error saving project: %v", err))
+	}
+	fmt.Println()
+	fmt.Println(successMsg(i18n.T("Excluded %d file(s), purged %d object(s) (%.2f MB freed)", excluded, purged, float64(freed)/(1024*1024))))
+}
+
+// objectNotes describes how an object is used, for the du table
+func objectNotes(u *project.ObjectUsage) string {
+	notes := []string{}
+	if u.InLatest {
+		notes = append(notes, "latest")
+	}
+	if u.Pinned {
+		notes = append(notes, "pinned")
+	}
+	if u.Proxy {
+		notes = append(notes, "proxy")
+	}
+	if u.Chunked {
+		notes = append(notes, "chunked")
+	}
+	return strings.Join(notes, ", ")
+}
+
+// versionRanges formats version numbers compactly, e.g. "v0-v3, v5"
+func versionRanges(numbers []int) string {
+	parts := []string{}
+	for i := 0; i < len(numbers); {
+		j := i
+		for j+1 < len(numbers) && numbers[j+1] == numbers[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("v%d-v%d", numbers[i], numbers[j]))
+		} else {
+			parts = append(parts, fmt.Sprintf("v%d", numbers[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+)
+
+// jsonError is the machine-readable error object printed in --json mode
+type jsonError struct {
+	Code     string `json:"code"`      // Category, e.g. "version_not_found"
+	ExitCode int    `json:"exit_code"` // Process exit code for the category
+	Message  string `json:"message"`
+}
+
+// jsonOutput reports whether --json was given
+func jsonOutput() bool {
+	enabled, _ := rootCmd.PersistentFlags().GetBool("json")
+	return enabled
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal JSON: %v\n", err)
+		return
+	}
+	fmt.Fprintln(jsonStdout, string(data))
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	return errs.KindOf(err).ExitCode()
+}
+
+// exitWithError reports a failed command and exits with the code for err's category
+// (1 when err is nil or uncategorized). msg and hints are shown to the user; in --json
+// mode an error object is printed instead.
+func exitWithError(err error, msg string, hints ...string) {
+	exitWithKind(errs.KindOf(err), msg, hints...)
+}
+
+// exitWithKind reports a failed command of the given category and exits
+func exitWithKind(kind errs.Kind, msg string, hints ...string) {
+	if jsonOutput() {
+		printJSON(map[string]jsonError{"error": {Code: kind.String(), ExitCode: kind.ExitCode(), Message: msg}})
+	} else {
+		fmt.Println(errorMsg(msg))
+		for _, hint := range hints {
+			fmt.Println(infoMsg(hint))
+		}
+	}
+	os.Exit(kind.ExitCode())
+}
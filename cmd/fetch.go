@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var fetchCmd = &cobra.Command{
+	Use:   "fetch <project> --metadata",
+	Short: "Fetch a project's history from shared storage without its files",
+	Long: `Set up (or refresh) a project in the current directory from Docker storage shared
+with other machines, e.g. a Docker host reached through DOCKER_HOST. Only the version
+history is read: messages, dates, labels and the list of assets. No project files or
+footage are downloaded, so even a huge project can be browsed right away; pull the
+versions you need with 'vervids pull <version>'.
+
+The history comes from the config each commit publishes next to the versions. For
+projects last committed before that, it is rebuilt from the versions' asset tracking,
+without labels, metadata or signatures.
+
+When the current directory already has the project, its history is read from the
+project's own storage, Docker or its S3 remote; its versions, labels and notes are
+replaced with the fetched ones and its settings for this machine are kept. Commits
+are refused while storage has versions this directory's config doesn't know (e.g. from
+a copied project folder); fetching them is how the copy catches up.
+
+Example:
+  vervids fetch promo --metadata
+  vervids log`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if metadata, _ := cmd.Flags().GetBool("metadata"); !metadata {
+			exitWithError(nil, i18n.T("Only metadata can be fetched; use --metadata"),
+				i18n.T("Files stay in storage; download a version with 'vervids pull <version>'."))
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		// A project already here is refreshed from its own storage, which may be a
+		// remote rather than Docker
+		var local *project.Project
+		if storage.IsInitialized() {
+			if local, err = project.Load(); err != nil {
+				exitWithError(err, i18n.T("Error loading project: %v", err))
+			}
+		}
+		var fetched *project.Project
+		var published bool
+		if local != nil {
+			if !storedAs(local, args[0]) {
+				exitWithKind(errs.Conflict, i18n.T("This directory already has project %s", local.ProjectName),
+					i18n.T("Run 'vervids fetch' in an empty directory to set up another project."))
+			}
+			fetched, published, err = local.FetchStoredMetadata(cwd)
+		} else {
+			target := findStoredProject(args[0])
+			fetched, published, err = project.FetchMetadata(docker.Volume{}, target.DockerPath, cwd)
+		}
+		if err != nil {
+			exitWithError(err, i18n.T("Error fetching %s: %v", args[0], err))
+		}
+		if !published {
+			fmt.Println(warningMsg(i18n.T("No published history found; rebuilt it from asset tracking (no labels, metadata or signatures)")))
+		}
+
+		proj := fetched
+		if local != nil {
+			local.AdoptMetadata(fetched)
+			proj = local
+		} else if err := storage.Initialize(); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
+		}
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+		if err := setProjectContext(proj, storage.GetConfigPath()); err != nil {
+			fmt.Println(warningMsg(i18n.T("Warning: Could not save project context: %v", err)))
+		}
+
+		fmt.Println(successMsg(i18n.T("Fetched %d version(s) of %s", len(proj.Versions), proj.ProjectName)))
+		fmt.Println()
+		showProjectCommits(proj)
+		fmt.Println(infoMsg(i18n.T("Use 'vervids pull <version>' to download a version.")))
+	},
+}
+
+// storedAs reports whether name, a project ID or name as findStoredProject takes it,
+// is proj
+func storedAs(proj *project.Project, name string) bool {
+	id := strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.EqualFold(filepath.Base(proj.StorageDir()), id) || strings.EqualFold(proj.ProjectName, name)
+}
+
+// findStoredProject finds a project in Docker storage by its ID or name, exiting with
+// the available projects when there is none
+func findStoredProject(name string) *project.ProjectInfo {
+	projects, err := project.GetAllProjects()
+	if err != nil {
+		exitWithError(err, i18n.T("Error getting projects: %v", err))
+	}
+	id := strings.TrimSuffix(name, filepath.Ext(name))
+	for i, p := range projects {
+		if strings.EqualFold(filepath.Base(p.DockerPath), id) || strings.EqualFold(p.Name, name) {
+			return &projects[i]
+		}
+	}
+	hints := []string{"", i18n.T("Available projects:")}
+	for _, p := range projects {
+		hints = append(hints, fmt.Sprintf("  • %s", p.Name))
+	}
+	exitWithKind(errs.ProjectNotFound, i18n.T("Project '%s' not found", name), hints...)
+	return nil
+}
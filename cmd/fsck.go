@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check that config.json and the project's storage agree",
+	Long: `Check every version of the current project without changing anything: versions
+whose storage is missing in Docker or the project's remote, versions whose asset-tracking.json is missing or
+disagrees with the assets recorded in config.json, and versions whose manifest.json
+is missing, fails its checksum or records different content than config.json.
+
+Use --repair to regenerate broken tracking files from config.json and write the
+manifests of versions committed before manifests existed. Manifests are never
+rewritten, so a damaged or disagreeing one is only reported. Versions with missing
+storage are removed with 'vervids prune'.
+
+Example:
+  vervids fsck
+  vervids fsck --repair`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get project from context (already ensured by PersistentPreRunE)
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		repair, _ := cmd.Flags().GetBool("repair")
+
+		if err := proj.EnsureStorageReady(); err != nil {
+			exitWithError(err, i18n.T("%v", err))
+		}
+
+		check, err := checkStorage(proj)
+		if err != nil {
+			exitWithError(err, i18n.T("Error checking tracking: %v", err))
+		}
+		for _, v := range check.missing {
+			fmt.Println(warningMsg(i18n.T("v%d: storage missing in %s (%s)", v.Number, proj.StorageName(), v.DockerPath)))
+		}
+		for _, pin := range check.missingPins {
+			fmt.Println(warningMsg(i18n.T("Pinned asset %s is missing in %s (%s)", pin.Filename, proj.StorageName(), pin.DockerPath)))
+		}
+		printTrackingIssues(check.issues)
+		for _, issue := range check.manifests {
+			fmt.Println(warningMsg(i18n.T("v%d: %s", issue.Version, issue.Problem)))
+		}
+
+		if check.ok() {
+			fmt.Println(successMsg(i18n.T("All %d version(s) OK", len(proj.Versions))))
+			return
+		}
+		if len(check.missing) > 0 {
+			fmt.Println(infoMsg(i18n.T("Run 'vervids prune' to remove versions with missing storage")))
+		}
+		if len(check.issues) > 0 {
+			if repair {
+				repairTracking(proj, check.issues)
+			} else {
+				fmt.Println(infoMsg(i18n.T("Run 'vervids fsck --repair' to regenerate tracking from config.json")))
+			}
+		}
+		missingManifests, badManifests := 0, 0
+		for _, issue := range check.manifests {
+			if issue.Missing {
+				missingManifests++
+			} else {
+				badManifests++
+			}
+		}
+		if missingManifests > 0 {
+			if repair {
+				repairManifests(proj, check.manifests)
+			} else {
+				fmt.Println(infoMsg(i18n.T("Run 'vervids fsck --repair' to write missing manifests from config.json")))
+			}
+		}
+		if len(check.missing) > 0 || len(check.missingPins) > 0 || badManifests > 0 || !repair {
+			os.Exit(1)
+		}
+	},
+}
+
+// storageCheck is what 'vervids fsck' finds wrong with a project's storage
+type storageCheck struct {
+	missing     []project.Version     // Versions whose storage is gone
+	missingPins []project.PinnedAsset // Pinned assets whose storage is gone
+	issues      []project.TrackingIssue
+	manifests   []project.ManifestIssue
+}
+
+// ok reports whether nothing was found
+func (c *storageCheck) ok() bool {
+	return len(c.missing) == 0 && len(c.missingPins) == 0 && len(c.issues) == 0 && len(c.manifests) == 0
+}
+
+// checkStorage checks a project's versions and pins against its storage and its
+// tracking files against config.json, without changing anything
+func checkStorage(proj *project.Project) (*storageCheck, error) {
+	check := &storageCheck{}
+	var err error
+	if check.missing, check.missingPins, err = proj.MissingStorage(); err != nil {
+		return nil, err
+	}
+	issues, err := proj.CheckTracking()
+	if err != nil {
+		return nil, err
+	}
+	check.issues = issues
+	if check.manifests, err = proj.CheckManifests(); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// printTrackingIssues prints one warning per version with inconsistent tracking
+func printTrackingIssues(issues []project.TrackingIssue) {
+	for _, issue := range issues {
+		fmt.Println(warningMsg(i18n.T("v%d: %s", issue.Version, issue.Problem)))
+	}
+}
+
+// repairTracking regenerates the tracking file of each version with an issue
+func repairTracking(proj *project.Project, issues []project.TrackingIssue) {
+	repaired := 0
+	for _, issue := range issues {
+		if err := proj.RegenerateTracking(issue.Version); err != nil {
+			fmt.Println(errorMsg(i18n.T("v%d: failed to regenerate tracking: %v", issue.Version, err)))
+			continue
+		}
+		repaired++
+	}
+	fmt.Println(successMsg(i18n.T("Regenerated tracking for %d version(s)", repaired)))
+}
+
+// repairManifests writes the manifest of each version that has none
+func repairManifests(proj *project.Project, issues []project.ManifestIssue) {
+	written := 0
+	for _, issue := range issues {
+		if !issue.Missing {
+			continue
+		}
+		if err := proj.WriteMissingManifest(issue.Version); err != nil {
+			fmt.Println(errorMsg(i18n.T("v%d: failed to write manifest: %v", issue.Version, err)))
+			continue
+		}
+		written++
+	}
+	fmt.Println(successMsg(i18n.T("Wrote manifests for %d version(s)", written)))
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// importedFromKey is the metadata key recording which file a version was imported from
+const importedFromKey = "imported_from"
+
+var importHistoryCmd = &cobra.Command{
+	Use:   "import-history <dir>",
+	Short: "Commit a folder of old project saves as backdated versions",
+	Long: `Commit every project file in a folder (.aepx, or .nk for Nuke projects) as a
+version of the current project, oldest first, each dated by its file's modification time. Use it to bring in history that was
+kept as manually saved copies (project_v1.aepx ... project_v38.aepx).
+
+Files are ordered by the numbers in their names (v2 before v10) or, with
+--sort mtime, by modification time. Assets are parsed from each file, so footage the
+old saves point to is stored as it was. Each version records the file it came from
+in its imported_from metadata; running the import again skips files already imported,
+so an interrupted import can simply be repeated.
+
+Example:
+  vervids import-history ./old_saves --dry-run
+  vervids import-history ./old_saves
+  vervids import-history ./old_saves --sort mtime --allow-missing`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sortBy, _ := cmd.Flags().GetString("sort")
+		if sortBy != "name" && sortBy != "mtime" {
+			exitWithError(nil, i18n.T("--sort must be 'name' or 'mtime'"))
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		dir, err := filepath.Abs(args[0])
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		// Only saves of the project's own format (.aepx, .nk) are imported
+		ext := strings.ToLower(filepath.Ext(proj.ProjectPath))
+		files, err := historyFiles(dir, ext, sortBy)
+		if err != nil {
+			exitWithError(err, i18n.T("Error reading %s: %v", dir, err))
+		}
+		if len(files) == 0 {
+			exitWithError(nil, i18n.T("No %s files in %s", ext, dir))
+		}
+
+		// Files imported by an earlier run are skipped
+		imported := make(map[string]bool)
+		for _, v := range proj.Versions {
+			if name := v.Meta[importedFromKey]; name != "" {
+				imported[name] = true
+			}
+		}
+		pending := []historyFile{}
+		rows := [][]string{}
+		for _, f := range files {
+			status := "import"
+			if imported[filepath.Base(f.Path)] {
+				status = "already imported"
+			} else {
+				pending = append(pending, f)
+			}
+			rows = append(rows, []string{filepath.Base(f.Path), f.ModTime.Format("2006-01-02 15:04"), status})
+		}
+		fmt.Println(ui.Table([]string{"File", "Date", "Status"}, rows, 2))
+		if len(pending) == 0 {
+			fmt.Println(successMsg(i18n.T("Nothing to import")))
+			return
+		}
+		if latest := proj.GetLatestVersion(); latest != nil && pending[0].ModTime.Before(latest.Timestamp) {
+			fmt.Println(warningMsg(i18n.T("Some files are older than v%d; versions will not be in date order", latest.Number)))
+		}
+		if dryRun {
+			fmt.Println(infoMsg(i18n.T("%d file(s) would be imported", len(pending))))
+			return
+		}
+
+		var opts project.CommitOptions
+		if allowMissing, _ := cmd.Flags().GetBool("allow-missing"); allowMissing {
+			opts.MissingAssets = project.MissingAllow
+		}
+		opts.ForceQuota, _ = cmd.Flags().GetBool("force-quota")
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
+		}
+		defer cleanup()
+
+		// Committing moves the project path to each imported file; the project keeps
+		// pointing at the file it was using before
+		projectPath := proj.ProjectPath
+		restorePath := func() {
+			proj.ProjectPath = projectPath
+			if err := proj.Save(); err != nil {
+				fmt.Println(warningMsg(i18n.T("Warning: Could not save project: %v", err)))
+			}
+		}
+
+		for i, f := range pending {
+			name := filepath.Base(f.Path)
+			fmt.Println(infoMsg(i18n.T("[%d/%d] Importing %s", i+1, len(pending), name)))
+			opts.Timestamp = f.ModTime
+			opts.Meta = map[string]string{importedFromKey: name}
+			v, err := proj.CommitWithOptions(i18n.T("Imported %s", name), f.Path, opts)
+			if err != nil {
+				restorePath()
+				hints := []string{i18n.T("Imported versions are kept; run the same command again to continue.")}
+				if errs.KindOf(err) == errs.StorageFull {
+					hints = append(hints, i18n.T("Use --force-quota to import past the storage quota."))
+				}
+				exitWithError(err, i18n.T("Error importing %s: %v", name, err), hints...)
+			}
+			fmt.Println(successMsg(i18n.T("Committed version %d", v.Number)))
+		}
+		restorePath()
+		fmt.Println(successMsg(i18n.T("Imported %d version(s)", len(pending))))
+	},
+}
+
+// historyFile is one old save found by 'vervids import-history'
+type historyFile struct {
+	Path    string
+	ModTime time.Time
+}
+
+// historyFiles returns the files with the given extension directly in dir, oldest
+// first: by the numbers in their names, or by modification time when sortBy is "mtime"
+func historyFiles(dir string, ext string, sortBy string) ([]historyFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := []historyFile{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ext) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, historyFile{Path: filepath.Join(dir, e.Name()), ModTime: info.ModTime()})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if sortBy == "mtime" && !files[i].ModTime.Equal(files[j].ModTime) {
+			return files[i].ModTime.Before(files[j].ModTime)
+		}
+		return naturalLess(filepath.Base(files[i].Path), filepath.Base(files[j].Path))
+	})
+	return files, nil
+}
+
+// naturalLess compares names with runs of digits taken as numbers, so v2 sorts
+// before v10
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := leadingDigits(a), leadingDigits(b)
+		if da != "" && db != "" {
+			na, _ := strconv.ParseUint(da, 10, 64)
+			nb, _ := strconv.ParseUint(db, 10, 64)
+			if na != nb {
+				return na < nb
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if ca, cb := strings.ToLower(a[:1]), strings.ToLower(b[:1]); ca != cb {
+			return ca < cb
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// leadingDigits returns the run of ASCII digits at the start of s
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show an overview of the current project",
+	Long: `Print an overview of the current project: its name and paths, the storage backend,
+how many versions it has and when the oldest and newest were committed, how much
+space it takes up and how much deduplication saves, missing assets, and whether
+anything is unfinished.
+
+A project is only locked while a commit runs; the status line reports the things
+that hold a project up instead: an interrupted commit that will resume, and versions
+staged with 'commit --offline' or left by a failed upload, waiting for 'vervids push'.
+
+Sizes are those recorded on commit, so no Docker access is needed.
+
+Example:
+  vervids info`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		context, err := storage.LoadContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		stats := proj.Stats()
+
+		field := func(name string, format string, a ...interface{}) {
+			fmt.Printf("%s %s\n", ui.InfoStyle.Render(fmt.Sprintf("%-10s", name+":")), fmt.Sprintf(format, a...))
+		}
+		mb := func(n int64) float64 { return float64(n) / (1024 * 1024) }
+
+		field("Project", "%s", proj.ProjectName)
+		field("File", "%s", proj.ProjectPath)
+		if proj.Generic {
+			field("Mode", "generic (whole-file snapshots, no asset parsing)")
+		}
+		field("Directory", "%s", filepath.Dir(filepath.Dir(context.ConfigPath)))
+		field("Config", "%s", context.ConfigPath)
+		if proj.Remote != nil {
+			field("Storage", "%s", proj.Remote.URL)
+		} else if proj.UseDocker {
+			backend := "Docker"
+			if proj.DockerVolume != "" {
+				backend += fmt.Sprintf(" (volume %s)", proj.DockerVolume)
+			}
+			if stats.Newest != nil && stats.Newest.DockerPath != "" {
+				backend += fmt.Sprintf(" at %s", filepath.Dir(filepath.Dir(stats.Newest.DockerPath)))
+			}
+			field("Storage", "%s", backend)
+		} else {
+			field("Storage", "Local")
+		}
+		fmt.Println()
+
+		field("Versions", "%d", stats.Versions)
+		if stats.Oldest != nil {
+			field("Oldest", "v%d  %s  %s", stats.Oldest.Number, stats.Oldest.Timestamp.Format("2006-01-02 15:04"), stats.Oldest.Message)
+			field("Newest", "v%d  %s  %s", stats.Newest.Number, stats.Newest.Timestamp.Format("2006-01-02 15:04"), stats.Newest.Message)
+		}
+		field("Stored", "%.2f MB (project files and %d asset object(s))", mb(stats.StoredSize), stats.StoredObjects)
+		if stats.LogicalSize > 0 {
+			field("Dedup", "%.2f MB saved (%.0f%% of %.2f MB across all versions)", mb(stats.Savings()), float64(stats.Savings())/float64(stats.LogicalSize)*100, mb(stats.LogicalSize))
+		}
+		if proj.QuotaGB > 0 {
+			quota := fmt.Sprintf("%.0f%% of %d GB", float64(stats.StoredSize)/float64(proj.Quota())*100, proj.QuotaGB)
+			if proj.OverQuota() {
+				quota = ui.ErrorStyle.Render(quota + ", over quota")
+			}
+			field("Quota", "%s", quota)
+		}
+		if stats.Missing > 0 {
+			field("Missing", "%s", ui.WarningStyle.Render(fmt.Sprintf("%d asset(s) in v%d", stats.Missing, stats.Newest.Number)))
+		} else {
+			field("Missing", "none")
+		}
+		if stats.Pinned > 0 {
+			field("Pinned", "%d asset(s)", stats.Pinned)
+		}
+		fmt.Println()
+
+		status := []string{}
+		journal, err := storage.ReadJournal(filepath.Join(filepath.Dir(context.ConfigPath), storage.CommitJournalFile))
+		if err != nil {
+			status = append(status, ui.WarningStyle.Render(i18n.T("commit journal unreadable: %v", err)))
+		} else if journal != nil {
+			status = append(status, ui.WarningStyle.Render(i18n.T("interrupted commit from %s (%d transfer(s) done); commit the same file again to resume", journal.Started.Format("2006-01-02 15:04"), len(journal.Completed))))
+		}
+		if staged, err := project.LoadStagedCommits(); err == nil {
+			count := 0
+			for _, s := range staged {
+				if s.ConfigPath == context.ConfigPath {
+					count++
+				}
+			}
+			if count > 0 {
+				status = append(status, ui.WarningStyle.Render(i18n.T("%d version(s) staged; run 'vervids push'", count)))
+			}
+		}
+		if len(status) == 0 {
+			field("Status", "%s", ui.SuccessStyle.Render(i18n.T("clean")))
+			return
+		}
+		for i, s := range status {
+			name := "Status"
+			if i > 0 {
+				name = ""
+			}
+			field(name, "%s", s)
+		}
+	},
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/project"
+)
+
+// jsonStdout is where --json documents are written. Commands that print one move
+// everything else they print to stderr, so stdout parses as a single document.
+var jsonStdout = os.Stdout
+
+// startJSONOutput reports whether --json was given and, if so, sends the progress,
+// warnings and prompts the command prints along the way to stderr
+func startJSONOutput() bool {
+	if !jsonOutput() {
+		return false
+	}
+	os.Stdout = os.Stderr
+	return true
+}
+
+// jsonProjectItem is a project in 'vervids list --json'
+type jsonProjectItem struct {
+	Number      int    `json:"number"` // What 'vervids list <number>' takes
+	Name        string `json:"name"`
+	DockerPath  string `json:"docker_path"`
+	Current     bool   `json:"current,omitempty"`
+	Uncommitted string `json:"uncommitted,omitempty"` // Working file saved with changes since the newest commit
+}
+
+// jsonProjectList is 'vervids list --json'
+type jsonProjectList struct {
+	Projects []jsonProjectItem `json:"projects"`
+	Current  *jsonCommitList   `json:"current,omitempty"` // Commits of the current project
+}
+
+// jsonCommitItem is a version in 'vervids list --json'; 'vervids show <n> --json'
+// has the rest of its fields
+type jsonCommitItem struct {
+	Number     int               `json:"number"`
+	Message    string            `json:"message"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Author     string            `json:"author,omitempty"`
+	Size       int64             `json:"size"`
+	AssetCount int               `json:"asset_count"`
+	TotalSize  int64             `json:"total_size"`
+	Missing    int               `json:"missing_assets,omitempty"`
+	Labels     []string          `json:"labels,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Meta       map[string]string `json:"meta,omitempty"`
+}
+
+// jsonCommitList is the commits of one project in 'vervids list --json'
+type jsonCommitList struct {
+	Project string           `json:"project"`
+	Total   int              `json:"total"` // Commits before --label/--meta filtering
+	Commits []jsonCommitItem `json:"commits"`
+}
+
+// newJSONCommitList lists the project's versions that pass the filter
+func newJSONCommitList(proj *project.Project, filter project.VersionFilter) jsonCommitList {
+	list := jsonCommitList{Project: proj.ProjectName, Total: len(proj.Versions), Commits: []jsonCommitItem{}}
+	for _, v := range filter.Filter(proj.Versions) {
+		list.Commits = append(list.Commits, jsonCommitItem{
+			Number:     v.Number,
+			Message:    v.Message,
+			Timestamp:  v.Timestamp,
+			Author:     v.Author,
+			Size:       v.Size,
+			AssetCount: v.AssetCount,
+			TotalSize:  v.TotalSize,
+			Missing:    len(v.Missing),
+			Labels:     v.Labels,
+			Tags:       v.Tags,
+			Meta:       v.Meta,
+		})
+	}
+	return list
+}
+
+// jsonProjectSummary is 'vervids show --project --json'
+type jsonProjectSummary struct {
+	Project   string    `json:"project"`
+	File      string    `json:"file"`
+	CreatedAt time.Time `json:"created_at"`
+	Storage   string    `json:"storage"`
+	Versions  int       `json:"versions"`
+	Latest    *int      `json:"latest,omitempty"` // Number of the newest version
+	Author    string    `json:"author,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+}
+
+// newJSONProjectSummary summarizes the project like 'vervids show --project'
+func newJSONProjectSummary(proj *project.Project) jsonProjectSummary {
+	summary := jsonProjectSummary{
+		Project:   proj.ProjectName,
+		File:      proj.ProjectPath,
+		CreatedAt: proj.CreatedAt,
+		Storage:   proj.StorageName(),
+		Versions:  len(proj.Versions),
+		Author:    proj.Author,
+		Notes:     proj.Notes,
+	}
+	if latest := proj.GetLatestVersion(); latest != nil {
+		summary.Latest = &latest.Number
+	}
+	return summary
+}
+
+// jsonCommitResult is 'vervids commit --json'. The version has the same fields as in
+// .vervids/config.json.
+type jsonCommitResult struct {
+	Version    *project.Version `json:"version"`
+	Storage    string           `json:"storage"`
+	StoredSize int64            `json:"stored_size"` // Bytes this commit added to storage
+	ReusedSize int64            `json:"reused_size"` // Bytes of assets already stored by earlier versions
+}
+
+// jsonStagedResult is 'vervids commit --offline --json'
+type jsonStagedResult struct {
+	Dir       string            `json:"dir"` // Where 'vervids push' picks it up
+	Message   string            `json:"message"`
+	Timestamp time.Time         `json:"timestamp"`
+	Meta      map[string]string `json:"meta,omitempty"`
+	Assets    int               `json:"assets"`
+	Missing   []string          `json:"missing_assets,omitempty"`
+	Size      int64             `json:"size"`
+}
+
+// jsonPullResult is 'vervids pull --json'
+type jsonPullResult struct {
+	Version     int    `json:"version"`
+	ProjectFile string `json:"project_file"`
+	AssetsDir   string `json:"assets_dir,omitempty"` // Only when assets had to be copied out of storage
+}
+
+// jsonPruneResult is 'vervids prune --json'
+type jsonPruneResult struct {
+	Pruned         int                 `json:"pruned"` // Versions removed because their storage was gone
+	Storage        string              `json:"storage"`
+	TrackingIssues []jsonTrackingIssue `json:"tracking_issues,omitempty"` // Left for 'vervids fsck --repair'
+}
+
+// jsonTrackingIssue is a version whose asset tracking disagrees with config.json
+type jsonTrackingIssue struct {
+	Version int    `json:"version"`
+	Problem string `json:"problem"`
+}
+
+// jsonDeleteResult is 'vervids delete --json'
+type jsonDeleteResult struct {
+	Deleted    string `json:"deleted"`
+	DockerPath string `json:"docker_path"`
+}
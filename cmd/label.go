@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var labelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "Organize versions with colored labels and milestones",
+	Long: `Attach colored labels such as "rough cut" or "final delivery" to versions. A label
+can be on any number of versions, and a version can carry several. Filter by label
+with 'vervids log --label', 'vervids list <n> --label' and the API's ?label= query.
+
+Example:
+  vervids label add "rough cut" 3 4 --color orange
+  vervids label add "final delivery" 9
+  vervids label remove "rough cut" 3
+  vervids label list
+  vervids log --label "rough cut"`,
+}
+
+var labelListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the project's labels and their versions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if len(proj.Labels) == 0 {
+			fmt.Println(infoMsg(i18n.T("No labels yet. Use 'vervids label add <name> <version>' to add one.")))
+			return
+		}
+
+		rows := make([][]string, 0, len(proj.Labels))
+		for _, l := range proj.SortedLabels() {
+			versions := []string{}
+			for _, n := range proj.LabelVersions(l.Name) {
+				versions = append(versions, fmt.Sprintf("v%d", n))
+			}
+			rows = append(rows, []string{ui.Label(l.Name, l.Color), l.Color, strings.Join(versions, ", ")})
+		}
+		fmt.Println(ui.Table([]string{"Label", "Color", "Versions"}, rows, 2))
+	},
+}
+
+var labelAddCmd = &cobra.Command{
+	Use:   "add <name> [version...]",
+	Short: "Attach a label to versions, creating it if needed",
+	Long: `Attach a label to one or more versions. The label is created the first time it is
+used, with the next color from the palette unless --color is given; --color on an
+existing label changes its color. With no versions, the label is only defined.
+
+Colors: red, orange, yellow, green, teal, blue, purple, pink, gray, an ANSI number
+0-255 or a #rrggbb value.
+
+Example:
+  vervids label add "rough cut" 3 4
+  vervids label add "final delivery" 9 --color green`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		versions := make([]int, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			versions = append(versions, parseVersionArg(proj, arg))
+		}
+		color, _ := cmd.Flags().GetString("color")
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		label, err := proj.AddLabel(args[0], color, versions)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+
+		if len(versions) == 0 {
+			fmt.Println(successMsg(i18n.T("Label %s defined", ui.Label(label.Name, label.Color))))
+			return
+		}
+		fmt.Println(successMsg(i18n.T("Labelled %d version(s) %s", len(versions), ui.Label(label.Name, label.Color))))
+	},
+}
+
+var labelRemoveCmd = &cobra.Command{
+	Use:   "remove <name> [version...]",
+	Short: "Detach a label from versions, or delete it",
+	Long: `Detach a label from the given versions. With no versions, the label is removed from
+every version and deleted.
+
+Example:
+  vervids label remove "rough cut" 3
+  vervids label remove "rough cut"`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		versions := make([]int, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			versions = append(versions, parseVersionArg(proj, arg))
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		changed, err := proj.RemoveLabel(args[0], versions)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+
+		if len(versions) == 0 {
+			fmt.Println(successMsg(i18n.T("Deleted label '%s' (was on %d version(s))", args[0], changed)))
+			return
+		}
+		fmt.Println(successMsg(i18n.T("Removed label '%s' from %d version(s)", args[0], changed)))
+	},
+}
+
+// commitFilter builds the version filter from a command's --label and --meta flags
+func commitFilter(cmd *cobra.Command) project.VersionFilter {
+	label, _ := cmd.Flags().GetString("label")
+	pairs, _ := cmd.Flags().GetStringArray("meta")
+	meta, err := project.ParseMeta(pairs, true)
+	if err != nil {
+		exitWithError(err, i18n.T("Error: %v", err))
+	}
+	noAutosaves, _ := cmd.Flags().GetBool("no-autosaves")
+	return project.VersionFilter{Label: label, Meta: meta, NoAutosaves: noAutosaves}
+}
+
+// describeFilter renders a version filter for the commit list header
+func describeFilter(proj *project.Project, filter project.VersionFilter) string {
+	parts := []string{}
+	if filter.Label != "" {
+		parts = append(parts, ui.Label(filter.Label, proj.LabelColor(filter.Label)))
+	}
+	for _, pair := range project.MetaPairs(filter.Meta) {
+		parts = append(parts, strings.TrimSuffix(pair, "="))
+	}
+	if filter.NoAutosaves {
+		parts = append(parts, "no autosaves")
+	}
+	return strings.Join(parts, ", ")
+}
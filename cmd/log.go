@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the current project's commit history",
+	Long: `Show the commits of the current project. Use --label to show only the versions
+carrying a label (see 'vervids label'), and --meta key=value to show only those
+committed with that metadata (--meta key matches any value).
+
+With -i, open an interactive history browser instead: arrow through the versions to
+see each one's details and its asset and composition changes since the previous
+version in a side pane, press a to scroll through all of its assets, and press p to
+pull the highlighted version into the current directory.
+
+Example:
+  vervids log
+  vervids log --label "rough cut"
+  vervids log --meta ticket=VFX-123
+  vervids log -i`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get project from context (already ensured by PersistentPreRunE)
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		if !interactive {
+			showFilteredCommits(proj, commitFilter(cmd))
+			return
+		}
+
+		if !term.IsTerminal(os.Stdin.Fd()) || !term.IsTerminal(os.Stdout.Fd()) {
+			exitWithError(nil, i18n.T("The history browser needs an interactive terminal"),
+				i18n.T("Use 'vervids log' and 'vervids diff' in scripts."))
+		}
+		if len(proj.Versions) == 0 {
+			exitWithError(nil, i18n.T("No commits yet. Use 'vervids commit \"message\" <file.aepx>' to create one."))
+		}
+		if err := newHistoryBrowser(proj).run(); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+	},
+}
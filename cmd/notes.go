@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var notesCmd = &cobra.Command{
+	Use:   "notes",
+	Short: "Keep free-form notes with the project",
+	Long: `Keep a Markdown note with the project: the client brief, delivery specs, font
+licenses, anything the next person opening it should know. Notes are stored in
+.vervids/config.json and shown by 'vervids show --project', the dashboard and the API.
+
+Example:
+  vervids notes edit
+  vervids notes edit --from brief.md
+  vervids notes show`,
+}
+
+var notesShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the project's notes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if proj.Notes == "" {
+			fmt.Println(infoMsg(i18n.T("No notes yet. Use 'vervids notes edit' to add some.")))
+			return
+		}
+		fmt.Println(proj.Notes)
+	},
+}
+
+var notesEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the project's notes in your editor",
+	Long: `Open the project's notes in $VISUAL or $EDITOR (vi, or Notepad on Windows, if
+neither is set) and save them when the editor exits. Emptying the file removes the
+notes. With --from, the notes are replaced by a file's contents instead.
+
+Example:
+  vervids notes edit
+  EDITOR="code --wait" vervids notes edit
+  vervids notes edit --from brief.md`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		var notes string
+		if from, _ := cmd.Flags().GetString("from"); from != "" {
+			notes, err = readNotes(from)
+		} else {
+			notes, err = editText(proj.Notes, "notes-*.md")
+		}
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		notes = strings.TrimSpace(notes)
+		if notes == proj.Notes {
+			fmt.Println(infoMsg(i18n.T("Notes unchanged")))
+			return
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		proj.Notes = notes
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+		if notes == "" {
+			fmt.Println(successMsg(i18n.T("Notes removed")))
+			return
+		}
+		fmt.Println(successMsg(i18n.T("Notes saved (%d lines)", strings.Count(notes, "\n")+1)))
+	},
+}
+
+// readNotes reads notes from a file, or from stdin for "-"
+func readNotes(path string) (string, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
+
+// editText opens text in the user's editor and returns it as saved
+func editText(text string, pattern string) (string, error) {
+	f, err := storage.CreateTemp(pattern)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if text != "" {
+		text += "\n"
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	f.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// The editor setting may carry its own arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], f.Name())...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", parts[0], err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// printProjectSummary prints the project's details and notes for 'show --project'
+func printProjectSummary(proj *project.Project) {
+	fmt.Printf("%s %s\n", ui.InfoStyle.Render("Project:  "), proj.ProjectName)
+	fmt.Printf("%s %s\n", ui.InfoStyle.Render("File:     "), proj.ProjectPath)
+	if !proj.CreatedAt.IsZero() {
+		fmt.Printf("%s %s\n", ui.InfoStyle.Render("Created:  "), proj.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("%s %d\n", ui.InfoStyle.Render("Versions: "), len(proj.Versions))
+	if latest := proj.GetLatestVersion(); latest != nil {
+		fmt.Printf("%s v%d  %s  %s\n", ui.InfoStyle.Render("Latest:   "), latest.Number, latest.Timestamp.Format("2006-01-02 15:04"), latest.Message)
+	}
+	if proj.Author != "" {
+		fmt.Printf("%s %s\n", ui.InfoStyle.Render("Author:   "), proj.Author)
+	}
+
+	fmt.Println()
+	if proj.Notes == "" {
+		fmt.Println(infoMsg(i18n.T("No notes yet. Use 'vervids notes edit' to add some.")))
+		return
+	}
+	fmt.Println(infoMsg(i18n.T("Notes:")))
+	for _, line := range strings.Split(proj.Notes, "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var optimizeCmd = &cobra.Command{
+	Use:   "optimize",
+	Short: "Rewrite stored assets into the current storage format",
+	Long: `Bring the current project's storage up to the current format, in place:
+  - copies stored before the content-addressed pool existed (one per filename or
+    version) move into the pool, and duplicates of the same content are dropped
+  - with chunk_threshold_mb set, whole files at or above it are split into chunks,
+    so versions of a re-exported file only keep the chunks that changed
+
+Each copy is checked against its SHA-256 before and after it moves; the old copy is
+deleted only once config.json points at the verified new one. Objects that fail a
+check are reported and left untouched. Pinned objects and proxies are skipped.
+Storage isn't compressed, since footage is already compressed by its codec.
+
+Example:
+  vervids optimize --dry-run
+  vervids optimize
+  vervids optimize --no-chunk`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		noChunk, _ := cmd.Flags().GetBool("no-chunk")
+		if !dryRun {
+			if err := proj.CheckWritable(); err != nil {
+				exitWithError(err, i18n.T("%v", err))
+			}
+		}
+
+		if err := docker.EnsureDockerReady(); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		steps, err := proj.OptimizePlan(!noChunk)
+		if err != nil {
+			exitWithError(err, i18n.T("Error checking storage: %v", err))
+		}
+		if len(steps) == 0 {
+			fmt.Println(successMsg(i18n.T("Storage is already optimized")))
+			return
+		}
+
+		rows := make([][]string, 0, len(steps))
+		for _, s := range steps {
+			rows = append(rows, []string{fmt.Sprintf("%.2f", float64(s.Size)/(1024*1024)), optimizeAction(s), s.From})
+		}
+		fmt.Println(ui.Table([]string{"Size(MB)", "Action", "Stored at"}, rows, 2))
+		if dryRun {
+			fmt.Println(infoMsg(i18n.T("%d object(s) would be rewritten", len(steps))))
+			return
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
+		}
+		defer cleanup()
+
+		result, err := proj.Optimize(steps)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		for _, failure := range result.Failed {
+			fmt.Println(warningMsg(failure))
+		}
+		fmt.Println(successMsg(i18n.T("Rewrote %d object(s), freed %.2f MB", len(result.Done), float64(result.Freed)/(1024*1024))))
+		if len(result.Failed) > 0 {
+			exitWithError(nil, i18n.T("%d object(s) could not be optimized", len(result.Failed)),
+				i18n.T("Run 'vervids fsck' to check the project's storage."))
+		}
+	},
+}
+
+// optimizeAction describes what 'vervids optimize' will do with an object
+func optimizeAction(s project.OptimizeStep) string {
+	switch {
+	case s.Reused:
+		return "drop duplicate"
+	case s.Chunk:
+		return "chunk"
+	default:
+		return "move to " + strings.TrimPrefix(filepath.Dir(filepath.Dir(s.To)), docker.StoragePath+"/") + "/"
+	}
+}
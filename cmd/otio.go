@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/otio"
+	"github.com/spf13/cobra"
+)
+
+var exportOTIOCmd = &cobra.Command{
+	Use:   "export-otio [output.otio]",
+	Short: "Export the version history as an OpenTimelineIO file",
+	Long: `Write the current project's version history as an OpenTimelineIO (.otio) file for
+pipeline tools that read OTIO.
+
+The "Versions" track has one clip per version, as long as its longest comp, carrying
+the message, author, date, labels, metadata and marker comments. Below it is a track
+per composition showing the comp's length in each version that has it, so renamed,
+added and retimed comps are visible across the history. Comp details need each
+version's .aepx from Docker; with --no-comps (or without Docker) only the Versions
+track is written.
+
+The output defaults to <project>.otio in the current directory; use - for stdout.
+
+Example:
+  vervids export-otio
+  vervids export-otio history.otio
+  vervids export-otio - --no-comps | otioconvert -i - -o history.edl`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		noComps, _ := cmd.Flags().GetBool("no-comps")
+
+		output := strings.TrimSuffix(proj.ProjectName, filepath.Ext(proj.ProjectName)) + ".otio"
+		if len(args) == 1 {
+			output = args[0]
+		}
+		// Progress goes to stderr when the file itself goes to stdout
+		status := os.Stdout
+		if output == "-" {
+			status = os.Stderr
+		}
+
+		comps := map[int][]aepx.Comp{}
+		if !noComps {
+			if err := proj.EnsureStorageReady(); err != nil {
+				fmt.Fprintln(status, warningMsg(i18n.T("Storage not available, exporting without comps: %v", err)))
+			} else {
+				for _, v := range proj.Versions {
+					if !assets.IsAEPX(v.DockerPath) {
+						continue // Only .aepx projects have comps
+					}
+					path, cleanup, err := proj.FetchVersionFile(v.Number)
+					if err == nil {
+						comps[v.Number], err = aepx.ExtractComps(path)
+						cleanup()
+					}
+					if err != nil {
+						fmt.Fprintln(status, warningMsg(i18n.T("v%d: comps not read: %v", v.Number, err)))
+					}
+				}
+			}
+		}
+
+		timeline := otio.FromProject(proj, comps)
+		if output == "-" {
+			if err := otio.Write(os.Stdout, timeline); err != nil {
+				exitWithError(err, i18n.T("Error writing OTIO: %v", err))
+			}
+			return
+		}
+		file, err := os.Create(output)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if err := otio.Write(file, timeline); err != nil {
+			file.Close()
+			exitWithError(err, i18n.T("Error writing OTIO: %v", err))
+		}
+		if err := file.Close(); err != nil {
+			exitWithError(err, i18n.T("Error writing OTIO: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("Exported %d version(s) to %s", len(proj.Versions), output)))
+	},
+}
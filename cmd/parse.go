@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var parseCmd = &cobra.Command{
+	Use:   "parse <file.aepx>",
+	Short: "List the assets a project file references, without committing",
+	Long: `Run the parser a commit uses on a project file and print what it finds: every
+asset on disk with its size, the references that can't be found, the compositions
+of an .aepx, and the totals. Nothing is stored and no project is needed, so scripts
+can use it for audits and pre-flight checks before a render or a handoff.
+
+Path mappings and excluded extensions and folders from settings.yaml apply, as they
+do on commit. Online-only files in synced folders are reported, not downloaded.
+With --json the parse result is printed as a JSON document; with --strict the
+command exits with status 1 when any asset is missing.
+
+Example:
+  vervids parse edit.aepx
+  vervids parse edit.aepx --json
+  vervids parse comp.nk --strict`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON := startJSONOutput()
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		file := args[0]
+		if _, err := os.Stat(file); err != nil {
+			exitWithError(nil, i18n.T("File '%s' does not exist", file))
+		}
+
+		s := loadSettings()
+		opts := assets.ParseOptions{PathMappings: s.PathMappings}
+		opts.Rules.ExcludeExtensions = s.ExcludeExtensions
+		opts.Rules.ExcludeFolders = s.ExcludeFolders
+
+		result, err := assets.ParseProjectFile(file, opts)
+		if err != nil {
+			exitWithError(err, i18n.T("Error parsing %s: %v", filepath.Base(file), err))
+		}
+
+		if asJSON {
+			printJSON(jsonParseResult{ParseResult: result, AssetCount: len(result.Assets), MissingCount: len(result.MissingAssets)})
+		} else {
+			printParseResult(result)
+		}
+		if strict && len(result.MissingAssets) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// jsonParseResult is 'vervids parse --json': the parser's result with its counts
+type jsonParseResult struct {
+	*assets.ParseResult
+	AssetCount   int `json:"asset_count"`
+	MissingCount int `json:"missing_count"`
+}
+
+// printParseResult lists the assets, missing references and exclusions of a parse
+func printParseResult(result *assets.ParseResult) {
+	fmt.Printf("%s %s\n", ui.InfoStyle.Render("Project file:"), result.ProjectFile)
+
+	if len(result.Assets) > 0 {
+		fmt.Println()
+		fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("Assets (%d):", len(result.Assets))))
+		for _, a := range result.Assets {
+			line := fmt.Sprintf("  %s  %.2f MB", a.Path, float64(a.Size)/(1024*1024))
+			if a.Mapped || a.FoundIn != "" {
+				line += fmt.Sprintf("  (referenced as %s)", a.Reference)
+			}
+			if a.Cloud != "" {
+				line += fmt.Sprintf("  [%s]", a.Cloud)
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if len(result.MissingAssets) > 0 {
+		fmt.Println()
+		fmt.Println(ui.WarningStyle.Render(fmt.Sprintf("Missing (%d):", len(result.MissingAssets))))
+		for _, path := range result.MissingAssets {
+			if provider, ok := result.Placeholders[path]; ok {
+				fmt.Printf("  %s  (online-only %s placeholder)\n", path, provider)
+			} else if reference, ok := result.MissingReferences[path]; ok && reference != path {
+				fmt.Printf("  %s  (referenced as %s)\n", path, reference)
+			} else {
+				fmt.Printf("  %s\n", path)
+			}
+		}
+	}
+
+	if len(result.Comps) > 0 {
+		fmt.Println()
+		fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("Compositions (%d):", len(result.Comps))))
+		for _, c := range result.Comps {
+			fmt.Printf("  %s: %s\n", c.Name, c)
+		}
+	}
+
+	if len(result.ExcludedAssets) > 0 {
+		fmt.Println()
+		fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("Excluded by rules (%d):", len(result.ExcludedAssets))))
+		for _, path := range result.ExcludedAssets {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+
+	fmt.Println()
+	summary := i18n.T("%d asset(s), %.2f MB; %d missing", len(result.Assets), float64(result.TotalSize)/(1024*1024), len(result.MissingAssets))
+	if len(result.MissingAssets) > 0 {
+		fmt.Println(warningMsg(summary))
+	} else {
+		fmt.Println(successMsg(summary))
+	}
+}
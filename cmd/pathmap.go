@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/settings"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var pathmapCmd = &cobra.Command{
+	Use:   "pathmap",
+	Short: "Manage path mappings for cross-machine asset resolution",
+	Long: `Path mappings translate asset locations between machines, e.g. a Mac workstation
+that mounts footage at /Volumes/Footage and a Windows render node that sees it as F:\Footage.
+
+Mappings work in both directions and are applied when parsing .aepx files on commit
+and when pulling versions. Project mappings are stored in .vervids/config.json and are
+tried before global mappings in ~/.vervids/settings.yaml.
+
+Example:
+  vervids pathmap add /Volumes/Footage 'F:\Footage'            # Current project
+  vervids pathmap add --global /Volumes/Footage 'F:\Footage'   # All projects
+  vervids pathmap list
+  vervids pathmap remove /Volumes/Footage`,
+}
+
+var pathmapAddCmd = &cobra.Command{
+	Use:   "add <from> <to>",
+	Short: "Add a path mapping",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		global, _ := cmd.Flags().GetBool("global")
+		mapping := assets.PathMapping{From: args[0], To: args[1]}
+
+		if global {
+			s, err := settings.Load()
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			s.PathMappings = upsertPathMapping(s.PathMappings, mapping)
+			if err := s.Save(); err != nil {
+				exitWithError(err, i18n.T("Error saving settings: %v", err))
+			}
+			fmt.Println(successMsg(i18n.T("Added global mapping: %s -> %s", mapping.From, mapping.To)))
+			return
+		}
+
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		proj.PathMappings = upsertPathMapping(proj.PathMappings, mapping)
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("Added mapping for %s: %s -> %s", proj.ProjectName, mapping.From, mapping.To)))
+	},
+}
+
+var pathmapRemoveCmd = &cobra.Command{
+	Use:   "remove <from>",
+	Short: "Remove a path mapping",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		global, _ := cmd.Flags().GetBool("global")
+
+		if global {
+			s, err := settings.Load()
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			var removed bool
+			s.PathMappings, removed = removePathMapping(s.PathMappings, args[0])
+			if !removed {
+				exitWithError(nil, i18n.T("No global mapping for '%s'", args[0]))
+			}
+			if err := s.Save(); err != nil {
+				exitWithError(err, i18n.T("Error saving settings: %v", err))
+			}
+			fmt.Println(successMsg(i18n.T("Removed global mapping for %s", args[0])))
+			return
+		}
+
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		var removed bool
+		proj.PathMappings, removed = removePathMapping(proj.PathMappings, args[0])
+		if !removed {
+			exitWithError(nil, i18n.T("No mapping for '%s' in %s", args[0], proj.ProjectName))
+		}
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("Removed mapping for %s", args[0])))
+	},
+}
+
+var pathmapListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List project and global path mappings",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Project mappings are shown only when a project is already selected
+		if storage.HasContext() {
+			if proj, err := ensureProjectContext(); err == nil {
+				fmt.Println(infoMsg(i18n.T("Project mappings (%s):", proj.ProjectName)))
+				printPathMappings(proj.PathMappings)
+				fmt.Println()
+			}
+		}
+
+		s, err := settings.Load()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		fmt.Println(infoMsg(i18n.T("Global mappings:")))
+		printPathMappings(s.PathMappings)
+	},
+}
+
+// printPathMappings prints mappings one per line, or a placeholder if there are none
+func printPathMappings(mappings []assets.PathMapping) {
+	if len(mappings) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	for _, m := range mappings {
+		fmt.Printf("  %s %s %s\n", m.From, ui.InfoStyle.Render("↔"), m.To)
+	}
+}
+
+// upsertPathMapping replaces the mapping with the same From prefix or appends a new one
+func upsertPathMapping(mappings []assets.PathMapping, mapping assets.PathMapping) []assets.PathMapping {
+	for i, m := range mappings {
+		if m.From == mapping.From {
+			mappings[i] = mapping
+			return mappings
+		}
+	}
+	return append(mappings, mapping)
+}
+
+// removePathMapping removes the mapping with the given From prefix
+func removePathMapping(mappings []assets.PathMapping, from string) ([]assets.PathMapping, bool) {
+	for i, m := range mappings {
+		if m.From == from {
+			return append(mappings[:i], mappings[i+1:]...), true
+		}
+	}
+	return mappings, false
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var pushCmd = &cobra.Command{
+	Use:     "push",
+	Aliases: []string{"flush"},
+	Short:   "Upload staged versions into storage",
+	Long: `Push staged versions into Docker or the project's remote, oldest first. 'vervids commit' snapshots
+the project file and changed assets into a local staging area before uploading them, so
+a commit whose upload failed is kept there, as is every 'vervids commit --offline'.
+Each one becomes the next version of its project, keeping the message, time and author
+it was committed with. Staged files are removed once stored, or when the project turns
+the version down, e.g. its pre-commit hook fails; one that couldn't be uploaded is kept
+for the next push.
+
+When another copy of the project (a teammate's checkout, another machine) has stored
+versions meanwhile, both histories have moved on from the same point. Rather than
+refusing, push asks how to combine them:
+  renumber    keep the other copy's versions; this copy's follow them
+  interleave  order both copies' versions by commit time, renumbering later ones
+  abort       store nothing and keep the staged versions
+--resolve gives the answer up front; without a terminal, push aborts.
+
+Example:
+  vervids commit --offline "Grade pass" "/path/to/exported.aepx"
+  vervids push --list
+  vervids push
+  vervids push --resolve interleave`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		staged, err := project.LoadStagedCommits()
+		if err != nil {
+			exitWithError(err, i18n.T("Error reading staged commits: %v", err))
+		}
+		if len(staged) == 0 {
+			fmt.Println(successMsg(i18n.T("Nothing to push")))
+			return
+		}
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			for _, s := range staged {
+				fmt.Printf("%s  %s  %s  %.2f MB\n", ui.InfoStyle.Render(s.ProjectName), s.Timestamp.Format("2006-01-02 15:04"), s.Message, float64(s.Size())/(1024*1024))
+			}
+			return
+		}
+
+		if stagedNeedDocker(staged) {
+			if err := docker.EnsureDockerReady(); err != nil {
+				exitWithError(err, i18n.T("Error: %v", err), i18n.T("Staged versions are kept; run 'vervids push' again once Docker is available."))
+			}
+		}
+
+		forceQuota, _ := cmd.Flags().GetBool("force-quota")
+		resolve := resolveFlag(cmd)
+		interleave := map[string]int{} // Config path to where interleaving starts
+		pushed := 0
+		for _, s := range staged {
+			fmt.Println(infoMsg(i18n.T("📦 %s: %s", s.ProjectName, s.Message)))
+			proj, v, err := pushStaged(s, forceQuota, resolve, interleave)
+			if project.IsRefused(err) {
+				// Pushing it again would be turned down the same way
+				fmt.Println(errorMsg(i18n.T("Error committing version: %v", err)))
+				if err := s.Remove(); err != nil {
+					fmt.Println(warningMsg(i18n.T("Could not remove %s: %v", s.Dir(), err)))
+				} else {
+					fmt.Println(infoMsg(i18n.T("Discarded the staged version")))
+				}
+				continue
+			}
+			if err != nil {
+				fmt.Println(errorMsg(i18n.T("Error committing version: %v", err)))
+				fmt.Println(infoMsg(i18n.T("Kept in %s", s.Dir())))
+				continue
+			}
+			if err := s.Remove(); err != nil {
+				fmt.Println(warningMsg(i18n.T("Could not remove %s: %v", s.Dir(), err)))
+			}
+			fmt.Println(successMsg(i18n.T("Committed version %d", v.Number)))
+			printCommitStorage(proj, v)
+			pushed++
+		}
+
+		// Staged versions older than the other copy's go back to their place by time
+		for configPath, base := range interleave {
+			err := inProjectDirectory(configPath, func() error {
+				proj, err := project.LoadFromPath(configPath)
+				if err != nil {
+					return err
+				}
+				return proj.InterleaveSince(base)
+			})
+			if err != nil {
+				fmt.Println(warningMsg(i18n.T("Could not order versions by time: %v", err)))
+			} else {
+				fmt.Println(successMsg(i18n.T("Ordered versions by commit time")))
+			}
+		}
+
+		fmt.Println()
+		if pushed < len(staged) {
+			exitWithError(nil, i18n.T("Pushed %d of %d staged version(s)", pushed, len(staged)))
+		}
+		fmt.Println(successMsg(i18n.T("Pushed %d staged version(s)", pushed)))
+	},
+}
+
+// stagedNeedDocker reports whether any staged version belongs to a project stored in
+// Docker rather than a remote
+func stagedNeedDocker(staged []*project.StagedCommit) bool {
+	for _, s := range staged {
+		if proj, err := project.LoadFromPath(s.ConfigPath); err != nil || proj.Remote == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// pushStaged commits a staged version from its project's directory, where the
+// project's config and hash cache live. Projects whose divergence was resolved by
+// interleaving are added to interleave, to be ordered once all their versions are in.
+func pushStaged(s *project.StagedCommit, forceQuota bool, resolve string, interleave map[string]int) (*project.Project, *project.Version, error) {
+	var proj *project.Project
+	var v *project.Version
+	err := inProjectDirectory(s.ConfigPath, func() (err error) {
+		if proj, err = project.LoadFromPath(s.ConfigPath); err != nil {
+			return err
+		}
+		strategy, base, err := settleDivergence(proj, resolve)
+		if err != nil {
+			return err
+		}
+		if strategy == project.ResolveInterleave {
+			if prev, ok := interleave[s.ConfigPath]; !ok || base < prev {
+				interleave[s.ConfigPath] = base
+			}
+		}
+		v, err = proj.CommitStaged(s, forceQuota)
+		return err
+	})
+	return proj, v, err
+}
+
+// resolveFlag returns --resolve, exiting when it isn't one of the strategies
+func resolveFlag(cmd *cobra.Command) string {
+	resolve, _ := cmd.Flags().GetString("resolve")
+	switch resolve {
+	case "", project.ResolveRenumber, project.ResolveInterleave, project.ResolveAbort:
+		return resolve
+	}
+	exitWithError(nil, i18n.T("Unknown --resolve '%s'", resolve),
+		i18n.T("Use %s, %s or %s", project.ResolveRenumber, project.ResolveInterleave, project.ResolveAbort))
+	return ""
+}
+
+// settleDivergence checks whether another copy of the project stored versions this
+// config doesn't know and, if so, combines the histories as resolve says, asking when
+// it's empty and stdin is a terminal. It returns the strategy used and where the
+// histories part. On abort nothing changes, and the commit is refused as before.
+func settleDivergence(proj *project.Project, resolve string) (string, int, error) {
+	d, err := proj.CheckDivergence()
+	if err != nil || d == nil {
+		return "", 0, err
+	}
+	strategy := resolve
+	if strategy == "" {
+		strategy = askResolution(d)
+	}
+	if strategy == project.ResolveAbort {
+		return strategy, d.Base, nil
+	}
+	if err := proj.ResolveDivergence(d, strategy); err != nil {
+		return "", 0, err
+	}
+	fmt.Println(successMsg(i18n.T("Combined with %d version(s) from %s", len(d.Remote), divergenceSource(d))))
+	return strategy, d.Base, nil
+}
+
+// askResolution shows how the histories differ and asks how to combine them
+func askResolution(d *project.Divergence) string {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return project.ResolveAbort
+	}
+	fmt.Println(warningMsg(i18n.T("⚠️  %s stored %d version(s) this copy doesn't have:", divergenceSource(d), len(d.Remote))))
+	printDivergentVersions(d.Remote)
+	if len(d.Local) > 0 {
+		fmt.Println(warningMsg(i18n.T("This copy has %d version(s) the other doesn't:", len(d.Local))))
+		printDivergentVersions(d.Local)
+	}
+	next := d.Base + len(d.Remote)
+	fmt.Println(infoMsg(i18n.T("How should the histories be combined?")))
+	fmt.Printf("  [r] Renumber: keep the other copy's versions; this copy's follow from v%03d\n", next)
+	fmt.Printf("  [i] Interleave: order both by commit time, renumbering later versions\n")
+	fmt.Printf("  [a] Abort: store nothing\n")
+	fmt.Print(infoMsg(i18n.T("Choice [r/i/A]: ")))
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "r", project.ResolveRenumber:
+		return project.ResolveRenumber
+	case "i", project.ResolveInterleave:
+		return project.ResolveInterleave
+	}
+	return project.ResolveAbort
+}
+
+// divergenceSource names the copy that published storage's history
+func divergenceSource(d *project.Divergence) string {
+	if d.From == "" {
+		return i18n.T("Another copy of this project")
+	}
+	return d.From
+}
+
+// printDivergentVersions lists versions one per line, as 'vervids list' does
+func printDivergentVersions(versions []project.Version) {
+	for _, v := range versions {
+		line := fmt.Sprintf("  v%03d  %s  %s", v.Number, v.Timestamp.Format("2006-01-02 15:04"), v.Message)
+		if v.Author != "" {
+			line += "  " + ui.InfoStyle.Render(v.Author)
+		}
+		fmt.Println(line)
+	}
+}
+
+// stageCommit handles 'vervids commit --offline', copying the version into the staging
+// area instead of Docker
+func stageCommit(proj *project.Project, message string, aepxFilePath string, opts project.CommitOptions, asJSON bool) {
+	fmt.Println(infoMsg(i18n.T("📦 Staging version offline...")))
+
+	s, err := proj.StageCommit(message, aepxFilePath, opts)
+	if err != nil {
+		exitWithError(err, i18n.T("Error staging version: %v", err))
+	}
+	if asJSON {
+		printJSON(jsonStagedResult{
+			Dir:       s.Dir(),
+			Message:   s.Message,
+			Timestamp: s.Timestamp,
+			Meta:      s.Meta,
+			Assets:    len(s.Assets),
+			Missing:   s.Missing,
+			Size:      s.Size(),
+		})
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(successMsg(i18n.T("Staged version offline")))
+	fmt.Printf("  Message: %s\n", s.Message)
+	fmt.Printf("  Time: %s\n", s.Timestamp.Format("2006-01-02 15:04:05"))
+	if len(s.Meta) > 0 {
+		fmt.Printf("  Meta: %s\n", strings.Join(project.MetaPairs(s.Meta), ", "))
+	}
+	fmt.Printf("  Assets: %d files\n", len(s.Assets))
+	if len(s.Missing) > 0 {
+		fmt.Println(warningMsg(i18n.T("  Missing: %d asset(s) not stored", len(s.Missing))))
+	}
+	fmt.Printf("  Staged: %.2f MB in %s\n", float64(s.Size())/(1024*1024), s.Dir())
+	fmt.Println(infoMsg(i18n.T("Run 'vervids push' when Docker is available to store it.")))
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/s3"
+	"github.com/ajeebtech/vervideos/internal/settings"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Store new projects in an S3-compatible bucket instead of Docker",
+	Long: `A remote is an S3-compatible bucket (AWS S3, MinIO, and the like) that new projects
+are stored in instead of the local Docker volume. init, commit, pull and prune work the
+same against either; the bucket is recorded in each project's config when it is
+initialized, so projects made before a remote was added stay in Docker, and removing
+the remote only affects projects initialized after that.
+
+Credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and, for temporary
+credentials, AWS_SESSION_TOKEN; they are never written to settings.yaml. The remote
+itself is kept in ~/.vervids/settings.yaml.
+
+Maintenance commands that run inside the storage container (optimize, dedupe
+consolidate, clean, bundle) only work on projects stored in Docker.
+
+Example:
+  vervids remote add s3://studio-vervids/projects --region eu-west-1
+  vervids remote add s3://vervids --endpoint http://nas.local:9000   # MinIO
+  vervids remote show
+  vervids remote remove`,
+}
+
+var remoteAddCmd = &cobra.Command{
+	Use:   "add <s3://bucket/prefix>",
+	Short: "Store new projects in a bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		endpoint, _ := cmd.Flags().GetString("endpoint")
+		region, _ := cmd.Flags().GetString("region")
+		remote := &s3.Config{URL: args[0], Endpoint: endpoint, Region: region}
+		bucket, err := s3.New(*remote, docker.StoragePath)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if noCheck, _ := cmd.Flags().GetBool("no-check"); !noCheck {
+			if err := bucket.EnsureReady(); err != nil {
+				exitWithError(err, i18n.T("Error: %v", err), i18n.T("Add it anyway with --no-check"))
+			}
+		}
+
+		s, err := settings.Load()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		s.Remote = remote
+		if err := s.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving settings: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("New projects will be stored in %s", bucket.Name())))
+	},
+}
+
+var remoteRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Store new projects in Docker again",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := settings.Load()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if s.Remote == nil {
+			exitWithError(nil, i18n.T("No remote is set"))
+		}
+		url := s.Remote.URL
+		s.Remote = nil
+		if err := s.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving settings: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("Removed remote %s; new projects will be stored in Docker", url)))
+		fmt.Println(infoMsg(i18n.T("Projects already stored there keep using it")))
+	},
+}
+
+var remoteShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show where new projects and the current project are stored",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := settings.Load()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if s.Remote == nil {
+			fmt.Printf("New projects: Docker\n")
+		} else {
+			fmt.Printf("New projects: %s\n", s.Remote.URL)
+			if s.Remote.Endpoint != "" {
+				fmt.Printf("  Endpoint: %s\n", s.Remote.Endpoint)
+			}
+			if s.Remote.Region != "" {
+				fmt.Printf("  Region: %s\n", s.Remote.Region)
+			}
+		}
+
+		// The current project is shown only when one is already selected
+		if storage.HasContext() {
+			if proj, err := ensureProjectContext(); err == nil {
+				fmt.Printf("%s: %s\n", proj.ProjectName, proj.StorageName())
+			}
+		}
+	},
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <version> --in-place",
+	Short: "Roll the working project file back to a version",
+	Long: `Overwrite your working project file with a version, unlike 'vervids pull', which
+copies the version into another directory. The working file is the one you last
+committed from; use --file to restore a different one.
+
+The current file is first copied to .vervids/backups, so the restore can be undone by
+hand. Assets missing on this machine are restored next to the file as on pull, and
+the project's asset references are rewritten to where they are found locally. The
+restore is recorded in the project's history and shown by 'vervids log'; nothing is
+committed.
+
+You are asked to confirm first, with a warning if the file has uncommitted changes;
+--yes restores without asking.
+
+Example:
+  vervids restore 3 --in-place
+  vervids restore 3 --in-place --file ./edit.aepx --yes`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		versionNum := parseVersionArg(proj, args[0])
+		v, err := proj.FindVersion(versionNum)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		target, _ := cmd.Flags().GetString("file")
+		if target == "" {
+			target = proj.WorkingFile()
+		}
+		if target == "" {
+			exitWithError(nil, i18n.T("No working project file found"),
+				i18n.T("Name the file to overwrite with --file, or use 'vervids pull %d' to copy the version elsewhere.", versionNum))
+		}
+		if target, err = filepath.Abs(target); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		yes, _ := cmd.Flags().GetBool("yes")
+		if !yes {
+			if !term.IsTerminal(os.Stdin.Fd()) {
+				exitWithError(nil, i18n.T("Refusing to overwrite %s without confirmation", target), i18n.T("Run with --yes to restore without asking"))
+			}
+			if target == proj.WorkingFile() && proj.WorkingFileChanged(storage.LoadWorkingHashCache()) {
+				fmt.Println(warningMsg(i18n.T("%s has changes that are not committed", filepath.Base(target))))
+			}
+			fmt.Print(infoMsg(i18n.T("Overwrite %s with version %d (%s)? (y/N): ", target, versionNum, v.Message)))
+			input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if answer := strings.ToLower(strings.TrimSpace(input)); answer != "y" && answer != "yes" {
+				fmt.Println(infoMsg(i18n.T("Restore cancelled")))
+				return
+			}
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		fmt.Println(infoMsg(i18n.T("📦 Restoring version %d...", versionNum)))
+		warnNewerAEVersion(v)
+		preserveStructure, _ := cmd.Flags().GetBool("preserve-structure")
+		record, err := proj.RestoreInPlace(versionNum, target, project.RestoreOptions{PreserveStructure: preserveStructure})
+		if err != nil {
+			exitWithError(err, i18n.T("Error restoring version: %v", err))
+		}
+
+		fmt.Println()
+		fmt.Println(successMsg(i18n.T("Restored version %d", versionNum)))
+		fmt.Printf("  Project file: %s\n", record.FilePath)
+		if record.Backup != "" {
+			fmt.Printf("  Backup: %s\n", record.Backup)
+		}
+	},
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/spf13/cobra"
+)
+
+var revertCmd = &cobra.Command{
+	Use:   "revert <version>",
+	Short: "Undo one version's changes as a new version",
+	Long: `Create a new version that undoes what the given version changed relative to the one
+before it, keeping everything committed since, like 'git revert'. History is not
+rewritten: the reverted version stays and the undo is recorded on top.
+
+Edits to the project file are undone line by line. If later versions changed the same
+parts of the file, nothing is committed; pull the version before it and re-apply the
+changes by hand instead. Assets the version added are dropped, assets it removed come
+back and assets it changed get their earlier content, unless they changed again later.
+Stored content is reused, so the asset files don't need to be on this machine.
+
+Your local .aepx is not touched; pull the new version to open it.
+
+Example:
+  vervids revert 4
+  vervids revert 4 -m "Back out the new title card"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		versionNum := parseVersionArg(proj, args[0])
+		target, err := proj.FindVersion(versionNum)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		message, _ := cmd.Flags().GetString("message")
+		if message == "" {
+			message = fmt.Sprintf("Revert \"%s\" (v%d)", target.Message, versionNum)
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		fmt.Println(infoMsg(i18n.T("📦 Reverting version %d...", versionNum)))
+		result, err := proj.RevertVersion(versionNum, message)
+		if errs.KindOf(err) == errs.Conflict {
+			exitWithError(err, i18n.T("Cannot revert version %d: %v", versionNum, err),
+				i18n.T("Pull version %d and re-apply the later changes by hand: vervids pull %d", versionNum-1, versionNum-1))
+		}
+		if err != nil {
+			exitWithError(err, i18n.T("Error reverting version: %v", err))
+		}
+
+		v := result.Version
+		fmt.Println()
+		fmt.Println(successMsg(i18n.T("Committed version %d", v.Number)))
+		fmt.Printf("  Message: %s\n", v.Message)
+		fmt.Printf("  Time: %s\n", v.Timestamp.Format("2006-01-02 15:04:05"))
+		if v.Author != "" {
+			fmt.Printf("  Author: %s\n", v.Author)
+		}
+		fmt.Printf("  Assets: %d files\n", v.AssetCount)
+		for _, name := range result.Kept {
+			fmt.Println(warningMsg(i18n.T("  Kept %s: changed again after v%d", name, versionNum)))
+		}
+		fmt.Println(infoMsg(i18n.T("Use 'vervids pull %d' to open the reverted project.", v.Number)))
+	},
+}
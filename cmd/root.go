@@ -3,16 +3,24 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ajeebtech/vervideos/internal/api"
+	"github.com/ajeebtech/vervideos/internal/assets"
 	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/logging"
 	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/settings"
 	"github.com/ajeebtech/vervideos/internal/storage"
 	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/ajeebtech/vervideos/internal/update"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 )
@@ -32,7 +40,16 @@ func SetVersionInfo(v, c, d string) {
 var rootCmd = &cobra.Command{
 	Use:   "vervids",
 	Short: "Version control for Adobe After Effects projects",
-	Long:  `vervids is a local version control system for .ae (Adobe After Effects) files.`,
+	Long: `vervids is a local version control system for .ae (Adobe After Effects) files.
+
+Exit codes: 0 success, 1 other failure, 3 Docker unavailable, 4 project not found,
+5 version not found, 6 storage full, 7 conflict, 8 read-only, 9 remote storage
+unavailable. With --json, failures are printed as
+{"error": {"code", "exit_code", "message"}}.
+
+With --json, list, show, commit, pull, prune and delete print their result as one JSON
+document on stdout, and progress and warnings on stderr. Versions and assets have the
+same fields as in .vervids/config.json.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		printBoxedHeader()
 
@@ -48,12 +65,12 @@ var rootCmd = &cobra.Command{
 					// We have a valid project context, show commits
 					showProjectCommits(proj)
 					fmt.Println()
-					fmt.Println(infoMsg("Available commands:"))
-					fmt.Println(infoMsg("  • vervids commit \"message\" <file.aepx> - Commit a new version"))
-					fmt.Println(infoMsg("  • vervids list - List all projects"))
-					fmt.Println(infoMsg("  • vervids show <version> - Show version details"))
-					fmt.Println(infoMsg("  • vervids pull <version> - Pull a version from Docker"))
-					fmt.Println(infoMsg("  • vervids help - Show all commands"))
+					fmt.Println(infoMsg(i18n.T("Available commands:")))
+					fmt.Println(infoMsg(i18n.T("  • vervids commit \"message\" <file.aepx> - Commit a new version")))
+					fmt.Println(infoMsg(i18n.T("  • vervids list - List all projects")))
+					fmt.Println(infoMsg(i18n.T("  • vervids show <version> - Show version details")))
+					fmt.Println(infoMsg(i18n.T("  • vervids pull <version> - Pull a version from Docker")))
+					fmt.Println(infoMsg(i18n.T("  • vervids help - Show all commands")))
 					return
 				}
 			}
@@ -64,11 +81,11 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			if strings.Contains(err.Error(), "no projects available") {
 				fmt.Println()
-				fmt.Println(infoMsg("To get started:"))
-				fmt.Println(infoMsg("  • Use 'vervids init <file.aepx>' to initialize a new project"))
-				fmt.Println(infoMsg("  • Use 'vervids help' to see all available commands"))
+				fmt.Println(infoMsg(i18n.T("To get started:")))
+				fmt.Println(infoMsg(i18n.T("  • Use 'vervids init <file.aepx>' to initialize a new project")))
+				fmt.Println(infoMsg(i18n.T("  • Use 'vervids help' to see all available commands")))
 			} else {
-				fmt.Println(errorMsg(fmt.Sprintf("Error: %v", err)))
+				fmt.Println(errorMsg(i18n.T("Error: %v", err)))
 			}
 			return
 		}
@@ -78,11 +95,11 @@ var rootCmd = &cobra.Command{
 			fmt.Println()
 			showProjectCommits(proj)
 			fmt.Println()
-			fmt.Println(infoMsg("Available commands:"))
-			fmt.Println(infoMsg("  • vervids commit \"message\" <file.aepx> - Commit a new version"))
-			fmt.Println(infoMsg("  • vervids list - List all projects"))
-			fmt.Println(infoMsg("  • vervids show <version> - Show version details"))
-			fmt.Println(infoMsg("  • vervids help - Show all commands"))
+			fmt.Println(infoMsg(i18n.T("Available commands:")))
+			fmt.Println(infoMsg(i18n.T("  • vervids commit \"message\" <file.aepx> - Commit a new version")))
+			fmt.Println(infoMsg(i18n.T("  • vervids list - List all projects")))
+			fmt.Println(infoMsg(i18n.T("  • vervids show <version> - Show version details")))
+			fmt.Println(infoMsg(i18n.T("  • vervids help - Show all commands")))
 		}
 	},
 }
@@ -105,14 +122,12 @@ var versionCmd = &cobra.Command{
 var (
 	headerStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("62")).
 			Padding(0, 1).
 			MarginBottom(1).
 			Align(lipgloss.Center).
 			Width(36)
 
 	headerTextStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("62")).
 			Bold(true)
 )
 
@@ -138,12 +153,114 @@ func printBoxedHeader() {
 		headerText = "🌊 vervids CLI (dev)"
 	}
 
+	notice := ""
+	if latest := updateNotice(); latest != "" {
+		notice = i18n.T("Update available: %s", latest)
+	}
+
+	if ui.Plain() {
+		fmt.Println(ui.Text(headerText))
+		if notice != "" {
+			fmt.Println(notice)
+		}
+		fmt.Println()
+		return
+	}
+
 	// Style the header text and render in box
-	styledText := headerTextStyle.Render(headerText)
-	box := headerStyle.Render(styledText)
+	accent := ui.AccentStyle.GetForeground()
+	styledText := headerTextStyle.Foreground(accent).Render(headerText)
+	if notice != "" {
+		styledText += "\n" + ui.WarningStyle.Render("⬆ "+notice)
+	}
+	box := headerStyle.BorderForeground(accent).Render(styledText)
 	fmt.Println(box)
 }
 
+// applyPlainFlags turns on plain output when --no-color or --plain is given
+func applyPlainFlags() {
+	noColor, _ := rootCmd.PersistentFlags().GetBool("no-color")
+	plainFlag, _ := rootCmd.PersistentFlags().GetBool("plain")
+	if noColor || plainFlag {
+		ui.SetPlain(true)
+	}
+}
+
+// setupLogging configures logging from --verbose, --debug and --log-file (or
+// log_to_file in settings.yaml)
+func setupLogging() {
+	verbose, _ := rootCmd.PersistentFlags().GetBool("verbose")
+	debug, _ := rootCmd.PersistentFlags().GetBool("debug")
+	toFile, _ := rootCmd.PersistentFlags().GetBool("log-file")
+	if s, err := settings.Load(); err == nil && s.LogToFile {
+		toFile = true
+	}
+	logPath, err := logging.Setup(logging.Options{Verbose: verbose, Debug: debug, File: toFile})
+	if err != nil {
+		fmt.Println(warningMsg(i18n.T("Logging: %v", err)))
+		return
+	}
+	slog.Debug("command", "args", strings.Join(os.Args[1:], " "), "version", version, "log", logPath)
+}
+
+// applyJSONFlag leaves error reporting to Execute in --json mode, so scripts get an
+// error object rather than cobra's text
+func applyJSONFlag() {
+	if jsonOutput() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+	}
+}
+
+// applySettings switches to the language and color theme chosen in ~/.vervids/settings.yaml
+func applySettings() {
+	s, err := settings.Load()
+	if err != nil {
+		return
+	}
+	storage.SetTempDir(s.TempDir)
+	storage.SetCacheDir(s.CacheDir)
+	docker.SetContainerOptions(s.Container.Options())
+	if s.Language != "" {
+		if err := i18n.SetLanguage(s.Language); err != nil {
+			fmt.Println(warningMsg(i18n.T("Settings: %v", err)))
+		}
+	}
+	if s.Theme == "" && s.Colors == (ui.Theme{}) {
+		return
+	}
+	theme, err := ui.ResolveTheme(s.Theme, s.Colors)
+	if err != nil {
+		fmt.Println(warningMsg(i18n.T("Settings: %v", err)))
+	}
+	ui.ApplyTheme(theme)
+}
+
+// updateCheckEnabled reports whether release lookups are allowed: not for dev builds, and
+// not when turned off with VERVIDS_NO_UPDATE_CHECK or no_update_check in settings.yaml
+func updateCheckEnabled() bool {
+	if !update.Enabled(version) {
+		return false
+	}
+	s, err := settings.Load()
+	return err == nil && !s.NoUpdateCheck
+}
+
+// startUpdateCheck looks up the latest release in the background, at most once a day
+func startUpdateCheck() {
+	if updateCheckEnabled() {
+		update.Start()
+	}
+}
+
+// updateNotice returns the newer release found by the last update check, if any
+func updateNotice() string {
+	if !updateCheckEnabled() {
+		return ""
+	}
+	return update.Notice(version)
+}
+
 // Helper functions for styled output (using shared ui package)
 func successMsg(msg string) string {
 	return ui.Success(msg)
@@ -167,6 +284,25 @@ var initCmd = &cobra.Command{
 	Long: `Initialize version control for an .aepx file. This creates a local .vervids config and stores the initial version in Docker.
 
 Docker is required (24.0.0 or newer). Files are stored under /vervids/<projectDir>/vXXX/ in the Docker volume.
+After 'vervids remote add s3://bucket/prefix', new projects are stored in that bucket
+instead, under <prefix>/<projectDir>/vXXX/, and Docker isn't needed.
+
+Nuke scripts (.nk) can be versioned the same way: the files their Read and Write nodes
+point at are tracked as assets, with frame sequences (####, %04d) stored frame by frame.
+
+With --generic, any file (a PSD, a C4D scene, a brief) is versioned as whole-file
+snapshots with messages, without looking for assets, so a project's collateral can sit
+in the same storage. Later commits must have the same extension, and the file is left
+in place rather than deleted after init.
+
+With --template, the project starts from a template in ~/.vervids/templates (or the
+templates_dir setting): a folder holding template.yaml with the folders to create, the
+project settings to use (any 'vervids config' key, e.g. exclude_folders or quota_gb)
+and starting notes, plus optionally files/, copied next to the project file, and
+hooks/, copied into .vervids/hooks. Executable pre-commit and post-commit hooks there
+run around every commit with the project file and version number as arguments; a
+failing pre-commit hook cancels the commit.
+Example: vervids init --template client-job promo.aepx
 
 If a .vervids directory exists for a different project file, it will be automatically removed.
 Use --force to re-initialize the same project file (this will delete existing version history).`,
@@ -177,22 +313,29 @@ Use --force to re-initialize the same project file (this will delete existing ve
 
 		// Check if file exists
 		if _, err := os.Stat(aepxFilePath); os.IsNotExist(err) {
-			fmt.Println(errorMsg(fmt.Sprintf("File '%s' does not exist", aepxFilePath)))
-			os.Exit(1)
+			exitWithError(nil, i18n.T("File '%s' does not exist", aepxFilePath))
+		}
+
+		// Check if it's a supported project file; --generic takes any file
+		generic, _ := cmd.Flags().GetBool("generic")
+		if !generic && !assets.IsProjectFile(aepxFilePath) {
+			exitWithError(nil, i18n.T("File must be a project file (%s)", strings.Join(assets.ProjectExtensions(), ", ")), i18n.T("Note: vervids works with .aepx (XML) files, not binary .ae files"))
 		}
 
-		// Check if it's an .aepx file
-		if filepath.Ext(aepxFilePath) != ".aepx" {
-			fmt.Println(errorMsg("File must have .aepx extension"))
-			fmt.Println(infoMsg("Note: vervids works with .aepx (XML) files, not binary .ae files"))
-			os.Exit(1)
+		// Load the template before anything is changed, so a typo doesn't cost the old project
+		var template *project.Template
+		if name, _ := cmd.Flags().GetString("template"); name != "" {
+			t, err := project.LoadTemplate(loadSettings().TemplatesPath(), name)
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			template = t
 		}
 
 		// Get absolute path for comparison
 		absPath, err := filepath.Abs(aepxFilePath)
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error: %v", err))
 		}
 
 		// Change to the directory containing the .aepx file
@@ -200,22 +343,19 @@ Use --force to re-initialize the same project file (this will delete existing ve
 		aepxDir := filepath.Dir(absPath)
 		originalDir, err := os.Getwd()
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error getting current directory: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error getting current directory: %v", err))
 		}
 		
 		// Check if we can write to the .aepx file's directory
 		if err := os.Chdir(aepxDir); err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error: Cannot access directory '%s': %v", aepxDir, err)))
-			fmt.Println(infoMsg("This may be a permissions issue. Please ensure you have write access to the directory."))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error: Cannot access directory '%s': %v", aepxDir, err), i18n.T("This may be a permissions issue. Please ensure you have write access to the directory."))
 		}
 		
 		// Restore original directory on exit
 		defer func() {
 			if err := os.Chdir(originalDir); err != nil {
 				// Non-fatal, just log
-				fmt.Println(warningMsg(fmt.Sprintf("Warning: Could not restore original directory: %v", err)))
+				fmt.Println(warningMsg(i18n.T("Warning: Could not restore original directory: %v", err)))
 			}
 		}()
 
@@ -229,66 +369,69 @@ Use --force to re-initialize the same project file (this will delete existing ve
 				if existingPath == absPath {
 					// Same file - user should use commit
 					if !force {
-						fmt.Println(errorMsg("This project file is already initialized"))
-						fmt.Printf("  Existing project: %s\n", existingProj.ProjectName)
-						fmt.Println(infoMsg("  Use 'vervids commit \"message\" <file.aepx>' to save new versions"))
-						fmt.Println(infoMsg("  Or use 'vervids delete <project-name>' to delete the project and start fresh"))
-						os.Exit(1)
+						exitWithKind(errs.Conflict, i18n.T("This project file is already initialized"),
+							fmt.Sprintf("  Existing project: %s", existingProj.ProjectName),
+							i18n.T("  Use 'vervids commit \"message\" <file.aepx>' to save new versions"),
+							i18n.T("  Or use 'vervids delete <project-name>' to delete the project and start fresh"))
 					}
 				} else {
 					// Different file - automatically remove old project
-					fmt.Println(warningMsg("Found existing project for a different file"))
+					fmt.Println(warningMsg(i18n.T("Found existing project for a different file")))
 					fmt.Printf("  Existing: %s\n", existingProj.ProjectName)
 					fmt.Printf("  New:      %s\n", filepath.Base(absPath))
-					fmt.Println(infoMsg("  Removing old project to initialize new one..."))
+					fmt.Println(infoMsg(i18n.T("  Removing old project to initialize new one...")))
 				}
 			} else {
 				// Can't load existing project - might be corrupted or incomplete
 				if !force {
-					fmt.Println(warningMsg("Found .vervids directory but couldn't load project"))
-					fmt.Println(infoMsg("  Removing it to start fresh..."))
+					fmt.Println(warningMsg(i18n.T("Found .vervids directory but couldn't load project")))
+					fmt.Println(infoMsg(i18n.T("  Removing it to start fresh...")))
 				} else {
-					fmt.Println(warningMsg("Force flag detected: removing existing .vervids directory..."))
+					fmt.Println(warningMsg(i18n.T("Force flag detected: removing existing .vervids directory...")))
 				}
 			}
 
 			// Remove existing .vervids directory
 			if err := os.RemoveAll(storage.VerVidsDir); err != nil {
-				fmt.Println(errorMsg(fmt.Sprintf("Error removing existing .vervids directory: %v", err)))
-				os.Exit(1)
+				exitWithError(err, i18n.T("Error removing existing .vervids directory: %v", err))
 			}
-			fmt.Println(successMsg("Removed existing .vervids directory"))
+			fmt.Println(successMsg(i18n.T("Removed existing .vervids directory")))
 		}
 
-		if err := docker.EnsureDockerReady(); err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("%v", err)))
-			os.Exit(1)
+		if s, err := settings.Load(); err == nil && s.Remote != nil {
+			fmt.Println(infoMsg(i18n.T("🚀 Initializing vervids project (storage: %s)...", s.Remote.URL)))
+		} else {
+			if err := docker.EnsureDockerReady(); err != nil {
+				exitWithError(err, i18n.T("%v", err))
+			}
+			fmt.Println(infoMsg(i18n.T("🚀 Initializing vervids project (Docker storage)...")))
 		}
-
-		fmt.Println(infoMsg("🚀 Initializing vervids project (Docker storage)..."))
-		proj, err := project.Initialize(absPath)
+		resolveSymlinks, _ := cmd.Flags().GetBool("resolve-symlinks")
+		proj, err := project.Initialize(absPath, project.InitOptions{ResolveSymlinks: resolveSymlinks, Generic: generic, Template: template})
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error initializing project: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error initializing project: %v", err))
 		}
 
 		// Delete the .aepx file after successful initialization and Docker execution
-		// Check if file still exists before attempting deletion
-		if _, err := os.Stat(absPath); err == nil {
-			if err := os.Remove(absPath); err != nil {
-				fmt.Println(warningMsg(fmt.Sprintf("Warning: Could not delete .aepx file '%s': %v", filepath.Base(absPath), err)))
-			} else {
-				fmt.Println(successMsg(fmt.Sprintf("✓ Deleted .aepx file: %s", filepath.Base(absPath))))
+		// Check if file still exists before attempting deletion. Generic files are
+		// originals rather than exports, so they stay where they are.
+		if !generic {
+			if _, err := os.Stat(absPath); err == nil {
+				if err := os.Remove(absPath); err != nil {
+					fmt.Println(warningMsg(i18n.T("Warning: Could not delete .aepx file '%s': %v", filepath.Base(absPath), err)))
+				} else {
+					fmt.Println(successMsg(i18n.T("✓ Deleted .aepx file: %s", filepath.Base(absPath))))
+				}
+			} else if os.IsNotExist(err) {
+				// File already doesn't exist (might have been deleted elsewhere)
+				fmt.Println(infoMsg(i18n.T("Note: .aepx file '%s' was already removed", filepath.Base(absPath))))
 			}
-		} else if os.IsNotExist(err) {
-			// File already doesn't exist (might have been deleted elsewhere)
-			fmt.Println(infoMsg(fmt.Sprintf("Note: .aepx file '%s' was already removed", filepath.Base(absPath))))
 		}
 
 		fmt.Println()
-		fmt.Println(successMsg("Initialized vervids project"))
+		fmt.Println(successMsg(i18n.T("Initialized vervids project")))
 		fmt.Printf("%s Project: %s\n", ui.SuccessStyle.Render("✓"), proj.ProjectName)
-		fmt.Println(successMsg("Initial version stored (v000)"))
+		fmt.Println(successMsg(i18n.T("Initial version stored (v000)")))
 
 		if len(proj.Versions) > 0 {
 			v := proj.Versions[0]
@@ -299,7 +442,14 @@ Use --force to re-initialize the same project file (this will delete existing ve
 			}
 		}
 
-		fmt.Printf("%s Storage: Docker volume '%s' under /vervids/<project>\n", ui.SuccessStyle.Render("✓"), proj.DockerVolume)
+		if proj.Remote != nil {
+			fmt.Printf("%s Storage: %s/<project>\n", ui.SuccessStyle.Render("✓"), strings.TrimSuffix(proj.Remote.URL, "/"))
+		} else {
+			fmt.Printf("%s Storage: Docker volume '%s' under /vervids/<project>\n", ui.SuccessStyle.Render("✓"), proj.DockerVolume)
+		}
+		if template != nil {
+			fmt.Printf("%s Template: %s\n", ui.SuccessStyle.Render("✓"), template.Name)
+		}
 
 		// Save project context
 		configPath := storage.GetConfigPath()
@@ -312,15 +462,15 @@ Use --force to re-initialize the same project file (this will delete existing ve
 			ConfigPath:  absConfigPath,
 		}
 		if err := storage.SaveContext(context); err != nil {
-			fmt.Println(warningMsg(fmt.Sprintf("Warning: Could not save project context: %v", err)))
+			fmt.Println(warningMsg(i18n.T("Warning: Could not save project context: %v", err)))
 		} else {
-			fmt.Println(successMsg("Project context saved"))
+			fmt.Println(successMsg(i18n.T("Project context saved")))
 		}
 
 		fmt.Println()
-		fmt.Println(infoMsg("📝 Next steps:"))
-		fmt.Println(infoMsg("  • Make changes to your .aepx file or assets"))
-		fmt.Println(infoMsg("  • Use 'vervids commit \"message\" <file.aepx>' to save a new version"))
+		fmt.Println(infoMsg(i18n.T("📝 Next steps:")))
+		fmt.Println(infoMsg(i18n.T("  • Make changes to your .aepx file or assets")))
+		fmt.Println(infoMsg(i18n.T("  • Use 'vervids commit \"message\" <file.aepx>' to save a new version")))
 	},
 }
 
@@ -328,97 +478,272 @@ var commitCmd = &cobra.Command{
 	Use:   "commit [message] [path/to/file.aepx]",
 	Short: "Save a new version of your project",
 	Long: `Commit the current state of your .aepx file with a message.
-This creates a new version with all assets in the Docker storage vault.
-
-The .aepx file path must be provided - typically exported from After Effects.
-Example: vervids commit "Added intro animation" "/path/to/exported.aepx"`,
-	Args: cobra.ExactArgs(2),
+This creates a new version with all assets in the project's storage: Docker, or the
+S3 remote the project was initialized with.
+
+The .aepx file path is typically exported from After Effects.
+Example: vervids commit "Added intro animation" "/path/to/exported.aepx"
+
+Without a path, the most recently modified .aepx in the project directory or next to
+the last committed file is used after you confirm it; --yes takes it without asking.
+Example: vervids commit "Added intro animation" --yes
+
+With -i, a guided commit picks the .aepx from recent files near the project, shows
+the pending asset changes, asks for the message and confirms the upload size first.
+Any message or file given on the command line skips that step.
+Example: vervids commit -i
+
+The .aepx and any assets not stored yet are first copied to a local staging area,
+then uploaded. If the upload fails the snapshot is kept, and 'vervids push' retries
+it. With --offline, only the first half runs, so Docker isn't needed; 'vervids push'
+stores the staged versions once Docker is available.
+Example: vervids commit --offline "Notes from the train" "/path/to/exported.aepx"
+
+With --meta, key=value metadata such as a tracker ticket is stored on the version and
+can be filtered on with 'vervids log --meta' and the API.
+Example: vervids commit "Comp fixes" file.aepx --meta ticket=VFX-123 --meta reviewer=anna
+
+When the project has a storage quota (vervids config set quota_gb 50), commits warn
+once it is 80% full and stop past it unless --force-quota is given.
+
+If another copy of the project stored versions since this one last committed, you're
+asked whether to renumber this copy's versions after them, interleave both by commit
+time, or abort; --resolve answers up front (see 'vervids push --help').`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+			return cobra.MaximumNArgs(2)(cmd, args)
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		message := args[0]
-		aepxFilePath := args[1]
+		// A guided commit (-i) may be given just the message or just the file
+		asJSON := startJSONOutput()
+		var message, aepxFilePath string
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		switch {
+		case len(args) == 2:
+			message, aepxFilePath = args[0], args[1]
+		case len(args) == 1 && assets.IsProjectFile(args[0]):
+			aepxFilePath = args[0]
+		case len(args) == 1:
+			message = args[0]
+		}
+		if interactive && aepxFilePath != "" {
+			if abs, err := filepath.Abs(aepxFilePath); err == nil {
+				aepxFilePath = abs
+			}
+		}
 
 		// Get project from context (already ensured by PersistentPreRunE)
 		proj, err := ensureProjectContext()
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error: %v", err))
 		}
 
 		// Change to the directory containing the .vervids config file
 		// This ensures we can save the config.json file correctly
 		cleanup, err := changeToProjectDirectory()
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error: %v", err)))
-			fmt.Println(infoMsg("Please ensure you have write access to the directory."))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error: %v", err), i18n.T("Please ensure you have write access to the directory."))
 		}
 		defer cleanup()
 
+		// Refuse up front, before prompting for or parsing anything
+		if err := proj.CheckWritable(); err != nil {
+			exitWithError(err, i18n.T("%v", err))
+		}
+
+		if interactive {
+			var ok bool
+			message, aepxFilePath, ok = guidedCommit(proj, message, aepxFilePath)
+			if !ok {
+				fmt.Println(infoMsg(i18n.T("Commit cancelled")))
+				return
+			}
+		} else if message == "" {
+			exitWithError(nil, i18n.T("A commit message is required"),
+				i18n.T("Example: vervids commit \"Added intro animation\" %s", aepxFilePath))
+		} else if aepxFilePath == "" {
+			// Only a message: commit the newest project file near the project
+			yes, _ := cmd.Flags().GetBool("yes")
+			var ok bool
+			if aepxFilePath, ok = detectProjectFile(proj, yes); !ok {
+				fmt.Println(infoMsg(i18n.T("Commit cancelled")))
+				return
+			}
+		}
+
 		// Validate .aepx file
 		if _, err := os.Stat(aepxFilePath); os.IsNotExist(err) {
-			fmt.Println(errorMsg(fmt.Sprintf("File '%s' does not exist", aepxFilePath)))
-			os.Exit(1)
+			exitWithError(nil, i18n.T("File '%s' does not exist", aepxFilePath))
 		}
 
-		if filepath.Ext(aepxFilePath) != ".aepx" {
-			fmt.Println(errorMsg("File must have .aepx extension"))
-			os.Exit(1)
+		if proj.Generic && !strings.EqualFold(filepath.Ext(aepxFilePath), filepath.Ext(proj.ProjectPath)) {
+			exitWithError(nil, i18n.T("File must have the project's %s extension", filepath.Ext(proj.ProjectPath)))
+		}
+		if !proj.Generic && !assets.IsProjectFile(aepxFilePath) {
+			exitWithError(nil, i18n.T("File must be a project file (%s)", strings.Join(assets.ProjectExtensions(), ", ")))
 		}
 
 		// Get absolute path
 		absPath, err := filepath.Abs(aepxFilePath)
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		// --resolve-symlinks becomes the project default from this commit on
+		if resolveSymlinks, _ := cmd.Flags().GetBool("resolve-symlinks"); resolveSymlinks {
+			proj.ResolveSymlinks = true
+		}
+
+		// --strict / --allow-missing override the project's missing_assets policy for this commit
+		var opts project.CommitOptions
+		strict, _ := cmd.Flags().GetBool("strict")
+		allowMissing, _ := cmd.Flags().GetBool("allow-missing")
+		if strict && allowMissing {
+			exitWithError(nil, i18n.T("--strict and --allow-missing cannot be used together"))
+		}
+		if strict {
+			opts.MissingAssets = project.MissingStrict
+		} else if allowMissing {
+			opts.MissingAssets = project.MissingAllow
+		}
+		opts.ForceQuota, _ = cmd.Flags().GetBool("force-quota")
+		resolve := resolveFlag(cmd)
+
+		pairs, _ := cmd.Flags().GetStringArray("meta")
+		if opts.Meta, err = project.ParseMeta(pairs, false); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		if offline, _ := cmd.Flags().GetBool("offline"); offline {
+			stageCommit(proj, message, absPath, opts, asJSON)
+			return
 		}
 
-		fmt.Println(infoMsg("📦 Creating new version..."))
+		fmt.Println(infoMsg(i18n.T("📦 Creating new version...")))
 
-		// Create new version with the provided .aepx file
-		v, err := proj.CommitWithPath(message, absPath)
+		// Snapshot locally first, so a failed upload doesn't lose the version
+		staged, err := proj.StageCommit(message, absPath, opts)
+		if err != nil {
+			exitWithError(err, i18n.T("Error staging version: %v", err))
+		}
+		// Another copy may have stored versions since; the new one goes after them either way
+		_, _, err = settleDivergence(proj, resolve)
+		if err != nil {
+			exitWithError(err, i18n.T("Error committing version: %v", err),
+				i18n.T("The snapshot is kept in %s; run 'vervids push' to retry.", staged.Dir()))
+		}
+		v, err := proj.CommitStaged(staged, opts.ForceQuota)
+		if errs.KindOf(err) == errs.StorageFull && proj.QuotaGB > 0 {
+			// Refused rather than failed: nothing to retry
+			staged.Remove()
+			exitWithError(err, i18n.T("Error committing version: %v", err),
+				i18n.T("Free space with 'vervids du -i', raise the quota with 'vervids config set quota_gb <GB>', or commit anyway with --force-quota."))
+		}
+		if project.IsRefused(err) {
+			staged.Remove()
+			exitWithError(err, i18n.T("Error committing version: %v", err))
+		}
+		if errs.KindOf(err) == errs.Conflict {
+			exitWithError(err, i18n.T("Error committing version: %v", err),
+				i18n.T("The snapshot is kept in %s; run 'vervids push --resolve renumber' or 'vervids push --resolve interleave' to combine it with the other copy's versions.", staged.Dir()))
+		}
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error committing version: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error committing version: %v", err),
+				i18n.T("The snapshot is kept in %s; run 'vervids push' to retry the upload.", staged.Dir()))
+		}
+		if err := staged.Remove(); err != nil {
+			fmt.Println(warningMsg(i18n.T("Could not remove %s: %v", staged.Dir(), err)))
+		}
+
+		if asJSON {
+			result := jsonCommitResult{Version: v, Storage: proj.StorageName()}
+			if cs, err := proj.CommitStorage(v.Number); err == nil {
+				result.StoredSize, result.ReusedSize = cs.StoredSize, cs.ReusedSize
+			}
+			printJSON(result)
+			return
 		}
 
 		fmt.Println()
-		fmt.Println(successMsg(fmt.Sprintf("Committed version %d", v.Number)))
+		fmt.Println(successMsg(i18n.T("Committed version %d", v.Number)))
 		fmt.Printf("  Message: %s\n", v.Message)
 		fmt.Printf("  Time: %s\n", v.Timestamp.Format("2006-01-02 15:04:05"))
+		if v.Author != "" {
+			fmt.Printf("  Author: %s\n", v.Author)
+		}
+		if len(v.Meta) > 0 {
+			fmt.Printf("  Meta: %s\n", strings.Join(project.MetaPairs(v.Meta), ", "))
+		}
+		if v.Signature != nil {
+			fmt.Printf("  Signed: %s\n", signatureSummary(v))
+		}
 		fmt.Printf("  Project file: %.2f MB\n", float64(v.Size)/(1024*1024))
 		fmt.Printf("  Assets: %d files\n", v.AssetCount)
+		if len(v.Missing) > 0 {
+			fmt.Println(warningMsg(i18n.T("  Missing: %d asset(s) not stored", len(v.Missing))))
+		}
 		if v.TotalSize > 0 {
 			fmt.Printf("  Total size: %.2f MB\n", float64(v.TotalSize)/(1024*1024))
 		}
+		printCommitStorage(proj, v)
 
-		if proj.UseDocker {
-			fmt.Println(infoMsg("  Storage: Docker"))
+		if proj.Remote != nil {
+			fmt.Println(infoMsg(i18n.T("  Storage: %s", proj.Remote.URL)))
+		} else if proj.UseDocker {
+			fmt.Println(infoMsg(i18n.T("  Storage: Docker")))
 		} else {
-			fmt.Println(infoMsg("  Storage: Local"))
+			fmt.Println(infoMsg(i18n.T("  Storage: Local")))
 		}
 	},
 }
 
+// printCommitStorage reports how much of a new version was stored and how much reused
+// from the pool, and what deduplication saves the project overall
+func printCommitStorage(proj *project.Project, v *project.Version) {
+	cs, err := proj.CommitStorage(v.Number)
+	if err != nil {
+		return
+	}
+	mb := func(n int64) float64 { return float64(n) / (1024 * 1024) }
+	fmt.Printf("  Stored: %.2f MB new, %.2f MB reused from earlier versions\n", mb(cs.StoredSize), mb(cs.ReusedSize))
+	if stats := proj.Stats(); stats.LogicalSize > 0 {
+		fmt.Printf("  Dedup: %.2f MB saved across %d version(s) (%.0f%% of %.2f MB)\n",
+			mb(stats.Savings()), stats.Versions, float64(stats.Savings())/float64(stats.LogicalSize)*100, mb(stats.LogicalSize))
+	}
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list [project-number]",
 	Short: "List projects or commits for a project",
 	Long: `List all projects stored in Docker. If a project number is provided, show commits for that project.
-You can also switch to a different project by selecting it from the list.
+Projects with a storage quota (the quota_gb setting) show how much of it they use.
+Projects whose project file was saved with changes since their newest commit are
+marked as uncommitted, so unsaved work stands out before shutting down for the day.
+Use 'vervids switch' to change the current project, or 'vervids ui' to browse projects
+interactively.
 
 Example:
-  vervids list              # Show all projects and option to switch
-  vervids list 1             # Show commits for project #1`,
+  vervids list              # Show all projects
+  vervids list 1             # Show commits for project #1
+  vervids list 1 --label "final delivery"
+  vervids list 1 --meta ticket=VFX-123`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		asJSON := startJSONOutput()
 		projects, err := project.GetAllProjects()
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error getting projects: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error getting projects: %v", err))
 		}
 
+		if len(projects) == 0 && asJSON {
+			printJSON(jsonProjectList{Projects: []jsonProjectItem{}})
+			return
+		}
 		if len(projects) == 0 {
-			fmt.Println(infoMsg("No projects found in Docker storage."))
-			fmt.Println(infoMsg("Use 'vervids init <file.aepx>' to create a project."))
+			fmt.Println(infoMsg(i18n.T("No projects found in Docker storage.")))
+			fmt.Println(infoMsg(i18n.T("Use 'vervids init <file.aepx>' to create a project.")))
 			return
 		}
 
@@ -426,143 +751,124 @@ Example:
 		if len(args) > 0 {
 			projectNum, err := strconv.Atoi(args[0])
 			if err != nil {
-				fmt.Println(errorMsg("Project number must be an integer"))
-				os.Exit(1)
+				exitWithError(nil, i18n.T("Project number must be an integer"))
 			}
 			// Convert from 1-based user input to 0-based array index
 			projectIndex := projectNum - 1
 			if projectIndex < 0 || projectIndex >= len(projects) {
-				fmt.Println(errorMsg(fmt.Sprintf("Project number %d does not exist (1-%d)", projectNum, len(projects))))
-				os.Exit(1)
+				exitWithKind(errs.ProjectNotFound, i18n.T("Project number %d does not exist (1-%d)", projectNum, len(projects)))
 			}
 
 			selectedProj := projects[projectIndex]
-			showCommitsForProject(selectedProj.Name)
+			proj := loadProjectByName(selectedProj.Name)
+			if asJSON {
+				printJSON(newJSONCommitList(proj, commitFilter(cmd)))
+				return
+			}
+			showFilteredCommits(proj, commitFilter(cmd))
 			return
 		}
 
 		// Show current project context if available
+		var current *project.Project
 		if storage.HasContext() {
 			context, err := storage.LoadContext()
 			if err == nil {
 				if proj, err := project.LoadFromPath(context.ConfigPath); err == nil {
-					fmt.Println(infoMsg(fmt.Sprintf("Current project: %s", proj.ProjectName)))
-					fmt.Println()
+					current = proj
 				}
 			}
 		}
+		if current != nil && !asJSON {
+			fmt.Println(infoMsg(i18n.T("Current project: %s", current.ProjectName)))
+			fmt.Println()
+		}
 
 		// Show all projects
-		fmt.Println(infoMsg("Projects in Docker storage:"))
-		fmt.Println()
-		fmt.Println(infoMsg("#   Project Name"))
-		fmt.Println(infoMsg("--  ------------------------------"))
+		rows := make([][]string, 0, len(projects))
+		items := make([]jsonProjectItem, 0, len(projects))
+		quotas := false
+		uncommitted := 0
+		hashes := storage.LoadWorkingHashCache()
 		for i, p := range projects {
 			// Display 1-based index
 			marker := "  "
-			if storage.HasContext() {
-				context, err := storage.LoadContext()
-				if err == nil {
-					if proj, err := project.LoadFromPath(context.ConfigPath); err == nil {
-						if strings.Contains(strings.ToLower(proj.ProjectName), strings.ToLower(p.Name)) ||
-							strings.Contains(strings.ToLower(p.Name), strings.ToLower(proj.ProjectName)) {
-							marker = "→ "
-						}
-					}
-				}
+			isCurrent := current != nil &&
+				(strings.Contains(strings.ToLower(current.ProjectName), strings.ToLower(p.Name)) ||
+					strings.Contains(strings.ToLower(p.Name), strings.ToLower(current.ProjectName)))
+			if isCurrent {
+				marker = "→ "
+			}
+			quota := projectQuotaStatus(p.Name)
+			quotas = quotas || quota != ""
+			working := projectWorkingFile(p.Name, hashes)
+			if working != "" {
+				uncommitted++
 			}
-			fmt.Printf("%s%s  %s\n", marker, ui.InfoStyle.Render(fmt.Sprintf("%02d", i+1)), p.Name)
+			items = append(items, jsonProjectItem{Number: i + 1, Name: p.Name, DockerPath: p.DockerPath, Current: isCurrent, Uncommitted: working})
+			if working != "" {
+				working = ui.WarningStyle.Render("● " + working)
+			}
+			rows = append(rows, []string{marker + fmt.Sprintf("%02d", i+1), p.Name, working, quota})
+		}
+		hashes.Save()
+		if asJSON {
+			list := jsonProjectList{Projects: items}
+			if current != nil {
+				commits := newJSONCommitList(current, commitFilter(cmd))
+				list.Current = &commits
+			}
+			printJSON(list)
+			return
 		}
-		fmt.Println()
-		fmt.Println(infoMsg("Use 'vervids list <number>' to see commits for a project"))
 
-		// Offer to switch project
+		fmt.Println(infoMsg(i18n.T("Projects in Docker storage:")))
 		fmt.Println()
-		reader := bufio.NewReader(os.Stdin)
-		fmt.Print(infoMsg("Switch to a different project? Enter project number (or press Enter to skip): "))
-		input, err := reader.ReadString('\n')
-		if err == nil {
-			input = strings.TrimSpace(input)
-			if input != "" {
-				projectNum, err := strconv.Atoi(input)
-				if err != nil {
-					fmt.Println(errorMsg("Invalid project number"))
-					return
-				}
-				projectIndex := projectNum - 1
-				if projectIndex < 0 || projectIndex >= len(projects) {
-					fmt.Println(errorMsg(fmt.Sprintf("Project number %d does not exist (1-%d)", projectNum, len(projects))))
-					return
+		headers := []string{"  #", "Project Name", "Uncommitted", "Quota"}
+		for col := len(headers) - 1; col >= 2; col-- {
+			if (col == 2 && uncommitted == 0) || (col == 3 && !quotas) {
+				headers = append(headers[:col], headers[col+1:]...)
+				for i := range rows {
+					rows[i] = append(rows[i][:col], rows[i][col+1:]...)
 				}
-
-				selectedProj := projects[projectIndex]
-
-				// Find the config file for this project using comprehensive search
-				configPath, err := findProjectConfigFile(selectedProj.Name)
-				if err != nil {
-					fmt.Println(errorMsg(fmt.Sprintf("Could not find config file for project: %s", selectedProj.Name)))
-					fmt.Println(infoMsg("Tip: Navigate to the project directory, or ensure .vervids/config.json exists."))
-					fmt.Println(infoMsg("The project exists in Docker storage, but the local config file is missing."))
-					return
-				}
-
-				// Load the project
-				proj, err := project.LoadFromPath(configPath)
-				if err != nil {
-					fmt.Println(errorMsg(fmt.Sprintf("Error loading project: %v", err)))
-					return
-				}
-
-				// Get absolute path for context
-				absConfigPath, err := filepath.Abs(configPath)
-				if err != nil {
-					absConfigPath = configPath
-				}
-
-				// Save context
-				context := &storage.ProjectContext{
-					ProjectName: proj.ProjectName,
-					ConfigPath:  absConfigPath,
-				}
-				if err := storage.SaveContext(context); err != nil {
-					fmt.Println(errorMsg(fmt.Sprintf("Error saving context: %v", err)))
-					return
-				}
-
-				fmt.Println(successMsg(fmt.Sprintf("Switched to project: %s", proj.ProjectName)))
-				fmt.Println()
-				// Show commits for the newly selected project
-				showProjectCommits(proj)
-				fmt.Println()
-				fmt.Println(infoMsg("Available commands:"))
-				fmt.Println(infoMsg("  • vervids commit \"message\" <file.aepx> - Commit a new version"))
-				fmt.Println(infoMsg("  • vervids list - List all projects"))
-				fmt.Println(infoMsg("  • vervids show <version> - Show version details"))
-				fmt.Println(infoMsg("  • vervids help - Show all commands"))
-				return
 			}
 		}
+		fmt.Println(ui.Table(headers, rows, 1))
+		fmt.Println()
+		if uncommitted > 0 {
+			fmt.Println(warningMsg(i18n.T("%d project(s) have changes saved since their last commit", uncommitted)))
+		}
+		fmt.Println(infoMsg(i18n.T("Use 'vervids list <number>' to see commits for a project")))
+		fmt.Println(infoMsg(i18n.T("Use 'vervids switch' to change the current project")))
 
-		// If no switch was made, show commits for current project if available
-		if storage.HasContext() {
-			context, err := storage.LoadContext()
-			if err == nil {
-				if proj, err := project.LoadFromPath(context.ConfigPath); err == nil {
-					fmt.Println()
-					showProjectCommits(proj)
-					fmt.Println()
-					fmt.Println(infoMsg("Available commands:"))
-					fmt.Println(infoMsg("  • vervids commit \"message\" <file.aepx> - Commit a new version"))
-					fmt.Println(infoMsg("  • vervids list - List all projects"))
-					fmt.Println(infoMsg("  • vervids show <version> - Show version details"))
-					fmt.Println(infoMsg("  • vervids pull <version> - Pull a version from Docker"))
-					fmt.Println(infoMsg("  • vervids help - Show all commands"))
-				}
-			}
+		// Show commits for the current project if available
+		if current != nil {
+			fmt.Println()
+			showFilteredCommits(current, commitFilter(cmd))
+			fmt.Println()
+			fmt.Println(infoMsg(i18n.T("Available commands:")))
+			fmt.Println(infoMsg(i18n.T("  • vervids commit \"message\" <file.aepx> - Commit a new version")))
+			fmt.Println(infoMsg(i18n.T("  • vervids list - List all projects")))
+			fmt.Println(infoMsg(i18n.T("  • vervids show <version> - Show version details")))
+			fmt.Println(infoMsg(i18n.T("  • vervids pull <version> - Pull a version from Docker")))
+			fmt.Println(infoMsg(i18n.T("  • vervids help - Show all commands")))
 		}
 	},
 }
 
+// setProjectContext makes proj, loaded from configPath, the current project
+func setProjectContext(proj *project.Project, configPath string) error {
+	absConfigPath, err := filepath.Abs(configPath)
+	if err != nil {
+		absConfigPath = configPath
+	}
+	context := &storage.ProjectContext{
+		ProjectName: proj.ProjectName,
+		ConfigPath:  absConfigPath,
+	}
+	return storage.SaveContext(context)
+}
+
 // findProjectConfigFile searches for a project's config.json file comprehensively
 func findProjectConfigFile(projectName string) (string, error) {
 	home := os.Getenv("HOME")
@@ -702,15 +1008,15 @@ func selectProject() (*project.Project, error) {
 	}
 
 	if len(projects) == 0 {
-		fmt.Println(infoMsg("No projects found."))
+		fmt.Println(infoMsg(i18n.T("No projects found.")))
 		fmt.Println()
-		fmt.Println(infoMsg("To get started:"))
-		fmt.Println(infoMsg("  • Use 'vervids init <file.aepx>' to initialize a new project"))
-		fmt.Println(infoMsg("  • Use 'vervids help' to see all available commands"))
-		return nil, fmt.Errorf("no projects available")
+		fmt.Println(infoMsg(i18n.T("To get started:")))
+		fmt.Println(infoMsg(i18n.T("  • Use 'vervids init <file.aepx>' to initialize a new project")))
+		fmt.Println(infoMsg(i18n.T("  • Use 'vervids help' to see all available commands")))
+		return nil, errs.New(errs.ProjectNotFound, "no projects available")
 	}
 
-	fmt.Println(infoMsg("Select a project to work with:"))
+	fmt.Println(infoMsg(i18n.T("Select a project to work with:")))
 	fmt.Println()
 	for i, p := range projects {
 		fmt.Printf("  %s  %s\n", ui.InfoStyle.Render(fmt.Sprintf("%d", i+1)), p.Name)
@@ -728,7 +1034,7 @@ func selectProject() (*project.Project, error) {
 		input = strings.TrimSpace(input)
 		projectNum, err := strconv.Atoi(input)
 		if err != nil || projectNum < 1 || projectNum > len(projects) {
-			fmt.Println(errorMsg(fmt.Sprintf("Please enter a number between 1 and %d", len(projects))))
+			fmt.Println(errorMsg(i18n.T("Please enter a number between 1 and %d", len(projects))))
 			continue
 		}
 
@@ -761,7 +1067,7 @@ func selectProject() (*project.Project, error) {
 			return nil, fmt.Errorf("error saving context: %w", err)
 		}
 
-		fmt.Println(successMsg(fmt.Sprintf("Selected project: %s", proj.ProjectName)))
+		fmt.Println(successMsg(i18n.T("Selected project: %s", proj.ProjectName)))
 		return proj, nil
 	}
 }
@@ -790,7 +1096,7 @@ func changeToProjectDirectory() (func(), error) {
 	// Return cleanup function
 	return func() {
 		if err := os.Chdir(originalDir); err != nil {
-			fmt.Println(warningMsg(fmt.Sprintf("Warning: Could not restore original directory: %v", err)))
+			fmt.Println(warningMsg(i18n.T("Warning: Could not restore original directory: %v", err)))
 		}
 	}, nil
 }
@@ -829,9 +1135,28 @@ func ensureProjectContext() (*project.Project, error) {
 }
 
 func init() {
+	// Plain output (no colors, styling or emoji) for CI logs, screen readers and files
+	rootCmd.PersistentFlags().Bool("no-color", false, "Disable colors, styling and emoji")
+	rootCmd.PersistentFlags().Bool("plain", false, "Alias for --no-color")
+	rootCmd.PersistentFlags().Bool("verbose", false, "Log Docker calls and timings to stderr")
+	rootCmd.PersistentFlags().Bool("debug", false, "Log debug detail to stderr (implies --verbose)")
+	rootCmd.PersistentFlags().Bool("log-file", false, "Append debug logs to ~/.vervids/logs")
+	rootCmd.PersistentFlags().Bool("json", false, "Print results and errors as JSON for scripts")
+	logging.Setup(logging.Options{}) // Silence logging until flags are parsed
+	if ui.PlainRequested() {
+		ui.SetPlain(true)
+	}
+	// Messages follow the locale unless settings.yaml picks a language; unsupported
+	// locales quietly stay in English
+	i18n.SetLanguage(i18n.Detect())
+	cobra.OnInitialize(applyPlainFlags, applyJSONFlag, applySettings, setupLogging, startUpdateCheck)
+
 	// Set custom help function to show boxed header
 	originalHelpFunc := rootCmd.HelpFunc()
 	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		// Help is shown before initializers run
+		applyPlainFlags()
+		applySettings()
 		// Only show header for root command help
 		if cmd == rootCmd {
 			printBoxedHeader()
@@ -847,10 +1172,23 @@ func init() {
 			return nil
 		}
 
+		// Global settings (--global, or keys that only exist globally) don't need a project
+		if global, err := cmd.Flags().GetBool("global"); err == nil && global {
+			return nil
+		}
+		if cmd.Parent() == configCmd && len(args) > 0 && globalConfigKey(cmd, args[0]) {
+			return nil
+		}
+
 		// Skip context check for these commands
-		skipContextCommands := []string{"init", "version", "help", "list", "serve"}
+		skipContextCommands := []string{"init", "version", "help", "list", "serve", "pathmap", "docs", "ui", "switch", "cache", "push", "bundle", "fetch", "container", "which", "adopt", "all", "remote", "demo", "token", "parse"}
 		cmdName := cmd.Name()
 
+		// Subcommands (e.g. "pathmap add") are matched by their top-level command
+		for c := cmd; c.HasParent() && c.Parent() != rootCmd; c = c.Parent() {
+			cmdName = c.Parent().Name()
+		}
+
 		// Check if this is one of the skip commands
 		for _, skipCmd := range skipContextCommands {
 			if cmdName == skipCmd {
@@ -879,22 +1217,206 @@ func init() {
 
 	rootCmd.AddCommand(versionCmd)
 	initCmd.Flags().BoolP("force", "f", false, "Force re-initialization of the same project file (removes existing version history)")
+	initCmd.Flags().Bool("resolve-symlinks", false, "Store symlinked assets under their targets and record the links for pull")
+	initCmd.Flags().String("template", "", "Start from a project template in ~/.vervids/templates (folders, settings, notes, hooks)")
+	initCmd.Flags().Bool("generic", false, "Version any file (PSD, C4D scene, brief) as whole-file snapshots, without asset parsing")
 	rootCmd.AddCommand(initCmd)
+	commitCmd.Flags().Bool("resolve-symlinks", false, "Resolve symlinked assets to their targets (saved as the project default)")
+	commitCmd.Flags().Bool("strict", false, "Fail the commit if any referenced asset is missing")
+	commitCmd.Flags().Bool("allow-missing", false, "Record missing assets on the version and commit anyway")
+	commitCmd.Flags().BoolP("interactive", "i", false, "Guided commit: pick the file, review pending changes, then enter a message")
+	commitCmd.Flags().Bool("offline", false, "Stage the version locally without Docker; push it later with 'vervids push'")
+	commitCmd.Flags().Bool("force-quota", false, "Commit even if the project goes over its storage quota")
+	commitCmd.Flags().BoolP("yes", "y", false, "Commit the newest project file found without asking, when no file is given")
+	commitCmd.Flags().String("resolve", "", "Combine with versions another copy stored meanwhile: renumber, interleave or abort (asks when empty)")
+	commitCmd.Flags().StringArray("meta", nil, "Store key=value metadata on the version (repeatable), e.g. --meta ticket=VFX-123")
 	rootCmd.AddCommand(commitCmd)
+	listCmd.Flags().String("label", "", "Show only commits with this label")
+	listCmd.Flags().StringArray("meta", nil, "Show only commits with this key=value metadata, or with the key set (repeatable)")
+	listCmd.Flags().Bool("no-autosaves", false, "Leave out versions committed by autosave")
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(infoCmd)
+	showCmd.Flags().Bool("assets", false, "List only the version's assets")
+	showCmd.Flags().Bool("detail", false, "Include codec, resolution, duration, hash and new/reused status for each asset")
+	showCmd.Flags().Bool("project", false, "Show the project and its notes instead of a version")
+	notesCmd.AddCommand(notesShowCmd, notesEditCmd)
+	notesEditCmd.Flags().String("from", "", "Read the notes from a file ('-' for stdin) instead of opening an editor")
+	rootCmd.AddCommand(notesCmd)
 	rootCmd.AddCommand(pruneCmd)
+	pullCmd.Flags().Bool("preserve-structure", false, "Restore assets under their original relative folders instead of one flat folder")
+	pullCmd.Flags().String("as-of", "", "Pull the latest version at or before this time, e.g. \"2024-03-01 18:00\"")
 	rootCmd.AddCommand(pullCmd)
+	revertCmd.Flags().StringP("message", "m", "", "Message for the new version (default: Revert \"<message>\" (vN))")
+	rootCmd.AddCommand(revertCmd)
+
+	restoreCmd.Flags().Bool("in-place", false, "Overwrite the working project file (required)")
+	restoreCmd.Flags().String("file", "", "Project file to overwrite (default: the file last committed from)")
+	restoreCmd.Flags().BoolP("yes", "y", false, "Restore without asking for confirmation")
+	restoreCmd.Flags().Bool("preserve-structure", false, "Restore missing assets under assets/<original relative path>")
+	restoreCmd.MarkFlagRequired("in-place")
+	rootCmd.AddCommand(restoreCmd)
+	labelAddCmd.Flags().String("color", "", "Label color: a name (red, blue, ...), an ANSI number 0-255 or #rrggbb")
+	labelCmd.AddCommand(labelListCmd, labelAddCmd, labelRemoveCmd)
+	rootCmd.AddCommand(labelCmd)
+	tagCmd.Flags().Bool("force", false, "Move the tag if it is already on another version")
+	tagCmd.AddCommand(tagListCmd, tagRemoveCmd)
+	rootCmd.AddCommand(tagCmd)
 	rootCmd.AddCommand(deleteCmd)
+	serveCmd.Flags().Bool("auth", false, "Require an API token (see 'vervids token') on every request except /health")
 	rootCmd.AddCommand(serveCmd)
+	tokenCmd.AddCommand(tokenCreateCmd, tokenListCmd, tokenRevokeCmd)
+	rootCmd.AddCommand(tokenCmd)
+	timelineCmd.Flags().Int("width", 40, "Width of the size bars in characters")
+	timelineCmd.Flags().Float64("threshold", 25, "Highlight versions whose size grew by at least this percentage")
+	rootCmd.AddCommand(timelineCmd)
+	duCmd.Flags().Int("largest", 10, "Number of objects to list (0 = all)")
+	duCmd.Flags().BoolP("interactive", "i", false, "Decide for each listed object whether to exclude or purge it")
+	rootCmd.AddCommand(duCmd)
+	cleanCmd.Flags().Bool("all", false, "Also remove items from the last hour")
+	cleanCmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
+	cleanCmd.Flags().Bool("dry-run", false, "Only list what would be removed")
+	rootCmd.AddCommand(cleanCmd)
+	importHistoryCmd.Flags().String("sort", "name", "Order files by 'name' (numbers in the name) or 'mtime'")
+	importHistoryCmd.Flags().Bool("dry-run", false, "Only list the files and the order they would be imported in")
+	importHistoryCmd.Flags().Bool("allow-missing", false, "Record missing assets on the versions and import anyway")
+	importHistoryCmd.Flags().Bool("force-quota", false, "Import even if the project goes over its storage quota")
+	rootCmd.AddCommand(importHistoryCmd)
+	optimizeCmd.Flags().Bool("dry-run", false, "Only list the objects that would be rewritten")
+	optimizeCmd.Flags().Bool("no-chunk", false, "Only move copies into the pool; don't split large files into chunks")
+	rootCmd.AddCommand(optimizeCmd)
+	exportOTIOCmd.Flags().Bool("no-comps", false, "Only export the Versions track; don't read comps from each version's .aepx")
+	rootCmd.AddCommand(exportOTIOCmd)
+	bundleCreateCmd.Flags().Int("since", -1, "Leave out this version and earlier, which the other machine already has (-1 = whole history)")
+	bundleApplyCmd.Flags().Bool("dry-run", false, "Only list the versions the bundle would add")
+	bundleCmd.AddCommand(bundleCreateCmd, bundleApplyCmd)
+	rootCmd.AddCommand(bundleCmd)
+	fetchCmd.Flags().Bool("metadata", false, "Fetch only the version history, without project files or assets")
+	rootCmd.AddCommand(fetchCmd)
+	autosaveEnableCmd.Flags().String("every", "30m", "How often to check the file, e.g. 15m or 1h")
+	autosaveEnableCmd.Flags().Int("keep", project.DefaultAutosaveKeep, "Newest autosaves that keep all their stored files")
+	autosaveCmd.AddCommand(autosaveEnableCmd, autosaveDisableCmd, autosaveStatusCmd, autosaveRunCmd)
+	rootCmd.AddCommand(autosaveCmd)
+	containerCmd.AddCommand(containerStatusCmd, containerRecreateCmd)
+	rootCmd.AddCommand(containerCmd)
+	rootCmd.AddCommand(whichCmd)
+	rootCmd.AddCommand(adoptCmd)
+	dedupeReportCmd.Flags().Bool("hash-missing", false, "Hash stored objects whose assets have no recorded hash (reads them in Docker)")
+	dedupeConsolidateCmd.Flags().Bool("hash-missing", false, "Hash stored objects whose assets have no recorded hash (reads them in Docker)")
+	dedupeConsolidateCmd.Flags().Bool("dry-run", false, "Only list what would be consolidated")
+	dedupeCmd.AddCommand(dedupeReportCmd, dedupeConsolidateCmd)
+	rootCmd.AddCommand(dedupeCmd)
+	allPruneCmd.Flags().Int("keep-last", 0, "Also purge stored files only versions older than the newest N use")
+	allCmd.AddCommand(allVerifyCmd, allPruneCmd, allStatsCmd)
+	rootCmd.AddCommand(allCmd)
+	pathmapAddCmd.Flags().BoolP("global", "g", false, "Store the mapping in ~/.vervids/settings.yaml for all projects")
+	pathmapRemoveCmd.Flags().BoolP("global", "g", false, "Remove the mapping from ~/.vervids/settings.yaml")
+	pathmapCmd.AddCommand(pathmapAddCmd, pathmapRemoveCmd, pathmapListCmd)
+	rootCmd.AddCommand(pathmapCmd)
+
+	remoteAddCmd.Flags().String("endpoint", "", "Server URL for S3-compatible storage such as MinIO, e.g. http://localhost:9000")
+	remoteAddCmd.Flags().String("region", "", "Bucket region (default: AWS_REGION, then us-east-1)")
+	remoteAddCmd.Flags().Bool("no-check", false, "Save the remote without checking the bucket can be reached")
+	remoteCmd.AddCommand(remoteAddCmd, remoteRemoveCmd, remoteShowCmd)
+	rootCmd.AddCommand(remoteCmd)
+	configCmd.PersistentFlags().BoolP("global", "g", false, "Use ~/.vervids/settings.yaml instead of the project's settings")
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd, configUnsetCmd)
+	rootCmd.AddCommand(configCmd)
+
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().Bool("comps", false, "Compare compositions (added/removed/renamed, layers, size, duration)")
+	diffCmd.Flags().Bool("expressions", false, "Compare layer expressions between the two versions")
+	diffCmd.Flags().String("working", "", "Compare this .aepx on disk against the given version")
+
+	rootCmd.AddCommand(changesCmd)
+	rootCmd.AddCommand(statusCmd)
+
+	assetCmd.AddCommand(assetTimelineCmd, assetPinCmd, assetUnpinCmd, assetRestoreCmd)
+	rootCmd.AddCommand(assetCmd)
+	assetPinCmd.Flags().BoolP("list", "l", false, "List pinned assets")
+	assetRestoreCmd.Flags().String("into", "", "Project file to update (default: the last committed .aepx)")
+
+	rootCmd.AddCommand(uiCmd)
+	logCmd.Flags().BoolP("interactive", "i", false, "Browse versions interactively with a diff side pane")
+	logCmd.Flags().String("label", "", "Show only commits with this label")
+	logCmd.Flags().StringArray("meta", nil, "Show only commits with this key=value metadata, or with the key set (repeatable)")
+	logCmd.Flags().Bool("no-autosaves", false, "Leave out versions committed by autosave")
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(switchCmd)
+	cacheCleanCmd.Flags().Bool("all", false, "Also remove temp files from the last hour")
+	cacheCmd.AddCommand(cacheCleanCmd)
+	rootCmd.AddCommand(cacheCmd)
+	pushCmd.Flags().Bool("list", false, "List staged versions without storing them")
+	pushCmd.Flags().Bool("force-quota", false, "Store staged versions even past a project's storage quota")
+	pushCmd.Flags().String("resolve", "", "Combine with versions another copy stored meanwhile: renumber, interleave or abort (asks when empty)")
+	rootCmd.AddCommand(pushCmd)
+	demoCmd.Flags().Bool("keep", false, "Leave the sandbox in place afterwards")
+	demoCmd.Flags().Bool("clean", false, "Remove a sandbox left by --keep")
+	rootCmd.AddCommand(demoCmd)
+	parseCmd.Flags().Bool("strict", false, "Exit with status 1 when any referenced asset is missing")
+	rootCmd.AddCommand(parseCmd)
+
+	rootCmd.AddCommand(fsckCmd)
+	fsckCmd.Flags().Bool("repair", false, "Regenerate missing or inconsistent tracking files from config.json")
+	verifySignaturesCmd.Flags().String("keys", "", "File of trusted public keys (default: .vervids/trusted_keys)")
+	verifySignaturesCmd.Flags().Bool("files", false, "Also fetch each stored project file and check it against the signed hash")
+	verifySignaturesCmd.Flags().Bool("strict", false, "Fail if any version is unsigned")
+	rootCmd.AddCommand(verifySignaturesCmd)
+
+	docsCmd.AddCommand(docsManCmd, docsMarkdownCmd)
+	rootCmd.AddCommand(docsCmd)
+	docsManCmd.Flags().String("dir", "man", "Directory to write man pages to")
+	docsMarkdownCmd.Flags().String("dir", "docs", "Directory to write Markdown files to")
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	update.Wait(time.Second)
+	if err != nil && jsonOutput() {
+		kind := errs.KindOf(err)
+		printJSON(map[string]jsonError{"error": {Code: kind.String(), ExitCode: kind.ExitCode(), Message: err.Error()}})
+	}
+	return err
+}
+
+// projectQuotaStatus describes a project's quota use for 'vervids list', or "" if it
+// has no quota or its config can't be found
+func projectQuotaStatus(projectName string) string {
+	configPath, err := findProjectConfigFile(projectName)
+	if err != nil {
+		return ""
+	}
+	proj, err := project.LoadFromPath(configPath)
+	if err != nil || proj.QuotaGB == 0 {
+		return ""
+	}
+	used := proj.Stats().StoredSize
+	status := fmt.Sprintf("%.1f / %d GB", float64(used)/(1024*1024*1024), proj.QuotaGB)
+	switch percent := float64(used) / float64(proj.Quota()) * 100; {
+	case percent > 100:
+		return ui.ErrorStyle.Render(status + " over quota")
+	case percent >= project.QuotaWarnPercent:
+		return ui.WarningStyle.Render(status)
+	}
+	return status
+}
+
+// projectWorkingFile names the working file of a project, found through its config on
+// this machine, when it was saved with changes since the project's newest commit
+func projectWorkingFile(projectName string, hashes *storage.HashCache) string {
+	configPath, err := findProjectConfigFile(projectName)
+	if err != nil {
+		return ""
+	}
+	proj, err := project.LoadFromPath(configPath)
+	if err != nil || !proj.WorkingFileChanged(hashes) {
+		return ""
+	}
+	return filepath.Base(proj.WorkingFile())
 }
 
-// showCommitsForProject finds and displays commits for a project by name
-func showCommitsForProject(projectName string) {
+// loadProjectByName finds and loads the config of a project listed in storage
+func loadProjectByName(projectName string) *project.Project {
 	// First try: look in current directory
 	if storage.IsInitialized() {
 		proj, err := project.Load()
@@ -902,8 +1424,7 @@ func showCommitsForProject(projectName string) {
 			// Check if this project's directory name matches
 			cwd, _ := os.Getwd()
 			if strings.Contains(filepath.Base(cwd), projectName) {
-				showProjectCommits(proj)
-				return
+				return proj
 			}
 		}
 	}
@@ -911,194 +1432,478 @@ func showCommitsForProject(projectName string) {
 	// Use comprehensive search to find the config file
 	configPath, err := findProjectConfigFile(projectName)
 	if err != nil {
-		fmt.Println(errorMsg(fmt.Sprintf("Could not find config.json for project '%s'", projectName)))
-		fmt.Println(infoMsg("Tip: Navigate to the project directory, or ensure .vervids/config.json exists."))
-		os.Exit(1)
+		exitWithKind(errs.ProjectNotFound, i18n.T("Could not find config.json for project '%s'", projectName), i18n.T("Tip: Navigate to the project directory, or ensure .vervids/config.json exists."))
 	}
 
 	proj, err := project.LoadFromPath(configPath)
 	if err != nil {
-		fmt.Println(errorMsg(fmt.Sprintf("Error loading project: %v", err)))
-		os.Exit(1)
+		exitWithError(err, i18n.T("Error loading project: %v", err))
 	}
-
-	showProjectCommits(proj)
+	return proj
 }
 
 // showProjectCommits displays commits for a loaded project
 func showProjectCommits(proj *project.Project) {
+	showFilteredCommits(proj, project.VersionFilter{})
+}
+
+// showFilteredCommits displays the project's commits that pass the filter
+func showFilteredCommits(proj *project.Project, filter project.VersionFilter) {
 
 	if len(proj.Versions) == 0 {
 		fmt.Printf("%s: %s\n", ui.InfoStyle.Render("Project"), proj.ProjectName)
-		fmt.Println(infoMsg("No commits yet. Use 'vervids commit \"message\" <file.aepx>' to create one."))
+		fmt.Println(infoMsg(i18n.T("No commits yet. Use 'vervids commit \"message\" <file.aepx>' to create one.")))
 		return
 	}
 
+	versions := filter.Filter(proj.Versions)
 	fmt.Printf("%s: %s\n", ui.InfoStyle.Render("Project"), proj.ProjectName)
-	fmt.Printf("%s: %d\n\n", ui.InfoStyle.Render("Commits"), len(proj.Versions))
-	fmt.Println(infoMsg("#   Time                 Size(MB)  Assets  Message"))
-	fmt.Println(infoMsg("--  -------------------  -------  ------  ------------------------------"))
-	for _, v := range proj.Versions {
-		fmt.Printf("%02d  %s  %7.2f  %6d  %s\n",
-			v.Number,
+	if !filter.IsEmpty() {
+		fmt.Printf("%s: %d of %d matching %s\n\n", ui.InfoStyle.Render("Commits"), len(versions), len(proj.Versions), describeFilter(proj, filter))
+	} else {
+		fmt.Printf("%s: %d\n\n", ui.InfoStyle.Render("Commits"), len(proj.Versions))
+	}
+	if len(versions) == 0 {
+		fmt.Println(infoMsg(i18n.T("No commits match the filter")))
+		return
+	}
+
+	labelled, tagged := false, false
+	for _, v := range versions {
+		labelled = labelled || len(v.Labels) > 0
+		tagged = tagged || len(v.Tags) > 0
+	}
+	rows := make([][]string, 0, len(versions))
+	for _, v := range versions {
+		row := []string{
+			fmt.Sprintf("%02d", v.Number),
 			v.Timestamp.Format("2006-01-02 15:04:05"),
-			float64(v.Size)/(1024*1024),
-			v.AssetCount,
+			fmt.Sprintf("%.2f", float64(v.Size)/(1024*1024)),
+			fmt.Sprintf("%d", v.AssetCount),
 			v.Message,
-		)
+		}
+		if labelled {
+			row = append(row, versionLabels(proj, v))
+		}
+		if tagged {
+			row = append(row, strings.Join(v.Tags, ", "))
+		}
+		rows = append(rows, row)
+	}
+	headers := []string{"#", "Time", "Size(MB)", "Assets", "Message"}
+	if labelled {
+		headers = append(headers, "Labels")
+	}
+	if tagged {
+		headers = append(headers, "Tags")
+	}
+	fmt.Println(ui.Table(headers, rows, 4))
+	if n := len(proj.Restores); n > 0 {
+		last := proj.Restores[n-1]
+		fmt.Println(infoMsg(i18n.T("Working file restored to version %d on %s (%d restore(s) in all)", last.Version, last.Timestamp.Format("2006-01-02 15:04:05"), n)))
 	}
 }
 
+// versionLabels renders a version's labels as colored chips
+func versionLabels(proj *project.Project, v *project.Version) string {
+	chips := make([]string, 0, len(v.Labels))
+	for _, name := range v.Labels {
+		chips = append(chips, ui.Label(name, proj.LabelColor(name)))
+	}
+	return strings.Join(chips, " ")
+}
+
 var showCmd = &cobra.Command{
 	Use:   "show [version-number]",
 	Short: "Show details for a specific version",
-	Args:  cobra.ExactArgs(1),
+	Long: `Show details for a specific version, given by number or by a tag set with
+'vervids tag'.
+
+Use --assets to list only the version's assets, and --detail to include each asset's
+codec, resolution and duration (probed with ffprobe on commit), content hash, and
+whether it was new in this version or reused from an earlier one.
+
+For .aepx projects, the compositions the version holds are listed with their
+resolution, duration, frame rate and layer count, as read on commit.
+
+Use --project instead of a version to show the project itself, with its notes.
+
+Example:
+  vervids show 3
+  vervids show client-review-1
+  vervids show 3 --assets --detail
+  vervids show --project`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if projectOnly, _ := cmd.Flags().GetBool("project"); projectOnly {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		asJSON := startJSONOutput()
 		// Get project from context (already ensured by PersistentPreRunE)
 		proj, err := ensureProjectContext()
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error: %v", err))
 		}
 
-		var num int
-		if _, err := fmt.Sscanf(args[0], "%d", &num); err != nil {
-			fmt.Println(errorMsg("Version-number must be an integer (e.g., 0, 1, 2)"))
-			os.Exit(1)
+		if projectOnly, _ := cmd.Flags().GetBool("project"); projectOnly {
+			if asJSON {
+				printJSON(newJSONProjectSummary(proj))
+				return
+			}
+			printProjectSummary(proj)
+			return
 		}
 
-		v, err := proj.GetVersion(num)
+		v, err := proj.FindVersion(parseVersionArg(proj, args[0]))
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("%v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("%v", err))
+		}
+
+		assetsOnly, _ := cmd.Flags().GetBool("assets")
+		detail, _ := cmd.Flags().GetBool("detail")
+		if asJSON && (assetsOnly || detail) {
+			printJSON(v.Assets)
+			return
+		}
+		if asJSON {
+			printJSON(v)
+			return
+		}
+		if assetsOnly || detail {
+			if len(v.Assets) == 0 {
+				fmt.Println(infoMsg(i18n.T("No assets in this version")))
+				return
+			}
+			if detail {
+				printAssetDetails(proj, v)
+			} else {
+				fmt.Println(assetTable(v.Assets))
+			}
+			return
 		}
 
 		fmt.Printf("%s Version:   %d\n", ui.InfoStyle.Render("Version:"), v.Number)
 		fmt.Printf("%s Message:   %s\n", ui.InfoStyle.Render("Message:"), v.Message)
 		fmt.Printf("%s Time:      %s\n", ui.InfoStyle.Render("Time:"), v.Timestamp.Format("2006-01-02 15:04:05"))
+		if v.Author != "" {
+			fmt.Printf("%s Author:    %s\n", ui.InfoStyle.Render("Author:"), v.Author)
+		}
+		if v.AppVersion != nil {
+			fmt.Printf("%s Saved by:  %s\n", ui.InfoStyle.Render("Saved by:"), v.AppVersion)
+		}
+		if len(v.Labels) > 0 {
+			fmt.Printf("%s Labels:    %s\n", ui.InfoStyle.Render("Labels:"), versionLabels(proj, v))
+		}
+		if len(v.Tags) > 0 {
+			fmt.Printf("%s Tags:      %s\n", ui.InfoStyle.Render("Tags:"), strings.Join(v.Tags, ", "))
+		}
+		if len(v.Meta) > 0 {
+			fmt.Printf("%s Meta:      %s\n", ui.InfoStyle.Render("Meta:"), strings.Join(project.MetaPairs(v.Meta), ", "))
+		}
+		if v.Signature != nil {
+			fmt.Printf("%s Signed:    %s\n", ui.InfoStyle.Render("Signed:"), signatureSummary(v))
+		}
 		fmt.Printf("%s Proj Size: %.2f MB\n", ui.InfoStyle.Render("Proj Size:"), float64(v.Size)/(1024*1024))
 		fmt.Printf("%s Assets:    %d files\n", ui.InfoStyle.Render("Assets:"), v.AssetCount)
+		if cs, err := proj.CommitStorage(v.Number); err == nil {
+			fmt.Printf("%s Stored:    %.2f MB new, %.2f MB reused\n", ui.InfoStyle.Render("Stored:"),
+				float64(cs.StoredSize)/(1024*1024), float64(cs.ReusedSize)/(1024*1024))
+		}
 		if v.DockerPath != "" {
 			fmt.Printf("%s Docker:    %s\n", ui.InfoStyle.Render("Docker:"), v.DockerPath)
 		}
-		if len(v.Assets) > 0 {
+		if len(v.Comps) > 0 {
+			fmt.Println()
+			fmt.Println(infoMsg(i18n.T("Compositions (%d):", len(v.Comps))))
+			for _, c := range v.Comps {
+				fmt.Printf("  - %s: %s\n", c.Name, c)
+			}
+		}
+		if len(v.LayerChanges) > 0 {
+			fmt.Println()
+			fmt.Println(infoMsg(i18n.T("Layer changes since previous version:")))
+			for _, c := range v.LayerChanges {
+				fmt.Printf("  - %s: %s added, %s removed, %s modified\n", c.Comp,
+					ui.SuccessStyle.Render(fmt.Sprintf("%d", c.Added)),
+					ui.ErrorStyle.Render(fmt.Sprintf("%d", c.Removed)),
+					ui.WarningStyle.Render(fmt.Sprintf("%d", c.Modified)))
+			}
+		}
+		if len(v.Missing) > 0 {
+			fmt.Println()
+			fmt.Println(warningMsg(i18n.T("Missing at commit (%d):", len(v.Missing))))
+			for _, m := range v.Missing {
+				fmt.Printf("  - %s\n", m)
+			}
+		}
+		if len(v.Markers) > 0 {
 			fmt.Println()
-			fmt.Println(infoMsg("Assets:"))
-			for _, a := range v.Assets {
-				fmt.Printf("  - %s (%s)  %.2f MB\n", a.Filename, a.Extension, float64(a.Size)/(1024*1024))
+			fmt.Println(infoMsg(i18n.T("Markers and notes:")))
+			for _, m := range v.Markers {
+				location := m.Comp
+				if m.Layer != "" {
+					location += " › " + m.Layer
+				}
+				if m.Kind == "note" {
+					location += " (note)"
+				}
+				fmt.Printf("  - %s: %s\n", ui.InfoStyle.Render(location), strings.ReplaceAll(m.Text, "\n", " "))
 			}
 		}
+		if len(v.Assets) > 0 {
+			fmt.Println()
+			fmt.Println(infoMsg(i18n.T("Assets:")))
+			fmt.Println(assetTable(v.Assets))
+		}
 	},
 }
 
+// assetTable renders a version's assets as a table, truncating long filenames to fit
+func assetTable(assetList []project.AssetInfo) string {
+	rows := make([][]string, 0, len(assetList))
+	for _, a := range assetList {
+		rows = append(rows, []string{a.Filename, a.Extension, fmt.Sprintf("%.2f MB", float64(a.Size)/(1024*1024))})
+	}
+	return ui.Table([]string{"File", "Type", "Size"}, rows, 0)
+}
+
+// printAssetDetails prints one block per asset with its probed media info, hash and
+// whether it was new in the version
+func printAssetDetails(proj *project.Project, v *project.Version) {
+	for i, a := range v.Assets {
+		if i > 0 {
+			fmt.Println()
+		}
+		status := ui.InfoStyle.Render("reused")
+		if a.Hash != "" && proj.IsNewAsset(v.Number, a.Hash) {
+			status = ui.SuccessStyle.Render("new")
+		}
+		fmt.Printf("%s  %s\n", a.Filename, status)
+		fmt.Printf("  %-11s %.2f MB\n", "Size:", float64(a.Size)/(1024*1024))
+
+		if a.Media != nil {
+			fmt.Printf("  %-11s %s\n", "Codec:", a.Media.Codec)
+			if res := a.Media.Resolution(); res != "" {
+				fmt.Printf("  %-11s %s\n", "Resolution:", res)
+			}
+			if dur := a.Media.FormatDuration(); dur != "" {
+				fmt.Printf("  %-11s %s\n", "Duration:", dur)
+			}
+		} else {
+			fmt.Printf("  %-11s %s\n", "Media:", "(no probe data)")
+		}
+
+		hash := a.Hash
+		if hash == "" {
+			hash = "(not recorded)"
+		}
+		fmt.Printf("  %-11s %s\n", "Hash:", hash)
+		if a.FoundIn != "" {
+			fmt.Printf("  %-11s %s\n", "Found in:", a.FoundIn)
+		}
+		if a.Cloud != "" {
+			fmt.Printf("  %-11s %s\n", "Synced by:", a.Cloud)
+		}
+	}
+}
+
 var pruneCmd = &cobra.Command{
 	Use:   "prune",
-	Short: "Remove commits whose storage is missing in Docker",
+	Short: "Remove commits whose storage is missing in Docker or the project's remote",
 	Run: func(cmd *cobra.Command, args []string) {
+		asJSON := startJSONOutput()
 		// Get project from context (already ensured by PersistentPreRunE)
 		proj, err := ensureProjectContext()
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error: %v", err))
 		}
 		
 		// Change to the directory containing the .vervids config file
 		cleanup, err := changeToProjectDirectory()
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error: %v", err))
 		}
 		defer cleanup()
 		
-		if err := docker.EnsureDockerReady(); err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("%v", err)))
-			os.Exit(1)
+		if err := proj.CheckWritable(); err != nil {
+			exitWithError(err, i18n.T("%v", err))
 		}
 		removed, err := proj.PruneMissingDockerVersions()
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error pruning: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error pruning: %v", err))
+		}
+		if asJSON {
+			// Tracking issues are only reported; repairing them needs the prompt
+			result := jsonPruneResult{Pruned: removed, Storage: proj.StorageName()}
+			issues, err := proj.CheckTracking()
+			if err != nil {
+				exitWithError(err, i18n.T("Error checking tracking: %v", err))
+			}
+			for _, issue := range issues {
+				result.TrackingIssues = append(result.TrackingIssues, jsonTrackingIssue{Version: issue.Version, Problem: issue.Problem})
+			}
+			printJSON(result)
+			return
 		}
 		if removed == 0 {
-			fmt.Println(successMsg("Nothing to prune; all versions present in Docker"))
+			fmt.Println(successMsg(i18n.T("Nothing to prune; all versions present in %s", proj.StorageName())))
 		} else {
-			fmt.Println(successMsg(fmt.Sprintf("Pruned %d missing version(s)", removed)))
+			fmt.Println(successMsg(i18n.T("Pruned %d missing version(s)", removed)))
+		}
+
+		// Keep asset-tracking.json in line with config.json for the versions that remain
+		issues, err := proj.CheckTracking()
+		if err != nil {
+			exitWithError(err, i18n.T("Error checking tracking: %v", err))
+		}
+		if len(issues) == 0 {
+			return
+		}
+		printTrackingIssues(issues)
+		fmt.Print(infoMsg(i18n.T("Regenerate tracking for these versions from config.json? (y/N): ")))
+		reader := bufio.NewReader(os.Stdin)
+		input, _ := reader.ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(input)); answer == "y" || answer == "yes" {
+			repairTracking(proj, issues)
 		}
 	},
 }
 
 var pullCmd = &cobra.Command{
-	Use:   "pull <version> [output-dir]",
-	Short: "Pull a version from Docker storage to local filesystem",
-	Long: `Pull a specific version from Docker storage to your local filesystem.
+	Use:   "pull <version> [output-dir] | pull --as-of <time> [output-dir]",
+	Short: "Pull a version from storage to local filesystem",
+	Long: `Pull a specific version from Docker or the project's remote to your local filesystem.
 The .aepx file and all assets will be copied. If assets don't exist at their
-original paths, they will be copied from Docker storage and the .aepx file
+original paths, they will be copied from storage and the .aepx file
 will be updated to reference the new asset locations.
 
 Requires a project to be selected. Use 'vervids list' to select a project.
+The version can be given by number or by a tag set with 'vervids tag'.
+
+Use --preserve-structure to restore assets under assets/<original relative path>
+instead of one flat folder, mirroring the original project organization.
+
+Use --as-of instead of a version number to pull whatever was the latest version at a
+point in time, e.g. what was shown at a review nobody remembers the version of. It
+takes "2024-03-01 18:00" in local time, or a date alone for the end of that day.
+
+A warning is shown when the version was saved by a newer After Effects release than
+the one installed here (found in the usual application folder, or set with
+'vervids config --global ae_version 2024').
 
 Example:
   vervids pull 2              # Pull version 2 to current directory
-  vervids pull 1 ./restored   # Pull version 1 to ./restored directory`,
-	Args: cobra.RangeArgs(1, 2),
+  vervids pull 1 ./restored   # Pull version 1 to ./restored directory
+  vervids pull final-v2 ./delivery
+  vervids pull 1 ./restored --preserve-structure
+  vervids pull --as-of "2024-03-01 18:00" ./review`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if asOf, _ := cmd.Flags().GetString("as-of"); asOf != "" {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		asJSON := startJSONOutput()
 		// Get project from context (already ensured by PersistentPreRunE)
 		proj, err := ensureProjectContext()
 		if err != nil {
 			if strings.Contains(err.Error(), "no projects available") {
-				fmt.Println(errorMsg("No projects available. Use 'vervids init <file.aepx>' to create a project first."))
-			} else {
-				fmt.Println(errorMsg(fmt.Sprintf("Error: %v", err)))
+				exitWithError(err, i18n.T("No projects available. Use 'vervids init <file.aepx>' to create a project first."))
 			}
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error: %v", err))
 		}
 
 		if proj == nil {
-			fmt.Println(errorMsg("No project selected. Use 'vervids list' to select a project."))
-			os.Exit(1)
+			exitWithKind(errs.ProjectNotFound, i18n.T("No project selected. Use 'vervids list' to select a project."))
 		}
 
-		// Parse version number
-		versionNum, err := strconv.Atoi(args[0])
-		if err != nil {
-			fmt.Println(errorMsg("Version must be a number"))
-			os.Exit(1)
+		// Parse version number, or find the version that was current at --as-of
+		var versionNum int
+		dirArgs := args
+		if asOf, _ := cmd.Flags().GetString("as-of"); asOf != "" {
+			t, err := project.ParseAsOf(asOf)
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			v, err := proj.VersionAsOf(t)
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			versionNum = v.Number
+			fmt.Println(infoMsg(i18n.T("Version %d was the latest at %s (committed %s: %s)",
+				v.Number, t.Format("2006-01-02 15:04"), v.Timestamp.Format("2006-01-02 15:04"), v.Message)))
+		} else {
+			versionNum = parseVersionArg(proj, args[0])
+			dirArgs = args[1:]
 		}
 
 		// Get output directory (default to current directory)
 		outputDir := "."
-		if len(args) > 1 {
-			outputDir = args[1]
+		if len(dirArgs) > 0 {
+			outputDir = dirArgs[0]
 		}
 
 		// Convert to absolute path
 		absOutputDir, err := filepath.Abs(outputDir)
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error getting absolute path: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error getting absolute path: %v", err))
 		}
 
-		fmt.Println(infoMsg(fmt.Sprintf("📦 Pulling version %d...", versionNum)))
+		fmt.Println(infoMsg(i18n.T("📦 Pulling version %d...", versionNum)))
+		if v, err := proj.FindVersion(versionNum); err == nil {
+			warnNewerAEVersion(v)
+		}
 
 		// Pull the version
-		restoredPath, err := proj.RestoreVersion(versionNum, absOutputDir)
+		preserveStructure, _ := cmd.Flags().GetBool("preserve-structure")
+		restoredPath, err := proj.RestoreVersion(versionNum, absOutputDir, project.RestoreOptions{
+			PreserveStructure: preserveStructure,
+		})
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error pulling version: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error pulling version: %v", err))
 		}
 
-		fmt.Println()
-		fmt.Println(successMsg(fmt.Sprintf("✓ Successfully pulled version %d", versionNum)))
-		fmt.Printf("  Project file: %s\n", restoredPath)
-
 		// Check if assets directory exists (only show if assets were copied)
 		assetsDir := filepath.Join(absOutputDir, "assets")
-		if _, err := os.Stat(assetsDir); err == nil {
+		if _, err := os.Stat(assetsDir); err != nil {
+			assetsDir = ""
+		}
+		if asJSON {
+			printJSON(jsonPullResult{Version: versionNum, ProjectFile: restoredPath, AssetsDir: assetsDir})
+			return
+		}
+
+		fmt.Println()
+		fmt.Println(successMsg(i18n.T("✓ Successfully pulled version %d", versionNum)))
+		fmt.Printf("  Project file: %s\n", restoredPath)
+		if assetsDir != "" {
 			fmt.Printf("  Assets directory: %s\n", assetsDir)
 		}
 	},
 }
 
+// warnNewerAEVersion warns when a version was saved by a newer After Effects than the
+// one installed here, which won't open it
+func warnNewerAEVersion(v *project.Version) {
+	if v.AppVersion == nil {
+		return
+	}
+	s, err := settings.Load()
+	if err != nil {
+		return
+	}
+	local := s.InstalledAEVersion()
+	if local == nil || !v.AppVersion.NewerThan(*local) {
+		return
+	}
+	fmt.Println(warningMsg(i18n.T("Version %d was saved with %s, but %s is installed here; it may not open",
+		v.Number, v.AppVersion, local)))
+	fmt.Println(infoMsg(i18n.T("Save a copy for the older release from %s, or set 'vervids config --global ae_version' if the installed release was detected wrongly", v.AppVersion)))
+}
+
 var deleteCmd = &cobra.Command{
 	Use:   "delete <project-name>",
 	Short: "Delete a project and all its data",
@@ -1110,19 +1915,18 @@ Example:
   vervids delete myproject`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		asJSON := startJSONOutput()
 		projectName := args[0]
 
 		// Ensure Docker is ready
 		if err := docker.EnsureDockerReady(); err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("%v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("%v", err))
 		}
 
 		// Get all projects to find the one to delete
 		projects, err := project.GetAllProjects()
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error getting projects: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error getting projects: %v", err))
 		}
 
 		// Find project by name (case-insensitive partial match)
@@ -1137,13 +1941,14 @@ Example:
 		}
 
 		if targetProject == nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Project '%s' not found", projectName)))
-			fmt.Println()
-			fmt.Println(infoMsg("Available projects:"))
+			hints := []string{"", i18n.T("Available projects:")}
 			for _, p := range projects {
-				fmt.Printf("  %s %s\n", ui.InfoStyle.Render("•"), p.Name)
+				hints = append(hints, fmt.Sprintf("  • %s", p.Name))
 			}
-			os.Exit(1)
+			exitWithKind(errs.ProjectNotFound, i18n.T("Project '%s' not found", projectName), hints...)
+		}
+		if err := checkProjectWritable(targetProject.Name); err != nil {
+			exitWithError(err, i18n.T("%v", err))
 		}
 
 		// Show project info
@@ -1152,38 +1957,56 @@ Example:
 		fmt.Println()
 
 		// Confirmation prompt
-		fmt.Print(warningMsg("WARNING: This will permanently delete all project data!\n"))
-		fmt.Print(infoMsg("Type 'DELETE' to confirm: "))
+		fmt.Print(warningMsg(i18n.T("WARNING: This will permanently delete all project data!\n")))
+		fmt.Print(infoMsg(i18n.T("Type 'DELETE' to confirm: ")))
 
 		reader := bufio.NewReader(os.Stdin)
 		confirmation, err := reader.ReadString('\n')
 		if err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error reading input: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error reading input: %v", err))
 		}
 
 		confirmation = strings.TrimSpace(confirmation)
 		if confirmation != "DELETE" {
-			fmt.Println(errorMsg("Deletion cancelled (confirmation did not match)"))
-			os.Exit(1)
+			exitWithError(nil, i18n.T("Deletion cancelled (confirmation did not match)"))
 		}
 
 		// Delete project
 		fmt.Println()
-		fmt.Println(infoMsg("🗑️  Deleting project..."))
+		fmt.Println(infoMsg(i18n.T("🗑️  Deleting project...")))
 
 		if err := project.DeleteProjectByName(targetProject.Name, targetProject.DockerPath); err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Error deleting project: %v", err)))
-			os.Exit(1)
+			exitWithError(err, i18n.T("Error deleting project: %v", err))
+		}
+		if asJSON {
+			printJSON(jsonDeleteResult{Deleted: targetProject.Name, DockerPath: targetProject.DockerPath})
+			return
 		}
 
-		fmt.Println(successMsg("Project deleted successfully"))
-		fmt.Println(successMsg("  • All versions removed from Docker"))
-		fmt.Println(successMsg("  • All assets removed from Docker"))
-		fmt.Println(successMsg("  • Local .vervids directory removed (if found)"))
+		fmt.Println(successMsg(i18n.T("Project deleted successfully")))
+		fmt.Println(successMsg(i18n.T("  • All versions removed from Docker")))
+		fmt.Println(successMsg(i18n.T("  • All assets removed from Docker")))
+		fmt.Println(successMsg(i18n.T("  • Local .vervids directory removed (if found)")))
 	},
 }
 
+// checkProjectWritable returns a ReadOnly error when the named project can't be
+// deleted: the vault is read-only, or its config on this machine is archived read-only
+func checkProjectWritable(name string) error {
+	if err := project.CheckVaultWritable(); err != nil {
+		return err
+	}
+	configPath, err := findProjectConfigFile(name)
+	if err != nil {
+		return nil // No config here, so nothing marks it read-only
+	}
+	proj, err := project.LoadFromPath(configPath)
+	if err != nil {
+		return nil
+	}
+	return proj.CheckWritable()
+}
+
 var serveCmd = &cobra.Command{
 	Use:   "serve [port]",
 	Short: "Start the HTTP API server for plugin access",
@@ -1192,35 +2015,63 @@ var serveCmd = &cobra.Command{
 The server provides REST endpoints:
   GET /api/projects - List all projects with their IDs
   GET /api/projects/{id}/commits - Get commits for a specific project
+  GET /api/events - Server-sent events when projects change
   GET /health - Health check endpoint
 
-Default port is 8080 if not specified.
+While it runs, the server watches the project configs and the project list in
+storage. Commits, reverts and other changes made from the terminal meanwhile are
+picked up within seconds and announced on /api/events, so the panel can reload
+instead of showing an outdated history.
+
+The server never changes a project. Projects that are read-only (see 'vervids config
+set read_only true') are marked "read_only" in the responses, so the panel can hide
+actions that would be refused.
+
+Default port is 8080 if not specified, or the port set with
+'vervids config set --global port <port>'.
+
+With --auth, every request except /health must carry an API token created with
+'vervids token create', as "Authorization: Bearer <token>"; others get 401. Browsers
+can't set headers on /api/events, so it also takes the token as ?access_token=.
 
 Example:
   vervids serve        # Start server on port 8080
-  vervids serve 3000   # Start server on port 3000`,
+  vervids serve 3000   # Start server on port 3000
+  vervids serve --auth # Require an API token`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		port := 8080
+		port := settings.DefaultPort
+		if s, err := settings.Load(); err == nil {
+			port = s.ServePort()
+		}
 		if len(args) > 0 {
 			p, err := strconv.Atoi(args[0])
 			if err != nil {
-				fmt.Println(errorMsg(fmt.Sprintf("Invalid port number: %v", err)))
-				os.Exit(1)
+				exitWithError(err, i18n.T("Invalid port number: %v", err))
 			}
 			if p < 1 || p > 65535 {
-				fmt.Println(errorMsg("Port must be between 1 and 65535"))
-				os.Exit(1)
+				exitWithError(nil, i18n.T("Port must be between 1 and 65535"))
 			}
 			port = p
 		}
 
+		var auth api.TokenValidator
+		if requireAuth, _ := cmd.Flags().GetBool("auth"); requireAuth {
+			if s, err := settings.Load(); err != nil || len(s.APITokens) == 0 {
+				exitWithError(err, i18n.T("No API tokens to accept"), i18n.T("Create one first: vervids token create <name>"))
+			}
+			// Settings are read per request, so tokens created or revoked meanwhile apply at once
+			auth = func(token string) bool {
+				s, err := settings.Load()
+				return err == nil && s.ValidAPIToken(token)
+			}
+		}
+
 		printBoxedHeader()
 		fmt.Println()
 
-		if err := api.StartServer(port); err != nil {
-			fmt.Println(errorMsg(fmt.Sprintf("Failed to start server: %v", err)))
-			os.Exit(1)
+		if err := api.StartServer(port, auth); err != nil {
+			exitWithError(err, i18n.T("Failed to start server: %v", err))
 		}
 	},
 }
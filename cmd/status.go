@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [file.aepx]",
+	Short: "Show what changed since the latest version",
+	Long: `Compare the working project file and the assets it references with the latest
+version: whether the project file changed, and which assets are new, modified or no
+longer referenced, judged by size and by the checksums recorded at commit time.
+Assets the file references that aren't on disk are listed as missing.
+
+Nothing is read from storage, so status works without Docker or the remote.
+Without a file, the project file the project was last committed from is used.
+
+Example:
+  vervids status
+  vervids status ./edit_v2.aepx`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		file := proj.WorkingFile()
+		if len(args) > 0 {
+			file = args[0]
+		}
+		if file == "" {
+			exitWithError(nil, i18n.T("No working project file found"), i18n.T("Name the project file to compare: vervids status <file.aepx>"))
+		}
+		if file, err = filepath.Abs(file); err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if _, err := os.Stat(file); err != nil {
+			exitWithError(nil, i18n.T("File '%s' does not exist", file))
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		status, err := proj.Status(file)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		v := status.Version
+		fmt.Printf("%s v%d - %s (%s)\n", ui.InfoStyle.Render("Latest version:"), v.Number, v.Message, v.Timestamp.Format("2006-01-02 15:04"))
+		if status.FileChanged {
+			fmt.Printf("%s %s  %s\n", ui.InfoStyle.Render("Project file:"), filepath.Base(file), ui.WarningStyle.Render("modified"))
+		} else {
+			fmt.Printf("%s %s  unchanged\n", ui.InfoStyle.Render("Project file:"), filepath.Base(file))
+		}
+
+		pending := status.Assets
+		fmt.Printf("%s %d new, %d modified, %d unchanged, %d removed, %d missing\n",
+			ui.InfoStyle.Render("Assets:"),
+			pending.Count("new"), pending.Count("modified"), pending.Count("unchanged"), len(pending.Removed), len(pending.Missing))
+		printPendingAssets(pending)
+
+		fmt.Println()
+		if status.Clean() {
+			fmt.Println(successMsg(i18n.T("Nothing to commit; %s matches v%d", filepath.Base(file), v.Number)))
+		} else {
+			fmt.Println(infoMsg(i18n.T("Run 'vervids commit \"<message>\" %s' to record these changes", filepath.Base(file))))
+		}
+	},
+}
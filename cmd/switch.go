@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var switchCmd = &cobra.Command{
+	Use:   "switch [query]",
+	Short: "Pick the current project with fuzzy search",
+	Long: `Open a fuzzy-search picker over all projects in Docker storage, showing each one's
+local path and last commit, and make the chosen project the current one.
+
+Type to filter, use ↑/↓ to move, enter to select and esc to cancel. A query given on
+the command line pre-fills the search; when it matches exactly one project, that
+project is selected without opening the picker.
+
+Example:
+  vervids switch
+  vervids switch promo`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projects, err := project.GetAllProjects()
+		if err != nil {
+			exitWithError(err, i18n.T("Error getting projects: %v", err))
+		}
+		if len(projects) == 0 {
+			exitWithKind(errs.ProjectNotFound, i18n.T("No projects found in Docker storage."),
+				i18n.T("Use 'vervids init <file.aepx>' to create a project."))
+		}
+
+		query := ""
+		if len(args) > 0 {
+			query = args[0]
+		}
+
+		candidates := make([]switchCandidate, len(projects))
+		items := make([]ui.PickItem, len(projects))
+		for i, p := range projects {
+			candidates[i] = loadSwitchCandidate(p)
+			items[i] = ui.PickItem{Label: p.Name, Detail: candidates[i].detail()}
+		}
+
+		choice := -1
+		if matches := ui.FuzzyFilter(query, items); query != "" && len(matches) == 1 {
+			choice = matches[0]
+		} else if query != "" && len(matches) == 0 {
+			exitWithKind(errs.ProjectNotFound, i18n.T("No project matches '%s'", query))
+		} else {
+			if !term.IsTerminal(os.Stdin.Fd()) || !term.IsTerminal(os.Stdout.Fd()) {
+				hints := []string{i18n.T("Give a query that matches one project:")}
+				for _, i := range matches {
+					hints = append(hints, fmt.Sprintf("  • %s", projects[i].Name))
+				}
+				exitWithError(nil, i18n.T("Choosing a project needs an interactive terminal"), hints...)
+			}
+			index, ok, err := ui.Pick(i18n.T("Switch to project:"), query, items)
+			if err != nil {
+				exitWithError(err, i18n.T("Error: %v", err))
+			}
+			if !ok {
+				fmt.Println(infoMsg(i18n.T("No project selected")))
+				return
+			}
+			choice = index
+		}
+
+		selected := candidates[choice]
+		if selected.err != nil {
+			exitWithKind(errs.ProjectNotFound, i18n.T("Could not find config file for project: %s", projects[choice].Name),
+				i18n.T("Tip: Navigate to the project directory, or ensure .vervids/config.json exists."),
+				i18n.T("The project exists in Docker storage, but the local config file is missing."))
+		}
+		if err := setProjectContext(selected.proj, selected.configPath); err != nil {
+			exitWithError(err, i18n.T("Error saving context: %v", err))
+		}
+
+		fmt.Println(successMsg(i18n.T("Switched to project: %s", selected.proj.ProjectName)))
+		fmt.Println()
+		showProjectCommits(selected.proj)
+	},
+}
+
+// switchCandidate is a project in Docker storage with its local config, if found
+type switchCandidate struct {
+	proj       *project.Project
+	configPath string
+	err        error
+}
+
+func loadSwitchCandidate(info project.ProjectInfo) switchCandidate {
+	var c switchCandidate
+	c.configPath, c.err = findProjectConfigFile(info.Name)
+	if c.err == nil {
+		c.proj, c.err = project.LoadFromPath(c.configPath)
+	}
+	return c
+}
+
+// detail describes where the project lives and its last commit
+func (c switchCandidate) detail() string {
+	if c.err != nil {
+		return i18n.T("local config not found")
+	}
+	parts := []string{filepath.Dir(filepath.Dir(c.configPath))}
+	if abs, err := filepath.Abs(parts[0]); err == nil {
+		parts[0] = abs
+	}
+	if v := c.proj.GetLatestVersion(); v != nil {
+		parts = append(parts, fmt.Sprintf("v%03d %s  %s", v.Number, v.Timestamp.Format("2006-01-02 15:04"), v.Message))
+	} else {
+		parts = append(parts, i18n.T("no commits"))
+	}
+	return strings.Join(parts, "  ·  ")
+}
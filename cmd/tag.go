@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <name> <version>",
+	Short: "Name a version so it can be used in place of its number",
+	Long: `Give a version a name such as "client-review-1" or "final-v2". The name can then be
+used anywhere a version number is taken: 'vervids show final-v2', 'vervids pull
+client-review-1 ./review', 'vervids diff client-review-1 final-v2'.
+
+Unlike labels, a tag names exactly one version. Tagging another version with a name
+already in use fails unless --force is given, which moves the tag. Names can't
+contain spaces or be a plain number, and "list" and "remove" are taken by the
+subcommands. Tags are stored in the project config.
+
+Example:
+  vervids tag client-review-1 4
+  vervids tag final-v2 9
+  vervids tag final-v2 10 --force
+  vervids tag list
+  vervids tag remove client-review-1`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		name := args[0]
+		version := parseVersionArg(proj, args[1])
+		force, _ := cmd.Flags().GetBool("force")
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		from, err := tagVersion(proj, name, version, force)
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		fmt.Println(successMsg(tagResult(name, from, version)))
+	},
+}
+
+// tagVersion puts a tag on a version and saves the project, for 'vervids tag' and the
+// dashboard. Runs from the project's directory, in the autosave scheduler's turn.
+func tagVersion(proj *project.Project, name string, version int, force bool) (int, error) {
+	from, err := proj.SetTag(name, version, force)
+	if err != nil {
+		return -1, err
+	}
+	if err := proj.Save(); err != nil {
+		return -1, fmt.Errorf("failed to save project: %w", err)
+	}
+	return from, nil
+}
+
+// tagResult describes a tag set by tagVersion
+func tagResult(name string, from int, version int) string {
+	if from >= 0 {
+		return i18n.T("Moved tag %s from version %d to version %d", name, from, version)
+	}
+	return i18n.T("Tagged version %d as %s", version, name)
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the project's tags and their versions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		tags := proj.SortedTags()
+		if len(tags) == 0 {
+			fmt.Println(infoMsg(i18n.T("No tags yet. Use 'vervids tag <name> <version>' to add one.")))
+			return
+		}
+
+		rows := make([][]string, 0, len(tags))
+		for _, t := range tags {
+			v, err := proj.FindVersion(t.Version)
+			if err != nil {
+				exitWithError(err, i18n.T("Error: tag %s points at a missing version: %v", t.Name, err))
+			}
+			rows = append(rows, []string{t.Name, fmt.Sprintf("v%d", t.Version), v.Timestamp.Format("2006-01-02 15:04"), v.Message})
+		}
+		fmt.Println(ui.Table([]string{"Tag", "Version", "Time", "Message"}, rows, 3))
+	},
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Delete a tag; the version is kept",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+
+		version, err := proj.RemoveTag(args[0])
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if err := proj.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving project: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("Removed tag %s from version %d", args[0], version)))
+	},
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var timelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "Plot project size and asset counts over versions",
+	Long: `Show a terminal chart of how the project has grown over its versions.
+
+Each row is one version with a bar scaled to its total size (project file + assets),
+followed by the size change and asset count. Versions whose size jumped by more
+than the threshold are highlighted so big-change commits stand out.
+
+Example:
+  vervids timeline
+  vervids timeline --width 60 --threshold 10`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get project from context (already ensured by PersistentPreRunE)
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+
+		width, _ := cmd.Flags().GetInt("width")
+		threshold, _ := cmd.Flags().GetFloat64("threshold")
+
+		if len(proj.Versions) == 0 {
+			fmt.Println(infoMsg(i18n.T("No commits yet. Use 'vervids commit \"message\" <file.aepx>' to create one.")))
+			return
+		}
+
+		// Collect sizes (MB) and asset counts per version
+		sizes := make([]float64, len(proj.Versions))
+		counts := make([]float64, len(proj.Versions))
+		maxSize := 0.0
+		for i, v := range proj.Versions {
+			sizes[i] = float64(v.Size+v.TotalSize) / (1024 * 1024)
+			counts[i] = float64(v.AssetCount)
+			if sizes[i] > maxSize {
+				maxSize = sizes[i]
+			}
+		}
+
+		fmt.Printf("%s: %s\n", ui.InfoStyle.Render("Project"), proj.ProjectName)
+		fmt.Printf("%s: %s\n", ui.InfoStyle.Render("Size  "), ui.Sparkline(sizes))
+		fmt.Printf("%s: %s\n\n", ui.InfoStyle.Render("Assets"), ui.Sparkline(counts))
+
+		fmt.Println(infoMsg(i18n.T("#   Date        %-*s  Size(MB)     Change  Assets", width, "Size")))
+		for i, v := range proj.Versions {
+			change := ""
+			big := false
+			if i > 0 {
+				delta := sizes[i] - sizes[i-1]
+				change = fmt.Sprintf("%+.2f", delta)
+				if sizes[i-1] > 0 && (delta/sizes[i-1])*100 >= threshold {
+					big = true
+				} else if sizes[i-1] == 0 && delta > 0 {
+					big = true
+				}
+			}
+
+			line := fmt.Sprintf("%02d  %s  %s  %8.2f  %9s  %6d",
+				v.Number,
+				v.Timestamp.Format("2006-01-02"),
+				ui.Bar(sizes[i], maxSize, width),
+				sizes[i],
+				change,
+				v.AssetCount,
+			)
+			if big {
+				fmt.Println(ui.WarningStyle.Render(line + "  ▲ " + v.Message))
+			} else {
+				fmt.Println(line + "  " + v.Message)
+			}
+		}
+
+		first := sizes[0]
+		last := sizes[len(sizes)-1]
+		fmt.Println()
+		fmt.Println(infoMsg(i18n.T("Growth: %.2f MB → %.2f MB across %d version(s)", first, last, len(proj.Versions))))
+	},
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/settings"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage the API tokens accepted by 'vervids serve --auth'",
+	Long: `API tokens let plugins and scripts use the HTTP API once 'vervids serve --auth'
+requires them. Each request sends one as "Authorization: Bearer <token>".
+
+Tokens are named, e.g. after the plugin or machine using them. A token is shown once,
+when it is created; only its SHA-256 hash is kept in ~/.vervids/settings.yaml.
+Creating a token under a name that exists rotates it: the old token stops working.
+A running server picks up new, rotated and revoked tokens on the next request.
+
+Example:
+  vervids token create ae-panel
+  vervids token list
+  vervids token revoke ae-panel`,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a token, or rotate the one with that name",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := settings.Load()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		token, rotated, err := s.CreateAPIToken(args[0])
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if err := s.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving settings: %v", err))
+		}
+		if rotated {
+			fmt.Println(successMsg(i18n.T("Rotated token '%s'; the previous one no longer works", args[0])))
+		} else {
+			fmt.Println(successMsg(i18n.T("Created token '%s'", args[0])))
+		}
+		fmt.Println(token)
+		fmt.Println(infoMsg(i18n.T("Copy it now: it can't be shown again.")))
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API tokens by name",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := settings.Load()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if len(s.APITokens) == 0 {
+			fmt.Println(infoMsg(i18n.T("No API tokens. Create one with 'vervids token create <name>'.")))
+			return
+		}
+		for _, t := range s.APITokens {
+			fmt.Printf("%s  created %s\n", ui.InfoStyle.Render(t.Name), t.CreatedAt.Format("2006-01-02 15:04"))
+		}
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: "Delete an API token",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := settings.Load()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		if !s.RemoveAPIToken(args[0]) {
+			exitWithError(nil, i18n.T("No token named '%s'", args[0]))
+		}
+		if err := s.Save(); err != nil {
+			exitWithError(err, i18n.T("Error saving settings: %v", err))
+		}
+		fmt.Println(successMsg(i18n.T("Revoked token '%s'", args[0])))
+	},
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/signing"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var verifySignaturesCmd = &cobra.Command{
+	Use:   "verify-signatures",
+	Short: "Check who signed each version",
+	Long: `Verify the signature on every version of the current project. Versions are signed
+on commit when the global signing_key setting points at an SSH or minisign private key:
+
+  vervids config set --global signing_key ~/.ssh/id_ed25519
+
+A signature covers the version's message, time, author, metadata and the content
+hashes of the project file and every asset, so any later change to what was recorded
+makes it fail. Checking needs ssh-keygen or minisign, whichever made the signature.
+
+Keys listed in .vervids/trusted_keys (or the file given with --keys) are the ones
+accepted: one public key per line, as found in id_ed25519.pub or minisign.pub. Valid
+signatures by any other key are reported as untrusted. Without a trust list, any
+valid signature passes.
+
+With --files, each stored project file is fetched from Docker and compared with the
+hash that was signed. With --strict, unsigned versions fail the check too.
+
+Example:
+  vervids verify-signatures
+  vervids verify-signatures --keys delivery-signers.txt --files --strict`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		proj, err := ensureProjectContext()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		keysFile, _ := cmd.Flags().GetString("keys")
+		checkFiles, _ := cmd.Flags().GetBool("files")
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		var trusted []string
+		if keysFile != "" {
+			if trusted, err = signing.LoadTrustedKeys(keysFile); err != nil {
+				exitWithError(err, i18n.T("Error reading trusted keys: %v", err))
+			}
+		}
+
+		cleanup, err := changeToProjectDirectory()
+		if err != nil {
+			exitWithError(err, i18n.T("Error: %v", err))
+		}
+		defer cleanup()
+		if keysFile == "" {
+			if trusted, err = project.LoadTrustedKeys(); err != nil {
+				exitWithError(err, i18n.T("Error reading trusted keys: %v", err))
+			}
+		}
+		if checkFiles {
+			if err := proj.EnsureStorageReady(); err != nil {
+				exitWithError(err, i18n.T("%v", err))
+			}
+		}
+
+		checks := proj.VerifySignatures(trusted, checkFiles)
+		counts := map[string]int{}
+		rows := make([][]string, 0, len(checks))
+		for _, c := range checks {
+			counts[c.Status]++
+			detail := ""
+			if c.Err != nil {
+				detail = c.Err.Error()
+			}
+			rows = append(rows, []string{fmt.Sprintf("%02d", c.Version), signatureStatus(c.Status), c.Signer, detail})
+		}
+		fmt.Println(ui.Table([]string{"#", "Signature", "Signer", "Detail"}, rows, 3))
+		fmt.Println()
+
+		if len(trusted) == 0 && counts[project.SignatureGood] > 0 {
+			fmt.Println(infoMsg(i18n.T("No trusted keys listed; any valid signature was accepted. Add keys to .vervids/trusted_keys to check who signed.")))
+		}
+		failed := counts[project.SignatureBad] + counts[project.SignatureUntrusted]
+		if strict {
+			failed += counts[project.SignatureMissing]
+		}
+		if failed > 0 {
+			exitWithError(nil, i18n.T("%d of %d version(s) failed signature verification", failed, len(checks)))
+		}
+		if counts[project.SignatureMissing] > 0 {
+			fmt.Println(warningMsg(i18n.T("%d version(s) unsigned", counts[project.SignatureMissing])))
+		}
+		fmt.Println(successMsg(i18n.T("%d signature(s) verified", counts[project.SignatureGood])))
+	},
+}
+
+// signatureStatus colors a signature check result
+func signatureStatus(status string) string {
+	switch status {
+	case project.SignatureGood:
+		return ui.SuccessStyle.Render(status)
+	case project.SignatureMissing:
+		return ui.InfoStyle.Render(status)
+	case project.SignatureUntrusted:
+		return ui.WarningStyle.Render(status)
+	}
+	return ui.ErrorStyle.Render(status)
+}
+
+// signatureSummary describes a version's signature for show and commit output
+func signatureSummary(v *project.Version) string {
+	if v.Signature == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s key)", v.Signature.Signer, v.Signature.Format)
+}
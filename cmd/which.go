@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/settings"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which",
+	Short: "Show which project the next command would act on, and why",
+	Long: `Explain how the current project is resolved: the context file, the project config it
+points at, the project's ID and storage directory, and the Docker daemon, container
+and volume the next command would use. Nothing is changed and Docker isn't contacted.
+
+Commands that work on a project use the one saved in ~/.vervids/current_project.json,
+which 'vervids init', 'vervids switch' and picking a project at a prompt set. Without
+a usable context the next command asks which project to use. The project in the
+current directory only matters to commands that create or fill one there, such as
+'vervids fetch' and 'vervids bundle apply'.
+
+Example:
+  vervids which`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		contextPath := storage.GetContextPath()
+		fmt.Printf("Context file:  %s\n", contextPath)
+
+		var proj *project.Project
+		var configPath string
+		context, err := storage.LoadContext()
+		switch {
+		case os.IsNotExist(err):
+			fmt.Println("Source:        none (no project selected yet)")
+		case err != nil:
+			fmt.Printf("Source:        none (context file unreadable: %v)\n", err)
+		default:
+			configPath = context.ConfigPath
+			if proj, err = project.LoadFromPath(configPath); err != nil {
+				fmt.Printf("Source:        none (saved context points at %s, which can't be loaded: %v)\n", configPath, err)
+			} else {
+				fmt.Println("Source:        saved context (set by init, switch or a project prompt)")
+			}
+		}
+
+		if proj != nil {
+			fmt.Printf("Config:        %s\n", configPath)
+			fmt.Printf("Project:       %s\n", proj.ProjectName)
+			fmt.Printf("Project ID:    %s\n", filepath.Base(proj.StorageDir()))
+			fmt.Printf("Directory:     %s\n", filepath.Dir(filepath.Dir(configPath)))
+			fmt.Printf("Versions:      %d\n", len(proj.Versions))
+		}
+
+		// The docker CLI picks the daemon from these, in this order
+		daemon := "local daemon (docker CLI default context)"
+		if host := os.Getenv("DOCKER_HOST"); host != "" {
+			daemon = fmt.Sprintf("%s (DOCKER_HOST)", host)
+		} else if dockerContext := os.Getenv("DOCKER_CONTEXT"); dockerContext != "" {
+			daemon = fmt.Sprintf("docker context %s (DOCKER_CONTEXT)", dockerContext)
+		}
+		fmt.Printf("Backend:       docker, %s\n", daemon)
+		fmt.Printf("Container:     %s\n", docker.ContainerName)
+		fmt.Printf("Volume:        %s mounted at %s\n", docker.VolumeName, docker.StoragePath)
+		if proj != nil {
+			fmt.Printf("Storage:       %s\n", proj.StorageDir())
+		}
+		fmt.Printf("Settings:      %s\n", settings.GetSettingsPath())
+
+		fmt.Println()
+		if proj == nil {
+			fmt.Println(infoMsg(i18n.T("The next command that needs a project will ask which one to use; pick one now with 'vervids switch'")))
+		}
+		if storage.IsInitialized() {
+			local, err := filepath.Abs(storage.GetConfigPath())
+			if err == nil && local != configPath {
+				name := filepath.Base(filepath.Dir(filepath.Dir(local)))
+				if here, err := project.Load(); err == nil {
+					name = here.ProjectName
+				}
+				fmt.Println(warningMsg(i18n.T("This directory has project %s, but commands act on the saved context; run 'vervids switch %s' to use it", name, name)))
+			}
+		}
+	},
+}
@@ -0,0 +1,178 @@
+package aepx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AppVersion is the After Effects release that saved a project
+type AppVersion struct {
+	Release  string `json:"release"`            // As Adobe names it, e.g. "2024" or "CC 2019"
+	Major    int    `json:"major"`              // Version number, e.g. 24 for 2024 and 16 for CC 2019
+	Minor    int    `json:"minor,omitempty"`    // Only set for releases like CC 2015 (13.5)
+	Platform string `json:"platform,omitempty"` // "Windows" or "Macintosh", when recorded
+}
+
+// String names the release, e.g. "After Effects 2024"
+func (v AppVersion) String() string {
+	return "After Effects " + v.Release
+}
+
+// NewerThan reports whether v is a later release than other
+func (v AppVersion) NewerThan(other AppVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor > other.Minor
+}
+
+// releaseNumbers maps release names from before the year and version number matched
+var releaseNumbers = map[string][2]int{
+	"CS3":       {8, 0},
+	"CS4":       {9, 0},
+	"CS5":       {10, 0},
+	"CS5.5":     {10, 5},
+	"CS6":       {11, 0},
+	"CC":        {12, 0},
+	"CC 2014":   {13, 0},
+	"CC 2015":   {13, 5},
+	"CC 2015.3": {13, 8},
+	"CC 2017":   {14, 0},
+	"CC 2018":   {15, 0},
+	"CC 2019":   {16, 0},
+	"2020":      {17, 0},
+	"2021":      {18, 0},
+}
+
+// appVersionPattern finds the application in XMP metadata, e.g. the softwareAgent
+// "Adobe After Effects 2024 (Windows)" of a save event
+var appVersionPattern = regexp.MustCompile(`Adobe After Effects ((?:CC )?20\d\d(?:\.\d)?|CS\d(?:\.5)?|CC)(?: \((\w+)\))?`)
+
+// ParseRelease reads a release name ("2024", "CC 2019", "CS6") or a version number
+// ("24.1", "16") as an AppVersion
+func ParseRelease(name string) (AppVersion, error) {
+	name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "Adobe After Effects"))
+	if n, ok := releaseNumbers[strings.ToUpper(name)]; ok {
+		return AppVersion{Release: strings.ToUpper(name), Major: n[0], Minor: n[1]}, nil
+	}
+	if year, err := strconv.Atoi(name); err == nil && year >= 2022 && year < 2100 {
+		return AppVersion{Release: name, Major: year - 2000}, nil
+	}
+	major, minor, _ := strings.Cut(name, ".")
+	m, err := strconv.Atoi(major)
+	if err != nil || m < 8 || m > 99 {
+		return AppVersion{}, fmt.Errorf("unknown After Effects release '%s' (expected e.g. 2024, CC 2019 or 24.1)", name)
+	}
+	v := AppVersion{Major: m}
+	if minor != "" {
+		if v.Minor, err = strconv.Atoi(minor); err != nil {
+			return AppVersion{}, fmt.Errorf("unknown After Effects release '%s' (expected e.g. 2024, CC 2019 or 24.1)", name)
+		}
+	}
+	v.Release = releaseName(v.Major, v.Minor)
+	return v, nil
+}
+
+// releaseName gives the release name for a version number (the latest release at or
+// before it, so 13.6 is CC 2015), or the number itself
+func releaseName(major int, minor int) string {
+	if major >= 22 {
+		return strconv.Itoa(2000 + major)
+	}
+	name, best := "", -1
+	for n, number := range releaseNumbers {
+		if number[0] == major && number[1] <= minor && number[1] > best {
+			name, best = n, number[1]
+		}
+	}
+	if name == "" {
+		return strconv.Itoa(major)
+	}
+	return name
+}
+
+// ExtractAppVersion reads which After Effects release last saved an .aepx file, or
+// nil when the file doesn't record it
+func ExtractAppVersion(path string) (*AppVersion, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	return ParseAppVersion(file)
+}
+
+// ParseAppVersion reads the After Effects release from .aepx XML. The project's XMP
+// metadata names the creator tool and one softwareAgent per save in its history, in
+// order, so the last one mentioned is the release that wrote the file.
+func ParseAppVersion(r io.Reader) (*AppVersion, error) {
+	decoder := xml.NewDecoder(r)
+	var found *AppVersion
+	check := func(text string) {
+		if !strings.Contains(text, "After Effects") {
+			return
+		}
+		for _, m := range appVersionPattern.FindAllStringSubmatch(text, -1) {
+			if v, err := ParseRelease(m[1]); err == nil {
+				v.Platform = m[2]
+				found = &v
+			}
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			for _, attr := range t.Attr {
+				check(attr.Value)
+			}
+		case xml.CharData:
+			check(string(t))
+		}
+	}
+	return found, nil
+}
+
+// InstalledAppVersions lists the After Effects releases installed in the usual
+// application folders, newest first. Always empty on platforms AE doesn't run on.
+func InstalledAppVersions() []AppVersion {
+	var pattern string
+	switch runtime.GOOS {
+	case "darwin":
+		pattern = "/Applications/Adobe After Effects *"
+	case "windows":
+		programFiles := os.Getenv("ProgramFiles")
+		if programFiles == "" {
+			programFiles = `C:\Program Files`
+		}
+		pattern = filepath.Join(programFiles, "Adobe", "Adobe After Effects *")
+	default:
+		return nil
+	}
+
+	matches, _ := filepath.Glob(pattern)
+	installed := []AppVersion{}
+	for _, dir := range matches {
+		// Betas ("Adobe After Effects (Beta)") don't name a release
+		if v, err := ParseRelease(filepath.Base(dir)); err == nil {
+			installed = append(installed, v)
+		}
+	}
+	sort.SliceStable(installed, func(i, j int) bool { return installed[i].NewerThan(installed[j]) })
+	return installed
+}
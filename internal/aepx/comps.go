@@ -0,0 +1,447 @@
+package aepx
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// itemTypeComp is the idta item type of a composition
+const itemTypeComp = 4
+
+// Comp is a composition in the project
+type Comp struct {
+	ID        uint32  `json:"id"`
+	Name      string  `json:"name"`
+	Width     int     `json:"width,omitempty"`
+	Height    int     `json:"height,omitempty"`
+	Duration  float64 `json:"duration,omitempty"`   // Seconds
+	FrameRate float64 `json:"frame_rate,omitempty"` // Frames per second
+	Layers    []Layer `json:"layers"`
+}
+
+// Layer is a layer in a composition. Hash covers the layer's whole XML, so any edit
+// to its properties, keyframes or effects changes it.
+type Layer struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+}
+
+// Resolution formats the comp size as WIDTHxHEIGHT
+func (c *Comp) Resolution() string {
+	if c.Width == 0 || c.Height == 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%dx%d", c.Width, c.Height)
+}
+
+// CompSummary is what a version records about a composition: its settings and how
+// many layers it has, without the layers themselves
+type CompSummary struct {
+	Name      string  `json:"name"`
+	Width     int     `json:"width,omitempty"`
+	Height    int     `json:"height,omitempty"`
+	Duration  float64 `json:"duration,omitempty"`   // Seconds
+	FrameRate float64 `json:"frame_rate,omitempty"` // Frames per second
+	Layers    int     `json:"layers"`
+}
+
+// Summarize reduces comps to their summaries, in the same order
+func Summarize(comps []Comp) []CompSummary {
+	summaries := make([]CompSummary, len(comps))
+	for i, c := range comps {
+		summaries[i] = CompSummary{Name: c.Name, Width: c.Width, Height: c.Height, Duration: c.Duration, FrameRate: c.FrameRate, Layers: len(c.Layers)}
+	}
+	return summaries
+}
+
+// String formats the comp's settings as "1920x1080, 0:10.000, 29.97 fps, 4 layer(s)"
+func (c CompSummary) String() string {
+	size := (&Comp{Width: c.Width, Height: c.Height}).Resolution()
+	rate := "?"
+	if c.FrameRate > 0 {
+		rate = fmt.Sprintf("%.4g", c.FrameRate)
+	}
+	return fmt.Sprintf("%s, %s, %s fps, %d layer(s)", size, formatSeconds(c.Duration), rate, c.Layers)
+}
+
+// itemState collects an <Item> while it is being parsed
+type itemState struct {
+	depth    int
+	itemType uint16
+	comp     Comp
+	named    bool
+	hasCdta  bool
+}
+
+// layerState collects a <Layr> while it is being parsed
+type layerState struct {
+	depth int
+	name  string
+	named bool
+	hash  io.Writer
+	sum   func() []byte
+}
+
+// ExtractComps reads every composition and its layers from an .aepx file
+func ExtractComps(path string) ([]Comp, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	return ParseComps(file)
+}
+
+// ParseComps reads every composition and its layers from .aepx XML. Comp IDs and types
+// come from the <idta> item header; size, duration and frame rate from <cdta>.
+func ParseComps(r io.Reader) ([]Comp, error) {
+	decoder := xml.NewDecoder(r)
+	comps := []Comp{}
+	items := []*itemState{}
+	var layer *layerState
+	depth := 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+		token = xml.CopyToken(token)
+
+		if layer != nil {
+			hashToken(layer.hash, token)
+		}
+
+		switch se := token.(type) {
+		case xml.StartElement:
+			depth++
+			var item *itemState
+			if len(items) > 0 {
+				item = items[len(items)-1]
+			}
+
+			switch se.Name.Local {
+			case "Item":
+				items = append(items, &itemState{depth: depth})
+			case "idta":
+				if item != nil && item.depth == depth-1 {
+					if data := bdata(se.Attr); len(data) >= 20 {
+						item.itemType = binary.BigEndian.Uint16(data[0:2])
+						item.comp.ID = binary.BigEndian.Uint32(data[16:20])
+					}
+				}
+			case "cdta":
+				if item != nil && item.depth == depth-1 {
+					item.hasCdta = true
+					readCompData(&item.comp, bdata(se.Attr))
+				}
+			case "Layr":
+				if item != nil && layer == nil {
+					h := sha256.New()
+					layer = &layerState{depth: depth, hash: h, sum: func() []byte { return h.Sum(nil) }}
+				}
+			case "string":
+				if layer != nil && layer.depth == depth-1 && !layer.named {
+					text, err := readText(decoder, &se, layer.hash)
+					if err != nil {
+						return nil, err
+					}
+					layer.name, layer.named = text, true
+					depth--
+				} else if layer == nil && item != nil && item.depth == depth-1 && !item.named {
+					text, err := readText(decoder, &se, nil)
+					if err != nil {
+						return nil, err
+					}
+					item.comp.Name, item.named = text, true
+					depth--
+				}
+			}
+
+		case xml.EndElement:
+			switch {
+			case layer != nil && se.Name.Local == "Layr" && depth == layer.depth:
+				item := items[len(items)-1]
+				item.comp.Layers = append(item.comp.Layers, Layer{Name: layer.name, Hash: hex.EncodeToString(layer.sum())})
+				layer = nil
+			case len(items) > 0 && se.Name.Local == "Item" && depth == items[len(items)-1].depth:
+				item := items[len(items)-1]
+				items = items[:len(items)-1]
+				if item.itemType == itemTypeComp || item.hasCdta {
+					if item.comp.Layers == nil {
+						item.comp.Layers = []Layer{}
+					}
+					comps = append(comps, item.comp)
+				}
+			}
+			depth--
+		}
+	}
+
+	return comps, nil
+}
+
+// readCompData reads size, duration and frame rate from a comp's <cdta> block
+func readCompData(comp *Comp, data []byte) {
+	if len(data) < 144 {
+		return
+	}
+	if divisor := binary.BigEndian.Uint32(data[5:9]); divisor != 0 {
+		comp.FrameRate = float64(binary.BigEndian.Uint32(data[9:13])) / float64(divisor)
+	}
+	if divisor := binary.BigEndian.Uint32(data[49:53]); divisor != 0 {
+		comp.Duration = float64(binary.BigEndian.Uint32(data[45:49])) / float64(divisor)
+	}
+	comp.Width = int(binary.BigEndian.Uint16(data[140:142]))
+	comp.Height = int(binary.BigEndian.Uint16(data[142:144]))
+}
+
+// bdata decodes the hex-encoded bdata attribute of a binary chunk element
+func bdata(attrs []xml.Attr) []byte {
+	for _, attr := range attrs {
+		if attr.Name.Local == "bdata" {
+			data, err := hex.DecodeString(attr.Value)
+			if err != nil {
+				return nil
+			}
+			return data
+		}
+	}
+	return nil
+}
+
+// readText reads the text of an element, feeding its tokens into h if it is non-nil
+func readText(decoder *xml.Decoder, se *xml.StartElement, h io.Writer) (string, error) {
+	var text string
+	if err := decoder.DecodeElement(&text, se); err != nil {
+		return "", fmt.Errorf("failed to parse XML: %w", err)
+	}
+	if h != nil {
+		io.WriteString(h, text)
+	}
+	return text, nil
+}
+
+// hashToken writes a token's content into h
+func hashToken(h io.Writer, token xml.Token) {
+	switch t := token.(type) {
+	case xml.StartElement:
+		io.WriteString(h, "<"+t.Name.Local)
+		for _, attr := range t.Attr {
+			io.WriteString(h, " "+attr.Name.Local+"="+attr.Value)
+		}
+		io.WriteString(h, ">")
+	case xml.EndElement:
+		io.WriteString(h, "</"+t.Name.Local+">")
+	case xml.CharData:
+		h.Write(t)
+	}
+}
+
+// CompChange describes how a composition differs between two versions
+type CompChange struct {
+	Name    string
+	OldName string   // Set when the comp was renamed
+	Status  string   // "added", "removed", "renamed" or "modified"
+	Details []string // Human-readable property changes
+}
+
+// DiffComps compares the compositions of two versions. Comps are matched by item ID,
+// so a comp whose name changed is reported as renamed rather than removed and added;
+// comps without an ID fall back to matching by name.
+func DiffComps(old, new []Comp) []CompChange {
+	matchedOld := make(map[int]bool)
+	changes := []CompChange{}
+
+	for _, n := range new {
+		idx := matchComp(old, n, matchedOld)
+		if idx < 0 {
+			changes = append(changes, CompChange{Name: n.Name, Status: "added",
+				Details: []string{fmt.Sprintf("%s, %s, %d layer(s)", n.Resolution(), formatSeconds(n.Duration), len(n.Layers))}})
+			continue
+		}
+		matchedOld[idx] = true
+		o := old[idx]
+
+		details := compDetails(o, n)
+		switch {
+		case o.Name != n.Name:
+			changes = append(changes, CompChange{Name: n.Name, OldName: o.Name, Status: "renamed", Details: details})
+		case len(details) > 0:
+			changes = append(changes, CompChange{Name: n.Name, Status: "modified", Details: details})
+		}
+	}
+
+	for i, o := range old {
+		if !matchedOld[i] {
+			changes = append(changes, CompChange{Name: o.Name, Status: "removed"})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return strings.ToLower(changes[i].Name) < strings.ToLower(changes[j].Name) })
+	return changes
+}
+
+// matchComp finds the unmatched old comp corresponding to c, by ID then by name
+func matchComp(old []Comp, c Comp, matched map[int]bool) int {
+	if c.ID != 0 {
+		for i, o := range old {
+			if !matched[i] && o.ID == c.ID {
+				return i
+			}
+		}
+	}
+	for i, o := range old {
+		if !matched[i] && o.Name == c.Name && (o.ID == 0 || c.ID == 0) {
+			return i
+		}
+	}
+	return -1
+}
+
+// compDetails lists the property changes between two versions of a comp
+func compDetails(o, n Comp) []string {
+	details := []string{}
+	if len(o.Layers) != len(n.Layers) {
+		details = append(details, fmt.Sprintf("layers: %d → %d", len(o.Layers), len(n.Layers)))
+	}
+	if o.Width != n.Width || o.Height != n.Height {
+		details = append(details, fmt.Sprintf("resolution: %s → %s", o.Resolution(), n.Resolution()))
+	}
+	if o.Duration != n.Duration {
+		details = append(details, fmt.Sprintf("duration: %s → %s", formatSeconds(o.Duration), formatSeconds(n.Duration)))
+	}
+	if o.FrameRate != n.FrameRate {
+		details = append(details, fmt.Sprintf("frame rate: %.3g → %.3g fps", o.FrameRate, n.FrameRate))
+	}
+	return details
+}
+
+// formatSeconds formats a duration in seconds as m:ss.ms
+func formatSeconds(seconds float64) string {
+	if seconds <= 0 {
+		return "?"
+	}
+	minutes := int(seconds) / 60
+	return fmt.Sprintf("%d:%06.3f", minutes, seconds-float64(minutes*60))
+}
+
+// LayerChanges counts the layers added, removed and modified in one composition
+type LayerChanges struct {
+	Comp     string `json:"comp"`
+	Added    int    `json:"added"`
+	Removed  int    `json:"removed"`
+	Modified int    `json:"modified"`
+}
+
+// Total returns the number of changed layers
+func (l LayerChanges) Total() int {
+	return l.Added + l.Removed + l.Modified
+}
+
+// DiffLayers counts layer changes per composition between two versions. Layers are
+// matched by name, in order for repeated names; a matched layer whose XML differs
+// counts as modified. Comps without changes are omitted.
+func DiffLayers(old, new []Comp) []LayerChanges {
+	matchedOld := make(map[int]bool)
+	result := []LayerChanges{}
+
+	for _, n := range new {
+		changes := LayerChanges{Comp: n.Name}
+		if idx := matchComp(old, n, matchedOld); idx >= 0 {
+			matchedOld[idx] = true
+			changes.Added, changes.Removed, changes.Modified = compareLayers(old[idx].Layers, n.Layers)
+		} else {
+			changes.Added = len(n.Layers)
+		}
+		if changes.Total() > 0 {
+			result = append(result, changes)
+		}
+	}
+	for i, o := range old {
+		if !matchedOld[i] && len(o.Layers) > 0 {
+			result = append(result, LayerChanges{Comp: o.Name, Removed: len(o.Layers)})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return strings.ToLower(result[i].Comp) < strings.ToLower(result[j].Comp) })
+	return result
+}
+
+// compareLayers counts added, removed and modified layers between two layer lists
+func compareLayers(old, new []Layer) (added, removed, modified int) {
+	a, r, m := matchLayers(old, new)
+	return len(a), len(r), len(m)
+}
+
+// matchLayers returns the names of the layers added, removed and modified between two
+// layer lists, matching layers by name and, for repeated names, in order
+func matchLayers(old, new []Layer) (added, removed, modified []string) {
+	oldByName := make(map[string][]Layer)
+	for _, l := range old {
+		oldByName[l.Name] = append(oldByName[l.Name], l)
+	}
+	for _, l := range new {
+		candidates := oldByName[l.Name]
+		if len(candidates) == 0 {
+			added = append(added, l.Name)
+			continue
+		}
+		if candidates[0].Hash != l.Hash {
+			modified = append(modified, l.Name)
+		}
+		oldByName[l.Name] = candidates[1:]
+	}
+	for _, l := range old {
+		if remaining := oldByName[l.Name]; len(remaining) > 0 {
+			removed = append(removed, l.Name)
+			oldByName[l.Name] = remaining[1:]
+		}
+	}
+	return added, removed, modified
+}
+
+// LayerChange is one layer added, removed or modified in a composition
+type LayerChange struct {
+	Comp   string
+	Layer  string
+	Status string // "added", "removed" or "modified"
+}
+
+// DiffLayerDetails lists the layers added, removed and modified in each composition
+// present in both versions, matched like DiffLayers. Comps added or removed as a whole
+// are left out; DiffComps reports those.
+func DiffLayerDetails(old, new []Comp) []LayerChange {
+	matchedOld := make(map[int]bool)
+	result := []LayerChange{}
+	for _, n := range new {
+		idx := matchComp(old, n, matchedOld)
+		if idx < 0 {
+			continue
+		}
+		matchedOld[idx] = true
+		added, removed, modified := matchLayers(old[idx].Layers, n.Layers)
+		for _, name := range added {
+			result = append(result, LayerChange{Comp: n.Name, Layer: name, Status: "added"})
+		}
+		for _, name := range removed {
+			result = append(result, LayerChange{Comp: n.Name, Layer: name, Status: "removed"})
+		}
+		for _, name := range modified {
+			result = append(result, LayerChange{Comp: n.Name, Layer: name, Status: "modified"})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return strings.ToLower(result[i].Comp) < strings.ToLower(result[j].Comp) })
+	return result
+}
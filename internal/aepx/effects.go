@@ -0,0 +1,178 @@
+package aepx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+)
+
+// effectParade is the match name of the property group holding a layer's effects
+const effectParade = "ADBE Effect Parade"
+
+// Effect is one effect applied to a layer. Hash covers the effect's XML, so any change
+// to its settings, keyframes or expressions changes it.
+type Effect struct {
+	Comp      string `json:"comp"`
+	Layer     string `json:"layer"`
+	Name      string `json:"name"`       // Name given in After Effects, or the effect's display name
+	MatchName string `json:"match_name"` // e.g. "ADBE Gaussian Blur 2"
+	Hash      string `json:"hash"`
+}
+
+// Key identifies the effect within the project
+func (e Effect) Key() string {
+	return e.Comp + " › " + e.Layer + " › " + e.Name
+}
+
+// ExtractEffects reads every layer effect from an .aepx file
+func ExtractEffects(path string) ([]Effect, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	return ParseEffects(file)
+}
+
+// ParseEffects reads every layer effect from .aepx XML. A layer's effects are the
+// property groups (<tdgp>) inside its "ADBE Effect Parade" group, each named by the
+// <tdmn> before it; an effect renamed in After Effects has its name in a <tdsn>.
+func ParseEffects(r io.Reader) ([]Effect, error) {
+	decoder := xml.NewDecoder(r)
+	stack := []*frame{}
+	effects := []Effect{}
+	var effect *Effect
+	var effectDepth int // Stack index of the open effect's <tdgp>
+	var h hash.Hash
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+		if effect != nil {
+			hashToken(h, token)
+		}
+
+		switch se := token.(type) {
+		case xml.StartElement:
+			f := &frame{name: se.Name.Local}
+			var parent *frame
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+				f.matchName = parent.lastTdmn
+			}
+
+			switch f.name {
+			case "tdmn":
+				if parent != nil {
+					parent.lastTdmn = decodeMatchName(se.Attr)
+				}
+			case "tdgp":
+				if effect == nil && parent != nil && parent.name == "tdgp" && parent.matchName == effectParade && f.matchName != "" {
+					comp, layer, _ := enclosingLayer(stack)
+					effect = &Effect{Comp: comp, Layer: layer, Name: displayMatchName(f.matchName), MatchName: f.matchName}
+					effectDepth = len(stack)
+					h = sha256.New()
+					hashToken(h, token)
+				}
+			case "string":
+				if parent == nil || parent.named {
+					break
+				}
+				// The first <string> inside an item or layer is its name, and inside an
+				// effect's <tdsn> the name it was given
+				isEffectName := effect != nil && parent.name == "tdsn" && len(stack) == effectDepth+2
+				if parent.name == "Item" || parent.name == "Layr" || isEffectName {
+					var text string
+					if err := decoder.DecodeElement(&text, &se); err != nil {
+						return nil, fmt.Errorf("failed to parse XML: %w", err)
+					}
+					if effect != nil {
+						io.WriteString(h, text)
+					}
+					parent.named = true
+					switch {
+					case isEffectName:
+						if text != "" {
+							effect.Name = text
+						}
+					case parent.name == "Item":
+						parent.itemName = text
+					case parent.name == "Layr":
+						parent.layerName = text
+					}
+					continue // DecodeElement consumed the end tag
+				}
+			}
+			stack = append(stack, f)
+
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if effect != nil && len(stack) == effectDepth {
+				effect.Hash = hex.EncodeToString(h.Sum(nil))
+				effects = append(effects, *effect)
+				effect = nil
+			}
+		}
+	}
+
+	return effects, nil
+}
+
+// EffectChange is one effect added, removed or changed between two versions
+type EffectChange struct {
+	Key    string
+	Status string // "added", "removed" or "modified"
+}
+
+// DiffEffects compares the effects of two versions, sorted by key. Repeated effects
+// on a layer are told apart by their order in the effect stack.
+func DiffEffects(old, new []Effect) []EffectChange {
+	oldByKey := indexEffects(old)
+	newByKey := indexEffects(new)
+
+	changes := []EffectChange{}
+	for key, hash := range oldByKey {
+		newHash, ok := newByKey[key]
+		switch {
+		case !ok:
+			changes = append(changes, EffectChange{Key: key, Status: "removed"})
+		case newHash != hash:
+			changes = append(changes, EffectChange{Key: key, Status: "modified"})
+		}
+	}
+	for key := range newByKey {
+		if _, ok := oldByKey[key]; !ok {
+			changes = append(changes, EffectChange{Key: key, Status: "added"})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// indexEffects maps each effect's key to its hash, numbering repeated keys
+func indexEffects(effects []Effect) map[string]string {
+	byKey := make(map[string]string)
+	seen := make(map[string]int)
+	for _, e := range effects {
+		key := e.Key()
+		seen[key]++
+		if seen[key] > 1 {
+			key = fmt.Sprintf("%s (%d)", key, seen[key])
+		}
+		byKey[key] = e.Hash
+	}
+	return byKey
+}
@@ -0,0 +1,221 @@
+package aepx
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Expression is the expression text on one property of one layer
+type Expression struct {
+	Comp     string `json:"comp"`
+	Layer    string `json:"layer"`
+	Property string `json:"property"` // Match-name path, e.g. "Transform/Position"
+	Text     string `json:"text"`
+}
+
+// Key identifies the property an expression belongs to
+func (e Expression) Key() string {
+	return e.Comp + " › " + e.Layer + " › " + e.Property
+}
+
+// frame is an open XML element while walking the project
+type frame struct {
+	name      string // Element local name
+	matchName string // Property match name given by the preceding <tdmn> sibling
+	lastTdmn  string // Most recent <tdmn> seen among this element's children
+	itemName  string // Name of an <Item> (project item, e.g. a composition)
+	layerName string // Name of a <Layr>
+	named     bool   // Whether the element's name string has been read
+}
+
+// ExtractExpressions reads every property expression from an .aepx file
+func ExtractExpressions(path string) ([]Expression, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	return ParseExpressions(file)
+}
+
+// ParseExpressions reads every property expression from .aepx XML. In an .aepx,
+// properties are named by a <tdmn> element holding the hex-encoded match name, and a
+// property with an expression (<tdbs>) carries the expression in a <string> child.
+func ParseExpressions(r io.Reader) ([]Expression, error) {
+	decoder := xml.NewDecoder(r)
+	stack := []*frame{}
+	expressions := []Expression{}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		switch se := token.(type) {
+		case xml.StartElement:
+			f := &frame{name: se.Name.Local}
+			var parent *frame
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+				f.matchName = parent.lastTdmn
+			}
+
+			switch f.name {
+			case "tdmn":
+				if parent != nil {
+					parent.lastTdmn = decodeMatchName(se.Attr)
+				}
+			case "string":
+				if parent == nil || parent.named {
+					break
+				}
+				// The first <string> inside an item or layer is its name
+				if parent.name == "Item" || parent.name == "Layr" || parent.name == "tdbs" {
+					var text string
+					if err := decoder.DecodeElement(&text, &se); err != nil {
+						return nil, fmt.Errorf("failed to parse XML: %w", err)
+					}
+					parent.named = true
+					switch parent.name {
+					case "Item":
+						parent.itemName = text
+					case "Layr":
+						parent.layerName = text
+					case "tdbs":
+						if strings.TrimSpace(text) != "" {
+							expressions = append(expressions, newExpression(stack, text))
+						}
+					}
+					continue // DecodeElement consumed the end tag
+				}
+			}
+			stack = append(stack, f)
+
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	return expressions, nil
+}
+
+// newExpression builds an Expression from the open elements enclosing a <tdbs>
+func newExpression(stack []*frame, text string) Expression {
+	e := Expression{Text: text}
+	var layerDepth int
+	e.Comp, e.Layer, layerDepth = enclosingLayer(stack)
+
+	props := []string{}
+	for i := layerDepth + 1; i < len(stack); i++ {
+		if (stack[i].name == "tdgp" || stack[i].name == "tdbs") && stack[i].matchName != "" {
+			props = append(props, displayMatchName(stack[i].matchName))
+		}
+	}
+	e.Property = strings.Join(props, "/")
+	return e
+}
+
+// enclosingLayer returns the comp and layer names of the innermost open <Layr>, and
+// its index in the stack (-1 when there is none)
+func enclosingLayer(stack []*frame) (comp string, layer string, layerDepth int) {
+	layerDepth = -1
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name == "Layr" && layerDepth < 0 {
+			layerDepth = i
+			layer = stack[i].layerName
+		}
+		if stack[i].name == "Item" && stack[i].itemName != "" && layerDepth >= 0 {
+			comp = stack[i].itemName
+			break
+		}
+	}
+	if comp == "" {
+		comp = "(unknown comp)"
+	}
+	if layer == "" {
+		layer = "(unnamed layer)"
+	}
+	return comp, layer, layerDepth
+}
+
+// decodeMatchName reads the match name from a <tdmn bdata="..."> element. The name is
+// hex-encoded ASCII padded with zero bytes.
+func decodeMatchName(attrs []xml.Attr) string {
+	for _, attr := range attrs {
+		if attr.Name.Local != "bdata" {
+			continue
+		}
+		data, err := hex.DecodeString(attr.Value)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimRight(string(data), "\x00")
+	}
+	return ""
+}
+
+// displayMatchName shortens Adobe's built-in match names ("ADBE Position" -> "Position")
+func displayMatchName(name string) string {
+	name = strings.TrimPrefix(name, "ADBE ")
+	return strings.TrimSuffix(name, " Group")
+}
+
+// ExpressionChange is one property whose expression differs between two versions
+type ExpressionChange struct {
+	Key    string
+	Status string // "added", "removed" or "modified"
+	Old    string
+	New    string
+}
+
+// DiffExpressions compares the expressions of two versions, sorted by property key.
+// Layers sharing a name are told apart by their order in the project.
+func DiffExpressions(old, new []Expression) []ExpressionChange {
+	oldByKey := indexExpressions(old)
+	newByKey := indexExpressions(new)
+
+	changes := []ExpressionChange{}
+	for key, text := range oldByKey {
+		newText, ok := newByKey[key]
+		switch {
+		case !ok:
+			changes = append(changes, ExpressionChange{Key: key, Status: "removed", Old: text})
+		case newText != text:
+			changes = append(changes, ExpressionChange{Key: key, Status: "modified", Old: text, New: newText})
+		}
+	}
+	for key, text := range newByKey {
+		if _, ok := oldByKey[key]; !ok {
+			changes = append(changes, ExpressionChange{Key: key, Status: "added", New: text})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// indexExpressions maps each expression's key to its text, numbering repeated keys
+func indexExpressions(expressions []Expression) map[string]string {
+	byKey := make(map[string]string)
+	seen := make(map[string]int)
+	for _, e := range expressions {
+		key := e.Key()
+		seen[key]++
+		if seen[key] > 1 {
+			key = fmt.Sprintf("%s (%d)", key, seen[key])
+		}
+		byKey[key] = strings.TrimSpace(e.Text)
+	}
+	return byKey
+}
@@ -0,0 +1,144 @@
+package aepx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Interpretation is how a footage item interprets its file: each setting chunk of the
+// item's <Pin> besides the file reference, by name, with a hash of its data. The
+// chunks are kept opaque; a changed hash means the interpretation changed even when
+// the file itself didn't.
+type Interpretation map[string]string
+
+// interpretationLabels describe the footage settings chunks After Effects writes
+var interpretationLabels = map[string]string{
+	"sspc": "frame rate, alpha or field interpretation",
+	"opti": "source options",
+	"CLRS": "color profile",
+	"linl": "linear light",
+}
+
+// interpretationState collects a footage item's <Pin> while it is being parsed
+type interpretationState struct {
+	depth     int
+	reference string
+	chunks    Interpretation
+	chunk     string // Chunk being hashed, a direct child of <Pin>
+	hash      io.Writer
+	sum       func() []byte
+}
+
+// ExtractInterpretations reads the interpretation of every footage file in an .aepx
+// file, keyed by the file's path as written in the project
+func ExtractInterpretations(path string) (map[string]Interpretation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	return ParseInterpretations(file)
+}
+
+// ParseInterpretations reads footage interpretations from .aepx XML. A footage item's
+// <Pin> holds its file reference (a <fileReference> with a fullpath) next to binary
+// chunks such as <sspc>, which carries the frame rate and alpha settings.
+func ParseInterpretations(r io.Reader) (map[string]Interpretation, error) {
+	decoder := xml.NewDecoder(r)
+	result := make(map[string]Interpretation)
+	var pin *interpretationState
+	depth := 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+		if pin != nil && pin.hash != nil {
+			hashToken(pin.hash, token)
+		}
+
+		switch se := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case se.Name.Local == "Pin" && pin == nil:
+				pin = &interpretationState{depth: depth, chunks: Interpretation{}}
+			case pin != nil && se.Name.Local == "fileReference":
+				for _, attr := range se.Attr {
+					if attr.Name.Local == "fullpath" && strings.TrimSpace(attr.Value) != "" {
+						pin.reference = strings.TrimSpace(attr.Value)
+					}
+				}
+				pin.hash, pin.chunk = nil, "" // Part of the file reference, not a setting
+			case pin != nil && depth == pin.depth+1 && !strings.HasPrefix(se.Name.Local, "Als"):
+				h := sha256.New()
+				pin.chunk, pin.hash, pin.sum = se.Name.Local, h, func() []byte { return h.Sum(nil) }
+				hashToken(h, se)
+			}
+
+		case xml.EndElement:
+			switch {
+			case pin != nil && depth == pin.depth+1 && pin.hash != nil:
+				// A repeated chunk is recorded as name#2, name#3, ...
+				name := pin.chunk
+				for n := 2; pin.chunks[name] != ""; n++ {
+					name = fmt.Sprintf("%s#%d", pin.chunk, n)
+				}
+				pin.chunks[name] = hex.EncodeToString(pin.sum())[:16]
+				pin.hash, pin.chunk = nil, ""
+			case pin != nil && depth == pin.depth:
+				if pin.reference != "" && len(pin.chunks) > 0 {
+					result[pin.reference] = pin.chunks
+				}
+				pin = nil
+			}
+			depth--
+		}
+	}
+
+	return result, nil
+}
+
+// InterpretationChanges describes the settings that differ between two
+// interpretations of the same file, sorted. Empty when either is unknown.
+func InterpretationChanges(old, new Interpretation) []string {
+	if len(old) == 0 || len(new) == 0 {
+		return nil
+	}
+	labels := make(map[string]bool)
+	for name, hash := range new {
+		if old[name] != hash {
+			labels[interpretationLabel(name)] = true
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			labels[interpretationLabel(name)] = true
+		}
+	}
+	changed := []string{}
+	for label := range labels {
+		changed = append(changed, label)
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// interpretationLabel describes a settings chunk, falling back to its name
+func interpretationLabel(name string) string {
+	name, _, _ = strings.Cut(name, "#")
+	if label, ok := interpretationLabels[name]; ok {
+		return label
+	}
+	return name + " settings"
+}
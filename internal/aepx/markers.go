@@ -0,0 +1,129 @@
+package aepx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Marker is a marker comment on a composition or layer, or a comp's project-panel comment
+type Marker struct {
+	Comp  string `json:"comp"`
+	Layer string `json:"layer,omitempty"` // Empty for comp markers and notes
+	Kind  string `json:"kind"`            // "marker" or "note"
+	Text  string `json:"text"`
+}
+
+// markerFrame is an open XML element while walking the project for markers
+type markerFrame struct {
+	name    string
+	label   string // Name of an <Item> or <Layr>
+	named   bool
+	comment string // First comment string of a <Nmrd> marker
+}
+
+// ExtractMarkers reads every marker comment and comp note from an .aepx file
+func ExtractMarkers(path string) ([]Marker, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	return ParseMarkers(file)
+}
+
+// ParseMarkers reads every marker comment and comp note from .aepx XML. Each marker is
+// a <Nmrd> element whose first <string> is its comment; item comments are <cmta> elements.
+func ParseMarkers(r io.Reader) ([]Marker, error) {
+	decoder := xml.NewDecoder(r)
+	stack := []*markerFrame{}
+	markers := []Marker{}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		switch se := token.(type) {
+		case xml.StartElement:
+			var parent *markerFrame
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+
+			switch se.Name.Local {
+			case "string":
+				if parent == nil {
+					break
+				}
+				nameable := (parent.name == "Item" || parent.name == "Layr") && !parent.named
+				marker := enclosing(stack, "Nmrd")
+				if !nameable && (marker == nil || marker.comment != "") {
+					break
+				}
+				var text string
+				if err := decoder.DecodeElement(&text, &se); err != nil {
+					return nil, fmt.Errorf("failed to parse XML: %w", err)
+				}
+				if nameable {
+					parent.label, parent.named = text, true
+				} else {
+					marker.comment = strings.TrimSpace(text)
+				}
+				continue // DecodeElement consumed the end tag
+			case "cmta":
+				if parent == nil || parent.name != "Item" {
+					break
+				}
+				text := string(bdata(se.Attr))
+				if text == "" {
+					if err := decoder.DecodeElement(&text, &se); err != nil {
+						return nil, fmt.Errorf("failed to parse XML: %w", err)
+					}
+				} else if err := decoder.Skip(); err != nil {
+					return nil, fmt.Errorf("failed to parse XML: %w", err)
+				}
+				if text = strings.TrimSpace(strings.TrimRight(text, "\x00")); text != "" {
+					markers = append(markers, Marker{Comp: parent.label, Kind: "note", Text: text})
+				}
+				continue
+			}
+			stack = append(stack, &markerFrame{name: se.Name.Local})
+
+		case xml.EndElement:
+			if len(stack) == 0 {
+				break
+			}
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if f.name == "Nmrd" && f.comment != "" {
+				m := Marker{Kind: "marker", Text: f.comment}
+				if item := enclosing(stack, "Item"); item != nil {
+					m.Comp = item.label
+				}
+				if layer := enclosing(stack, "Layr"); layer != nil {
+					m.Layer = layer.label
+				}
+				markers = append(markers, m)
+			}
+		}
+	}
+
+	return markers, nil
+}
+
+// enclosing returns the innermost open element with the given name, or nil
+func enclosing(stack []*markerFrame, name string) *markerFrame {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name == name {
+			return stack[i]
+		}
+	}
+	return nil
+}
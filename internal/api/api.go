@@ -4,12 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/ajeebtech/vervideos/internal/aepx"
 	"github.com/ajeebtech/vervideos/internal/project"
-	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
 )
 
 // APIResponse is a standard API response wrapper
@@ -25,40 +24,63 @@ type ProjectListItem struct {
 	Name        string `json:"name"`
 	DockerPath  string `json:"docker_path"`
 	CommitCount int    `json:"commit_count,omitempty"`
+	ReadOnly    bool   `json:"read_only,omitempty"` // Commit, delete, prune and other writes are blocked
 }
 
 // CommitItem represents a single commit/version
 type CommitItem struct {
-	Number      int    `json:"number"`
-	Message     string `json:"message"`
-	Timestamp   string `json:"timestamp"`
-	Size        int64  `json:"size"`
-	AssetCount  int    `json:"asset_count"`
-	TotalSize   int64  `json:"total_size"`
+	Number       int                 `json:"number"`
+	Message      string              `json:"message"`
+	Timestamp    string              `json:"timestamp"`
+	Size         int64               `json:"size"`
+	AssetCount   int                 `json:"asset_count"`
+	TotalSize    int64               `json:"total_size"`
+	LayerChanges []aepx.LayerChanges `json:"layer_changes,omitempty"`
+	Labels       []string            `json:"labels,omitempty"`
+	Tags         []string            `json:"tags,omitempty"`
+	Meta         map[string]string   `json:"meta,omitempty"`
 }
 
 // ProjectCommitsResponse contains commits for a project
 type ProjectCommitsResponse struct {
-	ProjectID   string       `json:"project_id"`
-	ProjectName string       `json:"project_name"`
-	Commits     []CommitItem `json:"commits"`
+	ProjectID   string          `json:"project_id"`
+	ProjectName string          `json:"project_name"`
+	Notes       string          `json:"notes,omitempty"`     // The project's Markdown notes
+	Labels      []project.Label `json:"labels,omitempty"`    // Label definitions; commits refer to them by name
+	ReadOnly    bool            `json:"read_only,omitempty"` // Commit, delete, prune and other writes are blocked
+	Commits     []CommitItem    `json:"commits"`
 }
 
-// StartServer starts the HTTP API server on the specified port
-func StartServer(port int) error {
+// StartServer starts the HTTP API server on the specified port. With a validator,
+// every request but /health needs a bearer token it accepts.
+func StartServer(port int, auth TokenValidator) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/projects", handleListProjects)
 	mux.HandleFunc("/api/projects/", handleGetProjectCommits)
+	mux.HandleFunc("/api/events", handleEvents)
 	mux.HandleFunc("/health", handleHealth)
 	
-	http.Handle("/", mux)
+	var handler http.Handler = mux
+	if auth != nil {
+		handler = requireToken(mux, auth)
+	}
+	http.Handle("/", handler)
 
 	addr := fmt.Sprintf(":%d", port)
-	fmt.Printf("🌐 Starting vervids API server on http://localhost%s\n", addr)
-	fmt.Printf("📡 API endpoints:\n")
+	fmt.Printf(ui.Text("🌐 Starting vervids API server on http://localhost%s\n"), addr)
+	fmt.Print(ui.Text("📡 API endpoints:\n"))
 	fmt.Printf("   GET /api/projects - List all projects\n")
 	fmt.Printf("   GET /api/projects/{id}/commits - Get commits for a project\n")
+	fmt.Printf("   GET /api/projects/{id}/commits?label=<name> - Only commits with a label\n")
+	fmt.Printf("   GET /api/projects/{id}/commits?meta=<key>=<value> - Only commits with metadata (repeatable)\n")
+	fmt.Printf("   GET /api/events - Stream of changes made by other vervids commands (server-sent events)\n")
 	fmt.Printf("   GET /health - Health check\n")
+	if auth != nil {
+		fmt.Print(ui.Text("🔒 Requests need an API token: Authorization: Bearer <token>\n"))
+	}
+
+	// Keep cached projects in step with commits made from the terminal meanwhile
+	go watchProjects(watchInterval)
 
 	return http.ListenAndServe(addr, nil)
 }
@@ -83,7 +105,7 @@ func handleListProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	projects, err := project.GetAllProjects()
+	projects, err := cache.allProjects()
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get projects: %v", err))
 		return
@@ -100,10 +122,12 @@ func handleListProjects(w http.ResponseWriter, r *http.Request) {
 
 		// Try to get commit count by loading the project
 		commitCount := 0
+		readOnly := project.CheckVaultWritable() != nil
 		configPath := findProjectConfig(p.Name)
 		if configPath != "" {
-			if proj, err := project.LoadFromPath(configPath); err == nil {
+			if proj, err := cache.load(configPath); err == nil {
 				commitCount = len(proj.Versions)
+				readOnly = proj.CheckWritable() != nil
 			}
 		}
 
@@ -112,6 +136,7 @@ func handleListProjects(w http.ResponseWriter, r *http.Request) {
 			Name:        p.Name,
 			DockerPath:  p.DockerPath,
 			CommitCount: commitCount,
+			ReadOnly:    readOnly,
 		})
 	}
 
@@ -121,7 +146,8 @@ func handleListProjects(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleGetProjectCommits handles GET /api/projects/{id}/commits
+// handleGetProjectCommits handles GET /api/projects/{id}/commits, optionally filtered
+// with ?label= and ?meta=key=value
 func handleGetProjectCommits(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -148,7 +174,7 @@ func handleGetProjectCommits(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find the project by ID
-	projects, err := project.GetAllProjects()
+	projects, err := cache.allProjects()
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get projects: %v", err))
 		return
@@ -180,28 +206,41 @@ func handleGetProjectCommits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	proj, err := project.LoadFromPath(configPath)
+	proj, err := cache.load(configPath)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to load project: %v", err))
 		return
 	}
 
-	// Convert versions to commits
+	// Convert versions to commits, keeping those that pass the ?label= and ?meta= filters
+	meta, err := project.ParseMeta(r.URL.Query()["meta"], true)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := project.VersionFilter{Label: r.URL.Query().Get("label"), Meta: meta}
 	commits := make([]CommitItem, 0, len(proj.Versions))
-	for _, v := range proj.Versions {
+	for _, v := range filter.Filter(proj.Versions) {
 		commits = append(commits, CommitItem{
-			Number:     v.Number,
-			Message:    v.Message,
-			Timestamp:  v.Timestamp.Format("2006-01-02 15:04:05"),
-			Size:       v.Size,
-			AssetCount: v.AssetCount,
-			TotalSize:  v.TotalSize,
+			Number:       v.Number,
+			Message:      v.Message,
+			Timestamp:    v.Timestamp.Format("2006-01-02 15:04:05"),
+			Size:         v.Size,
+			AssetCount:   v.AssetCount,
+			TotalSize:    v.TotalSize,
+			LayerChanges: v.LayerChanges,
+			Labels:       v.Labels,
+			Tags:         v.Tags,
+			Meta:         v.Meta,
 		})
 	}
 
 	response := ProjectCommitsResponse{
 		ProjectID:   projectID,
 		ProjectName: proj.ProjectName,
+		Notes:       proj.Notes,
+		Labels:      proj.Labels,
+		ReadOnly:    proj.CheckWritable() != nil,
 		Commits:     commits,
 	}
 
@@ -213,39 +252,12 @@ func handleGetProjectCommits(w http.ResponseWriter, r *http.Request) {
 
 // findProjectConfig searches for a project's config.json file
 func findProjectConfig(projectName string) string {
-	home := os.Getenv("HOME")
-	searchDirs := []string{
-		".",
-		filepath.Join(home, "Documents"),
-		filepath.Join(home, "Desktop"),
-		filepath.Join(home, "Projects"),
-	}
-
-	for _, baseDir := range searchDirs {
-		if entries, err := os.ReadDir(baseDir); err == nil {
-			for _, entry := range entries {
-				if entry.IsDir() {
-					configPath := filepath.Join(baseDir, entry.Name(), storage.VerVidsDir, storage.ConfigFile)
-					if _, err := os.Stat(configPath); err == nil {
-						if proj, err := project.LoadFromPath(configPath); err == nil {
-							// Check if this project matches
-							if strings.Contains(strings.ToLower(proj.ProjectName), strings.ToLower(projectName)) ||
-								strings.Contains(strings.ToLower(projectName), strings.ToLower(proj.ProjectName)) {
-								return configPath
-							}
-						}
-					}
-				}
-			}
-		}
-		// Also check if .vervids exists directly in baseDir
-		configPath := filepath.Join(baseDir, storage.VerVidsDir, storage.ConfigFile)
-		if _, err := os.Stat(configPath); err == nil {
-			if proj, err := project.LoadFromPath(configPath); err == nil {
-				if strings.Contains(strings.ToLower(proj.ProjectName), strings.ToLower(projectName)) ||
-					strings.Contains(strings.ToLower(projectName), strings.ToLower(proj.ProjectName)) {
-					return configPath
-				}
+	for _, configPath := range configPaths() {
+		if proj, err := cache.load(configPath); err == nil {
+			// Check if this project matches
+			if strings.Contains(strings.ToLower(proj.ProjectName), strings.ToLower(projectName)) ||
+				strings.Contains(strings.ToLower(projectName), strings.ToLower(proj.ProjectName)) {
+				return configPath
 			}
 		}
 	}
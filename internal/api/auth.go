@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TokenValidator reports whether a bearer token may use the API
+type TokenValidator func(token string) bool
+
+// requireToken rejects requests without a valid "Authorization: Bearer <token>" header
+// with 401. /health stays open for liveness checks; /api/events also takes the token
+// as ?access_token=, since browsers' EventSource can't send headers.
+func requireToken(next http.Handler, valid TokenValidator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := bearerToken(r)
+		if token == "" && r.URL.Path == "/api/events" {
+			token = r.URL.Query().Get("access_token")
+		}
+		if !valid(token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="vervids"`)
+			writeError(w, http.StatusUnauthorized, "Missing or invalid API token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken returns the token in the request's Authorization header, or ""
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
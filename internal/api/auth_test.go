@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireToken(t *testing.T) {
+	const token = "vvt_secret"
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := requireToken(next, func(t string) bool { return t == token })
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		want   int
+	}{
+		{"health is open", "/health", "", http.StatusOK},
+		{"missing token", "/api/projects", "", http.StatusUnauthorized},
+		{"bearer token", "/api/projects", "Bearer " + token, http.StatusOK},
+		{"scheme is case-insensitive", "/api/projects", "bearer " + token, http.StatusOK},
+		{"wrong token", "/api/projects", "Bearer vvt_other", http.StatusUnauthorized},
+		{"basic auth", "/api/projects", "Basic " + token, http.StatusUnauthorized},
+		{"events query token", "/api/events?access_token=" + token, "", http.StatusOK},
+		{"query token elsewhere", "/api/projects?access_token=" + token, "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+}
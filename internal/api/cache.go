@@ -0,0 +1,120 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ajeebtech/vervideos/internal/project"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// projectCache keeps the stored project list and loaded configs between requests.
+// The watcher drops entries when another vervids process changes them.
+type projectCache struct {
+	mu       sync.Mutex
+	projects []project.ProjectInfo
+	listed   bool
+	configs  map[string]*project.Project // By config.json path
+}
+
+var cache = &projectCache{configs: make(map[string]*project.Project)}
+
+// allProjects returns the projects in storage, listing them only when not cached
+func (c *projectCache) allProjects() ([]project.ProjectInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.listed {
+		return c.projects, nil
+	}
+	projects, err := project.GetAllProjects()
+	if err != nil {
+		return nil, err
+	}
+	c.projects, c.listed = projects, true
+	return projects, nil
+}
+
+// load returns the project in configPath, reading it only when not cached
+func (c *projectCache) load(configPath string) (*project.Project, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if proj, ok := c.configs[configPath]; ok {
+		return proj, nil
+	}
+	proj, err := project.LoadFromPath(configPath)
+	if err != nil {
+		return nil, err
+	}
+	c.configs[configPath] = proj
+	return proj, nil
+}
+
+// invalidate forgets the given configs, and the project list when listChanged
+func (c *projectCache) invalidate(configPaths []string, listChanged bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, path := range configPaths {
+		delete(c.configs, path)
+	}
+	if listChanged {
+		c.projects, c.listed = nil, false
+	}
+}
+
+// refreshProjects lists the projects in storage again and reports whether the list
+// differs from the cached one. Nothing is cached if the list was never requested.
+func (c *projectCache) refreshProjects() bool {
+	c.mu.Lock()
+	listed, previous := c.listed, c.projects
+	c.mu.Unlock()
+	if !listed {
+		return false
+	}
+	projects, err := project.GetAllProjects()
+	if err != nil {
+		return false
+	}
+
+	changed := len(projects) != len(previous)
+	for i := 0; !changed && i < len(projects); i++ {
+		changed = projects[i].DockerPath != previous[i].DockerPath
+	}
+	c.mu.Lock()
+	c.projects, c.listed = projects, true
+	c.mu.Unlock()
+	return changed
+}
+
+// configPaths returns every project config.json in the directories the server
+// searches: the working directory, ~/Documents, ~/Desktop, ~/Projects and their
+// immediate subdirectories
+func configPaths() []string {
+	home := os.Getenv("HOME")
+	searchDirs := []string{
+		".",
+		filepath.Join(home, "Documents"),
+		filepath.Join(home, "Desktop"),
+		filepath.Join(home, "Projects"),
+	}
+
+	paths := []string{}
+	for _, baseDir := range searchDirs {
+		if entries, err := os.ReadDir(baseDir); err == nil {
+			for _, entry := range entries {
+				if entry.IsDir() {
+					configPath := filepath.Join(baseDir, entry.Name(), storage.VerVidsDir, storage.ConfigFile)
+					if _, err := os.Stat(configPath); err == nil {
+						paths = append(paths, configPath)
+					}
+				}
+			}
+		}
+		// Also check if .vervids exists directly in baseDir
+		configPath := filepath.Join(baseDir, storage.VerVidsDir, storage.ConfigFile)
+		if _, err := os.Stat(configPath); err == nil {
+			paths = append(paths, configPath)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// watchInterval is how often the watcher looks for changes made by the CLI
+const watchInterval = time.Second
+
+// registryInterval is how often the project list in storage is checked; listing
+// it goes through the container, so less often than the local configs
+const registryInterval = 15 * time.Second
+
+// Event tells panel clients that project data changed outside the server, so they
+// reload instead of showing a stale history
+type Event struct {
+	Type    string `json:"type"`              // "project" (a config changed) or "projects" (one was added or removed)
+	Project string `json:"project,omitempty"` // Name of the changed project, for "project" events
+	Time    string `json:"time"`
+}
+
+// eventHub fans events out to the clients connected to /api/events
+type eventHub struct {
+	mu      sync.Mutex
+	clients map[chan Event]struct{}
+}
+
+var events = &eventHub{clients: make(map[chan Event]struct{})}
+
+func (h *eventHub) subscribe() chan Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan Event, 16)
+	h.clients[ch] = struct{}{}
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, ch)
+}
+
+// publish sends e to every client; a client too slow to keep up misses it rather
+// than holding up the others
+func (h *eventHub) publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// fileStamp is what the watcher compares to notice a file was rewritten
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchProjects polls the project configs, the manifests the CLI rewrites on every
+// commit, revert or config change. A change drops the cached copies and is pushed to
+// panel clients and logged in the terminal; a config appearing or disappearing (init,
+// adopt, delete) also drops the cached project list. The project list in storage is
+// checked separately every registryInterval, for projects created from elsewhere.
+func watchProjects(interval time.Duration) {
+	stamps := scanStamps()
+	registry := time.NewTicker(registryInterval)
+	defer registry.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-registry.C:
+			if cache.refreshProjects() {
+				fmt.Println(ui.Info("↻ Project list changed; reloading"))
+				events.publish(Event{Type: "projects", Time: time.Now().Format(time.RFC3339)})
+			}
+			continue
+		case <-ticker.C:
+		}
+
+		current := scanStamps()
+		changed := []string{}
+		listChanged := len(current) != len(stamps)
+		for path, stamp := range current {
+			previous, ok := stamps[path]
+			if !ok {
+				listChanged = true
+			}
+			if !ok || previous != stamp {
+				changed = append(changed, path)
+			}
+		}
+		for path := range stamps {
+			if _, ok := current[path]; !ok {
+				changed = append(changed, path)
+			}
+		}
+		stamps = current
+		if len(changed) == 0 {
+			continue
+		}
+
+		cache.invalidate(changed, listChanged)
+		now := time.Now().Format(time.RFC3339)
+		if listChanged {
+			fmt.Println(ui.Info("↻ Project list changed; reloading"))
+			events.publish(Event{Type: "projects", Time: now})
+		}
+		for _, path := range changed {
+			name := filepath.Base(filepath.Dir(filepath.Dir(path)))
+			if proj, err := cache.load(path); err == nil {
+				name = proj.ProjectName
+			}
+			fmt.Println(ui.Info(fmt.Sprintf("↻ %s changed; reloading", name)))
+			events.publish(Event{Type: "project", Project: name, Time: now})
+		}
+	}
+}
+
+// scanStamps stamps every project config the server can find
+func scanStamps() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, path := range configPaths() {
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+// handleEvents handles GET /api/events, a server-sent event stream of changes made
+// to projects outside the server
+func handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-ch:
+			data, _ := json.Marshal(e)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -3,38 +3,71 @@ package assets
 import (
 	"encoding/xml"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/aepx"
 )
 
 // Asset represents a file referenced in the .aepx project
 type Asset struct {
-	Path         string `json:"path"`
-	RelativePath string `json:"relative_path"`
-	Filename     string `json:"filename"`
-	Extension    string `json:"extension"`
-	Size         int64  `json:"size"`
+	Path         string   `json:"path"`
+	RelativePath string   `json:"relative_path"`
+	Filename     string   `json:"filename"`
+	Extension    string   `json:"extension"`
+	Size         int64    `json:"size"`
+	Reference    string   `json:"reference,omitempty"`  // Path exactly as written in the .aepx
+	Mapped       bool     `json:"mapped,omitempty"`     // Resolved through a path mapping
+	LinkPaths    []string `json:"link_paths,omitempty"` // Symlinks that resolved to this file
+	FoundIn      string   `json:"found_in,omitempty"`   // Search path the file was found under when its own path was missing
+	Source       string   `json:"source,omitempty"`     // Copy to read the content from instead of Path (offline commits)
+	Hash         string   `json:"hash,omitempty"`       // Content hash of a staged asset the project already stores, left uncopied
+	Cloud        string   `json:"cloud,omitempty"`      // Sync client of the folder the file is in (Dropbox, Google Drive, ...)
 }
 
 // ParseResult represents the output from the parser
 type ParseResult struct {
-	ProjectFile   string   `json:"project_file"`
-	Assets        []Asset  `json:"assets"`
-	MissingAssets []string `json:"missing_assets"`
-	TotalSize     int64    `json:"total_size"`
+	ProjectFile       string             `json:"project_file"`
+	Assets            []Asset            `json:"assets"`
+	MissingAssets     []string           `json:"missing_assets"`
+	MissingReferences map[string]string  `json:"missing_references,omitempty"` // Missing path -> path as written in the .aepx
+	ExcludedAssets    []string           `json:"excluded_assets,omitempty"`    // Skipped by include/exclude rules
+	Placeholders      map[string]string  `json:"placeholders,omitempty"`       // Online-only files left undownloaded (also missing) -> sync client
+	Hydrated          []string           `json:"hydrated,omitempty"`           // Online-only files downloaded while parsing
+	References        []string           `json:"references,omitempty"`         // Every path as written in the project file
+	Comps             []aepx.CompSummary `json:"comps,omitempty"`              // Compositions with their settings and layer counts (.aepx only)
+	TotalSize         int64              `json:"total_size"`
+}
+
+// ParseOptions controls how asset references found in the .aepx are resolved
+type ParseOptions struct {
+	PathMappings    []PathMapping     // Prefix mappings tried when a path doesn't exist on this machine
+	ResolveSymlinks bool              // Store symlinked assets under their target path
+	Rules           Rules             // Include/exclude rules by extension and folder
+	SearchPaths     []string          // Fallback roots searched by filename for missing assets
+	KnownHashes     map[string]string // Asset path -> content hash from an earlier commit, to pick among same-named files
+	Generic         bool              // Snapshot the file as a whole without looking for references
+	HydrateCloud    bool              // Download online-only files instead of reporting them missing
 }
 
 // ParseAEPX parses an .aepx file and extracts all asset references (native Go implementation)
 func ParseAEPX(aepxPath string, scriptPath string) (*ParseResult, error) {
 	// scriptPath parameter is kept for backward compatibility but not used
-	
+	return ParseAEPXWithOptions(aepxPath, ParseOptions{})
+}
+
+// ParseAEPXWithOptions parses an .aepx file like ParseAEPX, applying the given options
+func ParseAEPXWithOptions(aepxPath string, opts ParseOptions) (*ParseResult, error) {
 	result := &ParseResult{
-		ProjectFile:   "",
-		Assets:        []Asset{},
-		MissingAssets: []string{},
-		TotalSize:     0,
+		ProjectFile:       "",
+		Assets:            []Asset{},
+		MissingAssets:     []string{},
+		MissingReferences: map[string]string{},
+		TotalSize:         0,
 	}
 
 	// Get absolute path
@@ -51,8 +84,21 @@ func ParseAEPX(aepxPath string, scriptPath string) (*ParseResult, error) {
 	}
 	defer file.Close()
 
+	// Compositions are read from the same pass over the file; a comp parser that gives
+	// up early still drains its side so the asset scan isn't held up
+	compReader, compWriter := io.Pipe()
+	compsDone := make(chan []aepx.Comp)
+	go func() {
+		comps, err := aepx.ParseComps(compReader)
+		io.Copy(io.Discard, compReader)
+		if err != nil {
+			comps = nil
+		}
+		compsDone <- comps
+	}()
+
 	// Parse XML using decoder to handle large files efficiently
-	decoder := xml.NewDecoder(file)
+	decoder := xml.NewDecoder(io.TeeReader(file, compWriter))
 	assetPaths := make(map[string]bool) // Use map to avoid duplicates
 
 	for {
@@ -123,47 +169,147 @@ func ParseAEPX(aepxPath string, scriptPath string) (*ParseResult, error) {
 		}
 	}
 
+	compWriter.Close()
+	if comps := <-compsDone; len(comps) > 0 {
+		result.Comps = aepx.Summarize(comps)
+	}
+
+	resolveReferences(result, assetPaths, opts)
+	return result, nil
+}
+
+// resolveReferences turns the references collected from a project file into assets
+// (or missing paths) on result, applying path mappings, search paths, symlink
+// resolution and include/exclude rules. result.ProjectFile must already be set.
+func resolveReferences(result *ParseResult, assetPaths map[string]bool, opts ParseOptions) {
 	// Process each asset path
-	projectDir := filepath.Dir(absPath)
-	
-	for assetPath := range assetPaths {
+	projectDir := filepath.Dir(result.ProjectFile)
+	resolvedPaths := make(map[string]bool)
+	assetIndex := make(map[string]int) // resolved path -> index in result.Assets
+	search := newSearchIndex(opts.SearchPaths)
+
+	// Walk references in a stable order so duplicates always resolve the same way
+	references := make([]string, 0, len(assetPaths))
+	for reference := range assetPaths {
+		references = append(references, reference)
+	}
+	sort.Strings(references)
+	result.References = references
+
+	for _, reference := range references {
+		assetPath := reference
 		if assetPath == "" {
 			continue
 		}
 
-		// Skip URLs
-		if strings.HasPrefix(assetPath, "http://") || 
-		   strings.HasPrefix(assetPath, "https://") || 
-		   strings.HasPrefix(assetPath, "file://") {
+		// Skip remote URLs and turn file:// URIs into local paths
+		decoded, ok := DecodeAssetReference(assetPath)
+		if !ok {
 			continue
 		}
+		assetPath = ResolveAssetPath(decoded, projectDir)
+
+		// Paths from another machine may resolve through a configured mapping
+		mapped := false
+		if _, err := os.Stat(assetPath); err != nil && len(opts.PathMappings) > 0 {
+			if mappedPath, ok := MapPath(decoded, opts.PathMappings); ok {
+				assetPath = ResolveAssetPath(mappedPath, projectDir)
+				mapped = true
+			}
+		}
 
-		// Convert to absolute path if relative
-		if !filepath.IsAbs(assetPath) {
-			assetPath = filepath.Join(projectDir, assetPath)
+		// Percent-encoded paths (clip%20final.mov) are only decoded when the literal path doesn't exist
+		if _, err := os.Stat(assetPath); err != nil {
+			if unescaped, err := url.PathUnescape(decoded); err == nil && unescaped != decoded {
+				candidate := ResolveAssetPath(unescaped, projectDir)
+				if _, err := os.Stat(candidate); err == nil {
+					assetPath = candidate
+				}
+			}
 		}
 
-		// Normalize the path
-		assetPath = filepath.Clean(assetPath)
+		// Missing files may have moved to another drive or NAS mount; look for them by name
+		foundIn := ""
+		if _, err := os.Stat(assetPath); err != nil {
+			if found, root, ok := search.find(filepath.Base(assetPath), opts.KnownHashes[assetPath]); ok {
+				assetPath = found
+				foundIn = root
+			}
+		}
+
+		// Optionally store symlinked assets under their target so links to one file dedupe
+		linkPath := ""
+		if opts.ResolveSymlinks {
+			if linfo, err := os.Lstat(assetPath); err == nil && linfo.Mode()&os.ModeSymlink != 0 {
+				if target, err := filepath.EvalSymlinks(assetPath); err == nil {
+					linkPath = assetPath
+					assetPath = target
+				}
+			}
+		}
+
+		// Files filtered out by the project's include/exclude rules are neither tracked nor missing
+		if !opts.Rules.Allows(assetPath) {
+			if !resolvedPaths[assetPath] {
+				resolvedPaths[assetPath] = true
+				result.ExcludedAssets = append(result.ExcludedAssets, assetPath)
+			}
+			continue
+		}
+
+		// Different references (URI, encoded, relative, symlinked) can point at the same file
+		if resolvedPaths[assetPath] {
+			if idx, ok := assetIndex[assetPath]; ok && linkPath != "" {
+				result.Assets[idx].LinkPaths = appendUnique(result.Assets[idx].LinkPaths, linkPath)
+			}
+			continue
+		}
+		resolvedPaths[assetPath] = true
 
 		// Check if file exists
 		info, err := os.Stat(assetPath)
+		cloud := CloudProvider(assetPath)
+		if err == nil && !info.IsDir() && isPlaceholder(assetPath, info, cloud) {
+			// An online-only stub would be committed as an empty or unreadable file
+			err = os.ErrNotExist
+			if opts.HydrateCloud {
+				if hydrated, herr := hydrate(assetPath); herr == nil && !isPlaceholder(assetPath, hydrated, cloud) {
+					info, err = hydrated, nil
+					result.Hydrated = append(result.Hydrated, assetPath)
+				}
+			}
+			if err != nil {
+				result.addPlaceholder(assetPath, cloud)
+			}
+		} else if err != nil && icloudStub(assetPath) != "" {
+			result.addPlaceholder(assetPath, "iCloud Drive")
+		}
 		if err == nil && !info.IsDir() {
 			// File exists
 			relPath, _ := filepath.Rel(projectDir, assetPath)
 			ext := filepath.Ext(assetPath)
 			
-			result.Assets = append(result.Assets, Asset{
+			asset := Asset{
 				Path:         assetPath,
 				RelativePath: relPath,
 				Filename:     filepath.Base(assetPath),
 				Extension:    ext,
 				Size:         info.Size(),
-			})
+				Reference:    reference,
+				Mapped:       mapped,
+				FoundIn:      foundIn,
+				Cloud:        cloud,
+			}
+			if linkPath != "" {
+				asset.LinkPaths = []string{linkPath}
+			}
+			assetIndex[assetPath] = len(result.Assets)
+			result.Assets = append(result.Assets, asset)
 			result.TotalSize += info.Size()
 		} else {
 			// File missing
 			result.MissingAssets = append(result.MissingAssets, assetPath)
+			result.MissingReferences[assetPath] = reference
 		}
 	}
 
@@ -172,8 +318,29 @@ func ParseAEPX(aepxPath string, scriptPath string) (*ParseResult, error) {
 		return result.Assets[i].Path < result.Assets[j].Path
 	})
 	sort.Strings(result.MissingAssets)
+	sort.Strings(result.ExcludedAssets)
+}
 
-	return result, nil
+// addPlaceholder records an online-only file that couldn't be read; it is reported
+// missing as well
+func (r *ParseResult) addPlaceholder(path string, provider string) {
+	if r.Placeholders == nil {
+		r.Placeholders = make(map[string]string)
+	}
+	if provider == "" {
+		provider = "cloud storage"
+	}
+	r.Placeholders[path] = provider
+}
+
+// appendUnique appends value to values unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
 }
 
 // GetParserScriptPath is kept for backward compatibility but no longer needed
@@ -0,0 +1,84 @@
+package assets
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cloudFolders maps a path component to the sync client that owns folders under it.
+// On macOS, File Provider clients live in ~/Library/CloudStorage/<Client>-<account>.
+var cloudFolders = []struct {
+	prefix   string
+	provider string
+}{
+	{"dropbox", "Dropbox"},
+	{"google drive", "Google Drive"},
+	{"googledrive", "Google Drive"},
+	{"my drive", "Google Drive"},
+	{"onedrive", "OneDrive"},
+	{"icloud drive", "iCloud Drive"},
+	{"mobile documents", "iCloud Drive"},
+	{"box sync", "Box"},
+}
+
+// CloudProvider returns the sync client whose folder holds path (Dropbox, Google Drive,
+// OneDrive, iCloud Drive, Box), or "" when path isn't in a cloud-synced folder
+func CloudProvider(path string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i, part := range parts {
+		name := strings.ToLower(part)
+		for _, folder := range cloudFolders {
+			// "Dropbox", "Dropbox (Studio)", "GoogleDrive-me@example.com", "OneDrive - Studio"
+			if name == folder.prefix || strings.HasPrefix(name, folder.prefix+" ") ||
+				strings.HasPrefix(name, folder.prefix+"-") || strings.HasPrefix(name, folder.prefix+"(") {
+				return folder.provider
+			}
+		}
+		// Any File Provider client, named by its folder: CloudStorage/Box-Box
+		if i > 0 && strings.EqualFold(parts[i-1], "CloudStorage") {
+			if client, _, ok := strings.Cut(part, "-"); ok && client != "" {
+				return client
+			}
+		}
+	}
+	return ""
+}
+
+// isPlaceholder reports whether the file at path is an online-only stub rather than
+// its content: a file the OS marks as offline, one with no blocks allocated on disk,
+// or, inside a synced folder, an empty file
+func isPlaceholder(path string, info os.FileInfo, provider string) bool {
+	if offlineAttribute(info) {
+		return true
+	}
+	if provider == "" {
+		return false
+	}
+	return info.Size() == 0 || unallocated(info)
+}
+
+// hydrate reads the placeholder at path through once so the sync client downloads it,
+// and returns the file's info afterwards. Sync clients fetch online-only files on read.
+func hydrate(path string) (os.FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := io.Copy(io.Discard, file); err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
+}
+
+// icloudStub returns the stub iCloud Drive leaves in place of a file that was evicted
+// from this Mac (.clip.mov.icloud next to clip.mov), or "" if there is none
+func icloudStub(path string) string {
+	stub := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".icloud")
+	if _, err := os.Stat(stub); err != nil {
+		return ""
+	}
+	return stub
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package assets
+
+import (
+	"os"
+	"syscall"
+)
+
+// unallocated reports whether a non-empty file has no blocks on disk, which is how
+// File Provider and FUSE sync clients present online-only files
+func unallocated(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && info.Size() > 0 && stat.Blocks == 0
+}
+
+// offlineAttribute reports whether the OS itself marks the file as online-only; only
+// Windows has such an attribute
+func offlineAttribute(info os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package assets
+
+import (
+	"os"
+	"syscall"
+)
+
+// Attributes the Cloud Files API sets on placeholders (OneDrive, Dropbox, Google Drive)
+const (
+	fileAttributeOffline            = 0x00001000
+	fileAttributeRecallOnOpen       = 0x00040000
+	fileAttributeRecallOnDataAccess = 0x00400000
+)
+
+// unallocated is covered by the placeholder attributes on Windows
+func unallocated(info os.FileInfo) bool {
+	return false
+}
+
+// offlineAttribute reports whether Windows marks the file as online-only
+func offlineAttribute(info os.FileInfo) bool {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return false
+	}
+	return data.FileAttributes&(fileAttributeOffline|fileAttributeRecallOnOpen|fileAttributeRecallOnDataAccess) != 0
+}
@@ -0,0 +1,81 @@
+package assets
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Driver reads asset references from one kind of project file
+type Driver struct {
+	Name       string   // Shown to users, e.g. "After Effects"
+	Extensions []string // Lowercase, with the dot
+	Parse      func(path string, opts ParseOptions) (*ParseResult, error)
+}
+
+// drivers lists the supported project formats; the first is the default
+var drivers = []Driver{
+	{Name: "After Effects", Extensions: []string{".aepx"}, Parse: ParseAEPXWithOptions},
+	{Name: "Nuke", Extensions: []string{".nk"}, Parse: ParseNukeWithOptions},
+}
+
+// DriverFor returns the driver for a project file by its extension, or nil
+func DriverFor(path string) *Driver {
+	ext := strings.ToLower(filepath.Ext(path))
+	for i := range drivers {
+		for _, e := range drivers[i].Extensions {
+			if e == ext {
+				return &drivers[i]
+			}
+		}
+	}
+	return nil
+}
+
+// IsProjectFile reports whether path has the extension of a supported project format
+func IsProjectFile(path string) bool {
+	return DriverFor(path) != nil
+}
+
+// IsAEPX reports whether path is an After Effects .aepx project, the only format
+// comps, markers and expressions are read from
+func IsAEPX(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".aepx")
+}
+
+// ProjectExtensions returns the extensions of every supported project format
+func ProjectExtensions() []string {
+	exts := []string{}
+	for _, d := range drivers {
+		exts = append(exts, d.Extensions...)
+	}
+	return exts
+}
+
+// ParseProjectFile parses a project file of any supported format for its assets. With
+// opts.Generic any file is accepted and has no assets.
+func ParseProjectFile(path string, opts ParseOptions) (*ParseResult, error) {
+	if opts.Generic {
+		return parseGeneric(path)
+	}
+	driver := DriverFor(path)
+	if driver == nil {
+		return nil, fmt.Errorf("unsupported project file %s (supported: %s)", filepath.Base(path), strings.Join(ProjectExtensions(), ", "))
+	}
+	return driver.Parse(path, opts)
+}
+
+// parseGeneric returns an empty result for a file versioned as a whole (a PSD, a C4D
+// scene, a brief) whose references aren't tracked
+func parseGeneric(path string) (*ParseResult, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	return &ParseResult{
+		ProjectFile:       absPath,
+		Assets:            []Asset{},
+		MissingAssets:     []string{},
+		MissingReferences: map[string]string{},
+	}, nil
+}
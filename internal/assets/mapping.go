@@ -0,0 +1,90 @@
+package assets
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// PathMapping maps a path prefix on one machine to the equivalent prefix on another,
+// e.g. /Volumes/Footage on a Mac workstation to F:\Footage on a Windows render node.
+// Mappings apply in both directions.
+type PathMapping struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// MapPath rewrites path using the first mapping whose prefix matches it (in either
+// direction). Candidates that exist on disk win; otherwise the first candidate in
+// this platform's notation is returned. The second return value reports whether a
+// mapping was applied.
+func MapPath(path string, mappings []PathMapping) (string, bool) {
+	var fallback string
+	for _, m := range mappings {
+		for _, pair := range [][2]string{{m.From, m.To}, {m.To, m.From}} {
+			rest, ok := trimPathPrefix(path, pair[0])
+			if !ok || pair[1] == "" {
+				continue
+			}
+
+			candidate, _ := NormalizeAssetPath(joinMappedPath(pair[1], rest))
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate, true
+			}
+			if fallback == "" && isNativeAbsPath(candidate) {
+				fallback = candidate
+			}
+		}
+	}
+
+	if fallback != "" {
+		return fallback, true
+	}
+	return path, false
+}
+
+// trimPathPrefix strips prefix from path if path lies under it, comparing without
+// regard to separator style (and case, for Windows drive paths)
+func trimPathPrefix(path, prefix string) (string, bool) {
+	if prefix == "" {
+		return "", false
+	}
+	p := strings.ReplaceAll(path, `\`, "/")
+	pre := strings.TrimRight(strings.ReplaceAll(prefix, `\`, "/"), "/")
+
+	matches := strings.HasPrefix(p, pre)
+	if IsWindowsAbsPath(prefix) {
+		matches = strings.HasPrefix(strings.ToLower(p), strings.ToLower(pre))
+	}
+	if !matches {
+		return "", false
+	}
+
+	rest := p[len(pre):]
+	if rest != "" && !strings.HasPrefix(rest, "/") {
+		// /Volumes/Footage must not match /Volumes/FootageArchive
+		return "", false
+	}
+	return strings.TrimPrefix(rest, "/"), true
+}
+
+// joinMappedPath appends a forward-slash relative remainder to a mapped prefix
+func joinMappedPath(prefix, rest string) string {
+	if rest == "" {
+		return prefix
+	}
+	sep := "/"
+	if IsWindowsAbsPath(prefix) {
+		sep = `\`
+		rest = strings.ReplaceAll(rest, "/", `\`)
+	}
+	return strings.TrimRight(prefix, `\/`) + sep + rest
+}
+
+// isNativeAbsPath reports whether path is absolute in this platform's notation
+func isNativeAbsPath(path string) bool {
+	if runtime.GOOS == "windows" {
+		return IsWindowsAbsPath(path)
+	}
+	return IsPosixAbsPath(path)
+}
@@ -0,0 +1,155 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// studio maps a Mac workstation's footage volume to a Windows render node's drive
+// and a NAS share
+var studio = []PathMapping{
+	{From: "/Volumes/Footage", To: `F:\Footage`},
+	{From: "/mnt/renders", To: `\\nas\renders`},
+}
+
+func TestTrimPathPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		prefix   string
+		wantRest string
+		wantOK   bool
+	}{
+		{name: "posix prefix", path: "/Volumes/Footage/shot01/plate.mov", prefix: "/Volumes/Footage", wantRest: "shot01/plate.mov", wantOK: true},
+		{name: "prefix with trailing slash", path: "/Volumes/Footage/plate.mov", prefix: "/Volumes/Footage/", wantRest: "plate.mov", wantOK: true},
+		{name: "the prefix itself", path: "/Volumes/Footage", prefix: "/Volumes/Footage", wantRest: "", wantOK: true},
+		{name: "windows backslashes", path: `F:\Footage\shot01\plate.mov`, prefix: `F:\Footage`, wantRest: "shot01/plate.mov", wantOK: true},
+		{name: "windows forward slashes", path: "F:/Footage/plate.mov", prefix: `F:\Footage`, wantRest: "plate.mov", wantOK: true},
+		{name: "windows drive case", path: `f:\footage\plate.mov`, prefix: `F:\Footage`, wantRest: "plate.mov", wantOK: true},
+		{name: "unc share", path: `\\nas\renders\final\promo.mov`, prefix: `\\nas\renders`, wantRest: "final/promo.mov", wantOK: true},
+		{name: "posix case matters", path: "/volumes/footage/plate.mov", prefix: "/Volumes/Footage", wantOK: false},
+		{name: "longer folder name", path: "/Volumes/FootageArchive/plate.mov", prefix: "/Volumes/Footage", wantOK: false},
+		{name: "other folder", path: "/Users/ana/Desktop/plate.mov", prefix: "/Volumes/Footage", wantOK: false},
+		{name: "empty prefix", path: "/Volumes/Footage/plate.mov", prefix: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest, ok := trimPathPrefix(tt.path, tt.prefix)
+			if rest != tt.wantRest || ok != tt.wantOK {
+				t.Errorf("trimPathPrefix(%q, %q) = %q, %v; want %q, %v", tt.path, tt.prefix, rest, ok, tt.wantRest, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestJoinMappedPath(t *testing.T) {
+	tests := []struct {
+		prefix, rest, want string
+	}{
+		{prefix: "/Volumes/Footage", rest: "shot01/plate.mov", want: "/Volumes/Footage/shot01/plate.mov"},
+		{prefix: "/Volumes/Footage/", rest: "plate.mov", want: "/Volumes/Footage/plate.mov"},
+		{prefix: `F:\Footage`, rest: "shot01/plate.mov", want: `F:\Footage\shot01\plate.mov`},
+		{prefix: `F:\Footage\`, rest: "plate.mov", want: `F:\Footage\plate.mov`},
+		{prefix: `\\nas\renders`, rest: "final/promo.mov", want: `\\nas\renders\final\promo.mov`},
+		{prefix: "/Volumes/Footage", rest: "", want: "/Volumes/Footage"},
+	}
+	for _, tt := range tests {
+		if got := joinMappedPath(tt.prefix, tt.rest); got != tt.want {
+			t.Errorf("joinMappedPath(%q, %q) = %q, want %q", tt.prefix, tt.rest, got, tt.want)
+		}
+	}
+}
+
+func TestMapPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("expectations are in macOS/Linux notation")
+	}
+	onDisk := t.TempDir()
+	if err := os.WriteFile(filepath.Join(onDisk, "plate.mov"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		mappings []PathMapping
+		want     string
+		wantOK   bool
+	}{
+		{name: "windows drive to mac volume", path: `F:\Footage\shot01\plate.mov`, mappings: studio, want: "/Volumes/Footage/shot01/plate.mov", wantOK: true},
+		{name: "windows forward slashes", path: "F:/Footage/shot01/plate.mov", mappings: studio, want: "/Volumes/Footage/shot01/plate.mov", wantOK: true},
+		{name: "unc share to mount", path: `\\nas\renders\final\promo.mov`, mappings: studio, want: "/mnt/renders/final/promo.mov", wantOK: true},
+		{
+			name:     "existing candidate wins",
+			path:     `G:\Plates\plate.mov`,
+			mappings: []PathMapping{{From: "/Volumes/Plates", To: `G:\Plates`}, {From: onDisk, To: `G:\Plates`}},
+			want:     filepath.Join(onDisk, "plate.mov"),
+			wantOK:   true,
+		},
+		{name: "foreign-only candidate", path: "/Volumes/Footage/plate.mov", mappings: studio, want: "/Volumes/Footage/plate.mov", wantOK: false},
+		{name: "unmapped windows path", path: `D:\Music\score.wav`, mappings: studio, want: `D:\Music\score.wav`, wantOK: false},
+		{name: "unmapped posix path", path: "/Users/ana/Desktop/plate.mov", mappings: studio, want: "/Users/ana/Desktop/plate.mov", wantOK: false},
+		{name: "no mappings", path: `F:\Footage\plate.mov`, mappings: nil, want: `F:\Footage\plate.mov`, wantOK: false},
+		{name: "empty target", path: `F:\Footage\plate.mov`, mappings: []PathMapping{{From: `F:\Footage`}}, want: `F:\Footage\plate.mov`, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MapPath(tt.path, tt.mappings)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("MapPath(%q) = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNativeReferenceFor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mapped candidates are only taken in this platform's notation")
+	}
+	tests := []struct {
+		name      string
+		reference string
+		goos      string
+		want      string
+		wantOK    bool
+	}{
+		{name: "mapped windows path on mac", reference: `F:\Footage\shot01\plate.mov`, goos: "darwin", want: "/Volumes/Footage/shot01/plate.mov", wantOK: true},
+		{name: "unmapped windows path on mac", reference: `D:\Music\score.wav`, goos: "darwin", want: `D:\Music\score.wav`, wantOK: false},
+		{name: "native path on mac", reference: "/Volumes/Footage/plate.mov", goos: "darwin", want: "/Volumes/Footage/plate.mov", wantOK: false},
+		{name: "relative windows path on mac", reference: `footage\shot01\plate.mov`, goos: "darwin", want: "footage/shot01/plate.mov", wantOK: true},
+		{name: "relative mac path on windows", reference: "footage/shot01/plate.mov", goos: "windows", want: `footage\shot01\plate.mov`, wantOK: true},
+		{name: "native path on windows", reference: `F:\Footage\plate.mov`, goos: "windows", want: `F:\Footage\plate.mov`, wantOK: false},
+		{name: "file uri", reference: "file:///Volumes/Footage/plate.mov", goos: "windows", want: "file:///Volumes/Footage/plate.mov", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nativeReferenceFor(tt.reference, studio, tt.goos)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("nativeReferenceFor(%q, %s) = %q, %v; want %q, %v", tt.reference, tt.goos, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNormalizeAssetPathFor(t *testing.T) {
+	tests := []struct {
+		path, goos string
+		want       string
+		wantAbs    bool
+	}{
+		{path: `C:\footage\clip.mov`, goos: "darwin", want: "C:/footage/clip.mov", wantAbs: true},
+		{path: `\\nas\share\clip.mov`, goos: "linux", want: "//nas/share/clip.mov", wantAbs: true},
+		{path: "/Volumes/Footage/clip.mov", goos: "darwin", want: "/Volumes/Footage/clip.mov", wantAbs: true},
+		{path: `footage\clip.mov`, goos: "darwin", want: "footage/clip.mov", wantAbs: false},
+		{path: "/Volumes/Footage/clip.mov", goos: "windows", want: `\Volumes\Footage\clip.mov`, wantAbs: true},
+		{path: "footage/clip.mov", goos: "windows", want: `footage\clip.mov`, wantAbs: false},
+	}
+	for _, tt := range tests {
+		got, abs := normalizeAssetPathFor(tt.path, tt.goos)
+		if got != tt.want || abs != tt.wantAbs {
+			t.Errorf("normalizeAssetPathFor(%q, %s) = %q, %v; want %q, %v", tt.path, tt.goos, got, abs, tt.want, tt.wantAbs)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package assets
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// nukeFileNodes are the node classes whose file (and proxy) knobs point at media
+var nukeFileNodes = map[string]bool{
+	"Read": true, "Write": true,
+	"DeepRead": true, "DeepWrite": true,
+	"ReadGeo": true, "ReadGeo2": true, "WriteGeo": true,
+}
+
+// nukeNodeStart matches the first line of a node, e.g. "Read {"
+var nukeNodeStart = regexp.MustCompile(`^\s*([A-Za-z_]\w*)\s*\{\s*$`)
+
+// nukeScriptDir is the TCL idiom for paths relative to the script
+const nukeScriptDir = "[file dirname [value root.name]]"
+
+// nukeFramePattern matches frame-number placeholders: #### or printf-style %04d
+var nukeFramePattern = regexp.MustCompile(`#+|%0?(\d*)d`)
+
+// ParseNukeWithOptions parses a Nuke script (.nk) for the files its Read and Write
+// nodes point at. Frame sequences (plate.####.exr, plate.%04d.exr) are expanded to
+// the frames on disk; a sequence with no frames found is reported missing as written.
+// Paths built from TCL expressions other than the script directory are skipped.
+func ParseNukeWithOptions(nkPath string, opts ParseOptions) (*ParseResult, error) {
+	result := &ParseResult{
+		Assets:            []Asset{},
+		MissingAssets:     []string{},
+		MissingReferences: map[string]string{},
+	}
+	absPath, err := filepath.Abs(nkPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	result.ProjectFile = absPath
+
+	file, err := os.Open(nkPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	references, err := NukeReferences(file)
+	if err != nil {
+		return nil, err
+	}
+
+	scriptDir := filepath.Dir(absPath)
+	assetPaths := make(map[string]bool)
+	for _, reference := range references {
+		reference = strings.ReplaceAll(reference, nukeScriptDir, filepath.ToSlash(scriptDir))
+		if strings.Contains(reference, "[") {
+			continue
+		}
+		for _, path := range expandSequence(reference, scriptDir) {
+			assetPaths[path] = true
+		}
+	}
+
+	resolveReferences(result, assetPaths, opts)
+	return result, nil
+}
+
+// NukeReferences returns the file and proxy knob values of the file nodes in a Nuke
+// script, in the order they appear
+func NukeReferences(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	references := []string{}
+	depth := 0
+	fileNode := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if depth == 0 {
+			if m := nukeNodeStart.FindStringSubmatch(line); m != nil {
+				fileNode = nukeFileNodes[m[1]]
+				depth = 1
+			}
+			continue
+		}
+
+		// Knobs sit directly inside the node; nested braces are multi-line values
+		if depth == 1 && fileNode {
+			fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+			if len(fields) == 2 && (fields[0] == "file" || fields[0] == "proxy") {
+				if value := nukeKnobValue(fields[1]); value != "" {
+					references = append(references, value)
+				}
+			}
+		}
+		depth += braceDelta(line)
+		if depth <= 0 {
+			depth = 0
+			fileNode = false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Nuke script: %w", err)
+	}
+	return references, nil
+}
+
+// nukeKnobValue returns a knob value written bare, "quoted" or {braced}
+func nukeKnobValue(value string) string {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, `"`):
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			switch value[i] {
+			case '\\':
+				if i+1 < len(value) {
+					i++
+					b.WriteByte(value[i])
+				}
+			case '"':
+				return b.String()
+			default:
+				b.WriteByte(value[i])
+			}
+		}
+		return b.String()
+	case strings.HasPrefix(value, "{"):
+		if end := strings.LastIndex(value, "}"); end > 0 {
+			return strings.TrimSpace(value[1:end])
+		}
+		return ""
+	default:
+		if fields := strings.Fields(value); len(fields) > 0 {
+			return fields[0]
+		}
+		return ""
+	}
+}
+
+// braceDelta returns how many more braces a line opens than it closes, ignoring
+// escaped braces and those inside quotes
+func braceDelta(line string) int {
+	delta := 0
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case '{':
+			if !quoted {
+				delta++
+			}
+		case '}':
+			if !quoted {
+				delta--
+			}
+		}
+	}
+	return delta
+}
+
+// expandSequence returns the frames on disk matching a file path with a frame
+// placeholder, or the path itself when it has none or no frames exist
+func expandSequence(reference string, scriptDir string) []string {
+	name := filepath.Base(filepath.FromSlash(reference))
+	if !nukeFramePattern.MatchString(name) {
+		return []string{reference}
+	}
+
+	// Build a regexp for the file name with each placeholder as its digits
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, m := range nukeFramePattern.FindAllStringSubmatchIndex(name, -1) {
+		pattern.WriteString(regexp.QuoteMeta(name[last:m[0]]))
+		digits := m[1] - m[0] // ####
+		if name[m[0]] == '%' {
+			digits = 0
+			if m[2] >= 0 && m[3] > m[2] {
+				digits, _ = strconv.Atoi(name[m[2]:m[3]])
+			}
+		}
+		if digits > 0 {
+			pattern.WriteString(fmt.Sprintf(`-?\d{%d,}`, digits))
+		} else {
+			pattern.WriteString(`-?\d+`)
+		}
+		last = m[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(name[last:]) + "$")
+	frameRe, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return []string{reference}
+	}
+
+	dir := filepath.Dir(ResolveAssetPath(reference, scriptDir))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []string{reference}
+	}
+	frames := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && frameRe.MatchString(e.Name()) {
+			frames = append(frames, filepath.Join(dir, e.Name()))
+		}
+	}
+	if len(frames) == 0 {
+		return []string{reference}
+	}
+	return frames
+}
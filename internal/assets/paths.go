@@ -0,0 +1,172 @@
+package assets
+
+import (
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// windowsDrivePattern matches drive-letter paths like C:\footage or C:/footage
+var windowsDrivePattern = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+
+// IsWindowsAbsPath reports whether the path is an absolute Windows path (drive letter or UNC)
+func IsWindowsAbsPath(path string) bool {
+	return windowsDrivePattern.MatchString(path) || strings.HasPrefix(path, `\\`)
+}
+
+// IsPosixAbsPath reports whether the path is an absolute macOS/Linux path
+func IsPosixAbsPath(path string) bool {
+	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//")
+}
+
+// NormalizeAssetPath converts a path as written by After Effects on any OS into
+// the current platform's notation. The second return value reports whether the
+// path is absolute, either natively or in the other platform's notation, so
+// callers don't join foreign absolute paths onto the project directory.
+func NormalizeAssetPath(path string) (string, bool) {
+	return normalizeAssetPathFor(path, runtime.GOOS)
+}
+
+// normalizeAssetPathFor normalizes a path for the given GOOS
+func normalizeAssetPathFor(path string, goos string) (string, bool) {
+	winAbs := IsWindowsAbsPath(path)
+	posixAbs := IsPosixAbsPath(path)
+
+	if goos == "windows" {
+		// Windows accepts forward slashes, but keep paths consistent for comparisons
+		return strings.ReplaceAll(path, "/", `\`), winAbs || posixAbs
+	}
+
+	if winAbs {
+		// C:\footage\clip.mov -> C:/footage/clip.mov, \\nas\share -> //nas/share
+		return strings.ReplaceAll(path, `\`, "/"), true
+	}
+	if posixAbs {
+		return path, true
+	}
+
+	// Relative path written on Windows (footage\clip.mov)
+	return strings.ReplaceAll(path, `\`, "/"), false
+}
+
+// ResolveAssetPath converts a reference from the .aepx into an absolute, cleaned
+// path on this platform, resolving relative references against projectDir
+func ResolveAssetPath(reference string, projectDir string) string {
+	assetPath, isAbs := NormalizeAssetPath(reference)
+	if !isAbs {
+		assetPath = filepath.Join(projectDir, assetPath)
+	}
+
+	// Clean would collapse the leading slashes of a foreign UNC path
+	if runtime.GOOS == "windows" || !IsWindowsAbsPath(reference) {
+		assetPath = filepath.Clean(assetPath)
+	}
+	return assetPath
+}
+
+// DecodeAssetReference turns file:// URIs into filesystem paths and passes plain
+// paths through unchanged. It returns false for remote URLs (http, https) which
+// can't be versioned.
+func DecodeAssetReference(reference string) (string, bool) {
+	lower := strings.ToLower(reference)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return "", false
+	}
+	if !strings.HasPrefix(lower, "file:") {
+		return reference, true
+	}
+
+	u, err := url.Parse(reference)
+	if err != nil || u.Path == "" {
+		// Malformed URI - fall back to stripping the scheme by hand
+		path := strings.TrimPrefix(reference[len("file:"):], "//")
+		if unescaped, err := url.PathUnescape(path); err == nil {
+			path = unescaped
+		}
+		return path, path != ""
+	}
+
+	path := u.Path
+	// file:///C:/footage/clip.mov parses with a leading slash before the drive letter
+	if len(path) > 2 && path[0] == '/' && windowsDrivePattern.MatchString(path[1:]) {
+		path = path[1:]
+	}
+
+	// file://nas/share/clip.mov points at a network share
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		path = "//" + u.Host + path
+	}
+
+	return path, true
+}
+
+// NativeReference rewrites a reference written on another OS into this platform's
+// notation so the restored project opens without relinking: absolute paths go through
+// the path mappings (C:\Footage\clip.mov -> /Volumes/Footage/clip.mov), and relative
+// paths get this platform's separators. It returns false when the reference is
+// already native or is a foreign absolute path no mapping covers.
+func NativeReference(reference string, mappings []PathMapping) (string, bool) {
+	return nativeReferenceFor(reference, mappings, runtime.GOOS)
+}
+
+// nativeReferenceFor is NativeReference for the given GOOS
+func nativeReferenceFor(reference string, mappings []PathMapping, goos string) (string, bool) {
+	if strings.Contains(strings.ToLower(reference), "://") || strings.HasPrefix(strings.ToLower(reference), "file:") {
+		return reference, false // URIs are notation-independent
+	}
+	winAbs := IsWindowsAbsPath(reference)
+	posixAbs := IsPosixAbsPath(reference)
+
+	if (goos == "windows" && posixAbs) || (goos != "windows" && winAbs) {
+		mapped, ok := MapPath(reference, mappings)
+		if !ok {
+			return reference, false
+		}
+		if native, _ := normalizeAssetPathFor(mapped, goos); native != reference {
+			return native, true
+		}
+		return reference, false
+	}
+	if winAbs || posixAbs {
+		return reference, false
+	}
+
+	// Relative path: only the separators differ between platforms
+	native := strings.ReplaceAll(reference, `\`, "/")
+	if goos == "windows" {
+		native = strings.ReplaceAll(reference, "/", `\`)
+	}
+	return native, native != reference
+}
+
+// IsForeignPath reports whether an absolute path is written in the other platform's
+// notation (a drive letter on macOS/Linux, a /-rooted path on Windows)
+func IsForeignPath(path string) bool {
+	if runtime.GOOS == "windows" {
+		return IsPosixAbsPath(path)
+	}
+	return IsWindowsAbsPath(path)
+}
+
+// CaseCollisions groups the assets whose paths differ only by letter case (bg.PNG
+// and bg.png in one folder). They are separate files on Linux, but only one of them
+// can exist on macOS or Windows. Groups are sorted, as are the paths in each.
+func CaseCollisions(assetList []Asset) [][]string {
+	byFolded := make(map[string][]string)
+	for _, asset := range assetList {
+		key := strings.ToLower(filepath.ToSlash(asset.Path))
+		byFolded[key] = append(byFolded[key], asset.Path)
+	}
+	groups := [][]string{}
+	for _, paths := range byFolded {
+		if len(paths) > 1 {
+			sort.Strings(paths)
+			groups = append(groups, paths)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
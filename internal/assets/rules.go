@@ -0,0 +1,71 @@
+package assets
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Rules selects which referenced files are tracked as assets
+type Rules struct {
+	IncludeExtensions []string `json:"include_extensions,omitempty"` // Only track these extensions (empty = all)
+	ExcludeExtensions []string `json:"exclude_extensions,omitempty"` // Never track these extensions
+	ExcludeFolders    []string `json:"exclude_folders,omitempty"`    // Folder names (Renders) or paths (footage/proxies)
+	ExcludeFiles      []string `json:"exclude_files,omitempty"`      // Individual files, by full path or filename
+}
+
+// IsEmpty reports whether the rules allow every file
+func (r Rules) IsEmpty() bool {
+	return len(r.IncludeExtensions) == 0 && len(r.ExcludeExtensions) == 0 && len(r.ExcludeFolders) == 0 && len(r.ExcludeFiles) == 0
+}
+
+// Allows reports whether the file at path should be tracked
+func (r Rules) Allows(path string) bool {
+	ext := NormalizeExtension(filepath.Ext(path))
+
+	if len(r.IncludeExtensions) > 0 {
+		included := false
+		for _, e := range r.IncludeExtensions {
+			if NormalizeExtension(e) == ext {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, e := range r.ExcludeExtensions {
+		if NormalizeExtension(e) == ext {
+			return false
+		}
+	}
+
+	slashed := strings.ReplaceAll(path, `\`, "/")
+	for _, file := range r.ExcludeFiles {
+		file = strings.ReplaceAll(file, `\`, "/")
+		if strings.EqualFold(file, slashed) || strings.EqualFold(file, filepath.Base(slashed)) {
+			return false
+		}
+	}
+
+	// Match folders on whole path segments, case-insensitively
+	dir := "/" + strings.ToLower(strings.Trim(strings.ReplaceAll(filepath.Dir(path), `\`, "/"), "/")) + "/"
+	for _, folder := range r.ExcludeFolders {
+		folder = strings.ToLower(strings.Trim(strings.ReplaceAll(folder, `\`, "/"), "/"))
+		if folder != "" && strings.Contains(dir, "/"+folder+"/") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NormalizeExtension lowercases an extension and ensures it has a leading dot
+func NormalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
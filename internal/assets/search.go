@@ -0,0 +1,87 @@
+package assets
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// searchIndex finds missing assets by filename under fallback roots. Each root is
+// walked once, the first time a lookup is needed.
+type searchIndex struct {
+	roots  []string
+	byName map[string][]string // lowercase filename -> paths, in root order
+	built  bool
+}
+
+func newSearchIndex(roots []string) *searchIndex {
+	return &searchIndex{roots: roots}
+}
+
+// build walks every root that exists, skipping unreadable directories
+func (s *searchIndex) build() {
+	s.byName = make(map[string][]string)
+	s.built = true
+	for _, root := range s.roots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				// Hidden folders (.Trash, .Spotlight-V100, .vervids) never hold project footage
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			key := strings.ToLower(d.Name())
+			s.byName[key] = append(s.byName[key], path)
+			return nil
+		})
+	}
+}
+
+// find returns a file named filename under one of the roots, and the root it was found
+// in. When the expected content hash is known, a candidate with that hash is preferred.
+func (s *searchIndex) find(filename string, hash string) (string, string, bool) {
+	if len(s.roots) == 0 {
+		return "", "", false
+	}
+	if !s.built {
+		s.build()
+	}
+
+	candidates := s.byName[strings.ToLower(filename)]
+	if len(candidates) == 0 {
+		return "", "", false
+	}
+	chosen := candidates[0]
+	if hash != "" && len(candidates) > 1 {
+		for _, candidate := range candidates {
+			if h, err := storage.HashFile(candidate); err == nil && h == hash {
+				chosen = candidate
+				break
+			}
+		}
+	}
+	return chosen, s.rootOf(chosen), true
+}
+
+// rootOf returns the search root containing path
+func (s *searchIndex) rootOf(path string) string {
+	for _, root := range s.roots {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return root
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package chunks
+
+import (
+	"io"
+)
+
+// Chunk size bounds for content-defined chunking. Media files are large, so chunks
+// are sized in megabytes to keep the number of stored objects manageable.
+const (
+	MinSize = 512 * 1024
+	AvgSize = 2 * 1024 * 1024
+	MaxSize = 8 * 1024 * 1024
+)
+
+// Normalized chunking masks (FastCDC): a stricter mask before the average size and a
+// looser one after it pulls chunk sizes towards AvgSize. The bits sit at the top of
+// the fingerprint so each decision depends on the last 64 bytes.
+const (
+	maskS uint64 = ((1 << 23) - 1) << (64 - 23)
+	maskL uint64 = ((1 << 19) - 1) << (64 - 19)
+)
+
+// gear maps each byte value to a pseudo-random 64-bit number. The table must never
+// change, otherwise chunk boundaries (and with them deduplication) would shift.
+var gear [256]uint64
+
+func init() {
+	// splitmix64 with a fixed seed gives a stable, well-mixed table
+	seed := uint64(0x7665727669647321) // "vervids!"
+	for i := range gear {
+		seed += 0x9E3779B97F4A7C15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		gear[i] = z ^ (z >> 31)
+	}
+}
+
+// cutpoint returns the length of the first chunk in data
+func cutpoint(data []byte) int {
+	n := len(data)
+	if n <= MinSize {
+		return n
+	}
+	if n > MaxSize {
+		n = MaxSize
+	}
+	normal := AvgSize
+	if n < normal {
+		normal = n
+	}
+
+	var fp uint64
+	i := MinSize
+	for ; i < normal; i++ {
+		fp = (fp << 1) + gear[data[i]]
+		if fp&maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		fp = (fp << 1) + gear[data[i]]
+		if fp&maskL == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
+
+// Split reads r to EOF and calls fn with each content-defined chunk in order.
+// The slice passed to fn is reused, so fn must not keep it after returning.
+func Split(r io.Reader, fn func(chunk []byte) error) error {
+	buf := make([]byte, 0, 2*MaxSize)
+	eof := false
+	for {
+		// Keep at least MaxSize bytes buffered so every cut sees a full window
+		for !eof && len(buf) < MaxSize {
+			n, err := r.Read(buf[len(buf):cap(buf)])
+			buf = buf[:len(buf)+n]
+			if err == io.EOF {
+				eof = true
+			} else if err != nil {
+				return err
+			}
+		}
+		if len(buf) == 0 {
+			return nil
+		}
+
+		c := cutpoint(buf)
+		if err := fn(buf[:c]); err != nil {
+			return err
+		}
+		buf = append(buf[:0], buf[c:]...)
+	}
+}
@@ -0,0 +1,132 @@
+package chunks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/rand"
+	"testing"
+	"testing/iotest"
+)
+
+// randomData returns n reproducible pseudo-random bytes, like compressed media
+func randomData(seed int64, n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+// split returns the chunks Split cuts data into, read back in small pieces
+func split(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	var chunks [][]byte
+	err := Split(iotest.HalfReader(bytes.NewReader(data)), func(chunk []byte) error {
+		chunks = append(chunks, append([]byte{}, chunk...))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	return chunks
+}
+
+func TestCutpoint(t *testing.T) {
+	random := randomData(1, 3*MaxSize)
+	tests := []struct {
+		name     string
+		data     []byte
+		min, max int
+	}{
+		{name: "empty", data: nil, min: 0, max: 0},
+		{name: "shorter than the minimum", data: random[:1000], min: 1000, max: 1000},
+		{name: "exactly the minimum", data: random[:MinSize], min: MinSize, max: MinSize},
+		{name: "no cut in uniform data", data: make([]byte, 2*MaxSize), min: MaxSize, max: MaxSize},
+		{name: "random data", data: random, min: MinSize + 1, max: MaxSize},
+		{name: "short tail", data: random[:MinSize+10], min: MinSize + 1, max: MinSize + 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cutpoint(tt.data); got < tt.min || got > tt.max {
+				t.Errorf("cutpoint() = %d, want %d to %d", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestSplitSizes(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int // Chunks expected; 0 when any number will do
+	}{
+		{name: "empty", data: nil, want: 0},
+		{name: "one small chunk", data: randomData(2, 1000), want: 1},
+		{name: "random", data: randomData(3, 40*1024*1024)},
+		{name: "uniform", data: make([]byte, 3*MaxSize+5), want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := split(t, tt.data)
+			if tt.want > 0 && len(chunks) != tt.want {
+				t.Errorf("%d chunks, want %d", len(chunks), tt.want)
+			}
+			if len(tt.data) == 0 && len(chunks) != 0 {
+				t.Errorf("%d chunks of no data, want none", len(chunks))
+			}
+			for i, c := range chunks {
+				last := i == len(chunks)-1
+				if len(c) > MaxSize || (!last && len(c) < MinSize) || len(c) == 0 {
+					t.Errorf("chunk %d of %d is %d bytes, want %d to %d", i, len(chunks), len(c), MinSize, MaxSize)
+				}
+			}
+			if got := bytes.Join(chunks, nil); !bytes.Equal(got, tt.data) {
+				t.Errorf("chunks join to %d bytes that differ from the %d split", len(got), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestSplitBoundaryStability(t *testing.T) {
+	original := randomData(4, 40*1024*1024)
+	edit := func(at int, remove int, insert []byte) []byte {
+		edited := append([]byte{}, original[:at]...)
+		edited = append(edited, insert...)
+		return append(edited, original[at+remove:]...)
+	}
+	tests := []struct {
+		name   string
+		edited []byte
+	}{
+		{name: "unchanged", edited: original},
+		{name: "bytes inserted", edited: edit(10*1024*1024, 0, []byte("a new layer"))},
+		{name: "bytes overwritten", edited: edit(15*1024*1024, 1024, make([]byte, 1024))},
+		{name: "bytes deleted", edited: edit(5*1024*1024, 4096, nil)},
+		{name: "byte prepended", edited: edit(0, 0, []byte{0x42})},
+		{name: "bytes appended", edited: edit(len(original), 0, randomData(5, 3000))},
+	}
+
+	before := map[[32]byte]bool{}
+	chunks := split(t, original)
+	for _, c := range chunks {
+		before[sha256.Sum256(c)] = true
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// An edit changes the chunk holding it and, at most, the one after, as
+			// boundaries are set by content rather than by offset
+			changed := 0
+			after := split(t, tt.edited)
+			for _, c := range after {
+				if !before[sha256.Sum256(c)] {
+					changed++
+				}
+			}
+			max := 2
+			if bytes.Equal(tt.edited, original) {
+				max = 0
+			}
+			if changed > max {
+				t.Errorf("%d of %d chunks changed, want at most %d", changed, len(after), max)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package chunks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// ChunkRef identifies one chunk of a file
+type ChunkRef struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// Manifest lists the chunks that make up a file, in order
+type Manifest struct {
+	Hash      string     `json:"hash"`       // SHA-256 of the whole file
+	Size      int64      `json:"size"`       // Total size in bytes
+	ChunksDir string     `json:"chunks_dir"` // Storage directory holding the chunks
+	Chunks    []ChunkRef `json:"chunks"`
+}
+
+// StoreResult reports what storing a file added to the chunk store
+type StoreResult struct {
+	Manifest    *Manifest
+	NewChunks   int
+	NewBytes    int64
+	TotalChunks int
+}
+
+// chunkPath returns the location of a chunk relative to the chunks directory
+func chunkPath(hash string) string {
+	return filepath.Join(hash[:2], hash)
+}
+
+// existingChunks lists the chunk hashes already present in chunksDir
+func existingChunks(store storage.Storage, chunksDir string) (map[string]bool, error) {
+	if err := store.MakeDir(chunksDir); err != nil {
+		return nil, err
+	}
+	files, err := store.List(chunksDir)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	for _, file := range files {
+		existing[filepath.Base(file)] = true
+	}
+	return existing, nil
+}
+
+// Store splits the file at localPath into content-defined chunks and uploads the
+// chunks not yet present in chunksDir. New chunks are staged in a temp directory
+// and copied to storage in one batch.
+func Store(store storage.Storage, localPath string, chunksDir string) (*StoreResult, error) {
+	existing, err := existingChunks(store, chunksDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks in %s: %w", store.Name(), err)
+	}
+
+	stageDir, err := storage.MkdirTemp("chunks-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fileHash := sha256.New()
+	result := &StoreResult{Manifest: &Manifest{ChunksDir: chunksDir, Chunks: []ChunkRef{}}}
+	staged := make(map[string]bool)
+
+	err = Split(io.TeeReader(f, fileHash), func(chunk []byte) error {
+		sum := sha256.Sum256(chunk)
+		hash := hex.EncodeToString(sum[:])
+		result.Manifest.Chunks = append(result.Manifest.Chunks, ChunkRef{Hash: hash, Size: int64(len(chunk))})
+		result.Manifest.Size += int64(len(chunk))
+
+		if existing[hash] || staged[hash] {
+			return nil
+		}
+		stagedPath := filepath.Join(stageDir, chunkPath(hash))
+		if err := os.MkdirAll(filepath.Dir(stagedPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(stagedPath, chunk, 0644); err != nil {
+			return err
+		}
+		staged[hash] = true
+		result.NewChunks++
+		result.NewBytes += int64(len(chunk))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk %s: %w", filepath.Base(localPath), err)
+	}
+	result.Manifest.Hash = hex.EncodeToString(fileHash.Sum(nil))
+	result.TotalChunks = len(result.Manifest.Chunks)
+
+	if result.NewChunks > 0 {
+		if err := store.PutDir(stageDir, chunksDir); err != nil {
+			return nil, fmt.Errorf("failed to copy chunks to %s: %w", store.Name(), err)
+		}
+	}
+
+	return result, nil
+}
+
+// SaveManifest writes a manifest to dockerPath
+func SaveManifest(store storage.Storage, m *Manifest, dockerPath string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal chunk manifest: %w", err)
+	}
+	if err := store.MakeDir(filepath.Dir(dockerPath)); err != nil {
+		return err
+	}
+	return store.Put(bytes.NewReader(data), int64(len(data)), dockerPath)
+}
+
+// LoadManifest reads a manifest from dockerPath
+func LoadManifest(store storage.Storage, dockerPath string) (*Manifest, error) {
+	var buf bytes.Buffer
+	if err := store.Get(dockerPath, &buf); err != nil {
+		return nil, fmt.Errorf("failed to read chunk manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		return nil, fmt.Errorf("failed to parse chunk manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Restore reassembles the file described by m at destPath by streaming its chunks
+// out of storage in order, then verifies the result against the manifest hash
+func Restore(store storage.Storage, m *Manifest, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(m.Chunks))
+	for i, c := range m.Chunks {
+		names[i] = filepath.ToSlash(chunkPath(c.Hash))
+	}
+
+	hash := sha256.New()
+	streamErr := store.Concat(m.ChunksDir, names, io.MultiWriter(out, hash))
+	closeErr := out.Close()
+	if streamErr != nil {
+		os.Remove(destPath)
+		return fmt.Errorf("failed to reassemble chunks: %w", streamErr)
+	}
+	if closeErr != nil {
+		os.Remove(destPath)
+		return closeErr
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); got != m.Hash {
+		os.Remove(destPath)
+		return fmt.Errorf("reassembled file is corrupt (hash %s, expected %s)", got[:12], m.Hash[:12])
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package chunks
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// dirStore is storage kept in a local directory, for testing without Docker or a bucket
+type dirStore struct {
+	root string
+}
+
+func (d dirStore) local(path string) string { return filepath.Join(d.root, filepath.FromSlash(path)) }
+
+func (d dirStore) Name() string       { return "test" }
+func (d dirStore) EnsureReady() error { return nil }
+
+func (d dirStore) Exists(path string) (bool, error) {
+	_, err := os.Stat(d.local(path))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (d dirStore) MakeDir(path string) error { return os.MkdirAll(d.local(path), 0755) }
+
+func (d dirStore) PutFile(localPath string, path string) error {
+	return storage.CopyFile(localPath, d.local(path))
+}
+
+func (d dirStore) Put(r io.Reader, size int64, path string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(d.local(path)), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(d.local(path), data, 0644)
+}
+
+func (d dirStore) PutDir(localDir string, dir string) error {
+	return filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		return d.PutFile(path, filepath.Join(dir, rel))
+	})
+}
+
+func (d dirStore) GetFile(path string, localPath string) error {
+	return storage.CopyFile(d.local(path), localPath)
+}
+
+func (d dirStore) Get(path string, w io.Writer) error {
+	f, err := os.Open(d.local(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (d dirStore) Concat(dir string, names []string, w io.Writer) error {
+	for _, name := range names {
+		if err := d.Get(filepath.Join(dir, name), w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d dirStore) List(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.Walk(d.local(dir), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(d.root, path)
+		paths = append(paths, "/"+filepath.ToSlash(rel))
+		return err
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return paths, err
+}
+
+func (d dirStore) Remove(path string) error { return os.RemoveAll(d.local(path)) }
+
+// writeFile saves data in the test's temp directory and returns its path
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStoreRestore(t *testing.T) {
+	t.Setenv(storage.CacheDirEnv, t.TempDir())
+	store := dirStore{root: t.TempDir()}
+	const chunksDir = "/vervids/promo/chunks"
+
+	plate := randomData(6, 20*1024*1024)
+	regraded := append(append(append([]byte{}, plate[:7*1024*1024]...), "regraded"...), plate[7*1024*1024:]...)
+	tests := []struct {
+		name      string
+		data      []byte
+		newChunks func(total int) (min, max int) // Chunks the store doesn't hold yet
+	}{
+		{name: "new file", data: plate, newChunks: func(total int) (int, int) { return total, total }},
+		{name: "same file again", data: plate, newChunks: func(int) (int, int) { return 0, 0 }},
+		{name: "edited copy", data: regraded, newChunks: func(int) (int, int) { return 1, 2 }},
+		{name: "empty file", data: nil, newChunks: func(int) (int, int) { return 0, 0 }},
+		{name: "small file", data: []byte("title card"), newChunks: func(int) (int, int) { return 1, 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Store(store, writeFile(t, "plate.mov", tt.data), chunksDir)
+			if err != nil {
+				t.Fatalf("Store() error = %v", err)
+			}
+			m := result.Manifest
+			if min, max := tt.newChunks(result.TotalChunks); result.NewChunks < min || result.NewChunks > max {
+				t.Errorf("%d of %d chunks new, want %d to %d", result.NewChunks, result.TotalChunks, min, max)
+			}
+			if m.Size != int64(len(tt.data)) || len(m.Chunks) != result.TotalChunks {
+				t.Errorf("manifest holds %d bytes in %d chunks, want %d bytes in %d", m.Size, len(m.Chunks), len(tt.data), result.TotalChunks)
+			}
+
+			// The manifest survives storage, and its chunks put the file back together
+			manifestPath := "/vervids/promo/v000/plate.mov.chunks.json"
+			if err := SaveManifest(store, m, manifestPath); err != nil {
+				t.Fatalf("SaveManifest() error = %v", err)
+			}
+			loaded, err := LoadManifest(store, manifestPath)
+			if err != nil {
+				t.Fatalf("LoadManifest() error = %v", err)
+			}
+			restored := filepath.Join(t.TempDir(), "restored", "plate.mov")
+			if err := Restore(store, loaded, restored); err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+			got, err := os.ReadFile(restored)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Errorf("restored %d bytes that differ from the %d stored", len(got), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestRestoreCorrupt(t *testing.T) {
+	t.Setenv(storage.CacheDirEnv, t.TempDir())
+	tests := []struct {
+		name    string
+		damage  func(t *testing.T, store dirStore, m *Manifest)
+		wantErr string
+	}{
+		{
+			name: "chunk changed",
+			damage: func(t *testing.T, store dirStore, m *Manifest) {
+				path := filepath.Join(m.ChunksDir, chunkPath(m.Chunks[1].Hash))
+				if err := store.Put(strings.NewReader("bit rot"), 7, path); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "corrupt",
+		},
+		{
+			name: "chunk missing",
+			damage: func(t *testing.T, store dirStore, m *Manifest) {
+				if err := store.Remove(filepath.Join(m.ChunksDir, chunkPath(m.Chunks[0].Hash))); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "failed to reassemble",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := dirStore{root: t.TempDir()}
+			result, err := Store(store, writeFile(t, "plate.mov", randomData(7, 6*1024*1024)), "/vervids/promo/chunks")
+			if err != nil {
+				t.Fatalf("Store() error = %v", err)
+			}
+			if result.TotalChunks < 2 {
+				t.Fatalf("%d chunks, want several", result.TotalChunks)
+			}
+			tt.damage(t, store, result.Manifest)
+
+			// A file that can't be put back exactly isn't left behind
+			restored := filepath.Join(t.TempDir(), "plate.mov")
+			err = Restore(store, result.Manifest, restored)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Restore() error = %v, want one saying %q", err, tt.wantErr)
+			}
+			if _, statErr := os.Stat(restored); !os.IsNotExist(statErr) {
+				t.Errorf("%s left behind after a failed restore", restored)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package docker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// CopyOptions controls a copy between the host and the container
+type CopyOptions struct {
+	Verify bool // Checksum the source and the copy with SHA-256 and fail unless they match
+}
+
+// ErrChecksumMismatch is wrapped by the error of a verified copy that doesn't match its
+// source
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// hostChecksums returns the SHA-256 of root, or of every regular file under it keyed
+// by its slash-separated path relative to root, with the sizes of the files. A single
+// file is keyed "".
+func hostChecksums(root string) (map[string]string, map[string]int64, error) {
+	root = filepath.Clean(root)
+	sums := map[string]string{}
+	sizes := map[string]int64{}
+	add := func(file string, rel string) error {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		sum, err := storage.HashFile(file)
+		if err != nil {
+			return err
+		}
+		sums[rel] = sum
+		sizes[rel] = info.Size()
+		return nil
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		return sums, sizes, add(root, "")
+	}
+	err = filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		return add(file, filepath.ToSlash(rel))
+	})
+	return sums, sizes, err
+}
+
+// containerChecksums returns the SHA-256 of root inside the container, or of every
+// regular file under it, keyed like hostChecksums
+func containerChecksums(root string) (map[string]string, error) {
+	root = path.Clean(root)
+	var out bytes.Buffer
+	if err := StreamFromContainer(nil, &out, "sh", "-c", `find "$1" -type f -exec sha256sum {} +`, "sh", root); err != nil {
+		return nil, fmt.Errorf("failed to checksum %s: %w", root, err)
+	}
+
+	// GNU sha256sum escapes names holding a backslash or newline and marks the line with
+	// a leading backslash
+	unescape := strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r")
+	sums := map[string]string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		escaped := strings.HasPrefix(line, `\`)
+		line = strings.TrimPrefix(line, `\`)
+		if len(line) < 66 {
+			continue
+		}
+		sum, name := line[:64], line[66:]
+		if escaped {
+			name = unescape.Replace(name)
+		}
+		rel := ""
+		if name != root {
+			rel = strings.TrimPrefix(name, strings.TrimSuffix(root, "/")+"/")
+		}
+		sums[rel] = sum
+	}
+	if len(sums) == 0 {
+		return nil, fmt.Errorf("failed to checksum %s: no files found", root)
+	}
+	return sums, nil
+}
+
+// compareChecksums checks that every file of the source was copied with the same
+// content and returns the bytes verified. Files only the destination has, e.g. from a
+// copy merged into an existing directory, are ignored.
+func compareChecksums(source, copied map[string]string, sizes map[string]int64, dest string) (int64, error) {
+	rels := make([]string, 0, len(source))
+	for rel := range source {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	var verified int64
+	for _, rel := range rels {
+		name := dest
+		if rel != "" {
+			name = path.Join(filepath.ToSlash(dest), rel)
+		}
+		got, ok := copied[rel]
+		if !ok {
+			return verified, fmt.Errorf("%w: %s is missing from the copy", ErrChecksumMismatch, name)
+		}
+		if got != source[rel] {
+			return verified, fmt.Errorf("%w: %s has sha256 %s, expected %s", ErrChecksumMismatch, name, got, source[rel])
+		}
+		verified += sizes[rel]
+	}
+	return verified, nil
+}
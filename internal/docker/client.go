@@ -0,0 +1,171 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/logging"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// pingTimeout bounds the check that the Docker daemon answers
+const pingTimeout = 5 * time.Second
+
+var (
+	clientOnce sync.Once
+	apiClient  *client.Client
+	clientErr  error
+)
+
+// dockerClient returns the Docker Engine API client, set up on first use from
+// DOCKER_HOST and the other DOCKER_* variables, or the local daemon's socket. The API
+// version is negotiated with the daemon on the first request.
+func dockerClient() (*client.Client, error) {
+	clientOnce.Do(func() {
+		opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+		if os.Getenv(client.EnvOverrideHost) == "" {
+			if host, ok := localSocket(); ok {
+				opts = append(opts, client.WithHost(host))
+			}
+		}
+		apiClient, clientErr = client.NewClientWithOpts(opts...)
+		if clientErr != nil {
+			clientErr = fmt.Errorf("failed to set up Docker client: %w", clientErr)
+		}
+	})
+	return apiClient, clientErr
+}
+
+// localSocket finds the local daemon's socket: the default one, or Docker Desktop's in
+// the home directory when the default isn't set up. On Windows the client's default
+// named pipe is used.
+func localSocket() (string, bool) {
+	if runtime.GOOS == "windows" {
+		return client.DefaultDockerHost, true
+	}
+	candidates := []string{"/var/run/docker.sock"}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates,
+			filepath.Join(home, ".docker", "run", "docker.sock"),
+			filepath.Join(home, ".docker", "desktop", "docker.sock"))
+	}
+	for _, path := range candidates {
+		// Lstat: Docker Desktop's link stays in place while it's stopped
+		if _, err := os.Lstat(path); err == nil {
+			return "unix://" + path, true
+		}
+	}
+	return "", false
+}
+
+// logCall logs a Docker API call with its duration and outcome
+func logCall(call string, start time.Time, err error) {
+	if err != nil {
+		slog.Info("docker", "call", call, "took", logging.Since(start), "err", err)
+		return
+	}
+	slog.Info("docker", "call", call, "took", logging.Since(start))
+}
+
+// exitError is the error of a command in the container that exited with a non-zero status
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// runExec runs a command in the storage container, feeding it stdin when not nil and
+// writing its output to stdout and stderr. Fails with an exitError when the command
+// exits with a non-zero status. When ctx is done the stream is closed; the command
+// itself is left to finish in the container.
+func runExec(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) (err error) {
+	start := time.Now()
+	defer func() { logCall("exec "+strings.Join(command, " "), start, err) }()
+
+	cli, err := dockerClient()
+	if err != nil {
+		return err
+	}
+	created, err := cli.ContainerExecCreate(ctx, ContainerName, container.ExecOptions{
+		Cmd:          command,
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return err
+	}
+	attached, err := cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return err
+	}
+	defer attached.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			attached.Close()
+		case <-done:
+		}
+	}()
+
+	sent := make(chan error, 1)
+	if stdin != nil {
+		go func() {
+			_, err := io.Copy(attached.Conn, stdin)
+			if err == nil {
+				err = attached.CloseWrite()
+			}
+			sent <- err
+		}()
+	} else {
+		sent <- nil
+	}
+
+	_, copyErr := stdcopy.StdCopy(stdout, stderr, attached.Reader)
+	// The output ends when the command does; stop sending input it didn't read
+	attached.Close()
+	sendErr := <-sent
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if copyErr != nil {
+		return copyErr
+	}
+
+	inspect, err := cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return err
+	}
+	if inspect.ExitCode != 0 {
+		return &exitError{code: inspect.ExitCode}
+	}
+	// A command that got only part of its input, e.g. a file that failed to read,
+	// may still exit cleanly
+	if sendErr != nil {
+		return fmt.Errorf("failed to send input: %w", sendErr)
+	}
+	return nil
+}
+
+// execOutput runs a command in the storage container and returns its combined output
+func execOutput(ctx context.Context, command ...string) ([]byte, error) {
+	var output bytes.Buffer
+	err := runExec(ctx, nil, &output, &output, command...)
+	return output.Bytes(), err
+}
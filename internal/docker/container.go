@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+)
+
+// DefaultImage is the storage container's image when none is configured
+const DefaultImage = "alpine:latest"
+
+// ContainerOptions are the resource limits and isolation the storage container is
+// created with. They only take effect when the container is (re)created.
+type ContainerOptions struct {
+	CPUs     string // CPU limit, e.g. "2" or "0.5"; empty for none
+	Memory   string // Memory limit, e.g. "512m" or "2g"; empty for none
+	Image    string // Image, ideally pinned by digest; empty for DefaultImage
+	ReadOnly bool   // Read-only root filesystem; storage and /tmp stay writable
+}
+
+var containerOptions ContainerOptions
+
+// SetContainerOptions sets the options new storage containers are created with
+func SetContainerOptions(opts ContainerOptions) {
+	containerOptions = opts
+}
+
+// image returns the configured image or DefaultImage
+func (o ContainerOptions) image() string {
+	if o.Image != "" {
+		return o.Image
+	}
+	return DefaultImage
+}
+
+// hostConfig returns the container settings for the options
+func (o ContainerOptions) hostConfig() (*container.HostConfig, error) {
+	config := &container.HostConfig{}
+	if o.CPUs != "" {
+		cpus, err := ParseCPUs(o.CPUs)
+		if err != nil {
+			return nil, err
+		}
+		config.NanoCPUs = cpus
+	}
+	if o.Memory != "" {
+		memory, err := ParseMemory(o.Memory)
+		if err != nil {
+			return nil, err
+		}
+		config.Memory = memory
+	}
+	if o.ReadOnly {
+		// Staging files inside the container go to /tmp
+		config.ReadonlyRootfs = true
+		config.Tmpfs = map[string]string{"/tmp": ""}
+	}
+	return config, nil
+}
+
+// ParseCPUs validates a CPU limit and returns it in nano-CPUs, as Docker reports it
+func ParseCPUs(value string) (int64, error) {
+	cpus, err := strconv.ParseFloat(value, 64)
+	if err != nil || cpus <= 0 {
+		return 0, fmt.Errorf("invalid CPU limit '%s' (expected a number of CPUs, e.g. 2 or 0.5)", value)
+	}
+	return int64(cpus * 1e9), nil
+}
+
+// memoryPattern matches a memory limit: a number of bytes with an optional k, m or g
+var memoryPattern = regexp.MustCompile(`^(\d+)([kmg]?)b?$`)
+
+// ParseMemory validates a memory limit such as 512m or 2g and returns it in bytes
+func ParseMemory(value string) (int64, error) {
+	m := memoryPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if m == nil {
+		return 0, fmt.Errorf("invalid memory limit '%s' (expected e.g. 512m or 2g)", value)
+	}
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit '%s' (expected e.g. 512m or 2g)", value)
+	}
+	shift := map[string]uint{"": 0, "k": 10, "m": 20, "g": 30}[m[2]]
+	bytes := n << shift
+	if bytes < 6<<20 {
+		return 0, fmt.Errorf("memory limit '%s' is below Docker's minimum of 6m", value)
+	}
+	return bytes, nil
+}
+
+// ContainerDrift lists how the existing storage container differs from the configured
+// options, e.g. after the settings changed. Empty when it matches or doesn't exist.
+func ContainerDrift() ([]string, error) {
+	details, err := inspect()
+	if cerrdefs.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if details.ContainerJSONBase == nil || details.HostConfig == nil || details.Config == nil {
+		return nil, fmt.Errorf("unexpected container details for %s", ContainerName)
+	}
+	host := details.HostConfig
+
+	drift := []string{}
+	var wantCPUs, wantMemory int64
+	if containerOptions.CPUs != "" {
+		wantCPUs, _ = ParseCPUs(containerOptions.CPUs)
+	}
+	if containerOptions.Memory != "" {
+		wantMemory, _ = ParseMemory(containerOptions.Memory)
+	}
+	if got := host.NanoCPUs; got != wantCPUs {
+		drift = append(drift, fmt.Sprintf("CPU limit is %s, configured %s", describeLimit(float64(got)/1e9, "%g"), describeLimit(float64(wantCPUs)/1e9, "%g")))
+	}
+	if got := host.Memory; got != wantMemory {
+		drift = append(drift, fmt.Sprintf("memory limit is %s, configured %s", describeLimit(float64(got)/(1<<20), "%.0fm"), describeLimit(float64(wantMemory)/(1<<20), "%.0fm")))
+	}
+	if got := host.ReadonlyRootfs; got != containerOptions.ReadOnly {
+		drift = append(drift, fmt.Sprintf("read-only root filesystem is %t, configured %t", got, containerOptions.ReadOnly))
+	}
+	if got := details.Config.Image; got != containerOptions.image() {
+		drift = append(drift, fmt.Sprintf("image is %s, configured %s", got, containerOptions.image()))
+	}
+	return drift, nil
+}
+
+// describeLimit formats a limit, with 0 meaning none
+func describeLimit(value float64, format string) string {
+	if value == 0 {
+		return "none"
+	}
+	return fmt.Sprintf(format, value)
+}
+
+// RecreateContainer replaces the storage container with one created from the current
+// options. Stored data lives in the volume and is kept.
+func RecreateContainer() error {
+	if err := removeContainer(); err != nil {
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+	return CreateContainer()
+}
+
+// removeContainer force-removes the storage container, if there is one
+func removeContainer() error {
+	cli, err := dockerClient()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = cli.ContainerRemove(context.Background(), ContainerName, container.RemoveOptions{Force: true})
+	logCall("remove "+ContainerName, start, err)
+	if cerrdefs.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
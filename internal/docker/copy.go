@@ -0,0 +1,187 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// copyToContainer copies a local file or directory to destPath in the storage
+// container as 'docker cp' does, streaming it as a tar archive: to destPath itself, or
+// into it when it's an existing directory. A source ending in "/." copies the
+// directory's contents, merging them into destPath.
+func copyToContainer(ctx context.Context, srcPath, destPath string) (err error) {
+	start := time.Now()
+	defer func() { logCall("copy "+srcPath+" to "+destPath, start, err) }()
+
+	cli, err := dockerClient()
+	if err != nil {
+		return err
+	}
+	contents := strings.HasSuffix(srcPath, string(filepath.Separator)+".") || strings.HasSuffix(srcPath, "/.")
+	srcPath = filepath.Clean(srcPath)
+	info, err := os.Lstat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	// The archive is extracted in destPath's parent, under destPath's name, unless
+	// destPath is a directory to copy into
+	destPath = path.Clean(destPath)
+	extractDir, name := path.Dir(destPath), path.Base(destPath)
+	if !contents {
+		if stat, err := cli.ContainerStatPath(ctx, ContainerName, destPath); err == nil && stat.Mode.IsDir() {
+			extractDir, name = destPath, filepath.Base(srcPath)
+		}
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(writeArchive(w, srcPath, info, name))
+	}()
+	defer r.Close()
+	return cli.CopyToContainer(ctx, ContainerName, extractDir, r, container.CopyToContainerOptions{})
+}
+
+// writeArchive writes root, named name, and everything under it as a tar archive
+func writeArchive(w io.Writer, root string, rootInfo fs.FileInfo, name string) error {
+	tw := tar.NewWriter(w)
+	add := func(file string, info fs.FileInfo, entry string) error {
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			var err error
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = entry
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	}
+
+	if !rootInfo.IsDir() {
+		if err := add(root, rootInfo, name); err != nil {
+			return err
+		}
+		return tw.Close()
+	}
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		return add(file, info, path.Join(name, filepath.ToSlash(rel)))
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// copyFromContainer copies a file or directory in the storage container to destPath
+// on the host as 'docker cp' does: to destPath itself, or into it when it's an
+// existing directory
+func copyFromContainer(ctx context.Context, srcPath, destPath string) (err error) {
+	start := time.Now()
+	defer func() { logCall("copy "+srcPath+" to "+destPath, start, err) }()
+
+	cli, err := dockerClient()
+	if err != nil {
+		return err
+	}
+	archive, _, err := cli.CopyFromContainer(ctx, ContainerName, srcPath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	root := destPath
+	if info, err := os.Stat(destPath); err == nil && info.IsDir() {
+		root = filepath.Join(destPath, path.Base(srcPath))
+	}
+	return extractArchive(archive, root)
+}
+
+// extractArchive writes the entries of a tar archive from the container under root,
+// which takes the place of the archive's top-level entry
+func extractArchive(r io.Reader, root string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Drop the top-level name; refuse entries that would land outside root
+		_, rel, _ := strings.Cut(strings.TrimPrefix(path.Clean(header.Name), "./"), "/")
+		if !fs.ValidPath(path.Clean("./" + rel)) {
+			return fmt.Errorf("refusing to extract %s outside %s", header.Name, root)
+		}
+		target := filepath.Join(root, filepath.FromSlash(rel))
+		mode := fs.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			os.Remove(target)
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package docker
+
+import (
+	"archive/tar"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// fakeEngine serves the parts of the Docker Engine API a copy uses, with the
+// container's filesystem kept in a local directory
+type fakeEngine struct {
+	root string
+}
+
+func (e fakeEngine) local(p string) string { return filepath.Join(e.root, filepath.FromSlash(p)) }
+
+func (e fakeEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/_ping") {
+		w.Header().Set("API-Version", "1.47")
+		return
+	}
+	if !strings.HasSuffix(r.URL.Path, "/containers/"+ContainerName+"/archive") {
+		http.Error(w, `{"message":"No such container: other"}`, http.StatusNotFound)
+		return
+	}
+	target := r.URL.Query().Get("path")
+	info, statErr := os.Stat(e.local(target))
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		if statErr != nil {
+			http.Error(w, `{"message":"Could not find the file `+target+` in container `+ContainerName+`"}`, http.StatusNotFound)
+			return
+		}
+		stat, _ := json.Marshal(container.PathStat{Name: info.Name(), Size: info.Size(), Mode: info.Mode()})
+		w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(stat))
+		if r.Method == http.MethodGet {
+			writeArchive(w, e.local(target), info, path.Base(target))
+		}
+	case http.MethodPut:
+		if statErr != nil || !info.IsDir() {
+			http.Error(w, `{"message":"Could not find the file `+target+` in container `+ContainerName+`"}`, http.StatusNotFound)
+			return
+		}
+		tr := tar.NewReader(r.Body)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			dest := filepath.Join(e.local(target), filepath.FromSlash(header.Name))
+			if header.Typeflag == tar.TypeDir {
+				os.MkdirAll(dest, 0755)
+				continue
+			}
+			data, _ := io.ReadAll(tr)
+			os.WriteFile(dest, data, 0644)
+		}
+	}
+}
+
+// useFakeEngine points the Docker client at a fakeEngine for the test and returns the
+// directory holding the container's files
+func useFakeEngine(t *testing.T) string {
+	t.Helper()
+	engine := fakeEngine{root: t.TempDir()}
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")), client.WithAPIVersionNegotiation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientOnce.Do(func() {})
+	saved, savedErr := apiClient, clientErr
+	apiClient, clientErr = cli, nil
+	t.Cleanup(func() { apiClient, clientErr = saved, savedErr })
+	return engine.root
+}
+
+// writeFiles creates files, by slash-separated path relative to root, with their content
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readFiles returns the content of every file under root, by slash-separated path
+func readFiles(t *testing.T, root string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		rel, _ := filepath.Rel(root, file)
+		files[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestCopyToContainer(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  map[string]string // Files in the container before the copy
+		src       string            // Relative to the local files
+		dest      string
+		wantFiles map[string]string // Files in the container after it
+	}{
+		{
+			name:      "file to a new name",
+			src:       "plate.mov",
+			dest:      "/vervids/promo/v000/plate.mov.partial",
+			wantFiles: map[string]string{"vervids/promo/v000/plate.mov.partial": "plate"},
+		},
+		{
+			name:      "file into a directory",
+			existing:  map[string]string{"vervids/promo/v000/keep": "kept"},
+			src:       "plate.mov",
+			dest:      "/vervids/promo/v000",
+			wantFiles: map[string]string{"vervids/promo/v000/keep": "kept", "vervids/promo/v000/plate.mov": "plate"},
+		},
+		{
+			name:     "directory contents merged",
+			existing: map[string]string{"vervids/promo/objects/aa/old": "old"},
+			src:      "objects" + string(filepath.Separator) + ".",
+			dest:     "/vervids/promo/objects",
+			wantFiles: map[string]string{
+				"vervids/promo/objects/aa/old":  "old",
+				"vervids/promo/objects/aa/1234": "first",
+				"vervids/promo/objects/bb/5678": "second",
+			},
+		},
+		{
+			name: "directory to a new name",
+			src:  "objects",
+			dest: "/vervids/promo/copy",
+			wantFiles: map[string]string{
+				"vervids/promo/copy/aa/1234": "first",
+				"vervids/promo/copy/bb/5678": "second",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containerRoot := useFakeEngine(t)
+			writeFiles(t, containerRoot, tt.existing)
+			if err := os.MkdirAll(filepath.Join(containerRoot, "vervids", "promo", "v000"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			local := t.TempDir()
+			writeFiles(t, local, map[string]string{"plate.mov": "plate", "objects/aa/1234": "first", "objects/bb/5678": "second"})
+
+			if err := CopyToContainer(local+string(filepath.Separator)+tt.src, tt.dest); err != nil {
+				t.Fatalf("CopyToContainer() error = %v", err)
+			}
+			got := readFiles(t, containerRoot)
+			if len(got) != len(tt.wantFiles) {
+				t.Errorf("container holds %v, want %v", got, tt.wantFiles)
+			}
+			for name, want := range tt.wantFiles {
+				if got[name] != want {
+					t.Errorf("%s holds %q, want %q", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCopyFromContainer(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		dest      string            // Relative to the local directory, which holds "footage/"
+		wantFiles map[string]string // Local files after the copy
+		wantErr   error
+	}{
+		{
+			name:      "file to a new name",
+			src:       "/vervids/promo/v000/plate.mov",
+			dest:      "restored.mov",
+			wantFiles: map[string]string{"restored.mov": "plate"},
+		},
+		{
+			name:      "file into a directory",
+			src:       "/vervids/promo/v000/plate.mov",
+			dest:      "footage",
+			wantFiles: map[string]string{"footage/plate.mov": "plate"},
+		},
+		{
+			name:      "directory",
+			src:       "/vervids/promo/v000/assets",
+			dest:      "assets",
+			wantFiles: map[string]string{"assets/music.wav": "music", "assets/logos/logo.png": "logo"},
+		},
+		{
+			name:    "missing file",
+			src:     "/vervids/promo/v000/gone.mov",
+			dest:    "gone.mov",
+			wantErr: os.ErrNotExist,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containerRoot := useFakeEngine(t)
+			writeFiles(t, containerRoot, map[string]string{
+				"vervids/promo/v000/plate.mov":             "plate",
+				"vervids/promo/v000/assets/music.wav":      "music",
+				"vervids/promo/v000/assets/logos/logo.png": "logo",
+			})
+			local := t.TempDir()
+			if err := os.Mkdir(filepath.Join(local, "footage"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := CopyFromContainer(tt.src, filepath.Join(local, tt.dest))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("CopyFromContainer() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CopyFromContainer() error = %v", err)
+			}
+			got := readFiles(t, local)
+			if len(got) != len(tt.wantFiles) {
+				t.Errorf("copied %v, want %v", got, tt.wantFiles)
+			}
+			for name, want := range tt.wantFiles {
+				if got[name] != want {
+					t.Errorf("%s holds %q, want %q", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractArchiveRefusesEscapes(t *testing.T) {
+	var archive strings.Builder
+	tw := tar.NewWriter(&archive)
+	for _, name := range []string{"assets/", "assets/../../../outside.txt"} {
+		header := &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg}
+		if strings.HasSuffix(name, "/") {
+			header.Typeflag = tar.TypeDir
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+
+	dir := t.TempDir()
+	err := extractArchive(strings.NewReader(archive.String()), filepath.Join(dir, "assets"))
+	if err == nil || !strings.Contains(err.Error(), "refusing") {
+		t.Errorf("extractArchive() error = %v, want a refusal", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outside.txt")); !os.IsNotExist(err) {
+		t.Error("an entry was written outside the destination")
+	}
+}
@@ -1,34 +1,62 @@
 package docker
 
 import (
+    "bytes"
+    "context"
+    "encoding/json"
     "errors"
     "fmt"
+    "io"
+    "os"
     "os/exec"
     "regexp"
     "strconv"
     "strings"
     "time"
+
+    "github.com/ajeebtech/vervideos/internal/errs"
+    "github.com/ajeebtech/vervideos/internal/ui"
+    cerrdefs "github.com/containerd/errdefs"
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/api/types/image"
+    "github.com/docker/docker/api/types/mount"
+    "github.com/docker/docker/api/types/volume"
 )
 
 const (
-    ContainerName  = "vervids-storage"
-    VolumeName     = "vervids-data"
     StoragePath    = "/vervids"
     MinDockerSemver = "24.0.0"
 )
 
-// IsDockerInstalled checks if Docker is available
+// The storage container and the volume it mounts; UseSandbox switches to separate ones
+var (
+    ContainerName  = defaultContainerName
+    VolumeName     = defaultVolumeName
+)
+
+// IsDockerInstalled checks if Docker is available: DOCKER_HOST is set or a local
+// daemon's socket is there, even if the daemon isn't running
 func IsDockerInstalled() bool {
-	cmd := exec.Command("docker", "--version")
-	err := cmd.Run()
+	if os.Getenv("DOCKER_HOST") == "" {
+		if _, ok := localSocket(); !ok {
+			return false
+		}
+	}
+	_, err := dockerClient()
 	return err == nil
 }
 
 // IsDockerDaemonRunning checks if Docker daemon is accessible
 func IsDockerDaemonRunning() bool {
-	cmd := exec.Command("docker", "info")
-	cmd.Stderr = nil // Suppress stderr
-	err := cmd.Run()
+	cli, err := dockerClient()
+	if err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	start := time.Now()
+	_, err = cli.Ping(ctx)
+	logCall("ping", start, err)
 	return err == nil
 }
 
@@ -54,18 +82,23 @@ func WaitForDocker(maxWaitSeconds int) error {
 	return fmt.Errorf("Docker daemon did not become available within %d seconds", maxWaitSeconds)
 }
 
+// GetDockerVersion returns the Docker daemon's version, e.g. 24.0.7
 func GetDockerVersion() (string, error) {
-    out, err := exec.Command("docker", "--version").CombinedOutput()
-    if err != nil {
-        return "", err
-    }
-    // Example: Docker version 24.0.7, build ...
-    re := regexp.MustCompile(`Docker version ([0-9]+)\.([0-9]+)\.([0-9]+)`)
-    m := re.FindStringSubmatch(string(out))
-    if len(m) != 4 {
-        return "", errors.New("unable to parse docker version")
-    }
-    return fmt.Sprintf("%s.%s.%s", m[1], m[2], m[3]), nil
+	cli, err := dockerClient()
+	if err != nil {
+		return "", err
+	}
+	start := time.Now()
+	version, err := cli.ServerVersion(context.Background())
+	logCall("version", start, err)
+	if err != nil {
+		return "", err
+	}
+	m := regexp.MustCompile(`^v?([0-9]+)\.([0-9]+)\.([0-9]+)`).FindStringSubmatch(version.Version)
+	if len(m) != 4 {
+		return "", fmt.Errorf("unable to parse docker version %q", version.Version)
+	}
+	return fmt.Sprintf("%s.%s.%s", m[1], m[2], m[3]), nil
 }
 
 func versionGTE(a, b string) bool {
@@ -84,32 +117,40 @@ func versionGTE(a, b string) bool {
     return true
 }
 
-// IsContainerRunning checks if the vervids storage container is running
-func IsContainerRunning() bool {
-	cmd := exec.Command("docker", "ps", "--filter", fmt.Sprintf("name=%s", ContainerName), "--format", "{{.Names}}")
-	output, err := cmd.Output()
+// inspect reads the storage container's details. Its error says whether the container
+// is missing with cerrdefs.IsNotFound.
+func inspect() (container.InspectResponse, error) {
+	cli, err := dockerClient()
 	if err != nil {
-		return false
+		return container.InspectResponse{}, err
 	}
-	return strings.TrimSpace(string(output)) == ContainerName
+	start := time.Now()
+	details, err := cli.ContainerInspect(context.Background(), ContainerName)
+	logCall("inspect "+ContainerName, start, err)
+	return details, err
+}
+
+// IsContainerRunning checks if the vervids storage container is running
+func IsContainerRunning() bool {
+	details, err := inspect()
+	return err == nil && details.State != nil && details.State.Running
 }
 
 // IsContainerExists checks if the container exists (running or stopped)
 func IsContainerExists() bool {
-	cmd := exec.Command("docker", "ps", "-a", "--filter", fmt.Sprintf("name=%s", ContainerName), "--format", "{{.Names}}")
-	output, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-	return strings.TrimSpace(string(output)) == ContainerName
+	_, err := inspect()
+	return err == nil
 }
 
 // IsVolumeExists checks if the Docker volume exists
 func IsVolumeExists() bool {
-	cmd := exec.Command("docker", "volume", "inspect", VolumeName)
-	// Suppress stderr to avoid printing errors if volume doesn't exist
-	cmd.Stderr = nil
-	err := cmd.Run()
+	cli, err := dockerClient()
+	if err != nil {
+		return false
+	}
+	start := time.Now()
+	_, err = cli.VolumeInspect(context.Background(), VolumeName)
+	logCall("volume inspect "+VolumeName, start, err)
 	return err == nil
 }
 
@@ -124,37 +165,87 @@ func CreateContainer() error {
 		return nil
 	}
 
-	// Create volume (ignore error if it already exists)
-	volumeCmd := exec.Command("docker", "volume", "create", VolumeName)
-	output, err := volumeCmd.CombinedOutput()
+	cli, err := dockerClient()
 	if err != nil {
-		// Check if error is because volume already exists
-		outputStr := strings.ToLower(string(output))
-		if strings.Contains(outputStr, "already exists") {
-			// Volume exists, that's fine - continue
-		} else {
-			return fmt.Errorf("failed to create volume: %w (output: %s)", err, string(output))
-		}
+		return err
 	}
+	ctx := context.Background()
 
-	// Run container
-	cmd := exec.Command("docker", "run", "-d",
-		"--name", ContainerName,
-		"-v", fmt.Sprintf("%s:%s", VolumeName, StoragePath),
-        "alpine:latest",
-		"tail", "-f", "/dev/null")
+	// Create volume; one that already exists is returned as is
+	start := time.Now()
+	_, err = cli.VolumeCreate(ctx, volume.CreateOptions{Name: VolumeName})
+	logCall("volume create "+VolumeName, start, err)
+	if err != nil {
+		return fmt.Errorf("failed to create volume: %w", err)
+	}
 
-	if err := cmd.Run(); err != nil {
+	hostConfig, err := containerOptions.hostConfig()
+	if err != nil {
+		return err
+	}
+	hostConfig.Mounts = []mount.Mount{{Type: mount.TypeVolume, Source: VolumeName, Target: StoragePath}}
+	config := &container.Config{Image: containerOptions.image(), Cmd: []string{"tail", "-f", "/dev/null"}}
+	create := func() error {
+		start := time.Now()
+		_, err := cli.ContainerCreate(ctx, config, hostConfig, nil, nil, ContainerName)
+		logCall("create "+ContainerName, start, err)
+		return err
+	}
+
+	// Pull the image first if it isn't here, as 'docker run' does
+	err = create()
+	if cerrdefs.IsNotFound(err) {
+		if err = pullImage(ctx, config.Image); err == nil {
+			err = create()
+		}
+	}
+	if err != nil {
 		return fmt.Errorf("failed to create container: %w", err)
 	}
+	return StartContainer()
+}
 
-	return nil
+// pullImage pulls an image, waiting for the pull to finish
+func pullImage(ctx context.Context, ref string) (err error) {
+	start := time.Now()
+	defer func() { logCall("pull "+ref, start, err) }()
+	cli, err := dockerClient()
+	if err != nil {
+		return err
+	}
+	progress, err := cli.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull %s: %w", ref, err)
+	}
+	defer progress.Close()
+
+	// The pull reports progress, and any failure, as a stream of JSON messages
+	decoder := json.NewDecoder(progress)
+	for {
+		var message struct {
+			Error string `json:"error"`
+		}
+		if err := decoder.Decode(&message); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to pull %s: %w", ref, err)
+		}
+		if message.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", ref, message.Error)
+		}
+	}
 }
 
 // StartContainer starts an existing container
 func StartContainer() error {
-	cmd := exec.Command("docker", "start", ContainerName)
-	if err := cmd.Run(); err != nil {
+	cli, err := dockerClient()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = cli.ContainerStart(context.Background(), ContainerName, container.StartOptions{})
+	logCall("start "+ContainerName, start, err)
+	if err != nil {
 		return fmt.Errorf("failed to start container: %w", err)
 	}
 	return nil
@@ -162,29 +253,136 @@ func StartContainer() error {
 
 // CopyToContainer copies a file from host to container
 func CopyToContainer(srcPath, destPath string) error {
-	containerPath := fmt.Sprintf("%s:%s", ContainerName, destPath)
-	cmd := exec.Command("docker", "cp", srcPath, containerPath)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to copy to container: %w", err)
+	_, err := CopyToContainerWithOptions(srcPath, destPath, CopyOptions{})
+	return err
+}
+
+// CopyToContainerWithOptions copies a file or directory from host to container like
+// CopyToContainer. With opts.Verify the source is checksummed before the copy and the
+// copy with sha256sum inside the container after it, and the bytes verified are
+// returned; destPath must then name the copy itself, i.e. the file, or the directory
+// the source's contents end up in.
+func CopyToContainerWithOptions(srcPath, destPath string, opts CopyOptions) (int64, error) {
+	var sums map[string]string
+	var sizes map[string]int64
+	if opts.Verify {
+		var err error
+		if sums, sizes, err = hostChecksums(srcPath); err != nil {
+			return 0, fmt.Errorf("failed to checksum %s: %w", srcPath, err)
+		}
 	}
-	return nil
+
+	err := withHeal(func() error { return copyToContainer(context.Background(), srcPath, destPath) })
+	if err != nil {
+		return 0, copyError("failed to copy to container", err, "")
+	}
+	if !opts.Verify {
+		return 0, nil
+	}
+
+	copied, err := containerChecksums(destPath)
+	if err != nil {
+		return 0, err
+	}
+	return compareChecksums(sums, copied, sizes, destPath)
 }
 
 // CopyFromContainer copies a file from container to host
 func CopyFromContainer(srcPath, destPath string) error {
-	containerPath := fmt.Sprintf("%s:%s", ContainerName, srcPath)
-	cmd := exec.Command("docker", "cp", containerPath, destPath)
-	if err := cmd.Run(); err != nil {
+	_, err := CopyFromContainerWithOptions(srcPath, destPath, CopyOptions{})
+	return err
+}
+
+// CopyFromContainerWithOptions copies a file or directory from container to host like
+// CopyFromContainer, verifying the copy against checksums taken inside the container
+// with opts.Verify, as CopyToContainerWithOptions does
+func CopyFromContainerWithOptions(srcPath, destPath string, opts CopyOptions) (int64, error) {
+	var sums map[string]string
+	if opts.Verify {
+		var err error
+		if sums, err = containerChecksums(srcPath); err != nil {
+			return 0, err
+		}
+	}
+
+	err := withHeal(func() error { return copyFromContainer(context.Background(), srcPath, destPath) })
+	if err != nil {
+		return 0, copyError("failed to copy from container", err, "")
+	}
+	if !opts.Verify {
+		return 0, nil
+	}
+
+	copied, sizes, err := hostChecksums(destPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to checksum %s: %w", destPath, err)
+	}
+	return compareChecksums(sums, copied, sizes, destPath)
+}
+
+// UploadToContainer streams r into a file inside the container. Unlike CopyToContainer
+// the data passes through the caller, so it can report progress.
+func UploadToContainer(r io.Reader, destPath string) error {
+	if err := StreamFromContainer(r, io.Discard, "sh", "-c", `cat > "$1"`, "sh", destPath); err != nil {
+		return fmt.Errorf("failed to copy to container: %w", err)
+	}
+	return nil
+}
+
+// DownloadFromContainer streams a file inside the container to w
+func DownloadFromContainer(srcPath string, w io.Writer) error {
+	if err := StreamFromContainer(nil, w, "cat", srcPath); err != nil {
 		return fmt.Errorf("failed to copy from container: %w", err)
 	}
 	return nil
 }
 
+// copyError describes a failed transfer, marking it as StorageFull when the output or
+// error shows the destination ran out of space, and wrapping os.ErrNotExist when the
+// source isn't there
+func copyError(msg string, err error, output string) error {
+	output = strings.TrimSpace(output)
+	if errs.IsNoSpace(output) || errs.IsNoSpace(err.Error()) {
+		if output == "" {
+			output = err.Error()
+		}
+		return errs.New(errs.StorageFull, "%s: no space left on device (%s)", msg, output)
+	}
+	if isNotExist(output) {
+		return fmt.Errorf("%s: %w (%s)", msg, os.ErrNotExist, output)
+	}
+	if errors.Is(err, os.ErrNotExist) || (cerrdefs.IsNotFound(err) && isNotExist(err.Error())) {
+		return fmt.Errorf("%s: %w (%v)", msg, os.ErrNotExist, err)
+	}
+	if output == "" {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	return fmt.Errorf("%s: %w (output: %s)", msg, err, output)
+}
+
+// isNotExist reports whether output says a file is missing: "No such file or
+// directory" from commands in the container, "Could not find the file" from a copy
+func isNotExist(output string) bool {
+	output = strings.ToLower(output)
+	return strings.Contains(output, "no such file or directory") || strings.Contains(output, "could not find the file")
+}
+
 // ExecInContainer executes a command inside the container
 func ExecInContainer(command ...string) (string, error) {
-	args := append([]string{"exec", ContainerName}, command...)
-	cmd := exec.Command("docker", args...)
-	output, err := cmd.CombinedOutput()
+	return ExecInContainerContext(context.Background(), command...)
+}
+
+// ExecInContainerContext executes a command inside the container, giving up when ctx
+// is done
+func ExecInContainerContext(ctx context.Context, command ...string) (string, error) {
+	var output []byte
+	err := withHeal(func() (err error) {
+		output, err = execOutput(ctx, command...)
+		return err
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return "", fmt.Errorf("failed to execute in container: %w", ctxErr)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to execute in container: %w", err)
 	}
@@ -199,15 +397,20 @@ func CreateDirectory(path string) error {
 
 // GetVolumeInfo returns information about the volume
 func GetVolumeInfo() (map[string]string, error) {
-	cmd := exec.Command("docker", "volume", "inspect", VolumeName, "--format", "{{.Mountpoint}}")
-	output, err := cmd.Output()
+	cli, err := dockerClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volume info: %w", err)
+	}
+	start := time.Now()
+	vol, err := cli.VolumeInspect(context.Background(), VolumeName)
+	logCall("volume inspect "+VolumeName, start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get volume info: %w", err)
 	}
 
 	info := map[string]string{
 		"name":       VolumeName,
-		"mountpoint": strings.TrimSpace(string(output)),
+		"mountpoint": vol.Mountpoint,
 	}
 
 	return info, nil
@@ -219,6 +422,23 @@ func PathExistsInContainer(path string) bool {
     return err == nil
 }
 
+// CheckPathInContainer reports whether a path exists inside the container. Unlike
+// PathExistsInContainer, a container that can't be reached is an error rather than a
+// missing path.
+func CheckPathInContainer(path string) (bool, error) {
+	output, err := ExecInContainer("sh", "-c", fmt.Sprintf("if [ -e %q ]; then echo yes; else echo no; fi", path))
+	if err != nil {
+		return false, err
+	}
+	switch strings.TrimSpace(output) {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected output checking %s: %q", path, output)
+}
+
 // DeleteDirectory deletes a directory and all its contents recursively inside the container
 func DeleteDirectory(path string) error {
     _, err := ExecInContainer("rm", "-rf", path)
@@ -229,38 +449,41 @@ func DeleteDirectory(path string) error {
 // It will automatically start Docker Desktop if needed (macOS)
 func EnsureDockerReady() error {
     if !IsDockerInstalled() {
-        return fmt.Errorf("Docker is required. Please install Docker %s or newer.", MinDockerSemver)
+        return errs.New(errs.DockerUnavailable, "Docker is required. Please install Docker %s or newer.", MinDockerSemver)
     }
     
     // Check if Docker daemon is running
     if !IsDockerDaemonRunning() {
         // Try to start Docker Desktop automatically (macOS)
-        fmt.Println("🐳 Docker is not running. Starting Docker Desktop...")
-        if err := StartDockerDesktop(); err != nil {
-            return fmt.Errorf("Docker is not running. Please start Docker Desktop manually: %w", err)
+        if err := ui.RunStep("Starting Docker Desktop", StartDockerDesktop); err != nil {
+            return errs.New(errs.DockerUnavailable, "Docker is not running. Please start Docker Desktop manually: %w", err)
         }
         
         // Wait for Docker to become available (max 30 seconds)
-        fmt.Println("⏳ Waiting for Docker to start...")
-        if err := WaitForDocker(30); err != nil {
-            return fmt.Errorf("Docker did not start in time. Please ensure Docker Desktop is running: %w", err)
+        if err := ui.RunStep("Waiting for Docker to start", func() error { return WaitForDocker(30) }); err != nil {
+            return errs.New(errs.DockerUnavailable, "Docker did not start in time. Please ensure Docker Desktop is running: %w", err)
         }
-        fmt.Println("✓ Docker is ready")
     }
     
     v, err := GetDockerVersion()
     if err != nil {
-        return fmt.Errorf("failed to read Docker version: %v", err)
+        return errs.New(errs.DockerUnavailable, "failed to read Docker version: %v", err)
     }
     if !versionGTE(v, MinDockerSemver) {
-        return fmt.Errorf("Docker %s or newer is required (found %s). Please upgrade.", MinDockerSemver, v)
+        return errs.New(errs.DockerUnavailable, "Docker %s or newer is required (found %s). Please upgrade.", MinDockerSemver, v)
     }
-    if !IsContainerRunning() {
-        if IsContainerExists() {
-            return StartContainer()
-        }
-        return CreateContainer()
-    }
-    return nil
+    // Recreate or restart the container if it was removed, stopped or lost its volume
+    _, err = Heal()
+    return err
 }
 
+
+// StreamFromContainer runs a command inside the container, feeding it stdin and
+// writing its stdout to w. Used for binary output too large to buffer in memory.
+func StreamFromContainer(stdin io.Reader, w io.Writer, command ...string) error {
+	var stderr bytes.Buffer
+	if err := runExec(context.Background(), stdin, w, &stderr, command...); err != nil {
+		return copyError("failed to execute in container", err, stderr.String())
+	}
+	return nil
+}
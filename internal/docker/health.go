@@ -0,0 +1,131 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	cerrdefs "github.com/containerd/errdefs"
+)
+
+// containerHealth is what inspecting the storage container reports about it
+type containerHealth struct {
+	Exists  bool
+	Status  string // created, running, paused, restarting, exited, dead
+	Mounted bool   // The storage volume is mounted at StoragePath
+}
+
+// inspectContainer reads the storage container's state and mounts
+func inspectContainer() (containerHealth, error) {
+	details, err := inspect()
+	if cerrdefs.IsNotFound(err) {
+		return containerHealth{}, nil
+	}
+	if err != nil {
+		return containerHealth{}, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	health := containerHealth{Exists: true}
+	if details.State != nil {
+		health.Status = details.State.Status
+	}
+	for _, m := range details.Mounts {
+		if m.Name == VolumeName && m.Destination == StoragePath {
+			health.Mounted = true
+		}
+	}
+	return health, nil
+}
+
+// Heal checks the storage container and fixes what it safely can: a removed container
+// is created again, a stopped or paused one is started, and one that won't start or
+// lost its volume mount is recreated. Stored data lives in the volume, so recreating
+// keeps it; a container whose storage isn't on the volume and isn't empty is left for
+// the user, since recreating it would lose data. Returns what was fixed.
+func Heal() ([]string, error) {
+	health, err := inspectContainer()
+	if err != nil {
+		return nil, err
+	}
+
+	fixed := []string{}
+	fix := func(label string, fn func() error) error {
+		if err := ui.RunStep(label, fn); err != nil {
+			return err
+		}
+		slog.Info("self-heal", "fixed", label)
+		fixed = append(fixed, label)
+		return nil
+	}
+
+	switch {
+	case !health.Exists && !IsVolumeExists():
+		err = fix("Creating storage container (storage volume was missing, it starts empty)", CreateContainer)
+	case !health.Exists:
+		err = fix("Creating storage container", CreateContainer)
+	case !health.Mounted:
+		// The container's own storage can only be checked while it runs
+		if health.Status != "running" && StartContainer() != nil {
+			return fixed, errs.New(errs.DockerUnavailable,
+				"storage container %s doesn't have volume %s mounted at %s and won't start; check it with 'docker inspect %s'",
+				ContainerName, VolumeName, StoragePath, ContainerName)
+		}
+		var output strings.Builder
+		if runExec(context.Background(), nil, &output, io.Discard, "ls", "-A", StoragePath); len(strings.TrimSpace(output.String())) > 0 {
+			return fixed, errs.New(errs.DockerUnavailable,
+				"storage container %s doesn't have volume %s mounted at %s but holds data there; back it up with 'docker cp %s:%s <dir>', then remove the container",
+				ContainerName, VolumeName, StoragePath, ContainerName, StoragePath)
+		}
+		err = fix("Recreating storage container (volume not mounted)", RecreateContainer)
+	case health.Status == "paused":
+		err = fix("Resuming storage container", unpauseContainer)
+	case health.Status != "running":
+		err = fix("Starting storage container", StartContainer)
+		if err != nil {
+			err = fix("Recreating storage container (it would not start)", RecreateContainer)
+		}
+	}
+	if err != nil {
+		return fixed, errs.Wrap(errs.DockerUnavailable, err)
+	}
+	return fixed, nil
+}
+
+// unpauseContainer resumes the paused storage container
+func unpauseContainer() error {
+	cli, err := dockerClient()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = cli.ContainerUnpause(context.Background(), ContainerName)
+	logCall("unpause "+ContainerName, start, err)
+	return err
+}
+
+// containerGone reports whether a Docker call failed because the storage container is
+// missing, stopped or paused, which Heal can fix
+func containerGone(err error) bool {
+	message := strings.ToLower(err.Error())
+	if cerrdefs.IsNotFound(err) && strings.Contains(message, "no such container") {
+		return true
+	}
+	return cerrdefs.IsConflict(err) && (strings.Contains(message, "is not running") || strings.Contains(message, "is paused"))
+}
+
+// withHeal makes a Docker call and, if it failed because the container went away
+// mid-operation, heals the container and makes it once more
+func withHeal(run func() error) error {
+	err := run()
+	if err == nil || !containerGone(err) {
+		return err
+	}
+	if _, healErr := Heal(); healErr != nil {
+		return err
+	}
+	return run()
+}
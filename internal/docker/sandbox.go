@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+)
+
+// Default names of the storage container and volume
+const (
+	defaultContainerName = "vervids-storage"
+	defaultVolumeName    = "vervids-data"
+)
+
+// UseSandbox switches every following Docker call to a container and volume of their
+// own, named after name, so nothing touches the real vault. Used by 'vervids demo'.
+func UseSandbox(name string) {
+	ContainerName = fmt.Sprintf("vervids-%s-storage", name)
+	VolumeName = fmt.Sprintf("vervids-%s-data", name)
+}
+
+// IsSandbox reports whether UseSandbox is in effect
+func IsSandbox() bool {
+	return ContainerName != defaultContainerName || VolumeName != defaultVolumeName
+}
+
+// RemoveSandbox deletes the sandbox container and its volume with everything stored in
+// it. It refuses to touch the real vault.
+func RemoveSandbox() error {
+	if !IsSandbox() {
+		return fmt.Errorf("not using a sandbox; refusing to remove %s", VolumeName)
+	}
+	if err := removeContainer(); err != nil {
+		return fmt.Errorf("failed to remove container %s: %w", ContainerName, err)
+	}
+	cli, err := dockerClient()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = cli.VolumeRemove(context.Background(), VolumeName, false)
+	logCall("volume remove "+VolumeName, start, err)
+	if err != nil && !cerrdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to remove volume %s: %w", VolumeName, err)
+	}
+	return nil
+}
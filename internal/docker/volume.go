@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Volume is the Docker volume mounted in the storage container, used through the
+// storage.Storage interface
+type Volume struct{}
+
+// Name describes the backend
+func (Volume) Name() string {
+	return "Docker"
+}
+
+// EnsureReady checks Docker and the storage container, starting them if needed
+func (Volume) EnsureReady() error {
+	return EnsureDockerReady()
+}
+
+// Exists reports whether path exists in the container
+func (Volume) Exists(path string) (bool, error) {
+	return CheckPathInContainer(path)
+}
+
+// MakeDir creates a directory in the container
+func (Volume) MakeDir(path string) error {
+	return CreateDirectory(path)
+}
+
+// PutFile copies a local file into the container, under a temporary name first so an
+// interrupted copy never leaves a partial file at path
+func (Volume) PutFile(localPath string, path string) error {
+	partialPath := path + ".partial"
+	if err := CopyToContainer(localPath, partialPath); err != nil {
+		return err
+	}
+	return finishPut(partialPath, path)
+}
+
+// Put streams r into a file in the container, under a temporary name first like
+// PutFile. The size isn't needed.
+func (Volume) Put(r io.Reader, size int64, path string) error {
+	partialPath := path + ".partial"
+	if err := UploadToContainer(r, partialPath); err != nil {
+		return err
+	}
+	return finishPut(partialPath, path)
+}
+
+// finishPut moves a completed upload into place
+func finishPut(partialPath string, path string) error {
+	if _, err := ExecInContainer("mv", "-f", partialPath, path); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PutDir copies a local directory's contents into dir in one batch; "dir/." merges
+// into what is already there
+func (Volume) PutDir(localDir string, dir string) error {
+	return CopyToContainer(localDir+string(filepath.Separator)+".", dir)
+}
+
+// GetFile copies a file out of the container
+func (Volume) GetFile(path string, localPath string) error {
+	return CopyFromContainer(path, localPath)
+}
+
+// Get streams a file out of the container to w
+func (Volume) Get(path string, w io.Writer) error {
+	return DownloadFromContainer(path, w)
+}
+
+// Concat streams the named files out of the container in one exec
+func (Volume) Concat(dir string, names []string, w io.Writer) error {
+	list := strings.Join(names, "\n") + "\n"
+	return StreamFromContainer(strings.NewReader(list), w, "sh", "-c", fmt.Sprintf("cd %q && xargs cat", dir))
+}
+
+// List returns every file under dir in the container
+func (Volume) List(dir string) ([]string, error) {
+	output, err := ExecInContainer("find", dir, "-type", "f")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// Remove deletes a file or directory tree in the container
+func (Volume) Remove(path string) error {
+	return DeleteDirectory(path)
+}
@@ -0,0 +1,97 @@
+// Package errs categorizes failures so the CLI can exit with a code that tells scripts
+// what went wrong, rather than making them parse styled messages.
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kind is the category of a failure
+type Kind int
+
+const (
+	Unknown Kind = iota
+	DockerUnavailable
+	ProjectNotFound
+	VersionNotFound
+	StorageFull
+	Conflict
+	ReadOnly
+	StorageUnavailable
+)
+
+// Process exit codes. 1 is any other failure; 2 is left for usage errors.
+var exitCodes = map[Kind]int{
+	Unknown:            1,
+	DockerUnavailable:  3,
+	ProjectNotFound:    4,
+	VersionNotFound:    5,
+	StorageFull:        6,
+	Conflict:           7,
+	ReadOnly:           8,
+	StorageUnavailable: 9,
+}
+
+var names = map[Kind]string{
+	Unknown:            "error",
+	DockerUnavailable:  "docker_unavailable",
+	ProjectNotFound:    "project_not_found",
+	VersionNotFound:    "version_not_found",
+	StorageFull:        "storage_full",
+	Conflict:           "conflict",
+	ReadOnly:           "read_only",
+	StorageUnavailable: "storage_unavailable",
+}
+
+// ExitCode returns the process exit code for the kind
+func (k Kind) ExitCode() int {
+	return exitCodes[k]
+}
+
+// String returns the kind's machine-readable name, e.g. "version_not_found"
+func (k Kind) String() string {
+	return names[k]
+}
+
+// Error is an error with a category
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns an error of the given kind, formatted like fmt.Errorf (so %w wraps)
+func New(kind Kind, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap categorizes err. A nil err stays nil.
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// KindOf returns the category of the outermost categorized error in err's chain, or Unknown
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Unknown
+}
+
+// IsNoSpace reports whether command output says the destination ran out of space
+func IsNoSpace(output string) bool {
+	return strings.Contains(strings.ToLower(output), "no space left on device")
+}
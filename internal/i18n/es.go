@@ -0,0 +1,153 @@
+package i18n
+
+// spanish holds the Spanish (es) translations
+var spanish = map[string]string{
+	"  Missing: %d asset(s) not stored":                                              "  Faltantes: %d recurso(s) sin guardar",
+	"  Or use 'vervids delete <project-name>' to delete the project and start fresh": "  O usa 'vervids delete <nombre-del-proyecto>' para borrar el proyecto y empezar de cero",
+	"  Removing it to start fresh...":                                                "  Eliminándolo para empezar de cero...",
+	"  Removing old project to initialize new one...":                                "  Eliminando el proyecto anterior para inicializar el nuevo...",
+	"  Storage: Docker":                                                              "  Almacenamiento: Docker",
+	"  Storage: Local":                                                               "  Almacenamiento: local",
+	"  Use 'vervids commit \"message\" <file.aepx>' to save new versions":            "  Usa 'vervids commit \"mensaje\" <archivo.aepx>' para guardar nuevas versiones",
+	"  • All assets removed from Docker":                                             "  • Todos los recursos eliminados de Docker",
+	"  • All versions removed from Docker":                                           "  • Todas las versiones eliminadas de Docker",
+	"  • Local .vervids directory removed (if found)":                                "  • Directorio local .vervids eliminado (si existía)",
+	"  • Make changes to your .aepx file or assets":                                  "  • Haz cambios en tu archivo .aepx o en sus recursos",
+	"  • Use 'vervids commit \"message\" <file.aepx>' to save a new version":         "  • Usa 'vervids commit \"mensaje\" <archivo.aepx>' para guardar una nueva versión",
+	"  • Use 'vervids help' to see all available commands":                           "  • Usa 'vervids help' para ver todos los comandos",
+	"  • Use 'vervids init <file.aepx>' to initialize a new project":                 "  • Usa 'vervids init <archivo.aepx>' para inicializar un proyecto nuevo",
+	"  • vervids commit \"message\" <file.aepx> - Commit a new version":              "  • vervids commit \"mensaje\" <archivo.aepx> - Guardar una nueva versión",
+	"  • vervids help - Show all commands":                                           "  • vervids help - Mostrar todos los comandos",
+	"  • vervids list - List all projects":                                           "  • vervids list - Listar todos los proyectos",
+	"  • vervids pull <version> - Pull a version from Docker":                        "  • vervids pull <versión> - Recuperar una versión desde Docker",
+	"  • vervids show <version> - Show version details":                              "  • vervids show <versión> - Mostrar los detalles de una versión",
+	"#   Date        %-*s  Size(MB)     Change  Assets":                              "#   Fecha       %-*s  Tamaño(MB)   Cambio  Recursos",
+	"%d expression(s) changed":                                                       "%d expresión(es) modificada(s)",
+	"'%s' does not appear in any version":                                            "'%s' no aparece en ninguna versión",
+	"'%s' is not pinned":                                                             "'%s' no está fijado",
+	"--strict and --allow-missing cannot be used together":                           "--strict y --allow-missing no se pueden usar juntos",
+	"Added global mapping: %s -> %s":                                                 "Mapeo global añadido: %s -> %s",
+	"Added mapping for %s: %s -> %s":                                                 "Mapeo añadido para %s: %s -> %s",
+	"All %d version(s) OK":                                                           "Las %d versión(es) están bien",
+	"Assets:":                                                                        "Recursos:",
+	"Available commands:":                                                            "Comandos disponibles:",
+	"Available projects:":                                                            "Proyectos disponibles:",
+	"Committed version %d":                                                           "Versión %d guardada",
+	"Composition changes from %s to %s:":                                             "Cambios de composiciones de %s a %s:",
+	"Could not find config file for project: %s":                                     "No se encontró el archivo de configuración del proyecto: %s",
+	"Could not find config.json for project '%s'":                                    "No se encontró config.json del proyecto '%s'",
+	"Current project: %s":                                                            "Proyecto actual: %s",
+	"Deleted label '%s' (was on %d version(s))":                                      "Etiqueta '%s' eliminada (estaba en %d versión(es))",
+	"Deletion cancelled (confirmation did not match)":                                "Eliminación cancelada (la confirmación no coincide)",
+	"Error checking tracking: %v":                                                    "Error al comprobar el seguimiento: %v",
+	"Error committing version: %v":                                                   "Error al guardar la versión: %v",
+	"Error deleting project: %v":                                                     "Error al borrar el proyecto: %v",
+	"Error getting absolute path: %v":                                                "Error al obtener la ruta absoluta: %v",
+	"Error getting current directory: %v":                                            "Error al obtener el directorio actual: %v",
+	"Error getting projects: %v":                                                     "Error al obtener los proyectos: %v",
+	"Error initializing project: %v":                                                 "Error al inicializar el proyecto: %v",
+	"Error loading project: %v":                                                      "Error al cargar el proyecto: %v",
+	"Error pruning: %v":                                                              "Error al depurar: %v",
+	"Error pulling version: %v":                                                      "Error al recuperar la versión: %v",
+	"Error reading input: %v":                                                        "Error al leer la entrada: %v",
+	"Error removing existing .vervids directory: %v":                                 "Error al eliminar el directorio .vervids existente: %v",
+	"Error saving context: %v":                                                       "Error al guardar el contexto: %v",
+	"Error saving project: %v":                                                       "Error al guardar el proyecto: %v",
+	"Error saving settings: %v":                                                      "Error al guardar los ajustes: %v",
+	"Error: %v":                                                                      "Error: %v",
+	"Error: Cannot access directory '%s': %v":                                        "Error: no se puede acceder al directorio '%s': %v",
+	"Expression changes from %s to %s:":                                              "Cambios de expresiones de %s a %s:",
+	"Failed to start server: %v":                                                     "No se pudo iniciar el servidor: %v",
+	"File '%s' does not exist":                                                       "El archivo '%s' no existe",
+	"File must have .aepx extension":                                                 "El archivo debe tener la extensión .aepx",
+	"Force flag detected: removing existing .vervids directory...":                   "Opción --force detectada: eliminando el directorio .vervids existente...",
+	"Found .vervids directory but couldn't load project":                             "Se encontró el directorio .vervids pero no se pudo cargar el proyecto",
+	"Found existing project for a different file":                                    "Ya existe un proyecto para otro archivo",
+	"Global mappings:":                                                               "Mapeos globales:",
+	"Growth: %.2f MB → %.2f MB across %d version(s)":                                 "Crecimiento: %.2f MB → %.2f MB en %d versión(es)",
+	"Initial version stored (v000)":                                                  "Versión inicial guardada (v000)",
+	"Initialized vervids project":                                                    "Proyecto vervids inicializado",
+	"Invalid port number: %v":                                                        "Número de puerto no válido: %v",
+	"Invalid project number":                                                         "Número de proyecto no válido",
+	"Layer changes since previous version:":                                          "Cambios de capas desde la versión anterior:",
+	"Markers and notes:":                                                             "Marcadores y notas:",
+	"Missing at commit (%d):":                                                        "Faltaban al guardar (%d):",
+	"No assets in this version":                                                      "Esta versión no tiene recursos",
+	"No commits match the filter":                                                    "Ninguna versión coincide con el filtro",
+	"No commits yet. Use 'vervids commit \"message\" <file.aepx>' to create one.":    "Todavía no hay versiones. Usa 'vervids commit \"mensaje\" <archivo.aepx>' para crear una.",
+	"No composition changes between %s and %s":                                       "No hay cambios de composiciones entre %s y %s",
+	"No expression changes between %s and %s":                                        "No hay cambios de expresiones entre %s y %s",
+	"No global mapping for '%s'":                                                     "No hay un mapeo global para '%s'",
+	"No mapping for '%s' in %s":                                                      "No hay un mapeo para '%s' en %s",
+	"No pinned assets":                                                               "No hay recursos fijados",
+	"No project selected. Use 'vervids list' to select a project.":                   "No hay ningún proyecto seleccionado. Usa 'vervids list' para elegir uno.",
+	"No projects available. Use 'vervids init <file.aepx>' to create a project first.": "No hay proyectos. Usa 'vervids init <archivo.aepx>' para crear uno primero.",
+	"No projects found in Docker storage.":                                             "No se encontraron proyectos en el almacenamiento de Docker.",
+	"No projects found in Docker storage or on this machine.":                          "No se encontraron proyectos en el almacenamiento de Docker ni en esta máquina.",
+	"No projects found.":                                                  "No se encontraron proyectos.",
+	"Note: .aepx file '%s' was already removed":                           "Nota: el archivo .aepx '%s' ya se había eliminado",
+	"Note: vervids works with .aepx (XML) files, not binary .ae files":    "Nota: vervids trabaja con archivos .aepx (XML), no con archivos .ae binarios",
+	"Nothing removed":                                                     "No se eliminó nada",
+	"Nothing to compare. Use --comps and/or --expressions.":               "Nada que comparar. Usa --comps y/o --expressions.",
+	"Nothing to prune; all versions present in %s":                        "Nada que depurar; todas las versiones están en %s",
+	"Nothing to push":                                                     "Nada que enviar",
+	"Pinned %s (content from v%d)":                                        "%s fijado (contenido de v%d)",
+	"Pinned asset %s is missing in Docker (%s)":                           "El recurso fijado %s falta en Docker (%s)",
+	"Pinned assets (%d):":                                                 "Recursos fijados (%d):",
+	"Please ensure you have write access to the directory.":               "Asegúrate de tener permiso de escritura en el directorio.",
+	"Please enter a number between 1 and %d":                              "Introduce un número entre 1 y %d",
+	"Port must be between 1 and 65535":                                    "El puerto debe estar entre 1 y 65535",
+	"Project '%s' not found":                                              "No se encontró el proyecto '%s'",
+	"Project context saved":                                               "Contexto del proyecto guardado",
+	"Project deleted successfully":                                        "Proyecto borrado correctamente",
+	"Project mappings (%s):":                                              "Mapeos del proyecto (%s):",
+	"Project number %d does not exist (1-%d)":                             "El proyecto número %d no existe (1-%d)",
+	"Project number must be an integer":                                   "El número de proyecto debe ser un entero",
+	"Projects in Docker storage:":                                         "Proyectos en el almacenamiento de Docker:",
+	"Pruned %d missing version(s)":                                        "%d versión(es) faltante(s) depurada(s)",
+	"Regenerate tracking for these versions from config.json? (y/N): ":    "¿Regenerar el seguimiento de estas versiones desde config.json? (y/N): ",
+	"Regenerated tracking for %d version(s)":                              "Seguimiento regenerado para %d versión(es)",
+	"Remove these items? (y/N): ":                                         "¿Eliminar estos elementos? (y/N): ",
+	"Removed existing .vervids directory":                                 "Directorio .vervids existente eliminado",
+	"Removed global mapping for %s":                                       "Mapeo global eliminado para %s",
+	"Removed label '%s' from %d version(s)":                               "Etiqueta '%s' quitada de %d versión(es)",
+	"Removed mapping for %s":                                              "Mapeo eliminado para %s",
+	"Restored %s from v%d":                                                "%s recuperado de la v%d",
+	"Run 'vervids fsck --repair' to regenerate tracking from config.json": "Ejecuta 'vervids fsck --repair' para regenerar el seguimiento desde config.json",
+	"Run 'vervids prune' to remove versions with missing storage":         "Ejecuta 'vervids prune' para quitar las versiones sin almacenamiento",
+	"Run 'vervids push' when Docker is available to store it.":            "Ejecuta 'vervids push' cuando Docker esté disponible para guardarla.",
+	"Select a project to work with:":                                      "Elige un proyecto con el que trabajar:",
+	"Selected project: %s":                                                "Proyecto seleccionado: %s",
+	"Settings: %v":                                                        "Ajustes: %v",
+	"Staged version offline":                                              "Versión preparada sin conexión",
+	"Switch to a different project? Enter project number (or press Enter to skip): ": "¿Cambiar de proyecto? Introduce el número (o pulsa Enter para omitir): ",
+	"Switched to project: %s": "Cambiado al proyecto: %s",
+	"The project exists in Docker storage, but the local config file is missing.":            "El proyecto existe en Docker, pero falta el archivo de configuración local.",
+	"This may be a permissions issue. Please ensure you have write access to the directory.": "Puede ser un problema de permisos. Asegúrate de tener permiso de escritura en el directorio.",
+	"This project file is already initialized":                                               "Este archivo de proyecto ya está inicializado",
+	"Tip: Navigate to the project directory, or ensure .vervids/config.json exists.":         "Consejo: ve al directorio del proyecto o comprueba que exista .vervids/config.json.",
+	"To get started:":            "Para empezar:",
+	"Type 'DELETE' to confirm: ": "Escribe 'DELETE' para confirmar: ",
+	"Unpinned %s":                "%s ya no está fijado",
+	"Update available: %s":       "Actualización disponible: %s",
+	"Use 'vervids init <file.aepx>' to create a project.":       "Usa 'vervids init <archivo.aepx>' para crear un proyecto.",
+	"Use 'vervids list <number>' to see commits for a project":  "Usa 'vervids list <número>' para ver las versiones de un proyecto",
+	"Version must be a number":                                  "La versión debe ser un número",
+	"Version-number must be an integer (e.g., 0, 1, 2)":         "El número de versión debe ser un entero (p. ej., 0, 1, 2)",
+	"WARNING: This will permanently delete all project data!\n": "ATENCIÓN: ¡esto borrará todos los datos del proyecto de forma permanente!\n",
+	"Warning: Could not delete .aepx file '%s': %v":             "Atención: no se pudo borrar el archivo .aepx '%s': %v",
+	"Warning: Could not restore original directory: %v":         "Atención: no se pudo volver al directorio original: %v",
+	"Warning: Could not save project context: %v":               "Atención: no se pudo guardar el contexto del proyecto: %v",
+	"v%d: failed to regenerate tracking: %v":                    "v%d: no se pudo regenerar el seguimiento: %v",
+	"v%d: storage missing in Docker (%s)":                       "v%d: falta el almacenamiento en Docker (%s)",
+	"v%d: tracking unavailable (%v)":                            "v%d: seguimiento no disponible (%v)",
+	"✓ Deleted .aepx file: %s":                                  "✓ Archivo .aepx borrado: %s",
+	"✓ Successfully pulled version %d":                          "✓ Versión %d recuperada correctamente",
+	"📝 Next steps:":                                             "📝 Siguientes pasos:",
+	"📦 Creating new version...":                                 "📦 Creando nueva versión...",
+	"📦 Pulling version %d...":                                   "📦 Recuperando la versión %d...",
+	"📦 Staging version offline...":                              "📦 Preparando la versión sin conexión...",
+	"🗑️  Deleting project...":                                   "🗑️  Borrando el proyecto...",
+	"🚀 Initializing vervids project (Docker storage)...":        "🚀 Inicializando el proyecto vervids (almacenamiento Docker)...",
+	"🚀 Initializing vervids project (storage: %s)...":           "🚀 Inicializando el proyecto vervids (almacenamiento: %s)...",
+}
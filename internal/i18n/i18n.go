@@ -0,0 +1,80 @@
+// Package i18n translates user-facing messages. Messages are looked up by their English
+// text, so a message without a translation is shown in English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// catalogs maps a language code to its translations, keyed by the English message
+var catalogs = map[string]map[string]string{
+	"es": spanish,
+}
+
+// language is the active language; "en" uses the messages as written
+var language = "en"
+
+// T translates format into the active language and formats it with args like fmt.Sprintf
+func T(format string, args ...interface{}) string {
+	if translated, ok := catalogs[language][format]; ok {
+		format = translated
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// SetLanguage switches the active language. Locale names such as "es_ES.UTF-8" are
+// reduced to their language code; unsupported languages fall back to English.
+func SetLanguage(lang string) error {
+	code := normalize(lang)
+	if code == "" || code == "en" || code == "c" || code == "posix" {
+		language = "en"
+		return nil
+	}
+	if _, ok := catalogs[code]; !ok {
+		language = "en"
+		return fmt.Errorf("unsupported language '%s' (available: %s)", lang, strings.Join(Languages(), ", "))
+	}
+	language = code
+	return nil
+}
+
+// Language returns the active language code
+func Language() string {
+	return language
+}
+
+// Languages returns the supported language codes, sorted
+func Languages() []string {
+	langs := []string{"en"}
+	for code := range catalogs {
+		langs = append(langs, code)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Detect returns the language requested by the environment: VERVIDS_LANG, then the
+// usual LC_ALL, LC_MESSAGES and LANG locale variables
+func Detect() string {
+	for _, env := range []string{"VERVIDS_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// normalize turns a locale name into a lowercase language code
+func normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
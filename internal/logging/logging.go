@@ -0,0 +1,115 @@
+// Package logging sets up leveled logging (log/slog) for the CLI. By default nothing is
+// logged; --verbose and --debug log to stderr, and logs can also be appended to a file
+// under ~/.vervids/logs for debugging failed commits after the fact.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+const LogsDir = "logs"
+
+// Options controls where logs go and how much is logged
+type Options struct {
+	Verbose bool // Log operations such as Docker calls, with timings, to stderr
+	Debug   bool // Also log debug detail to stderr
+	File    bool // Append debug-level logs to ~/.vervids/logs
+}
+
+// Setup installs the default slog logger according to opts and returns the path of
+// the log file, if one is written
+func Setup(opts Options) (string, error) {
+	handlers := []slog.Handler{}
+	switch {
+	case opts.Debug:
+		handlers = append(handlers, slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	case opts.Verbose:
+		handlers = append(handlers, slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	}
+
+	logPath := ""
+	if opts.File {
+		dir := GetLogsDir()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create logs directory: %w", err)
+		}
+		logPath = filepath.Join(dir, fmt.Sprintf("vervids-%s.log", time.Now().Format("2006-01-02")))
+		f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return "", fmt.Errorf("failed to open log file: %w", err)
+		}
+		// Left open for the life of the process
+		handlers = append(handlers, slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+
+	if len(handlers) == 0 {
+		slog.SetDefault(slog.New(discardHandler{}))
+		return "", nil
+	}
+	slog.SetDefault(slog.New(teeHandler(handlers)))
+	return logPath, nil
+}
+
+// GetLogsDir returns the directory log files are written to
+func GetLogsDir() string {
+	return filepath.Join(storage.GetUserDir(), LogsDir)
+}
+
+// Since returns the time elapsed since start, rounded for logs
+func Since(start time.Time) time.Duration {
+	return time.Since(start).Round(time.Millisecond)
+}
+
+// teeHandler sends each record to every handler that accepts its level
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			if err := h.Handle(ctx, r.Clone()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+// discardHandler drops every record
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
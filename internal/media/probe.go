@@ -0,0 +1,111 @@
+package media
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Info holds technical metadata for a media file, as reported by ffprobe
+type Info struct {
+	Codec    string  `json:"codec,omitempty"`
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	Duration float64 `json:"duration,omitempty"` // Seconds; zero for stills
+}
+
+// probeExtensions are the asset types worth probing
+var probeExtensions = map[string]bool{
+	".mov": true, ".mp4": true, ".m4v": true, ".avi": true, ".mxf": true,
+	".mkv": true, ".webm": true, ".mpg": true, ".mpeg": true, ".wmv": true,
+	".wav": true, ".mp3": true, ".aac": true, ".aif": true, ".aiff": true, ".m4a": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".tif": true, ".tiff": true,
+	".exr": true, ".dpx": true, ".tga": true, ".psd": true, ".gif": true,
+}
+
+// IsProbeable reports whether a file extension is media ffprobe can describe
+func IsProbeable(ext string) bool {
+	return probeExtensions[strings.ToLower(ext)]
+}
+
+// IsFFprobeInstalled checks if ffprobe is available on PATH
+func IsFFprobeInstalled() bool {
+	cmd := exec.Command("ffprobe", "-version")
+	return cmd.Run() == nil
+}
+
+// ffprobeOutput is the subset of `ffprobe -print_format json` output we read
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+		Duration  string `json:"duration"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// Probe reads codec, resolution and duration from the first video stream of a file,
+// falling back to the first audio stream for sound files
+func Probe(path string) (*Info, error) {
+	cmd := exec.Command("ffprobe", "-v", "error",
+		"-show_entries", "stream=codec_type,codec_name,width,height,duration:format=duration",
+		"-print_format", "json",
+		path)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var probed ffprobeOutput
+	if err := json.Unmarshal(output, &probed); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	info := &Info{}
+	for _, kind := range []string{"video", "audio"} {
+		for _, s := range probed.Streams {
+			if s.CodecType != kind {
+				continue
+			}
+			info.Codec = s.CodecName
+			info.Width = s.Width
+			info.Height = s.Height
+			info.Duration, _ = strconv.ParseFloat(s.Duration, 64)
+			break
+		}
+		if info.Codec != "" {
+			break
+		}
+	}
+	if info.Codec == "" {
+		return nil, fmt.Errorf("no video or audio stream found")
+	}
+	if info.Duration == 0 {
+		info.Duration, _ = strconv.ParseFloat(probed.Format.Duration, 64)
+	}
+	return info, nil
+}
+
+// Resolution formats the frame size as WIDTHxHEIGHT, or "" for audio
+func (i *Info) Resolution() string {
+	if i.Width == 0 || i.Height == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", i.Width, i.Height)
+}
+
+// FormatDuration formats a duration in seconds as m:ss.ms, or "" for stills
+func (i *Info) FormatDuration() string {
+	if i.Duration <= 0 {
+		return ""
+	}
+	minutes := int(i.Duration) / 60
+	seconds := i.Duration - float64(minutes*60)
+	return fmt.Sprintf("%d:%06.3f", minutes, seconds)
+}
@@ -0,0 +1,174 @@
+// Package merge applies line-based changes between versions of a text file, such as
+// undoing one version's edits to an .aepx while keeping everything changed since
+package merge
+
+import "strings"
+
+// maxCells bounds the line comparison table; larger differences are treated as one
+// changed block rather than compared line by line
+const maxCells = 4 << 20
+
+// hunk records that lines [a0,a1) of one text were replaced by lines [b0,b1) of another
+type hunk struct {
+	a0, a1 int
+	b0, b1 int
+}
+
+// SplitLines splits text into lines, keeping each line's ending so joining them
+// restores the text exactly
+func SplitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Revert undoes the edit that turned base into changed, applied to current (a later
+// text that descends from changed). Edits whose lines were changed again in current
+// can't be undone safely; their number is returned as conflicts and the result is nil.
+func Revert(base, changed, current []string) (result []string, conflicts int) {
+	hunks := diff(changed, base)
+	if len(hunks) == 0 {
+		return append([]string{}, current...), 0
+	}
+
+	// Where each line of changed ended up in current, if it is still there unchanged
+	position := make([]int, len(changed))
+	for i := range position {
+		position[i] = -1
+	}
+	for _, m := range matches(changed, current) {
+		position[m[0]] = m[1]
+	}
+
+	type splice struct {
+		c0, c1 int
+		lines  []string
+	}
+	splices := []splice{}
+	for _, h := range hunks {
+		c0, c1, ok := locate(h, position, len(current))
+		if !ok || (len(splices) > 0 && c0 < splices[len(splices)-1].c1) {
+			conflicts++
+			continue
+		}
+		splices = append(splices, splice{c0, c1, base[h.b0:h.b1]})
+	}
+	if conflicts > 0 {
+		return nil, conflicts
+	}
+
+	result = make([]string, 0, len(current))
+	next := 0
+	for _, s := range splices {
+		result = append(result, current[next:s.c0]...)
+		result = append(result, s.lines...)
+		next = s.c1
+	}
+	return append(result, current[next:]...), 0
+}
+
+// locate finds the lines of current corresponding to the hunk's lines of changed. The
+// hunk applies only if those lines are all still present, in one unbroken run; a pure
+// insertion needs its neighbouring lines to still be adjacent.
+func locate(h hunk, position []int, currentLen int) (int, int, bool) {
+	if h.a1 > h.a0 {
+		for i := h.a0; i < h.a1; i++ {
+			if position[i] < 0 || (i > h.a0 && position[i] != position[i-1]+1) {
+				return 0, 0, false
+			}
+		}
+		return position[h.a0], position[h.a1-1] + 1, true
+	}
+
+	c := 0
+	if h.a0 > 0 {
+		if position[h.a0-1] < 0 {
+			return 0, 0, false
+		}
+		c = position[h.a0-1] + 1
+	}
+	if h.a0 < len(position) {
+		if position[h.a0] < 0 || (h.a0 > 0 && position[h.a0] != c) {
+			return 0, 0, false
+		}
+		c = position[h.a0]
+	} else if h.a0 == 0 {
+		c = currentLen
+	}
+	return c, c, true
+}
+
+// diff returns the blocks of a that differ from b
+func diff(a, b []string) []hunk {
+	hunks := []hunk{}
+	ai, bi := 0, 0
+	for _, m := range append(matches(a, b), [2]int{len(a), len(b)}) {
+		if m[0] > ai || m[1] > bi {
+			hunks = append(hunks, hunk{ai, m[0], bi, m[1]})
+		}
+		ai, bi = m[0]+1, m[1]+1
+	}
+	return hunks
+}
+
+// matches pairs up the lines a and b have in common, in order: the longest common
+// subsequence after trimming the shared start and end
+func matches(a, b []string) [][2]int {
+	pairs := [][2]int{}
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		pairs = append(pairs, [2]int{prefix, prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(am) > 0 && len(bm) > 0 && len(am)*len(bm) <= maxCells {
+		for _, m := range lcs(am, bm) {
+			pairs = append(pairs, [2]int{prefix + m[0], prefix + m[1]})
+		}
+	}
+
+	for i := suffix; i > 0; i-- {
+		pairs = append(pairs, [2]int{len(a) - i, len(b) - i})
+	}
+	return pairs
+}
+
+// lcs returns the index pairs of a longest common subsequence of a and b
+func lcs(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	width := m + 1
+	table := make([]int32, (n+1)*width) // table[i][j]: LCS length of a[i:] and b[j:]
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i*width+j] = table[(i+1)*width+j+1] + 1
+			} else if down, right := table[(i+1)*width+j], table[i*width+j+1]; down >= right {
+				table[i*width+j] = down
+			} else {
+				table[i*width+j] = right
+			}
+		}
+	}
+
+	pairs := [][2]int{}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case table[(i+1)*width+j] >= table[i*width+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
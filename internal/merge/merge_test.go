@@ -0,0 +1,130 @@
+package merge
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lines splits a text written with | for line breaks
+func lines(text string) []string {
+	return SplitLines(strings.ReplaceAll(text, "|", "\n"))
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\nb", []string{"a\n", "b"}},
+		{"a\r\nb\n", []string{"a\r\n", "b\n"}},
+	}
+	for _, tt := range tests {
+		got := SplitLines(tt.text)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitLines(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if joined := strings.Join(got, ""); joined != tt.text {
+			t.Errorf("SplitLines(%q) joins back to %q", tt.text, joined)
+		}
+	}
+}
+
+func TestRevert(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          string // Parent of the reverted version
+		changed       string // The reverted version
+		current       string // Latest version, descended from changed
+		want          string
+		wantConflicts int
+	}{
+		{
+			name: "no change to undo", base: "a|b|c|", changed: "a|b|c|", current: "a|b|c|x|",
+			want: "a|b|c|x|",
+		},
+		{
+			name: "undo an edit", base: "a|b|c|", changed: "a|B|c|", current: "a|B|c|",
+			want: "a|b|c|",
+		},
+		{
+			name: "undo an addition", base: "a|c|", changed: "a|b|c|", current: "a|b|c|",
+			want: "a|c|",
+		},
+		{
+			name: "undo a removal", base: "a|b|c|", changed: "a|c|", current: "a|c|",
+			want: "a|b|c|",
+		},
+		{
+			name: "undo an addition at the end", base: "a|", changed: "a|b|", current: "a|b|",
+			want: "a|",
+		},
+		{
+			name: "undo an addition at the start", base: "b|", changed: "a|b|", current: "a|b|",
+			want: "b|",
+		},
+		{
+			name: "keep later edits elsewhere", base: "a|b|c|d|", changed: "a|B|c|d|", current: "a|B|c|D|",
+			want: "a|b|c|D|",
+		},
+		{
+			name: "follow lines moved by later additions", base: "a|b|c|", changed: "a|B|c|", current: "x|y|a|B|c|z|",
+			want: "x|y|a|b|c|z|",
+		},
+		{
+			name: "undo several edits", base: "a|b|c|d|e|", changed: "A|b|C|d|E|", current: "A|b|C|d|E|f|",
+			want: "a|b|c|d|e|f|",
+		},
+		{
+			name: "edited again since", base: "a|b|c|", changed: "a|B|c|", current: "a|BB|c|",
+			wantConflicts: 1,
+		},
+		{
+			name: "removed since", base: "a|b|c|", changed: "a|B|c|", current: "a|c|",
+			wantConflicts: 1,
+		},
+		{
+			name: "insertion point split since", base: "a|c|", changed: "a|b|c|", current: "a|b|x|c|",
+			want: "a|x|c|",
+		},
+		{
+			name: "neighbours of a removal separated since", base: "a|b|c|", changed: "a|c|", current: "a|x|c|",
+			wantConflicts: 1,
+		},
+		{
+			name: "one of two edits conflicts", base: "a|b|c|d|", changed: "A|b|c|D|", current: "A|b|c|DD|",
+			wantConflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Revert(lines(tt.base), lines(tt.changed), lines(tt.current))
+			if conflicts != tt.wantConflicts {
+				t.Fatalf("Revert() conflicts = %d, want %d (result %q)", conflicts, tt.wantConflicts, got)
+			}
+			if tt.wantConflicts > 0 {
+				if got != nil {
+					t.Errorf("Revert() with conflicts = %q, want nil", got)
+				}
+				return
+			}
+			if joined, want := strings.Join(got, ""), strings.ReplaceAll(tt.want, "|", "\n"); joined != want {
+				t.Errorf("Revert() = %q, want %q", joined, want)
+			}
+		})
+	}
+}
+
+func TestRevertLeavesInputsAlone(t *testing.T) {
+	base, changed, current := lines("a|b|"), lines("a|B|"), lines("a|B|")
+	Revert(base, changed, current)
+	if !reflect.DeepEqual(current, lines("a|B|")) || !reflect.DeepEqual(base, lines("a|b|")) {
+		t.Errorf("Revert() modified its inputs: base %q, current %q", base, current)
+	}
+}
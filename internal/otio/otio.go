@@ -0,0 +1,338 @@
+// Package otio writes a project's version history as an OpenTimelineIO (.otio) file,
+// so editorial tools can show the iteration history next to their own timelines.
+package otio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/project"
+)
+
+const (
+	// DefaultRate is the frame rate used when no version has a comp with one
+	DefaultRate = 24.0
+
+	// defaultSlot is how long a version's slot is, in seconds, when it has no comps
+	defaultSlot = 5.0
+
+	// metadataKey namespaces everything vervids adds to OTIO metadata
+	metadataKey = "vervids"
+)
+
+// RationalTime is a point or length in time, in frames at Rate
+type RationalTime struct {
+	Schema string  `json:"OTIO_SCHEMA"`
+	Rate   float64 `json:"rate"`
+	Value  float64 `json:"value"`
+}
+
+// TimeRange is a span of time
+type TimeRange struct {
+	Schema    string       `json:"OTIO_SCHEMA"`
+	StartTime RationalTime `json:"start_time"`
+	Duration  RationalTime `json:"duration"`
+}
+
+// Marker is a note at a point on a clip
+type Marker struct {
+	Schema      string         `json:"OTIO_SCHEMA"`
+	Name        string         `json:"name"`
+	Metadata    map[string]any `json:"metadata"`
+	Color       string         `json:"color"`
+	MarkedRange TimeRange      `json:"marked_range"`
+	Comment     string         `json:"comment"`
+}
+
+// MissingReference stands in for media OTIO can't open; a version lives in vervids
+// storage and is fetched with 'vervids pull'
+type MissingReference struct {
+	Schema         string         `json:"OTIO_SCHEMA"`
+	Name           string         `json:"name"`
+	Metadata       map[string]any `json:"metadata"`
+	AvailableRange *TimeRange     `json:"available_range"`
+}
+
+// Item is a clip or gap on a track
+type Item struct {
+	Schema         string            `json:"OTIO_SCHEMA"`
+	Name           string            `json:"name"`
+	Metadata       map[string]any    `json:"metadata"`
+	SourceRange    TimeRange         `json:"source_range"`
+	Effects        []any             `json:"effects"`
+	Markers        []Marker          `json:"markers"`
+	Enabled        bool              `json:"enabled"`
+	MediaReference *MissingReference `json:"media_reference,omitempty"`
+}
+
+// Track is a sequence of items
+type Track struct {
+	Schema      string         `json:"OTIO_SCHEMA"`
+	Name        string         `json:"name"`
+	Kind        string         `json:"kind"`
+	Metadata    map[string]any `json:"metadata"`
+	SourceRange *TimeRange     `json:"source_range"`
+	Effects     []any          `json:"effects"`
+	Markers     []Marker       `json:"markers"`
+	Enabled     bool           `json:"enabled"`
+	Children    []Item         `json:"children"`
+}
+
+// Stack holds the timeline's tracks, top to bottom
+type Stack struct {
+	Schema      string         `json:"OTIO_SCHEMA"`
+	Name        string         `json:"name"`
+	Metadata    map[string]any `json:"metadata"`
+	SourceRange *TimeRange     `json:"source_range"`
+	Effects     []any          `json:"effects"`
+	Markers     []Marker       `json:"markers"`
+	Enabled     bool           `json:"enabled"`
+	Children    []Track        `json:"children"`
+}
+
+// Timeline is the root of an .otio file
+type Timeline struct {
+	Schema          string         `json:"OTIO_SCHEMA"`
+	Name            string         `json:"name"`
+	Metadata        map[string]any `json:"metadata"`
+	GlobalStartTime *RationalTime  `json:"global_start_time"`
+	Tracks          Stack          `json:"tracks"`
+}
+
+// FromProject lays out the project's versions one after another on a "Versions" track,
+// with a track per composition underneath showing each comp's length in every version
+// that has it. comps holds the compositions of each version by number; versions
+// missing from it get a default-length slot and no comp clips.
+func FromProject(p *project.Project, comps map[int][]aepx.Comp) *Timeline {
+	rate := frameRate(p, comps)
+
+	versionsTrack := newTrack("Versions")
+	compTracks := map[string]*Track{}
+	compOrder := []string{}
+	var position int64 // Frames into the timeline
+
+	for i := range p.Versions {
+		v := &p.Versions[i]
+		versionComps := comps[v.Number]
+		slot := toFrames(defaultSlot, rate)
+		if longest := toFrames(longestComp(versionComps), rate); longest > 0 {
+			slot = longest
+		}
+
+		clip := newItem("Clip.1", fmt.Sprintf("v%03d", v.Number), 0, slot, rate)
+		clip.Metadata[metadataKey] = versionMetadata(p, v, versionComps)
+		clip.MediaReference = &MissingReference{
+			Schema:   "MissingReference.1",
+			Metadata: map[string]any{metadataKey: map[string]any{"project": p.ProjectName, "version": v.Number}},
+		}
+		for _, m := range v.Markers {
+			clip.Markers = append(clip.Markers, newMarker(m, rate))
+		}
+		versionsTrack.Children = append(versionsTrack.Children, clip)
+
+		// Every comp track gets exactly one slot's worth of items per version so the
+		// tracks stay aligned with the Versions track
+		present := map[string]bool{}
+		for _, c := range versionComps {
+			track, ok := compTracks[c.Name]
+			if !ok {
+				t := newTrack("Comp: " + c.Name)
+				track = &t
+				if position > 0 {
+					track.Children = append(track.Children, newItem("Gap.1", "", 0, position, rate))
+				}
+				compTracks[c.Name] = track
+				compOrder = append(compOrder, c.Name)
+			}
+			if present[c.Name] {
+				continue // Duplicate comp names: only the first is shown
+			}
+			present[c.Name] = true
+
+			length := toFrames(c.Duration, rate)
+			if length < 1 {
+				length = 1 // Comps without a readable duration still get a visible clip
+			}
+			compClip := newItem("Clip.1", fmt.Sprintf("%s v%03d", c.Name, v.Number), 0, length, rate)
+			compClip.Metadata[metadataKey] = compMetadata(c)
+			compClip.MediaReference = &MissingReference{Schema: "MissingReference.1", Metadata: map[string]any{}}
+			track.Children = append(track.Children, compClip)
+			if rest := slot - length; rest > 0 {
+				track.Children = append(track.Children, newItem("Gap.1", "", 0, rest, rate))
+			}
+		}
+		for _, name := range compOrder {
+			if !present[name] {
+				compTracks[name].Children = append(compTracks[name].Children, newItem("Gap.1", "", 0, slot, rate))
+			}
+		}
+		position += slot
+	}
+
+	tracks := []Track{versionsTrack}
+	for _, name := range compOrder {
+		tracks = append(tracks, *compTracks[name])
+	}
+	return &Timeline{
+		Schema: "Timeline.1",
+		Name:   p.ProjectName,
+		Metadata: map[string]any{metadataKey: map[string]any{
+			"project":      p.ProjectName,
+			"project_path": p.ProjectPath,
+			"versions":     len(p.Versions),
+		}},
+		Tracks: Stack{
+			Schema:   "Stack.1",
+			Name:     "tracks",
+			Metadata: map[string]any{},
+			Effects:  []any{},
+			Markers:  []Marker{},
+			Enabled:  true,
+			Children: tracks,
+		},
+	}
+}
+
+// Write encodes the timeline as indented OTIO JSON
+func Write(w io.Writer, t *Timeline) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(t)
+}
+
+// frameRate returns the frame rate of the newest version's first comp that has one
+func frameRate(p *project.Project, comps map[int][]aepx.Comp) float64 {
+	for i := len(p.Versions) - 1; i >= 0; i-- {
+		for _, c := range comps[p.Versions[i].Number] {
+			if c.FrameRate > 0 {
+				return c.FrameRate
+			}
+		}
+	}
+	return DefaultRate
+}
+
+// longestComp returns the longest comp duration in seconds, or 0
+func longestComp(comps []aepx.Comp) float64 {
+	longest := 0.0
+	for _, c := range comps {
+		if c.Duration > longest {
+			longest = c.Duration
+		}
+	}
+	return longest
+}
+
+// versionMetadata describes a version for its clip
+func versionMetadata(p *project.Project, v *project.Version, comps []aepx.Comp) map[string]any {
+	meta := map[string]any{
+		"version":     v.Number,
+		"message":     v.Message,
+		"timestamp":   v.Timestamp,
+		"size":        v.Size,
+		"total_size":  v.TotalSize,
+		"asset_count": v.AssetCount,
+	}
+	if v.Author != "" {
+		meta["author"] = v.Author
+	}
+	if len(v.Labels) > 0 {
+		meta["labels"] = v.Labels
+	}
+	if len(v.Meta) > 0 {
+		meta["meta"] = v.Meta
+	}
+	if len(v.Missing) > 0 {
+		meta["missing_assets"] = len(v.Missing)
+	}
+	if len(comps) > 0 {
+		names := make([]string, 0, len(comps))
+		for _, c := range comps {
+			names = append(names, c.Name)
+		}
+		sort.Strings(names)
+		meta["comps"] = names
+	}
+	if len(v.LayerChanges) > 0 {
+		meta["layer_changes"] = v.LayerChanges
+	}
+	return meta
+}
+
+// compMetadata describes a composition for its clip
+func compMetadata(c aepx.Comp) map[string]any {
+	return map[string]any{
+		"id":         c.ID,
+		"name":       c.Name,
+		"resolution": c.Resolution(),
+		"duration":   c.Duration,
+		"frame_rate": c.FrameRate,
+		"layers":     len(c.Layers),
+	}
+}
+
+// newMarker places a version's marker comment at the start of its clip; .aepx markers
+// are read without their times
+func newMarker(m aepx.Marker, rate float64) Marker {
+	name := m.Comp
+	if m.Layer != "" {
+		name += " / " + m.Layer
+	}
+	color := "RED"
+	if m.Kind == "note" {
+		color = "YELLOW"
+	}
+	return Marker{
+		Schema:      "Marker.2",
+		Name:        name,
+		Metadata:    map[string]any{metadataKey: map[string]any{"kind": m.Kind}},
+		Color:       color,
+		MarkedRange: timeRange(0, 0, rate),
+		Comment:     m.Text,
+	}
+}
+
+// newTrack returns an empty video track
+func newTrack(name string) Track {
+	return Track{
+		Schema:   "Track.1",
+		Name:     name,
+		Kind:     "Video",
+		Metadata: map[string]any{},
+		Effects:  []any{},
+		Markers:  []Marker{},
+		Enabled:  true,
+		Children: []Item{},
+	}
+}
+
+// newItem returns a clip or gap covering the given frames
+func newItem(schema string, name string, start, duration int64, rate float64) Item {
+	return Item{
+		Schema:      schema,
+		Name:        name,
+		Metadata:    map[string]any{},
+		SourceRange: timeRange(start, duration, rate),
+		Effects:     []any{},
+		Markers:     []Marker{},
+		Enabled:     true,
+	}
+}
+
+// timeRange returns the range of frames at rate
+func timeRange(start, duration int64, rate float64) TimeRange {
+	return TimeRange{
+		Schema:    "TimeRange.1",
+		StartTime: RationalTime{Schema: "RationalTime.1", Rate: rate, Value: float64(start)},
+		Duration:  RationalTime{Schema: "RationalTime.1", Rate: rate, Value: float64(duration)},
+	}
+}
+
+// toFrames converts seconds to whole frames at rate. Slots are counted in whole frames
+// so the comp tracks line up exactly with the Versions track.
+func toFrames(seconds float64, rate float64) int64 {
+	return int64(seconds*rate + 0.5)
+}
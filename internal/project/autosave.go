@@ -0,0 +1,150 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// AutosaveLabel marks versions committed by the autosave scheduler
+const AutosaveLabel = "autosave"
+
+// DefaultAutosaveKeep is how many autosaves keep all their stored files
+const DefaultAutosaveKeep = 10
+
+// autosaveSettle is how long the file must be left alone before it is autosaved, so a
+// save in progress isn't committed half-written
+const autosaveSettle = 5 * time.Second
+
+// AutosaveSettings schedules automatic commits of a project file
+type AutosaveSettings struct {
+	File  string `json:"file"`
+	Every string `json:"every"`          // Interval as a duration, e.g. "30m"
+	Keep  int    `json:"keep,omitempty"` // Newest autosaves kept whole; 0 uses DefaultAutosaveKeep
+}
+
+// Interval returns the parsed autosave interval
+func (s *AutosaveSettings) Interval() (time.Duration, error) {
+	every, err := time.ParseDuration(s.Every)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval '%s' (use e.g. 30m or 1h)", s.Every)
+	}
+	if every < time.Minute {
+		return 0, fmt.Errorf("interval must be at least 1m")
+	}
+	return every, nil
+}
+
+// KeepCount returns how many autosaves keep their stored files
+func (s *AutosaveSettings) KeepCount() int {
+	if s.Keep > 0 {
+		return s.Keep
+	}
+	return DefaultAutosaveKeep
+}
+
+// IsAutosave reports whether the version was committed by the autosave scheduler
+func (v *Version) IsAutosave() bool {
+	return v.HasLabel(AutosaveLabel)
+}
+
+// EnableAutosave schedules commits of file every interval, replacing any schedule
+func (p *Project) EnableAutosave(file string, every string, keep int) error {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return fmt.Errorf("cannot autosave %s: %w", file, err)
+	}
+	if !p.acceptsFile(abs) {
+		return fmt.Errorf("%s is not a file this project can commit", filepath.Base(abs))
+	}
+	settings := &AutosaveSettings{File: abs, Every: every, Keep: keep}
+	if _, err := settings.Interval(); err != nil {
+		return err
+	}
+	p.Autosave = settings
+	return nil
+}
+
+// AutosaveDue reports whether the autosaved file differs from the newest version and
+// has been left alone long enough to commit
+func (p *Project) AutosaveDue() (bool, error) {
+	if p.Autosave == nil {
+		return false, fmt.Errorf("autosave is not enabled")
+	}
+	info, err := os.Stat(p.Autosave.File)
+	if err != nil {
+		return false, err
+	}
+	if time.Since(info.ModTime()) < autosaveSettle {
+		return false, nil
+	}
+	latest := p.GetLatestVersion()
+	if latest == nil {
+		return true, nil
+	}
+	if latest.FileHash == "" {
+		return info.ModTime().After(latest.Timestamp), nil
+	}
+	hash, err := storage.HashFile(p.Autosave.File)
+	if err != nil {
+		return false, err
+	}
+	return hash != latest.FileHash, nil
+}
+
+// CommitAutosave commits the autosaved file as a version labeled autosave, then
+// prunes the stored files of older autosaves
+func (p *Project) CommitAutosave() (*Version, error) {
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	opts := CommitOptions{MissingAssets: MissingAllow}
+	v, err := p.CommitWithOptions(fmt.Sprintf("Autosave %s", time.Now().Format("2006-01-02 15:04")), p.Autosave.File, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.AddLabel(AutosaveLabel, "", []int{v.Number}); err != nil {
+		return nil, err
+	}
+	if err := p.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+	return v, nil
+}
+
+// PruneAutosaves purges from storage the objects used only by autosaves older than the
+// newest keep, so autosaves don't hold on to footage no regular version needs. Their
+// project files stay, so the versions remain in the history. Returns the number of
+// objects purged and the bytes freed.
+func (p *Project) PruneAutosaves(keep int) (int, int64, error) {
+	if err := p.CheckWritable(); err != nil {
+		return 0, 0, err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+	autosaves := []int{}
+	for _, v := range p.Versions {
+		if v.IsAutosave() {
+			autosaves = append(autosaves, v.Number)
+		}
+	}
+	if len(autosaves) <= keep {
+		return 0, 0, nil
+	}
+	prunable := make(map[int]bool)
+	for _, number := range autosaves[:len(autosaves)-keep] {
+		prunable[number] = true
+	}
+	return p.purgeObjectsOf(prunable)
+}
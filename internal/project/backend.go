@@ -0,0 +1,62 @@
+package project
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/s3"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// store returns where the project's versions are kept: its S3 remote, or the Docker
+// volume
+func (p *Project) store() (storage.Storage, error) {
+	if p.backend != nil {
+		return p.backend, nil
+	}
+	if p.Remote == nil {
+		return docker.Volume{}, nil
+	}
+	bucket, err := s3.New(*p.Remote, docker.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote: %w", err)
+	}
+	return bucket, nil
+}
+
+// readyStore returns the project's storage once it can be reached
+func (p *Project) readyStore() (storage.Storage, error) {
+	store, err := p.store()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.EnsureReady(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// EnsureStorageReady checks the project's storage can be reached: Docker and the
+// storage container for the volume, the bucket for a remote
+func (p *Project) EnsureStorageReady() error {
+	_, err := p.readyStore()
+	return err
+}
+
+// StorageName describes where the project's versions are kept, e.g. "Docker" or
+// "s3://bucket/prefix"
+func (p *Project) StorageName() string {
+	if p.Remote == nil {
+		return "Docker"
+	}
+	return p.Remote.URL
+}
+
+// requireDocker fails for projects stored in a remote bucket, for operations that run
+// commands inside the storage container
+func (p *Project) requireDocker(operation string) error {
+	if p.Remote != nil {
+		return fmt.Errorf("%s needs Docker storage; this project is stored in %s", operation, p.Remote.URL)
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/chunks"
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+)
+
+// bundleMagic is the first line of every bundle file
+const bundleMagic = "# vervids bundle v1"
+
+// BundleHeader describes a bundle: the versions it carries and the version the
+// receiving machine must already have. The header is a line of JSON after the magic
+// line; a tar of the stored objects follows it.
+type BundleHeader struct {
+	ProjectID string      `json:"project_id"`
+	Created   time.Time   `json:"created"`
+	Base      *BundleBase `json:"base,omitempty"` // Nil for a bundle of the whole history
+	Project   Project     `json:"project"`        // Settings and labels, with only the bundled versions
+	Objects   int         `json:"objects"`        // Stored paths in the tar
+	Size      int64       `json:"size"`           // Approximate size of the stored objects
+}
+
+// BundleBase identifies the newest version left out of a bundle
+type BundleBase struct {
+	Number    int       `json:"number"`
+	Timestamp time.Time `json:"timestamp"`
+	FileHash  string    `json:"file_hash,omitempty"`
+}
+
+// Versions returns the version numbers in the bundle
+func (h *BundleHeader) Versions() []int {
+	numbers := make([]int, len(h.Project.Versions))
+	for i, v := range h.Project.Versions {
+		numbers[i] = v.Number
+	}
+	return numbers
+}
+
+// For reports whether the bundle was made from the same project as p
+func (h *BundleHeader) For(p *Project) bool {
+	return p.projectID() == h.ProjectID
+}
+
+// CreateBundle writes the versions after since to w, with every stored object they use
+// that version since and earlier don't. A since of -1 bundles the whole history. Needs
+// Docker.
+func (p *Project) CreateBundle(w io.Writer, since int) (*BundleHeader, error) {
+	if err := p.requireDocker("bundle"); err != nil {
+		return nil, err
+	}
+	if err := docker.EnsureDockerReady(); err != nil {
+		return nil, err
+	}
+
+	header := &BundleHeader{ProjectID: p.projectID(), Created: time.Now(), Project: *p}
+	header.Project.Versions = nil
+	header.Project.PinnedAssets = nil // Pins refer to this machine's storage
+	header.Project.SearchPaths = nil
+	var base, included []Version
+	for _, v := range p.Versions {
+		if v.Number <= since {
+			base = append(base, v)
+		} else {
+			included = append(included, v)
+		}
+	}
+	if since >= 0 {
+		b, err := p.FindVersion(since)
+		if err != nil {
+			return nil, err
+		}
+		header.Base = &BundleBase{Number: b.Number, Timestamp: b.Timestamp, FileHash: b.FileHash}
+	}
+	if len(included) == 0 {
+		return nil, fmt.Errorf("no versions after v%03d to bundle", since)
+	}
+	header.Project.Versions = included
+
+	paths, size, err := p.bundlePaths(base, included)
+	if err != nil {
+		return nil, err
+	}
+	header.Objects = len(paths)
+	header.Size = size
+
+	// The list of paths goes to a file in the container for tar -T, which avoids
+	// argument-length limits on projects with many chunks
+	listPath := fmt.Sprintf("/tmp/vervids-bundle-%d.list", os.Getpid())
+	if err := docker.UploadToContainer(strings.NewReader(strings.Join(paths, "\n")+"\n"), listPath); err != nil {
+		return nil, err
+	}
+	defer docker.ExecInContainer("rm", "-f", listPath)
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bundle header: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "%s\n%s\n", bundleMagic, data); err != nil {
+		return nil, err
+	}
+	projectDir := filepath.Join(docker.StoragePath, header.ProjectID)
+	if err := docker.StreamFromContainer(nil, w, "tar", "-cf", "-", "-C", projectDir, "-T", listPath); err != nil {
+		return nil, fmt.Errorf("failed to pack stored objects: %w", err)
+	}
+	return header, nil
+}
+
+// bundlePaths lists, relative to the project's storage directory, the version folders
+// of the included versions and the objects they use that the base versions don't
+func (p *Project) bundlePaths(base, included []Version) ([]string, int64, error) {
+	projectDir := filepath.Join(docker.StoragePath, p.projectID())
+	have := make(map[string]bool)
+	haveChunks := make(map[string]bool)
+	for _, v := range base {
+		for _, a := range v.Assets {
+			if a.Purged {
+				continue
+			}
+			if a.Proxy != nil {
+				have[a.Proxy.DockerPath] = true
+			}
+			if a.DockerPath == "" || have[a.DockerPath] {
+				continue
+			}
+			have[a.DockerPath] = true
+			if a.Chunked {
+				manifest, err := chunks.LoadManifest(docker.Volume{}, a.DockerPath)
+				if err != nil {
+					return nil, 0, err
+				}
+				for _, c := range manifest.Chunks {
+					haveChunks[filepath.Join(manifest.ChunksDir, c.Hash[:2], c.Hash)] = true
+				}
+			}
+		}
+	}
+
+	var size int64
+	wanted := make(map[string]bool)
+	add := func(path string) error {
+		rel, ok := strings.CutPrefix(path, projectDir+"/")
+		if !ok {
+			return fmt.Errorf("%s is outside the project's storage", path)
+		}
+		wanted[rel] = true
+		return nil
+	}
+	for _, v := range included {
+		if err := add(filepath.Dir(v.DockerPath)); err != nil {
+			return nil, 0, err
+		}
+		size += v.Size
+		for _, a := range v.Assets {
+			if a.Purged {
+				continue
+			}
+			if a.Proxy != nil && !have[a.Proxy.DockerPath] {
+				if err := add(a.Proxy.DockerPath); err != nil {
+					return nil, 0, err
+				}
+				have[a.Proxy.DockerPath] = true
+				size += a.Proxy.Size
+			}
+			if a.DockerPath == "" || have[a.DockerPath] {
+				continue
+			}
+			have[a.DockerPath] = true
+			if err := add(a.DockerPath); err != nil {
+				return nil, 0, err
+			}
+			if !a.Chunked {
+				if !a.ProxyOnly {
+					size += a.Size
+				}
+				continue
+			}
+			manifest, err := chunks.LoadManifest(docker.Volume{}, a.DockerPath)
+			if err != nil {
+				return nil, 0, err
+			}
+			for _, c := range manifest.Chunks {
+				chunk := filepath.Join(manifest.ChunksDir, c.Hash[:2], c.Hash)
+				if haveChunks[chunk] {
+					continue
+				}
+				haveChunks[chunk] = true
+				if err := add(chunk); err != nil {
+					return nil, 0, err
+				}
+				size += c.Size
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(wanted))
+	for path := range wanted {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, size, nil
+}
+
+// ReadBundleHeader reads a bundle's header, leaving r at the start of its objects
+func ReadBundleHeader(r *bufio.Reader) (*BundleHeader, error) {
+	magic, err := r.ReadString('\n')
+	if err != nil || strings.TrimSpace(magic) != bundleMagic {
+		return nil, fmt.Errorf("not a vervids bundle")
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("bundle header is truncated: %w", err)
+	}
+	var header BundleHeader
+	if err := json.Unmarshal([]byte(line), &header); err != nil {
+		return nil, fmt.Errorf("bundle header is corrupt: %w", err)
+	}
+	return &header, nil
+}
+
+// NewProjectFromBundle returns a project for a bundle applied on a machine that doesn't
+// have it yet, with its project file in dir. It has no versions until ApplyBundle.
+func NewProjectFromBundle(h *BundleHeader, dir string) *Project {
+	p := h.Project
+	p.Versions = []Version{}
+	p.ProjectPath = filepath.Join(dir, filepath.Base(p.ProjectPath))
+	p.DockerVolume = docker.VolumeName
+	return &p
+}
+
+// BundleVersions returns the versions in the bundle that p doesn't have yet. It fails
+// when p lacks the version the bundle was made on top of, or when p has a different
+// version under one of the bundle's numbers (the histories have diverged).
+func (p *Project) BundleVersions(h *BundleHeader) ([]Version, error) {
+	if !h.For(p) {
+		return nil, errs.New(errs.Conflict, "bundle is for project %s, not %s", h.ProjectID, p.projectID())
+	}
+	if h.Base != nil {
+		v, err := p.FindVersion(h.Base.Number)
+		if err != nil {
+			return nil, errs.New(errs.VersionNotFound, "bundle needs v%03d, which this machine doesn't have", h.Base.Number)
+		}
+		if !sameVersion(*v, h.Base.Timestamp, h.Base.FileHash) {
+			return nil, errs.New(errs.Conflict, "v%03d here is not the v%03d the bundle was made from; the histories have diverged", v.Number, h.Base.Number)
+		}
+	}
+
+	missing := []Version{}
+	for _, bv := range h.Project.Versions {
+		v, err := p.FindVersion(bv.Number)
+		if err != nil {
+			missing = append(missing, bv)
+			continue
+		}
+		if !sameVersion(*v, bv.Timestamp, bv.FileHash) {
+			return nil, errs.New(errs.Conflict, "v%03d here differs from v%03d in the bundle; the histories have diverged", v.Number, bv.Number)
+		}
+	}
+	return missing, nil
+}
+
+// sameVersion reports whether v is the version with the given commit time and file hash
+func sameVersion(v Version, timestamp time.Time, fileHash string) bool {
+	if v.FileHash != "" && fileHash != "" {
+		return v.FileHash == fileHash
+	}
+	return v.Timestamp.Equal(timestamp)
+}
+
+// ApplyBundle unpacks a bundle's objects into storage and adds the versions p doesn't
+// have, along with the labels they carry, then saves config.json. objects is the
+// reader ReadBundleHeader left after the header. Needs Docker.
+func (p *Project) ApplyBundle(h *BundleHeader, objects io.Reader) ([]Version, error) {
+	if err := p.CheckWritable(); err != nil {
+		return nil, err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	missing, err := p.BundleVersions(h)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) == 0 {
+		return missing, nil
+	}
+	if err := p.requireDocker("bundle"); err != nil {
+		return nil, err
+	}
+	if err := docker.EnsureDockerReady(); err != nil {
+		return nil, err
+	}
+
+	projectDir := filepath.Join(docker.StoragePath, h.ProjectID)
+	if err := docker.CreateDirectory(projectDir); err != nil {
+		return nil, err
+	}
+	if err := docker.StreamFromContainer(objects, io.Discard, "tar", "-xf", "-", "-C", projectDir); err != nil {
+		return nil, fmt.Errorf("failed to unpack bundle: %w", err)
+	}
+	for _, v := range missing {
+		if !docker.PathExistsInContainer(v.DockerPath) {
+			return nil, fmt.Errorf("bundle is incomplete: v%03d's project file is missing", v.Number)
+		}
+	}
+
+	p.Versions = append(p.Versions, missing...)
+	sort.SliceStable(p.Versions, func(i, j int) bool { return p.Versions[i].Number < p.Versions[j].Number })
+	for _, v := range missing {
+		for _, name := range v.Labels {
+			if p.FindLabel(name) != nil {
+				continue
+			}
+			label := Label{Name: name}
+			if l := h.Project.FindLabel(name); l != nil {
+				label = *l
+			}
+			p.Labels = append(p.Labels, label)
+		}
+	}
+	if err := p.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+	p.publishMetadata()
+	return missing, nil
+}
@@ -0,0 +1,105 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+)
+
+// versionDirPattern matches the vXXX directories versions are stored in
+var versionDirPattern = regexp.MustCompile(`^v\d{3,}$`)
+
+// OrphanDir is a directory in storage that no version in config.json points to,
+// usually left by a commit that was interrupted before config.json was saved
+type OrphanDir struct {
+	DockerPath string
+	Size       int64 // Bytes; 0 if du failed
+}
+
+// IncompleteVersionDirs returns the project's vXXX directories in storage that no
+// version references. Only the Docker volume is scanned; projects in a remote bucket
+// have none returned.
+func (p *Project) IncompleteVersionDirs() ([]OrphanDir, error) {
+	if p.Remote != nil {
+		return nil, nil
+	}
+	projectDir := filepath.Join(docker.StoragePath, p.projectID())
+	if !docker.PathExistsInContainer(projectDir) {
+		return nil, nil
+	}
+	output, err := docker.ExecInContainer("find", projectDir, "-mindepth", "1", "-maxdepth", "1", "-type", "d")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", projectDir, err)
+	}
+
+	referenced := make(map[string]bool)
+	for _, v := range p.Versions {
+		if v.DockerPath != "" {
+			referenced[filepath.Dir(v.DockerPath)] = true
+		}
+	}
+
+	orphans := []OrphanDir{}
+	for _, line := range strings.Split(output, "\n") {
+		dir := strings.TrimSpace(line)
+		if dir == "" || !versionDirPattern.MatchString(filepath.Base(dir)) || referenced[dir] {
+			continue
+		}
+		orphans = append(orphans, OrphanDir{DockerPath: dir})
+	}
+	if len(orphans) == 0 {
+		return orphans, nil
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].DockerPath < orphans[j].DockerPath })
+
+	// One du for all of them; sizes are informational, so a failure leaves them at 0
+	args := []string{"du", "-sk"}
+	for _, o := range orphans {
+		args = append(args, o.DockerPath)
+	}
+	if output, err := docker.ExecInContainer(args...); err == nil {
+		sizes := make(map[string]int64)
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+			if kb, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+				sizes[fields[1]] = kb * 1024
+			}
+		}
+		for i := range orphans {
+			orphans[i].Size = sizes[orphans[i].DockerPath]
+		}
+	}
+	return orphans, nil
+}
+
+// IncompleteStagedCommits returns staging directories without a staged.json, left by
+// 'commit --offline' runs that stopped while copying. Directories modified in the last
+// minAge are skipped since a running commit may still be filling them.
+func IncompleteStagedCommits(minAge time.Duration) []string {
+	dirs, err := filepath.Glob(filepath.Join(StagingDir(), "*", "*"))
+	if err != nil {
+		return nil
+	}
+	incomplete := []string{}
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() || time.Since(info.ModTime()) < minAge {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, StagedFile)); err == nil {
+			continue
+		}
+		incomplete = append(incomplete, dir)
+	}
+	return incomplete
+}
@@ -0,0 +1,279 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/proxy"
+)
+
+// configKey describes a project setting that can be read and changed by key
+type configKey struct {
+	description string
+	get         func(p *Project) string
+	set         func(p *Project, values []string) error
+}
+
+// configKeys are the project settings editable through `vervids config`
+var configKeys = map[string]configKey{
+	"include_extensions": {
+		description: "Only track assets with these extensions (empty = all)",
+		get:         func(p *Project) string { return strings.Join(p.AssetRules.IncludeExtensions, ",") },
+		set: func(p *Project, values []string) error {
+			p.AssetRules.IncludeExtensions = normalizeExtensions(values)
+			return nil
+		},
+	},
+	"exclude_extensions": {
+		description: "Never track assets with these extensions",
+		get:         func(p *Project) string { return strings.Join(p.AssetRules.ExcludeExtensions, ",") },
+		set: func(p *Project, values []string) error {
+			p.AssetRules.ExcludeExtensions = normalizeExtensions(values)
+			return nil
+		},
+	},
+	"exclude_folders": {
+		description: "Never track assets inside these folders (names or relative paths)",
+		get:         func(p *Project) string { return strings.Join(p.AssetRules.ExcludeFolders, ",") },
+		set: func(p *Project, values []string) error {
+			p.AssetRules.ExcludeFolders = splitValues(values)
+			return nil
+		},
+	},
+	"exclude_files": {
+		description: "Never track these files (full paths or filenames)",
+		get:         func(p *Project) string { return strings.Join(p.AssetRules.ExcludeFiles, ",") },
+		set: func(p *Project, values []string) error {
+			p.AssetRules.ExcludeFiles = splitValues(values)
+			return nil
+		},
+	},
+	"resolve_symlinks": {
+		description: "Store symlinked assets under their targets",
+		get:         func(p *Project) string { return strconv.FormatBool(p.ResolveSymlinks) },
+		set: func(p *Project, values []string) error {
+			b, err := parseBoolValue(values)
+			if err != nil {
+				return err
+			}
+			p.ResolveSymlinks = b
+			return nil
+		},
+	},
+	"proxy_threshold_mb": {
+		description: "Create low-res proxies for footage at least this many MB (0 = disabled, needs ffmpeg)",
+		get:         func(p *Project) string { return strconv.FormatInt(p.Proxy.ThresholdMB, 10) },
+		set: func(p *Project, values []string) error {
+			n, err := parseIntValue(values)
+			if err != nil {
+				return err
+			}
+			p.Proxy.ThresholdMB = int64(n)
+			return nil
+		},
+	},
+	"proxy_mode": {
+		description: "Store 'both' the original and proxy, or 'proxy_only'",
+		get: func(p *Project) string {
+			if p.Proxy.Mode == "" {
+				return proxy.ModeBoth
+			}
+			return p.Proxy.Mode
+		},
+		set: func(p *Project, values []string) error {
+			if len(values) == 0 {
+				p.Proxy.Mode = ""
+				return nil
+			}
+			if len(values) != 1 || (values[0] != proxy.ModeBoth && values[0] != proxy.ModeProxyOnly) {
+				return fmt.Errorf("proxy_mode must be '%s' or '%s'", proxy.ModeBoth, proxy.ModeProxyOnly)
+			}
+			p.Proxy.Mode = values[0]
+			return nil
+		},
+	},
+	"proxy_height": {
+		description: "Proxy height in pixels (default 540)",
+		get: func(p *Project) string {
+			if p.Proxy.Height == 0 {
+				return strconv.Itoa(proxy.DefaultHeight)
+			}
+			return strconv.Itoa(p.Proxy.Height)
+		},
+		set: func(p *Project, values []string) error {
+			n, err := parseIntValue(values)
+			if err != nil {
+				return err
+			}
+			p.Proxy.Height = n
+			return nil
+		},
+	},
+	"search_paths": {
+		description: "Folders searched by filename for assets missing from their recorded path",
+		get:         func(p *Project) string { return strings.Join(p.SearchPaths, ",") },
+		set: func(p *Project, values []string) error {
+			paths := splitValues(values)
+			for i, path := range paths {
+				abs, err := filepath.Abs(path)
+				if err != nil {
+					return fmt.Errorf("invalid path '%s': %w", path, err)
+				}
+				paths[i] = abs
+			}
+			p.SearchPaths = paths
+			return nil
+		},
+	},
+	"missing_assets": {
+		description: "Commit policy for missing assets: 'allow' (record and proceed) or 'strict' (fail)",
+		get: func(p *Project) string {
+			if p.MissingAssets == "" {
+				return MissingAllow
+			}
+			return p.MissingAssets
+		},
+		set: func(p *Project, values []string) error {
+			if len(values) == 0 {
+				p.MissingAssets = ""
+				return nil
+			}
+			if len(values) != 1 || (values[0] != MissingAllow && values[0] != MissingStrict) {
+				return fmt.Errorf("missing_assets must be '%s' or '%s'", MissingAllow, MissingStrict)
+			}
+			p.MissingAssets = values[0]
+			return nil
+		},
+	},
+	"author": {
+		description: "Author recorded on this project's commits, e.g. \"Name <email>\" (empty uses the global author)",
+		get:         func(p *Project) string { return p.Author },
+		set: func(p *Project, values []string) error {
+			p.Author = strings.TrimSpace(strings.Join(values, " "))
+			return nil
+		},
+	},
+	"quota_gb": {
+		description: fmt.Sprintf("Storage quota in GB; commits warn at %d%% and need --force-quota past it (0 = none)", QuotaWarnPercent),
+		get:         func(p *Project) string { return strconv.FormatInt(p.QuotaGB, 10) },
+		set: func(p *Project, values []string) error {
+			n, err := parseIntValue(values)
+			if err != nil {
+				return err
+			}
+			p.QuotaGB = int64(n)
+			return nil
+		},
+	},
+	"read_only": {
+		description: "Archive the project: block commit, delete, prune and other writes",
+		get:         func(p *Project) string { return strconv.FormatBool(p.ReadOnly) },
+		set: func(p *Project, values []string) error {
+			b, err := parseBoolValue(values)
+			if err != nil {
+				return err
+			}
+			p.ReadOnly = b
+			return nil
+		},
+	},
+	"chunk_threshold_mb": {
+		description: "Store assets at least this many MB as deduplicated chunks (0 = disabled, new projects: 1024)",
+		get:         func(p *Project) string { return strconv.FormatInt(p.ChunkThresholdMB, 10) },
+		set: func(p *Project, values []string) error {
+			n, err := parseIntValue(values)
+			if err != nil {
+				return err
+			}
+			p.ChunkThresholdMB = int64(n)
+			return nil
+		},
+	},
+}
+
+// ConfigKeys returns the names of all project settings, sorted
+func ConfigKeys() []string {
+	keys := make([]string, 0, len(configKeys))
+	for k := range configKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ConfigDescription returns the help text for a project setting
+func ConfigDescription(key string) string {
+	return configKeys[key].description
+}
+
+// GetConfig returns the current value of a project setting
+func (p *Project) GetConfig(key string) (string, error) {
+	k, ok := configKeys[key]
+	if !ok {
+		return "", fmt.Errorf("unknown setting '%s' (available: %s)", key, strings.Join(ConfigKeys(), ", "))
+	}
+	return k.get(p), nil
+}
+
+// SetConfig changes a project setting. List settings accept several values or a
+// comma-separated list; passing no values clears them.
+func (p *Project) SetConfig(key string, values []string) error {
+	k, ok := configKeys[key]
+	if !ok {
+		return fmt.Errorf("unknown setting '%s' (available: %s)", key, strings.Join(ConfigKeys(), ", "))
+	}
+	return k.set(p, values)
+}
+
+// splitValues flattens comma-separated values and drops empty entries
+func splitValues(values []string) []string {
+	result := []string{}
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// normalizeExtensions splits values and normalizes each extension to ".ext"
+func normalizeExtensions(values []string) []string {
+	exts := splitValues(values)
+	for i, e := range exts {
+		exts[i] = assets.NormalizeExtension(e)
+	}
+	return exts
+}
+
+// parseIntValue parses a single non-negative integer setting value; no values means 0
+func parseIntValue(values []string) (int, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+	if len(values) != 1 {
+		return 0, fmt.Errorf("expected a single number")
+	}
+	n, err := strconv.Atoi(values[0])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid value '%s' (expected a non-negative number)", values[0])
+	}
+	return n, nil
+}
+
+// parseBoolValue parses a single boolean setting value
+func parseBoolValue(values []string) (bool, error) {
+	if len(values) != 1 {
+		return false, fmt.Errorf("expected a single value (true or false)")
+	}
+	b, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return false, fmt.Errorf("invalid value '%s' (expected true or false)", values[0])
+	}
+	return b, nil
+}
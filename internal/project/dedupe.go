@@ -0,0 +1,162 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+)
+
+// DuplicateGroup is content stored more than once under different object paths,
+// e.g. the same clip committed as clip.mov and clip_copy.mp4
+type DuplicateGroup struct {
+	Hash   string
+	Size   int64         // Size of one copy
+	Keep   ObjectUsage   // The copy the others are consolidated into
+	Copies []ObjectUsage // The redundant copies
+}
+
+// Wasted returns the bytes the redundant copies take up
+func (g *DuplicateGroup) Wasted() int64 {
+	return g.Size * int64(len(g.Copies))
+}
+
+// DedupeResult reports what 'vervids dedupe consolidate' did
+type DedupeResult struct {
+	Consolidated int      // Copies whose references now point at the kept copy
+	Freed        int64    // Bytes no longer stored
+	Failed       []string // Copies left as they were, with the reason
+}
+
+// Duplicates groups the stored objects by content hash and returns the content stored
+// under more than one path, most space wasted first, along with the number of objects
+// that couldn't be compared because their assets have no hash. With hashMissing those
+// are hashed in the container instead, which needs Docker. Proxies and chunked assets
+// are left out: proxies are derived files and chunks are already shared.
+func (p *Project) Duplicates(hashMissing bool) ([]DuplicateGroup, int, error) {
+	if hashMissing {
+		if err := p.requireDocker("--hash-missing"); err != nil {
+			return nil, 0, err
+		}
+	}
+	hashes := make(map[string]string)
+	for _, v := range p.Versions {
+		for _, a := range v.Assets {
+			if a.Hash != "" && a.DockerPath != "" {
+				hashes[a.DockerPath] = a.Hash
+			}
+		}
+	}
+
+	byHash := make(map[string][]ObjectUsage)
+	unhashed := 0
+	for _, u := range p.ObjectUsage() {
+		if u.Proxy || u.Chunked {
+			continue
+		}
+		hash := hashes[u.DockerPath]
+		if hash == "" && hashMissing {
+			var err error
+			if hash, err = containerHash(u.DockerPath); err != nil {
+				return nil, 0, err
+			}
+		}
+		if hash == "" {
+			unhashed++
+			continue
+		}
+		byHash[hash] = append(byHash[hash], u)
+	}
+
+	poolDir := filepath.Join(docker.StoragePath, p.projectID(), "assets")
+	groups := []DuplicateGroup{}
+	for hash, objects := range byHash {
+		if len(objects) < 2 {
+			continue
+		}
+		// Keep a pinned copy, then one in the pool, then the one most versions use
+		sort.SliceStable(objects, func(i, j int) bool {
+			a, b := objects[i], objects[j]
+			if a.Pinned != b.Pinned {
+				return a.Pinned
+			}
+			aPool := filepath.Dir(filepath.Dir(a.DockerPath)) == poolDir
+			bPool := filepath.Dir(filepath.Dir(b.DockerPath)) == poolDir
+			if aPool != bPool {
+				return aPool
+			}
+			return len(a.Versions) > len(b.Versions)
+		})
+		groups = append(groups, DuplicateGroup{Hash: hash, Size: objects[0].Size, Keep: objects[0], Copies: objects[1:]})
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Wasted() > groups[j].Wasted() })
+	return groups, unhashed, nil
+}
+
+// Consolidate points every asset and pin that uses a redundant copy at the group's
+// kept copy, then deletes the copy. Both copies are checked against the hash first,
+// config.json is saved before anything is deleted, and a copy that fails a check is
+// reported and left in place. Needs Docker.
+func (p *Project) Consolidate(groups []DuplicateGroup) (*DedupeResult, error) {
+	if err := p.CheckWritable(); err != nil {
+		return nil, err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := p.requireDocker("dedupe"); err != nil {
+		return nil, err
+	}
+	if err := docker.EnsureDockerReady(); err != nil {
+		return nil, err
+	}
+	result := &DedupeResult{}
+	for _, g := range groups {
+		if got, err := containerHash(g.Keep.DockerPath); err != nil || got != g.Hash {
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: kept copy does not match its hash, group skipped", g.Keep.DockerPath))
+			continue
+		}
+		for _, c := range g.Copies {
+			freed, err := p.consolidateCopy(c.DockerPath, g)
+			if err != nil {
+				result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", c.DockerPath, err))
+				continue
+			}
+			result.Consolidated++
+			result.Freed += freed
+		}
+	}
+	return result, nil
+}
+
+// consolidateCopy moves the references to one redundant copy over to the kept copy
+// and returns the bytes freed by deleting it
+func (p *Project) consolidateCopy(copyPath string, g DuplicateGroup) (int64, error) {
+	if got, err := containerHash(copyPath); err != nil {
+		return 0, err
+	} else if got != g.Hash {
+		return 0, fmt.Errorf("stored copy does not match its hash (%s), left as is", shortHash(got))
+	}
+
+	for i := range p.Versions {
+		for j := range p.Versions[i].Assets {
+			a := &p.Versions[i].Assets[j]
+			if a.DockerPath == copyPath && !a.Purged {
+				a.DockerPath = g.Keep.DockerPath
+				a.Hash = g.Hash
+			}
+		}
+	}
+	for i := range p.PinnedAssets {
+		if p.PinnedAssets[i].DockerPath == copyPath {
+			p.PinnedAssets[i].DockerPath = g.Keep.DockerPath
+		}
+	}
+	if removed, err := p.releaseObject(copyPath); err != nil || !removed {
+		return 0, err
+	}
+	return g.Size, nil
+}
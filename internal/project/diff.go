@@ -0,0 +1,75 @@
+package project
+
+import (
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/tracking"
+)
+
+// VersionDiff is what changed from one version to another
+type VersionDiff struct {
+	From    int
+	To      int
+	Assets  *tracking.AssetTracking // To's assets compared with From's
+	Parsed  bool                    // Whether the project files were compared; only .aepx files can be
+	Comps   []aepx.CompChange
+	Layers  []aepx.LayerChange
+	Effects []aepx.EffectChange
+}
+
+// DiffVersions compares two versions. Assets are compared from the records in
+// config.json; when both project files are .aepx, they are fetched from Docker and
+// their compositions, layers and effects compared too.
+func (p *Project) DiffVersions(from int, to int) (*VersionDiff, error) {
+	oldVersion, err := p.FindVersion(from)
+	if err != nil {
+		return nil, err
+	}
+	newVersion, err := p.FindVersion(to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &VersionDiff{
+		From:   from,
+		To:     to,
+		Assets: tracking.CreateTracking(newVersion.Number, newVersion.Message, trackingInputs(newVersion.Assets), trackingInputs(oldVersion.Assets)),
+	}
+	if p.Generic || !assets.IsAEPX(oldVersion.DockerPath) || !assets.IsAEPX(newVersion.DockerPath) {
+		return diff, nil
+	}
+
+	oldPath, oldCleanup, err := p.FetchVersionFile(from)
+	if err != nil {
+		return nil, err
+	}
+	defer oldCleanup()
+	newPath, newCleanup, err := p.FetchVersionFile(to)
+	if err != nil {
+		return nil, err
+	}
+	defer newCleanup()
+
+	oldComps, err := aepx.ExtractComps(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newComps, err := aepx.ExtractComps(newPath)
+	if err != nil {
+		return nil, err
+	}
+	oldEffects, err := aepx.ExtractEffects(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newEffects, err := aepx.ExtractEffects(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	diff.Parsed = true
+	diff.Comps = aepx.DiffComps(oldComps, newComps)
+	diff.Layers = aepx.DiffLayerDetails(oldComps, newComps)
+	diff.Effects = aepx.DiffEffects(oldEffects, newEffects)
+	return diff, nil
+}
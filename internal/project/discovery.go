@@ -0,0 +1,236 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// discoveryTimeout bounds project discovery, so a slow Docker daemon or a stalled
+// network home directory gives partial results instead of hanging
+const discoveryTimeout = 10 * time.Second
+
+// localConfig is a project config found on disk while discovering projects
+type localConfig struct {
+	id      string // Storage ID derived from the project name, matched against Docker folders
+	name    string // Project name without its extension
+	path    string // The config.json it was read from
+	project *Project
+}
+
+// dirScan is the configs found in one search directory
+type dirScan struct {
+	index   int
+	configs []localConfig
+}
+
+// dockerListing is the project folders found in Docker storage
+type dockerListing struct {
+	paths []string
+	err   error
+}
+
+// discoverySearchDirs returns the directories whose subfolders are searched for
+// project configs
+func discoverySearchDirs() []string {
+	home := os.Getenv("HOME")
+	return []string{
+		".",
+		filepath.Join(home, "Documents"),
+		filepath.Join(home, "Desktop"),
+		filepath.Join(home, "Projects"),
+	}
+}
+
+// GetAllProjects scans Docker storage and returns all projects. The Docker listing and
+// the search for local configs naming them run concurrently; whatever hasn't finished
+// within discoveryTimeout is skipped with a warning. If Docker storage doesn't answer
+// in time, the projects found on disk are returned instead.
+func GetAllProjects() ([]ProjectInfo, error) {
+	if err := docker.EnsureDockerReady(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+	defer cancel()
+
+	listed := make(chan dockerListing, 1)
+	go func() {
+		paths, err := listDockerProjects(ctx)
+		listed <- dockerListing{paths: paths, err: err}
+	}()
+
+	// Buffered so a scan stuck on a stalled directory can still finish after we stop waiting
+	dirs := discoverySearchDirs()
+	scans := make(chan dirScan, len(dirs))
+	for i, dir := range dirs {
+		go func(i int, dir string) {
+			scans <- dirScan{index: i, configs: scanLocalConfigs(dir)}
+		}(i, dir)
+	}
+
+	found := make([][]localConfig, len(dirs))
+	scanned := make([]bool, len(dirs))
+wait:
+	for pending := len(dirs); pending > 0; pending-- {
+		select {
+		case scan := <-scans:
+			found[scan.index] = scan.configs
+			scanned[scan.index] = true
+		case <-ctx.Done():
+			break wait
+		}
+	}
+	for i, dir := range dirs {
+		if !scanned[i] {
+			fmt.Println(ui.Warning(fmt.Sprintf("Skipped %s: it did not respond within %s", dir, discoveryTimeout)))
+		}
+	}
+	var configs []localConfig
+	for _, dirConfigs := range found {
+		configs = append(configs, dirConfigs...)
+	}
+
+	var listing dockerListing
+	select {
+	case listing = <-listed:
+	case <-ctx.Done():
+		listing = dockerListing{err: ctx.Err()}
+	}
+	if errors.Is(listing.err, context.DeadlineExceeded) {
+		fmt.Println(ui.Warning(fmt.Sprintf("Docker storage did not respond within %s; showing the %d project(s) found on disk", discoveryTimeout, len(configs))))
+		return projectsFromConfigs(configs), nil
+	}
+	if listing.err != nil {
+		return []ProjectInfo{}, nil // No projects found, return empty
+	}
+
+	var projects []ProjectInfo
+	seen := make(map[string]bool)
+	for _, projectPath := range listing.paths {
+		// Extract project name: could be direct child of /vervids or nested
+		relPath := strings.TrimPrefix(projectPath, docker.StoragePath+"/")
+		parts := strings.Split(relPath, "/")
+		projectName := parts[len(parts)-1]
+
+		// Use the name from a matching local config.json when there is one
+		for _, c := range configs {
+			if c.id == projectName || strings.Contains(projectName, c.id) || strings.Contains(c.id, projectName) {
+				projectName = c.name
+				break
+			}
+		}
+
+		// Use full path as unique key to avoid duplicates
+		if projectName != "" && !seen[projectPath] {
+			seen[projectPath] = true
+			projects = append(projects, ProjectInfo{
+				Name:       projectName,
+				DockerPath: projectPath,
+			})
+		}
+	}
+
+	return projects, nil
+}
+
+// GetRemoteProjects lists the projects stored in an S3-compatible bucket whose configs
+// are found on this machine, searching where GetAllProjects does. It doesn't need
+// Docker, and their ConfigPath is set.
+func GetRemoteProjects() []ProjectInfo {
+	projects := []ProjectInfo{}
+	seen := make(map[string]bool)
+	for _, dir := range discoverySearchDirs() {
+		for _, c := range scanLocalConfigs(dir) {
+			if c.project.Remote == nil {
+				continue
+			}
+			path, err := filepath.Abs(c.path)
+			if err != nil {
+				path = c.path
+			}
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			projects = append(projects, ProjectInfo{Name: c.name, ConfigPath: path})
+		}
+	}
+	return projects
+}
+
+// listDockerProjects lists the directories in Docker storage that contain version
+// folders (v000, v001, etc.), so it finds actual projects, not just top-level folders
+func listDockerProjects(ctx context.Context) ([]string, error) {
+	output, err := docker.ExecInContainerContext(ctx, "sh", "-c", fmt.Sprintf(
+		"find %s -type d -name 'v[0-9][0-9][0-9]' -mindepth 2 -maxdepth 2 | sed 's|/v[0-9][0-9][0-9]$||' | sort -u",
+		docker.StoragePath))
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+// scanLocalConfigs reads the project configs in the subfolders of dir
+func scanLocalConfigs(dir string) []localConfig {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var configs []localConfig
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name(), storage.VerVidsDir, storage.ConfigFile)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var proj Project
+		if json.Unmarshal(data, &proj) != nil {
+			continue
+		}
+		name := strings.TrimSuffix(proj.ProjectName, filepath.Ext(proj.ProjectName))
+		if name == "" {
+			continue
+		}
+		configs = append(configs, localConfig{id: sanitizeProjectName(name), name: name, path: path, project: &proj})
+	}
+	return configs
+}
+
+// projectsFromConfigs lists the projects whose configs were found on disk, for when
+// Docker storage can't be listed
+func projectsFromConfigs(configs []localConfig) []ProjectInfo {
+	projects := []ProjectInfo{}
+	seen := make(map[string]bool)
+	for _, c := range configs {
+		if c.project.Remote != nil {
+			continue // Stored in a bucket, not Docker
+		}
+		dockerPath := filepath.Join(docker.StoragePath, c.project.projectID())
+		if seen[dockerPath] {
+			continue
+		}
+		seen[dockerPath] = true
+		projects = append(projects, ProjectInfo{Name: c.name, DockerPath: dockerPath})
+	}
+	return projects
+}
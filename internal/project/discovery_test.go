@@ -0,0 +1,48 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ajeebtech/vervideos/internal/s3"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// writeConfig saves p as the config of a project folder under dir
+func writeConfig(t *testing.T, dir string, folder string, p *Project) string {
+	t.Helper()
+	path := filepath.Join(dir, folder, storage.VerVidsDir, storage.ConfigFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGetRemoteProjects(t *testing.T) {
+	home := inProjectDir(t)
+	documents := filepath.Join(home, "Documents")
+	remote := &s3.Config{URL: "s3://renders/studio"}
+	promo := writeConfig(t, documents, "promo", &Project{ProjectName: "promo.aepx", Remote: remote})
+	writeConfig(t, documents, "teaser", &Project{ProjectName: "teaser.aepx"})
+	trailer := writeConfig(t, filepath.Join(home, "Projects"), "trailer", &Project{ProjectName: "trailer.aepx", Remote: remote})
+
+	got := GetRemoteProjects()
+	want := map[string]string{"promo": promo, "trailer": trailer}
+	if len(got) != len(want) {
+		t.Fatalf("GetRemoteProjects() = %v, want %v", got, want)
+	}
+	for _, info := range got {
+		if want[info.Name] != info.ConfigPath {
+			t.Errorf("%s found at %q, want %q", info.Name, info.ConfigPath, want[info.Name])
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// HooksDir holds a project's hook scripts, under .vervids
+const HooksDir = "hooks"
+
+// Hooks run around commits
+const (
+	HookPreCommit  = "pre-commit"  // Before anything is stored; a failure cancels the commit
+	HookPostCommit = "post-commit" // After the version is saved; a failure is only reported
+)
+
+// hookPath returns the path of the named hook script in the project's .vervids folder
+func hookPath(name string) string {
+	return filepath.Join(storage.VerVidsDir, HooksDir, name)
+}
+
+// runHook runs the named hook script, if the project has one, from the project
+// directory. The project file and version are passed as arguments and in VERVIDS_*
+// variables. Output goes straight to the terminal.
+func (p *Project) runHook(name string, file string, version int) error {
+	path := hookPath(name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+	if info.Mode()&0111 == 0 {
+		return fmt.Errorf("hook %s is not executable (chmod +x %s)", name, path)
+	}
+	abs, _ := filepath.Abs(path)
+	cmd := exec.Command(abs, file, fmt.Sprintf("%d", version))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"VERVIDS_PROJECT="+p.ProjectName,
+		"VERVIDS_PROJECT_ID="+p.projectID(),
+		"VERVIDS_FILE="+file,
+		fmt.Sprintf("VERVIDS_VERSION=%d", version),
+	)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
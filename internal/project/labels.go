@@ -0,0 +1,148 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// Label is a colored marker such as "rough cut" or "final delivery" that can be
+// attached to any number of versions
+type Label struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"` // A ui label color name, ANSI 256 number or hex value
+}
+
+// HasLabel reports whether the label is attached to the version (case-insensitive)
+func (v *Version) HasLabel(name string) bool {
+	for _, l := range v.Labels {
+		if strings.EqualFold(l, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindLabel returns the project's label with the given name (case-insensitive), or nil
+func (p *Project) FindLabel(name string) *Label {
+	for i := range p.Labels {
+		if strings.EqualFold(p.Labels[i].Name, name) {
+			return &p.Labels[i]
+		}
+	}
+	return nil
+}
+
+// LabelColor returns the color of the named label, or "" if it isn't defined
+func (p *Project) LabelColor(name string) string {
+	if l := p.FindLabel(name); l != nil {
+		return l.Color
+	}
+	return ""
+}
+
+// LabelVersions returns the numbers of the versions the label is attached to
+func (p *Project) LabelVersions(name string) []int {
+	numbers := []int{}
+	for _, v := range p.Versions {
+		if v.HasLabel(name) {
+			numbers = append(numbers, v.Number)
+		}
+	}
+	return numbers
+}
+
+// AddLabel attaches a label to the given versions, defining it first if needed.
+// A new label without a color gets the next unused one; a color given for an
+// existing label changes it.
+func (p *Project) AddLabel(name string, color string, versions []int) (*Label, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("label name cannot be empty")
+	}
+	if color != "" {
+		var err error
+		if color, err = ui.ValidateLabelColor(color); err != nil {
+			return nil, err
+		}
+	}
+	for _, number := range versions {
+		if _, err := p.FindVersion(number); err != nil {
+			return nil, err
+		}
+	}
+
+	label := p.FindLabel(name)
+	if label == nil {
+		if color == "" {
+			color = ui.LabelColorNames[len(p.Labels)%len(ui.LabelColorNames)]
+		}
+		p.Labels = append(p.Labels, Label{Name: name, Color: color})
+		label = &p.Labels[len(p.Labels)-1]
+	} else if color != "" {
+		label.Color = color
+	}
+
+	for _, number := range versions {
+		v, _ := p.FindVersion(number) // Checked above
+		if !v.HasLabel(label.Name) {
+			v.Labels = append(v.Labels, label.Name)
+		}
+	}
+	return label, nil
+}
+
+// RemoveLabel detaches a label from the given versions. With no versions the label is
+// removed from every version and deleted. Returns the number of versions changed.
+func (p *Project) RemoveLabel(name string, versions []int) (int, error) {
+	label := p.FindLabel(name)
+	if label == nil {
+		return 0, fmt.Errorf("no label named '%s'", name)
+	}
+	name = label.Name
+
+	targets := versions
+	if len(versions) == 0 {
+		targets = p.LabelVersions(name)
+	}
+	changed := 0
+	for _, number := range targets {
+		v, err := p.FindVersion(number)
+		if err != nil {
+			return changed, err
+		}
+		kept := v.Labels[:0]
+		for _, l := range v.Labels {
+			if !strings.EqualFold(l, name) {
+				kept = append(kept, l)
+			}
+		}
+		if len(kept) != len(v.Labels) {
+			changed++
+		}
+		v.Labels = kept
+		if len(v.Labels) == 0 {
+			v.Labels = nil
+		}
+	}
+
+	if len(versions) == 0 {
+		defined := p.Labels[:0]
+		for _, l := range p.Labels {
+			if l.Name != name {
+				defined = append(defined, l)
+			}
+		}
+		p.Labels = defined
+	}
+	return changed, nil
+}
+
+// SortedLabels returns the project's labels sorted by name
+func (p *Project) SortedLabels() []Label {
+	labels := append([]Label{}, p.Labels...)
+	sort.Slice(labels, func(i, j int) bool { return strings.ToLower(labels[i].Name) < strings.ToLower(labels[j].Name) })
+	return labels
+}
@@ -0,0 +1,234 @@
+package project
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// VersionManifestFile is written once into each version folder in storage, describing
+// the version so it can be read without the project's config.json
+const VersionManifestFile = "manifest.json"
+
+// manifestFormat is the current VersionManifest format
+const manifestFormat = 1
+
+// VersionManifest describes exactly which project file, objects and hashes make up a
+// version. It is written when the version is committed and never changed; the
+// version's signature, when it has one, covers the same content.
+type VersionManifest struct {
+	Format   int     `json:"format"`
+	Project  string  `json:"project"`
+	Version  Version `json:"version"`  // As committed; labels and tags are left out as they change later
+	Checksum string  `json:"checksum"` // SHA-256 of the manifest with this field empty
+}
+
+// ManifestIssue describes a version whose manifest is missing, damaged or disagrees
+// with config.json
+type ManifestIssue struct {
+	Version int
+	Problem string
+	Missing bool // No manifest was written; one can be added from config.json
+}
+
+// newVersionManifest builds the checksummed manifest of a committed version
+func newVersionManifest(projectName string, v Version) (*VersionManifest, error) {
+	v.Labels = nil
+	v.Tags = nil
+	m := &VersionManifest{Format: manifestFormat, Project: projectName, Version: v}
+	sum, err := m.checksum()
+	if err != nil {
+		return nil, err
+	}
+	m.Checksum = sum
+	return m, nil
+}
+
+// checksum hashes the manifest as marshalled without its checksum
+func (m *VersionManifest) checksum() (string, error) {
+	unsummed := *m
+	unsummed.Checksum = ""
+	data, err := json.Marshal(unsummed)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// VerifyChecksum reports whether the manifest is as it was written
+func (m *VersionManifest) VerifyChecksum() error {
+	sum, err := m.checksum()
+	if err != nil {
+		return err
+	}
+	if sum != m.Checksum {
+		return fmt.Errorf("manifest checksum mismatch")
+	}
+	return nil
+}
+
+// writeVersionManifest stores the manifest of a committed version in its folder
+func (p *Project) writeVersionManifest(v *Version) error {
+	m, err := newVersionManifest(p.ProjectName, *v)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	store, err := p.store()
+	if err != nil {
+		return err
+	}
+	return store.Put(bytes.NewReader(data), int64(len(data)), filepath.Join(filepath.Dir(v.DockerPath), VersionManifestFile))
+}
+
+// WriteMissingManifest adds the manifest of a version committed before manifests were
+// written, from config.json. Existing manifests are never overwritten.
+func (p *Project) WriteMissingManifest(versionNum int) error {
+	v, err := p.FindVersion(versionNum)
+	if err != nil {
+		return err
+	}
+	if v.DockerPath == "" {
+		return fmt.Errorf("version %d has no Docker path", versionNum)
+	}
+	store, err := p.readyStore()
+	if err != nil {
+		return err
+	}
+	exists, err := store.Exists(filepath.Join(filepath.Dir(v.DockerPath), VersionManifestFile))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("version %d already has a manifest", versionNum)
+	}
+	return p.writeVersionManifest(v)
+}
+
+// ReadVersionManifest reads the manifest in a version folder in store, or nil if the
+// version has none. Storage that can't be read is an error.
+func ReadVersionManifest(store storage.Storage, versionDir string) (*VersionManifest, error) {
+	var buf bytes.Buffer
+	if err := store.Get(filepath.Join(versionDir, VersionManifestFile), &buf); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", VersionManifestFile, err)
+	}
+	var m VersionManifest
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", VersionManifestFile, err)
+	}
+	return &m, nil
+}
+
+// readVersionManifests reads the manifests of every version folder in dockerDir in one
+// pass, keyed by version number. Manifests failing their checksum are returned in bad.
+func readVersionManifests(dockerDir string) (map[int]*VersionManifest, []int, error) {
+	var buf bytes.Buffer
+	script := fmt.Sprintf("cat %q/v[0-9][0-9][0-9]/%s 2>/dev/null; true", dockerDir, VersionManifestFile)
+	if err := docker.StreamFromContainer(nil, &buf, "sh", "-c", script); err != nil {
+		return nil, nil, fmt.Errorf("failed to read manifests: %w", err)
+	}
+	manifests := make(map[int]*VersionManifest)
+	var bad []int
+	decoder := json.NewDecoder(&buf)
+	for {
+		var m VersionManifest
+		if err := decoder.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		if m.VerifyChecksum() != nil {
+			bad = append(bad, m.Version.Number)
+			continue
+		}
+		manifests[m.Version.Number] = &m
+	}
+	return manifests, bad, nil
+}
+
+// CheckManifests compares each stored version's manifest with config.json. Versions
+// whose storage is missing are skipped.
+func (p *Project) CheckManifests() ([]ManifestIssue, error) {
+	store, err := p.readyStore()
+	if err != nil {
+		return nil, err
+	}
+	issues := []ManifestIssue{}
+	for _, v := range p.Versions {
+		if v.DockerPath == "" {
+			continue
+		}
+		exists, err := store.Exists(v.DockerPath)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		m, err := ReadVersionManifest(store, filepath.Dir(v.DockerPath))
+		if err != nil {
+			issues = append(issues, ManifestIssue{Version: v.Number, Problem: err.Error()})
+			continue
+		}
+		if m == nil {
+			issues = append(issues, ManifestIssue{Version: v.Number, Problem: "manifest.json is missing", Missing: true})
+			continue
+		}
+		if err := m.VerifyChecksum(); err != nil {
+			issues = append(issues, ManifestIssue{Version: v.Number, Problem: err.Error()})
+			continue
+		}
+		if problem := manifestMismatch(&v, &m.Version); problem != "" {
+			issues = append(issues, ManifestIssue{Version: v.Number, Problem: problem})
+		}
+	}
+	return issues, nil
+}
+
+// manifestMismatch returns why a manifest's record of a version disagrees with
+// config.json, or ""
+func manifestMismatch(v *Version, recorded *Version) string {
+	if recorded.Number != v.Number {
+		return fmt.Sprintf("manifest is for v%d", recorded.Number)
+	}
+	if !sameVersion(*v, recorded.Timestamp, recorded.FileHash) {
+		return "manifest records a different project file"
+	}
+	hashes, recordedHashes := assetHashes(v.Assets), assetHashes(recorded.Assets)
+	if len(hashes) != len(recordedHashes) {
+		return fmt.Sprintf("manifest lists %d asset(s), config has %d", len(recordedHashes), len(hashes))
+	}
+	for i := range hashes {
+		if hashes[i] != recordedHashes[i] {
+			return "manifest records different asset content"
+		}
+	}
+	return ""
+}
+
+// assetHashes returns the sorted content hashes of a version's assets
+func assetHashes(assets []AssetInfo) []string {
+	hashes := make([]string, len(assets))
+	for i, a := range assets {
+		hashes[i] = a.Hash
+	}
+	sort.Strings(hashes)
+	return hashes
+}
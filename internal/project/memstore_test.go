@@ -0,0 +1,177 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memStore is storage held in memory, for testing project operations without Docker
+// or a bucket. err, when set, fails every read and write, like storage that can't be
+// reached.
+type memStore struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	err   error
+}
+
+func newMemStore() *memStore {
+	return &memStore{files: map[string][]byte{}}
+}
+
+func (m *memStore) Name() string       { return "memory" }
+func (m *memStore) EnsureReady() error { return m.err }
+
+func (m *memStore) Exists(path string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return false, m.err
+	}
+	for name := range m.files {
+		if name == path || strings.HasPrefix(name, path+"/") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memStore) MakeDir(path string) error { return m.err }
+
+func (m *memStore) PutFile(localPath string, path string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	return m.Put(bytes.NewReader(data), int64(len(data)), path)
+}
+
+func (m *memStore) Put(r io.Reader, size int64, path string) error {
+	if m.err != nil {
+		return m.err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[path] = data
+	return nil
+}
+
+func (m *memStore) PutDir(localDir string, dir string) error {
+	return filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		return m.PutFile(path, filepath.Join(dir, rel))
+	})
+}
+
+func (m *memStore) GetFile(path string, localPath string) error {
+	var buf bytes.Buffer
+	if err := m.Get(path, &buf); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(localPath, buf.Bytes(), 0644)
+}
+
+func (m *memStore) Get(path string, w io.Writer) error {
+	m.mu.Lock()
+	data, ok := m.files[path]
+	err := m.err
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (m *memStore) Concat(dir string, names []string, w io.Writer) error {
+	for _, name := range names {
+		if err := m.Get(filepath.Join(dir, name), w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memStore) List(dir string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	var paths []string
+	for name := range m.files {
+		if strings.HasPrefix(name, dir+"/") {
+			paths = append(paths, name)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (m *memStore) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	for name := range m.files {
+		if name == path || strings.HasPrefix(name, path+"/") {
+			delete(m.files, name)
+		}
+	}
+	return nil
+}
+
+// read returns the file at path, failing the test when there is none
+func (m *memStore) read(t *testing.T, path string) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[path]
+	if !ok {
+		t.Fatalf("%s is not in storage", path)
+	}
+	return string(data)
+}
+
+// inProjectDir runs the test in an empty directory with a .vervids folder, where
+// Save writes config.json, and with its own home directory for settings
+func inProjectDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if err := os.MkdirAll(".vervids", 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
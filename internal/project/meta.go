@@ -0,0 +1,75 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VersionFilter selects versions for log, list and the API; empty fields match everything
+type VersionFilter struct {
+	Label       string
+	Meta        map[string]string // Metadata the version must carry; an empty value only requires the key
+	NoAutosaves bool              // Leave out versions committed by the autosave scheduler
+}
+
+// IsEmpty reports whether the filter matches every version
+func (f VersionFilter) IsEmpty() bool {
+	return f.Label == "" && len(f.Meta) == 0 && !f.NoAutosaves
+}
+
+// Matches reports whether the version passes the filter
+func (f VersionFilter) Matches(v *Version) bool {
+	if f.Label != "" && !v.HasLabel(f.Label) {
+		return false
+	}
+	if f.NoAutosaves && v.IsAutosave() {
+		return false
+	}
+	for key, value := range f.Meta {
+		got, ok := v.Meta[key]
+		if !ok || (value != "" && got != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the project's versions that pass the filter, oldest first
+func (f VersionFilter) Filter(versions []Version) []*Version {
+	matched := []*Version{}
+	for i := range versions {
+		if f.Matches(&versions[i]) {
+			matched = append(matched, &versions[i])
+		}
+	}
+	return matched
+}
+
+// ParseMeta parses key=value pairs such as "ticket=VFX-123" into a metadata map. With
+// keyOnly, a bare key is accepted and maps to "" (used by filters to require the key).
+func ParseMeta(pairs []string, keyOnly bool) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	meta := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if key == "" || strings.ContainsAny(key, " \t") || (!found && !keyOnly) {
+			return nil, fmt.Errorf("invalid metadata '%s' (expected key=value)", pair)
+		}
+		meta[key] = strings.TrimSpace(value)
+	}
+	return meta, nil
+}
+
+// MetaPairs returns metadata as key=value strings sorted by key
+func MetaPairs(meta map[string]string) []string {
+	pairs := make([]string, 0, len(meta))
+	for key, value := range meta {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
@@ -0,0 +1,285 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+const (
+	// StagingDirName is the directory under the cache dir holding offline commits
+	StagingDirName = "staging"
+
+	// StagedFile describes one staged commit inside its staging directory
+	StagedFile = "staged.json"
+)
+
+// StagedCommit is a version snapshotted to the local staging area: the .aepx and its
+// changed assets are copied there before anything is uploaded, and pushed into Docker
+// right away by 'vervids commit', or later by 'vervids push' when that upload failed or
+// the commit was made with --offline
+type StagedCommit struct {
+	ProjectName string             `json:"project_name"`
+	ConfigPath  string             `json:"config_path"`  // Absolute path to the project's .vervids/config.json
+	ProjectPath string             `json:"project_path"` // The .aepx as it was committed
+	Message     string             `json:"message"`
+	Timestamp   time.Time          `json:"timestamp"`
+	Author      string             `json:"author,omitempty"`
+	Meta        map[string]string  `json:"meta,omitempty"`
+	Assets      []assets.Asset     `json:"assets"` // Source is relative to the staging directory
+	Missing     []string           `json:"missing,omitempty"`
+	TotalSize   int64              `json:"total_size"`
+	Comps       []aepx.CompSummary `json:"comps,omitempty"`
+
+	dir string
+}
+
+// StagingDir returns the directory holding all offline commits
+func StagingDir() string {
+	return filepath.Join(storage.CacheDir(), StagingDirName)
+}
+
+// StageCommit records a commit without Docker: the .aepx is parsed and hashed as for a
+// normal commit, and the file and its assets are copied into the staging area. Assets
+// whose content the project already stores are not copied. The version number is
+// assigned when the commit is pushed.
+func (p *Project) StageCommit(message string, aepxFilePath string, opts CommitOptions) (*StagedCommit, error) {
+	if err := p.CheckWritable(); err != nil {
+		return nil, err
+	}
+	configPath, err := filepath.Abs(storage.GetConfigPath())
+	if err != nil {
+		return nil, err
+	}
+
+	parseResult, err := parseProjectFile(aepxFilePath, p.commitParseOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse .aepx file: %w", err)
+	}
+	if len(parseResult.ExcludedAssets) > 0 {
+		fmt.Println(ui.Info(fmt.Sprintf("Skipped %d file(s) excluded by asset rules", len(parseResult.ExcludedAssets))))
+	}
+	policy := opts.MissingAssets
+	if policy == "" {
+		policy = p.MissingAssets
+	}
+	if err := checkMissingAssets(parseResult, policy); err != nil {
+		return nil, err
+	}
+	warnCaseCollisions(parseResult.Assets)
+
+	staged := &StagedCommit{
+		ProjectName: p.ProjectName,
+		ConfigPath:  configPath,
+		ProjectPath: aepxFilePath,
+		Message:     message,
+		Timestamp:   time.Now(),
+		Author:      p.commitAuthor(),
+		Meta:        opts.Meta,
+		Assets:      []assets.Asset{},
+		Missing:     parseResult.MissingAssets,
+		TotalSize:   parseResult.TotalSize,
+		Comps:       parseResult.Comps,
+	}
+	staged.dir = filepath.Join(StagingDir(), p.projectID(), staged.Timestamp.Format("20060102-150405.000"))
+	if err := os.MkdirAll(staged.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	if err := staged.copyFiles(p, aepxFilePath, parseResult.Assets); err != nil {
+		os.RemoveAll(staged.dir)
+		return nil, err
+	}
+	if err := staged.save(); err != nil {
+		os.RemoveAll(staged.dir)
+		return nil, err
+	}
+	return staged, nil
+}
+
+// copyFiles copies the .aepx and the content of each asset p doesn't already store into
+// the staging directory. Asset copies are named by content hash, so a file referenced
+// twice is kept once; stored assets only keep their hash.
+func (s *StagedCommit) copyFiles(p *Project, aepxFilePath string, parsed []assets.Asset) error {
+	if err := storage.CopyFile(aepxFilePath, s.projectFile()); err != nil {
+		return fmt.Errorf("failed to stage project file: %w", err)
+	}
+
+	cache := storage.LoadHashCache()
+	defer func() {
+		if err := cache.Save(); err != nil {
+			fmt.Println(ui.Warning(fmt.Sprintf("Failed to save hash cache: %v", err)))
+		}
+	}()
+
+	return ui.RunStep(fmt.Sprintf("Staging %d asset(s)", len(parsed)), func() error {
+		for _, asset := range parsed {
+			hash, _, err := cache.Hash(asset.Path)
+			if err != nil {
+				fmt.Println(ui.Warning(fmt.Sprintf("Failed to hash asset %s: %v", asset.Filename, err)))
+				continue
+			}
+			if p.storedAsset(hash) != nil {
+				asset.Hash = hash
+				s.Assets = append(s.Assets, asset)
+				continue
+			}
+			object := filepath.Join("objects", hash[:2], hash+strings.ToLower(asset.Extension))
+			if _, err := os.Stat(filepath.Join(s.dir, object)); os.IsNotExist(err) {
+				if err := storage.CopyFile(asset.Path, filepath.Join(s.dir, object)); err != nil {
+					return fmt.Errorf("failed to stage asset %s: %w", asset.Filename, err)
+				}
+			}
+			asset.Source = object
+			s.Assets = append(s.Assets, asset)
+		}
+		return nil
+	})
+}
+
+// projectFile returns the staged copy of the .aepx. It keeps the original file name,
+// which the project's Docker directory is named after.
+func (s *StagedCommit) projectFile() string {
+	return filepath.Join(s.dir, filepath.Base(s.ProjectPath))
+}
+
+// Dir returns the staging directory holding the commit's files
+func (s *StagedCommit) Dir() string {
+	return s.dir
+}
+
+// Size returns the bytes staged for the commit
+func (s *StagedCommit) Size() int64 {
+	var total int64
+	filepath.Walk(s.dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+func (s *StagedCommit) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal staged commit: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, StagedFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write staged commit: %w", err)
+	}
+	return nil
+}
+
+// parseResult returns the staged assets as a parse result whose sources point at the
+// staged copies
+func (s *StagedCommit) parseResult() *assets.ParseResult {
+	result := &assets.ParseResult{
+		ProjectFile:   s.projectFile(),
+		Assets:        make([]assets.Asset, len(s.Assets)),
+		MissingAssets: s.Missing,
+		TotalSize:     s.TotalSize,
+		Comps:         s.Comps,
+	}
+	for i, asset := range s.Assets {
+		if asset.Source != "" {
+			asset.Source = filepath.Join(s.dir, asset.Source)
+		}
+		result.Assets[i] = asset
+	}
+	return result
+}
+
+// Remove deletes the staged files, e.g. once the commit has been pushed
+func (s *StagedCommit) Remove() error {
+	if err := os.RemoveAll(s.dir); err != nil {
+		return err
+	}
+	// Drop the project's staging directory once nothing is left in it
+	os.Remove(filepath.Dir(s.dir))
+	return nil
+}
+
+// LoadStagedCommits returns every staged commit, oldest first
+func LoadStagedCommits() ([]*StagedCommit, error) {
+	files, err := filepath.Glob(filepath.Join(StagingDir(), "*", "*", StagedFile))
+	if err != nil {
+		return nil, err
+	}
+	staged := []*StagedCommit{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read staged commit: %w", err)
+		}
+		var s StagedCommit
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		s.dir = filepath.Dir(file)
+		staged = append(staged, &s)
+	}
+	sort.SliceStable(staged, func(i, j int) bool { return staged[i].Timestamp.Before(staged[j].Timestamp) })
+	return staged, nil
+}
+
+// CommitStaged stores a staged commit in Docker as the project's next version, keeping
+// the message, time, author and metadata it was staged with
+func (p *Project) CommitStaged(s *StagedCommit, forceQuota bool) (*Version, error) {
+	return p.CommitWithOptions(s.Message, s.projectFile(), CommitOptions{staged: s, ForceQuota: forceQuota})
+}
+
+// refusedError is a commit the project turned down, e.g. by its pre-commit hook, rather
+// than one that failed to reach storage. Pushing its snapshot again fails the same way.
+type refusedError struct {
+	err error
+}
+
+func (e *refusedError) Error() string { return e.err.Error() }
+func (e *refusedError) Unwrap() error { return e.err }
+
+// refused marks err as the project turning the commit down
+func refused(err error) error {
+	return &refusedError{err: err}
+}
+
+// IsRefused reports whether a commit failed because the project turned it down, so a
+// staged snapshot of it is no use to keep, rather than because storage couldn't be
+// reached or written
+func IsRefused(err error) bool {
+	var r *refusedError
+	return errors.As(err, &r)
+}
+
+// storedAsset returns an asset entry whose content is in the project's storage, newest
+// version first, or nil when no version stores the hash
+func (p *Project) storedAsset(hash string) *AssetInfo {
+	for i := len(p.Versions) - 1; i >= 0; i-- {
+		if a := p.Versions[i].findAssetByHash(hash); a != nil && a.DockerPath != "" && !a.Purged {
+			return a
+		}
+	}
+	return nil
+}
+
+// checkUnchanged fails when the file at path no longer has the content staged as hash
+func checkUnchanged(path string, hash string) error {
+	current, err := storage.HashFile(path)
+	if err != nil {
+		return err
+	}
+	if current != hash {
+		return fmt.Errorf("changed since it was staged")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// writeHook installs a pre-commit hook that records the file it was given in hook.log
+// and exits with code
+func writeHook(t *testing.T, code string) string {
+	t.Helper()
+	path := hookPath(HookPreCommit)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\necho \"$1\" > hook.log\nexit " + code + "\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return "hook.log"
+}
+
+func TestCommitStaged(t *testing.T) {
+	tests := []struct {
+		name        string
+		hookCode    string
+		storeErr    bool
+		wantRefused bool
+	}{
+		{name: "stored", hookCode: "0"},
+		{name: "hook refuses", hookCode: "1", wantRefused: true},
+		{name: "storage failing", hookCode: "0", storeErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := inProjectDir(t)
+			store := newMemStore()
+			p := &Project{ProjectName: "brief.txt", Generic: true, backend: store}
+			file := filepath.Join(dir, "brief.txt")
+			if err := os.WriteFile(file, []byte("final cut"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			log := writeHook(t, tt.hookCode)
+
+			s, err := p.StageCommit("Final", file, CommitOptions{})
+			if err != nil {
+				t.Fatalf("StageCommit() error = %v", err)
+			}
+			if !strings.HasPrefix(s.Dir(), storage.CacheDir()) {
+				t.Errorf("staged in %s, want under %s", s.Dir(), storage.CacheDir())
+			}
+			if tt.storeErr {
+				store.err = errors.New("connection reset")
+			}
+
+			v, err := p.CommitStaged(s, false)
+			if got := IsRefused(err); got != tt.wantRefused {
+				t.Errorf("IsRefused(%v) = %v, want %v", err, got, tt.wantRefused)
+			}
+			if (err != nil) != (tt.wantRefused || tt.storeErr) {
+				t.Fatalf("CommitStaged() = %v, %v", v, err)
+			}
+			if err == nil && v.FilePath != file {
+				t.Errorf("version committed from %s, want %s", v.FilePath, file)
+			}
+
+			// The hook is given the artist's file, not the staged copy
+			got, err := os.ReadFile(log)
+			if err != nil {
+				t.Fatalf("hook didn't run: %v", err)
+			}
+			if strings.TrimSpace(string(got)) != file {
+				t.Errorf("hook given %q, want %q", strings.TrimSpace(string(got)), file)
+			}
+		})
+	}
+}
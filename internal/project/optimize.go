@@ -0,0 +1,223 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/chunks"
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// OptimizeStep is one object 'vervids optimize' rewrites
+type OptimizeStep struct {
+	From   string // Current object path
+	To     string // Pool object or chunk manifest it moves to
+	Hash   string // Content hash; computed in the container when the assets have none
+	Size   int64
+	Chunk  bool // Split into chunks rather than moved
+	Reused bool // To already holds the content, so From is only deleted
+}
+
+// OptimizeResult reports what 'vervids optimize' did
+type OptimizeResult struct {
+	Done   []OptimizeStep
+	Freed  int64    // Bytes no longer stored
+	Failed []string // Objects left as they were, with the reason
+}
+
+// OptimizePlan lists the stored objects not yet in the current storage format: copies
+// outside the content-addressed pool (from before it existed) and, when chunking is
+// enabled, whole files at or above the chunk threshold. Proxies, pinned objects and
+// purged assets are left alone. Needs Docker.
+func (p *Project) OptimizePlan(chunk bool) ([]OptimizeStep, error) {
+	if err := p.requireDocker("optimize"); err != nil {
+		return nil, err
+	}
+	poolDir := filepath.Join(docker.StoragePath, p.projectID(), "assets")
+	steps := []OptimizeStep{}
+	seen := make(map[string]bool)
+	for _, v := range p.Versions {
+		for _, a := range v.Assets {
+			if a.Purged || a.Chunked || a.ProxyOnly || a.DockerPath == "" || seen[a.DockerPath] || p.IsPinned(a.DockerPath) {
+				continue
+			}
+			seen[a.DockerPath] = true
+
+			hash := a.Hash
+			inPool := hash != "" && a.DockerPath == assetObjectPath(poolDir, hash, a.Extension)
+			bigEnough := chunk && p.ChunkThresholdMB > 0 && a.Size >= p.ChunkThresholdMB*1024*1024
+			if inPool && !bigEnough {
+				continue
+			}
+			if hash == "" {
+				var err error
+				if hash, err = containerHash(a.DockerPath); err != nil {
+					return nil, err
+				}
+			}
+
+			step := OptimizeStep{From: a.DockerPath, Hash: hash, Size: a.Size, Chunk: bigEnough}
+			if bigEnough {
+				step.To = filepath.Join(poolDir, hash[:2], hash+".chunks.json")
+			} else {
+				step.To = assetObjectPath(poolDir, hash, a.Extension)
+			}
+			step.Reused = docker.PathExistsInContainer(step.To)
+			steps = append(steps, step)
+		}
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Size > steps[j].Size })
+	return steps, nil
+}
+
+// Optimize carries out the plan from OptimizePlan. Every copy is checked against its
+// hash before anything changes, config.json is saved as each object is rewritten, and
+// an old copy is only deleted once no version or pin refers to it any more. A failed
+// object is reported and left in place; the rest carry on.
+func (p *Project) Optimize(steps []OptimizeStep) (*OptimizeResult, error) {
+	if err := p.CheckWritable(); err != nil {
+		return nil, err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := p.requireDocker("optimize"); err != nil {
+		return nil, err
+	}
+	if err := docker.EnsureDockerReady(); err != nil {
+		return nil, err
+	}
+	result := &OptimizeResult{}
+	for _, step := range steps {
+		freed, err := p.optimizeObject(&step)
+		if err != nil {
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", step.From, err))
+			continue
+		}
+		result.Done = append(result.Done, step)
+		result.Freed += freed
+	}
+	return result, nil
+}
+
+// optimizeObject rewrites one object and returns the bytes freed
+func (p *Project) optimizeObject(step *OptimizeStep) (int64, error) {
+	if got, err := containerHash(step.From); err != nil {
+		return 0, err
+	} else if got != step.Hash {
+		return 0, fmt.Errorf("stored copy does not match its hash (%s), left as is", shortHash(got))
+	}
+
+	var added int64
+	switch {
+	case step.Chunk:
+		stored, err := chunkObject(step)
+		if err != nil {
+			return 0, err
+		}
+		added = stored
+	case docker.PathExistsInContainer(step.To):
+		if got, err := containerHash(step.To); err != nil {
+			return 0, err
+		} else if got != step.Hash {
+			return 0, fmt.Errorf("pool object %s does not match its hash", step.To)
+		}
+		step.Reused = true
+	default:
+		if err := docker.CreateDirectory(filepath.Dir(step.To)); err != nil {
+			return 0, err
+		}
+		partialPath := step.To + ".partial"
+		if _, err := docker.ExecInContainer("cp", step.From, partialPath); err != nil {
+			return 0, err
+		}
+		if got, err := containerHash(partialPath); err != nil || got != step.Hash {
+			docker.DeleteDirectory(partialPath)
+			return 0, fmt.Errorf("copy to %s did not verify", step.To)
+		}
+		if _, err := docker.ExecInContainer("mv", "-f", partialPath, step.To); err != nil {
+			return 0, err
+		}
+		added = step.Size
+	}
+
+	for i := range p.Versions {
+		for j := range p.Versions[i].Assets {
+			a := &p.Versions[i].Assets[j]
+			if a.DockerPath != step.From || a.Purged {
+				continue
+			}
+			a.DockerPath = step.To
+			a.Hash = step.Hash
+			a.Chunked = step.Chunk
+		}
+	}
+	if removed, err := p.releaseObject(step.From); err != nil || !removed {
+		return 0, err
+	}
+	return step.Size - added, nil
+}
+
+// chunkObject splits a stored whole file into the project's chunk store and writes its
+// manifest at step.To. Returns the bytes the new chunks take up.
+func chunkObject(step *OptimizeStep) (int64, error) {
+	if step.Reused {
+		return 0, nil
+	}
+	tmpDir, err := storage.MkdirTemp("optimize-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	localPath := filepath.Join(tmpDir, filepath.Base(step.From))
+	if err := docker.CopyFromContainer(step.From, localPath); err != nil {
+		return 0, err
+	}
+	if got, err := storage.HashFile(localPath); err != nil {
+		return 0, err
+	} else if got != step.Hash {
+		return 0, fmt.Errorf("copy out of Docker did not verify")
+	}
+
+	// The pool is <project>/assets; chunks live next to it in <project>/chunks
+	chunksDir := filepath.Join(filepath.Dir(filepath.Dir(filepath.Dir(step.To))), "chunks")
+	result, err := chunks.Store(docker.Volume{}, localPath, chunksDir)
+	if err != nil {
+		return 0, err
+	}
+	if result.Manifest.Hash != step.Hash {
+		return 0, fmt.Errorf("chunked content does not match its hash")
+	}
+	if err := chunks.SaveManifest(docker.Volume{}, result.Manifest, step.To); err != nil {
+		return 0, err
+	}
+	return result.NewBytes, nil
+}
+
+// containerHash returns the SHA-256 of a file inside the container
+func containerHash(path string) (string, error) {
+	output, err := docker.ExecInContainer("sha256sum", path)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("failed to hash %s: no output", path)
+	}
+	return fields[0], nil
+}
+
+// shortHash returns the first characters of a hash for messages
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -0,0 +1,219 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// PendingAsset is an asset referenced by an .aepx, compared with the last commit
+type PendingAsset struct {
+	Path   string
+	Size   int64
+	Status string // "new", "modified" or "unchanged"
+	Upload bool   // Content is not stored in any version yet and will be copied
+}
+
+// PendingCommit describes what committing an .aepx would store
+type PendingCommit struct {
+	ProjectFileSize int64
+	Assets          []PendingAsset
+	Removed         []string // Assets of the compared version the file no longer references
+	Missing         []string
+	Excluded        int   // Assets skipped by asset rules
+	UploadSize      int64 // Project file plus asset content not stored yet
+}
+
+// Count returns the number of pending assets with the given status
+func (pc *PendingCommit) Count(status string) int {
+	count := 0
+	for _, a := range pc.Assets {
+		if a.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// PendingChanges parses an .aepx and compares its assets with the last commit without
+// touching Docker storage. Content already stored by any version counts as reused, so
+// UploadSize estimates the transfer before proxies and chunking.
+func (p *Project) PendingChanges(aepxFilePath string) (*PendingCommit, error) {
+	return p.pendingChanges(aepxFilePath, p.GetLatestVersion())
+}
+
+// PendingChangesSince is like PendingChanges but compares the assets with the given
+// version instead of the last commit, e.g. to see how far a file drifted from it
+func (p *Project) PendingChangesSince(aepxFilePath string, versionNum int) (*PendingCommit, error) {
+	version, err := p.FindVersion(versionNum)
+	if err != nil {
+		return nil, err
+	}
+	return p.pendingChanges(aepxFilePath, version)
+}
+
+// pendingChanges compares the assets of an .aepx with those of base (nil for none)
+func (p *Project) pendingChanges(aepxFilePath string, base *Version) (*PendingCommit, error) {
+	fileSize, err := storage.GetFileSize(aepxFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file size: %w", err)
+	}
+	parsed, err := parseProjectFile(aepxFilePath, p.parseOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse .aepx file: %w", err)
+	}
+
+	stored := make(map[string]bool)
+	for _, v := range p.Versions {
+		for _, a := range v.Assets {
+			if a.Hash != "" {
+				stored[a.Hash] = true
+			}
+		}
+	}
+	previous := make(map[string]AssetInfo)
+	if base != nil {
+		for _, a := range base.Assets {
+			previous[a.OriginalPath] = a
+		}
+	}
+
+	pending := &PendingCommit{
+		ProjectFileSize: fileSize,
+		Missing:         parsed.MissingAssets,
+		Excluded:        len(parsed.ExcludedAssets),
+		UploadSize:      fileSize,
+	}
+	cache := storage.LoadHashCache()
+	defer cache.Save()
+	referenced := make(map[string]bool)
+	for _, asset := range parsed.Assets {
+		referenced[asset.Path] = true
+		hash, _, err := cache.Hash(asset.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", asset.Filename, err)
+		}
+
+		pa := PendingAsset{Path: asset.Path, Size: asset.Size, Status: "new", Upload: !stored[hash]}
+		if prev, ok := previous[asset.Path]; ok {
+			pa.Status = "modified"
+			if prev.Hash == hash && prev.Size == asset.Size {
+				pa.Status = "unchanged"
+			}
+		}
+		if pa.Upload {
+			pending.UploadSize += asset.Size
+			stored[hash] = true // Identical files in one commit are copied once
+		}
+		pending.Assets = append(pending.Assets, pa)
+	}
+	for path := range previous {
+		if !referenced[path] {
+			pending.Removed = append(pending.Removed, path)
+		}
+	}
+	sort.Strings(pending.Removed)
+	return pending, nil
+}
+
+// recentFileDepth is how far below each directory RecentProjectFiles looks
+const recentFileDepth = 2
+
+// RecentProjectFiles returns project files near the project, most recently modified
+// first: under the directory of the last committed file and under dir (usually the
+// directory holding .vervids)
+func (p *Project) RecentProjectFiles(dir string, limit int) []string {
+	roots := []string{dir}
+	if p.ProjectPath != "" {
+		roots = append(roots, filepath.Dir(p.ProjectPath))
+	}
+
+	type found struct {
+		path    string
+		modTime int64
+	}
+	seen := make(map[string]bool)
+	files := []found{}
+	for _, root := range roots {
+		root, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		baseDepth := strings.Count(root, string(filepath.Separator))
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && (strings.HasPrefix(d.Name(), ".") || strings.Count(path, string(filepath.Separator))-baseDepth >= recentFileDepth) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !p.acceptsFile(path) || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			if info, err := os.Stat(path); err == nil {
+				files = append(files, found{path, info.ModTime().UnixNano()})
+			}
+			return nil
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime > files[j].modTime })
+	paths := []string{}
+	for i := 0; i < len(files) && i < limit; i++ {
+		paths = append(paths, files[i].path)
+	}
+	return paths
+}
+
+// acceptsFile reports whether path can be committed to the project: a file of a
+// supported format, or for generic projects, one with the same extension as the project
+func (p *Project) acceptsFile(path string) bool {
+	if p.Generic {
+		return strings.EqualFold(filepath.Ext(path), filepath.Ext(p.ProjectPath))
+	}
+	return assets.IsProjectFile(path)
+}
+
+// WorkingFile returns the project file the project was last committed from that is
+// still on disk, or "" if none is. Versions committed from elsewhere, e.g. a revert's
+// temporary file, are skipped.
+func (p *Project) WorkingFile() string {
+	for i := len(p.Versions) - 1; i >= 0; i-- {
+		if path := p.Versions[i].FilePath; path != "" {
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// WorkingFileChanged reports whether the working file has been saved since the newest
+// version with different content, i.e. holds uncommitted work. The file is only
+// hashed when it was modified after the commit, through cache.
+func (p *Project) WorkingFileChanged(cache *storage.HashCache) bool {
+	latest := p.GetLatestVersion()
+	path := p.WorkingFile()
+	if latest == nil || path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.ModTime().After(latest.Timestamp) {
+		return false
+	}
+	if latest.FileHash == "" {
+		return true
+	}
+	hash, _, err := cache.Hash(path)
+	return err == nil && hash != latest.FileHash
+}
@@ -0,0 +1,123 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+)
+
+// PinnedAsset is stored content that must be kept even when no version references it,
+// e.g. licensed stock footage that has to be retained
+type PinnedAsset struct {
+	Filename   string    `json:"filename"`
+	Hash       string    `json:"hash"`
+	DockerPath string    `json:"docker_path"`
+	Version    int       `json:"version"` // Version the pinned content was taken from
+	PinnedAt   time.Time `json:"pinned_at"`
+}
+
+// PinAsset pins the newest stored content of the asset with the given filename or
+// relative path. Pinning an already pinned asset is a no-op.
+func (p *Project) PinAsset(name string) (*PinnedAsset, error) {
+	for i := len(p.Versions) - 1; i >= 0; i-- {
+		v := &p.Versions[i]
+		for _, a := range v.Assets {
+			if !assetMatchesName(&a, name) || a.DockerPath == "" {
+				continue
+			}
+			if pin := p.findPin(a.Hash, a.DockerPath); pin != nil {
+				return pin, nil
+			}
+			p.PinnedAssets = append(p.PinnedAssets, PinnedAsset{
+				Filename:   a.Filename,
+				Hash:       a.Hash,
+				DockerPath: a.DockerPath,
+				Version:    v.Number,
+				PinnedAt:   time.Now(),
+			})
+			return &p.PinnedAssets[len(p.PinnedAssets)-1], nil
+		}
+	}
+	return nil, fmt.Errorf("no stored asset named '%s'", name)
+}
+
+// UnpinAsset removes every pin for the asset with the given filename. Returns the number removed.
+func (p *Project) UnpinAsset(name string) int {
+	kept := make([]PinnedAsset, 0, len(p.PinnedAssets))
+	for _, pin := range p.PinnedAssets {
+		if !strings.EqualFold(pin.Filename, name) && !strings.EqualFold(pin.Filename, filepath.Base(name)) {
+			kept = append(kept, pin)
+		}
+	}
+	removed := len(p.PinnedAssets) - len(kept)
+	p.PinnedAssets = kept
+	return removed
+}
+
+// IsPinned reports whether the object at dockerPath is pinned
+func (p *Project) IsPinned(dockerPath string) bool {
+	return p.findPin("", dockerPath) != nil
+}
+
+// LiveObjects returns every Docker object the project must keep: those referenced by
+// a version (files, proxies, chunk manifests) plus pinned assets. Anything that cleans
+// up storage has to leave these in place.
+func (p *Project) LiveObjects() map[string]bool {
+	live := make(map[string]bool)
+	for _, v := range p.Versions {
+		for _, a := range v.Assets {
+			if a.Purged {
+				continue
+			}
+			if a.DockerPath != "" {
+				live[a.DockerPath] = true
+			}
+			if a.Proxy != nil {
+				live[a.Proxy.DockerPath] = true
+			}
+		}
+	}
+	for _, pin := range p.PinnedAssets {
+		live[pin.DockerPath] = true
+	}
+	return live
+}
+
+// releaseObject saves the config, whose references have been moved off the object at
+// path, and then deletes the object unless something else still uses it. Saving first
+// means config.json never points at a removed object. Returns whether it was deleted.
+func (p *Project) releaseObject(path string) (bool, error) {
+	if err := p.Save(); err != nil {
+		return false, fmt.Errorf("failed to save config: %w", err)
+	}
+	if p.LiveObjects()[path] {
+		return false, nil
+	}
+	if err := docker.DeleteDirectory(path); err != nil {
+		return false, fmt.Errorf("references moved, but the old copy could not be removed: %w", err)
+	}
+	return true, nil
+}
+
+// findPin returns the pin for the given content hash or object path, or nil
+func (p *Project) findPin(hash string, dockerPath string) *PinnedAsset {
+	for i := range p.PinnedAssets {
+		pin := &p.PinnedAssets[i]
+		if (hash != "" && pin.Hash == hash) || pin.DockerPath == dockerPath {
+			return pin
+		}
+	}
+	return nil
+}
+
+// assetMatchesName reports whether name is the asset's filename or relative path
+func assetMatchesName(a *AssetInfo, name string) bool {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.EqualFold(a.Filename, name) {
+		return true
+	}
+	return a.RelativePath != "" && strings.EqualFold(strings.ReplaceAll(a.RelativePath, "\\", "/"), name)
+}
@@ -3,13 +3,23 @@ package project
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/ajeebtech/vervideos/internal/aepx"
 	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/chunks"
 	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/logging"
+	"github.com/ajeebtech/vervideos/internal/media"
+	"github.com/ajeebtech/vervideos/internal/proxy"
+	"github.com/ajeebtech/vervideos/internal/s3"
+	"github.com/ajeebtech/vervideos/internal/settings"
+	"github.com/ajeebtech/vervideos/internal/signing"
 	"github.com/ajeebtech/vervideos/internal/storage"
 	"github.com/ajeebtech/vervideos/internal/tracking"
 	"github.com/ajeebtech/vervideos/internal/ui"
@@ -17,48 +27,140 @@ import (
 
 // AssetInfo represents an asset file tracked in a version
 type AssetInfo struct {
-	OriginalPath string `json:"original_path"`
-	RelativePath string `json:"relative_path"`
-	Filename     string `json:"filename"`
-	Extension    string `json:"extension"`
-	Size         int64  `json:"size"`
-	DockerPath   string `json:"docker_path"`
+	OriginalPath   string              `json:"original_path"`
+	RelativePath   string              `json:"relative_path"`
+	Filename       string              `json:"filename"`
+	Extension      string              `json:"extension"`
+	Size           int64               `json:"size"`
+	Hash           string              `json:"hash,omitempty"` // SHA-256 of the content, used as the pool key
+	DockerPath     string              `json:"docker_path"`
+	LinkPaths      []string            `json:"link_paths,omitempty"`     // Symlinks that pointed at this file when committed
+	Proxy          *ProxyInfo          `json:"proxy,omitempty"`          // Low-resolution proxy of oversized footage
+	ProxyOnly      bool                `json:"proxy_only,omitempty"`     // Only the proxy is stored; Hash identifies the original
+	Chunked        bool                `json:"chunked,omitempty"`        // DockerPath is a chunk manifest, not the file itself
+	Media          *media.Info         `json:"media,omitempty"`          // Codec/resolution/duration probed on commit
+	FoundIn        string              `json:"found_in,omitempty"`       // Search path the asset was found under, if it had moved
+	Cloud          string              `json:"cloud,omitempty"`          // Sync client of the folder it was committed from (Dropbox, Google Drive, ...)
+	Purged         bool                `json:"purged,omitempty"`         // Content removed from storage with 'vervids du'; can't be restored
+	Interpretation aepx.Interpretation `json:"interpretation,omitempty"` // How the .aepx interprets the footage (frame rate, alpha, color profile)
+}
+
+// ProxyInfo describes a stored proxy for an asset
+type ProxyInfo struct {
+	DockerPath string `json:"docker_path"`
+	Hash       string `json:"hash"`
+	Size       int64  `json:"size"`
+}
+
+// DefaultChunkThresholdMB is the chunk threshold new projects start with: footage of a
+// gigabyte or more is stored as content-defined chunks, so a re-export that changed
+// slightly only adds the chunks that differ instead of a second copy of the file
+const DefaultChunkThresholdMB = 1024
+
+// ProxySettings controls proxy generation for oversized footage on commit
+type ProxySettings struct {
+	ThresholdMB int64  `json:"threshold_mb,omitempty"` // Footage at least this large gets a proxy (0 = disabled)
+	Mode        string `json:"mode,omitempty"`         // "both" (default) or "proxy_only"
+	Height      int    `json:"height,omitempty"`       // Proxy height in pixels (default 540)
 }
 
 // Version represents a single version/commit of the project
 type Version struct {
-	Number       int         `json:"number"`
-	Message      string      `json:"message"`
-	Timestamp    time.Time   `json:"timestamp"`
-	Size         int64       `json:"size"`
-	FilePath     string      `json:"file_path"`
-	DockerPath   string      `json:"docker_path"`
-	Assets       []AssetInfo `json:"assets"`
-	AssetCount   int         `json:"asset_count"`
-	TotalSize    int64       `json:"total_size"`
+	Number       int                 `json:"number"`
+	Message      string              `json:"message"`
+	Timestamp    time.Time           `json:"timestamp"`
+	Size         int64               `json:"size"`
+	FilePath     string              `json:"file_path"`
+	DockerPath   string              `json:"docker_path"`
+	Assets       []AssetInfo         `json:"assets"`
+	AssetCount   int                 `json:"asset_count"`
+	TotalSize    int64               `json:"total_size"`
+	LayerChanges []aepx.LayerChanges `json:"layer_changes,omitempty"`  // Per-comp layer changes since the previous version
+	Markers      []aepx.Marker       `json:"markers,omitempty"`        // Marker comments and comp notes found in the project
+	Comps        []aepx.CompSummary  `json:"comps,omitempty"`          // Compositions with their size, duration, frame rate and layer count
+	AppVersion   *aepx.AppVersion    `json:"app_version,omitempty"`    // After Effects release that saved the project file
+	Missing      []string            `json:"missing_assets,omitempty"` // Referenced assets that could not be found on commit
+	Author       string              `json:"author,omitempty"`         // Who committed, from the author settings
+	Labels       []string            `json:"labels,omitempty"`         // Names of the project labels attached to the version
+	Tags         []string            `json:"tags,omitempty"`           // Unique names that stand for the version's number, e.g. "client-review-1"
+	Meta         map[string]string   `json:"meta,omitempty"`           // Key-value metadata given with --meta, e.g. a tracker ticket
+	FileHash     string              `json:"file_hash,omitempty"`      // SHA-256 of the project file as committed
+	Signature    *signing.Signature  `json:"signature,omitempty"`      // Signed manifest, when a signing key is configured
 }
 
 // Project represents a vervids project
 type Project struct {
-	ProjectName  string    `json:"project_name"`
-	ProjectPath  string    `json:"project_path"`
-	CreatedAt    time.Time `json:"created_at"`
-	Versions     []Version `json:"versions"`
-    UseDocker    bool      `json:"use_docker"`
-	DockerVolume string    `json:"docker_volume,omitempty"`
+	ProjectName      string               `json:"project_name"`
+	ProjectPath      string               `json:"project_path"`
+	CreatedAt        time.Time            `json:"created_at"`
+	Versions         []Version            `json:"versions"`
+	UseDocker        bool                 `json:"use_docker"`
+	DockerVolume     string               `json:"docker_volume,omitempty"`
+	PathMappings     []assets.PathMapping `json:"path_mappings,omitempty"`
+	ResolveSymlinks  bool                 `json:"resolve_symlinks,omitempty"`
+	AssetRules       assets.Rules         `json:"asset_rules"`
+	Proxy            ProxySettings        `json:"proxy"`
+	ChunkThresholdMB int64                `json:"chunk_threshold_mb,omitempty"`
+	SearchPaths      []string             `json:"search_paths,omitempty"`
+	MissingAssets    string               `json:"missing_assets,omitempty"` // Default missing-asset policy for commits
+	PinnedAssets     []PinnedAsset        `json:"pinned_assets,omitempty"`
+	Author           string               `json:"author,omitempty"`         // Overrides the global author for this project's commits
+	Notes            string               `json:"notes,omitempty"`          // Free-form Markdown kept with the project (brief, delivery specs, licenses)
+	Labels           []Label              `json:"labels,omitempty"`         // Labels and milestones that can be attached to versions
+	QuotaGB          int64                `json:"quota_gb,omitempty"`       // Storage quota; commits past it need --force-quota
+	Generic          bool                 `json:"generic,omitempty"`        // Any file, snapshotted whole without asset parsing
+	Autosave         *AutosaveSettings    `json:"autosave,omitempty"`       // Scheduled commits run by 'vervids autosave run'
+	PublishedFrom    string               `json:"published_from,omitempty"` // Host and directory of the copy that last published the config to storage
+	ReadOnly         bool                 `json:"read_only,omitempty"`      // Archived: commit, delete, prune and other writes are blocked
+	Restores         []RestoreRecord      `json:"restores,omitempty"`       // Working-file restores by 'vervids restore --in-place', oldest first
+	Remote           *s3.Config           `json:"remote,omitempty"`         // S3-compatible bucket holding the versions; nil for Docker
+
+	backend    storage.Storage // Replaces Remote and Docker as the project's storage when set, as tests do
+	loadedFrom string          // Absolute path of the config.json p was read from or saved to
+	loadedHash string          // That config's content hash then, to notice other writes
+	writing    bool            // p holds the project's write lock
 }
 
-// Initialize creates a new project with the initial version (Docker-only storage)
-func Initialize(aepxFilePath string) (*Project, error) {
+// Missing-asset policies for commits
+const (
+	MissingAllow  = "allow"  // Record missing assets on the version and commit anyway
+	MissingStrict = "strict" // Refuse to commit while any asset is missing
+)
+
+// CommitOptions configures a single commit
+type CommitOptions struct {
+	MissingAssets string            // MissingAllow or MissingStrict; empty uses the project default
+	Meta          map[string]string // Key-value metadata stored on the version
+	ForceQuota    bool              // Commit even if it takes the project past its storage quota
+	Timestamp     time.Time         // Backdates the version; zero means now
+
+	staged *StagedCommit // Commit a version staged offline instead of parsing the file
+}
+
+// InitOptions configures a newly initialized project
+type InitOptions struct {
+	ResolveSymlinks bool      // Store symlinked assets under their targets
+	Generic         bool      // Version any file as a whole, without asset parsing
+	Template        *Template // Seeds folders, files, hooks and settings; nil for none
+}
+
+// Initialize creates a new project with the initial version, stored in the remote set
+// up with 'vervids remote add' or else in Docker
+func Initialize(aepxFilePath string, opts InitOptions) (*Project, error) {
+	if err := CheckVaultWritable(); err != nil {
+		return nil, err
+	}
+
     // Create .vervids directory structure (local metadata)
     if err := storage.Initialize(); err != nil {
         return nil, fmt.Errorf("failed to create .vervids directory: %w", err)
     }
 
-    // Ensure Docker is ready
-    if err := docker.EnsureDockerReady(); err != nil {
-        return nil, err
-    }
+	// New projects go to the configured remote, if any
+	var remote *s3.Config
+	if s, err := settings.Load(); err == nil {
+		remote = s.Remote
+	}
 
 	// Get file info
 	fileSize, err := storage.GetFileSize(aepxFilePath)
@@ -72,8 +174,27 @@ func Initialize(aepxFilePath string) (*Project, error) {
 		ProjectPath:  aepxFilePath,
 		CreatedAt:    time.Now(),
 		Versions:     []Version{},
-        UseDocker:    true,
+        UseDocker:    remote == nil,
 		DockerVolume: docker.VolumeName,
+		ResolveSymlinks: opts.ResolveSymlinks,
+		Generic:      opts.Generic,
+		Remote:       remote,
+		ChunkThresholdMB: DefaultChunkThresholdMB,
+	}
+	if remote != nil {
+		proj.DockerVolume = ""
+	}
+	store, err := proj.readyStore()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Template != nil {
+		if err := opts.Template.seed("."); err != nil {
+			return nil, fmt.Errorf("failed to apply template %s: %w", opts.Template.Name, err)
+		}
+		if err := opts.Template.apply(proj); err != nil {
+			return nil, fmt.Errorf("failed to apply template %s: %w", opts.Template.Name, err)
+		}
 	}
 
 	// Create initial version (version 0)
@@ -85,96 +206,543 @@ func Initialize(aepxFilePath string) (*Project, error) {
 		Assets:     []AssetInfo{},
 		AssetCount: 0,
 		TotalSize:  fileSize,
+		Author:     proj.commitAuthor(),
+	}
+	if abs, err := filepath.Abs(aepxFilePath); err == nil {
+		version.FilePath = abs // Where the project is being worked on, for 'vervids list'
 	}
 
 	// Parse .aepx file for assets
-	parseResult, err := assets.ParseAEPX(aepxFilePath, "")
+	parseResult, err := parseProjectFile(aepxFilePath, proj.commitParseOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse .aepx file: %w", err)
 	}
+	if err := checkMissingAssets(parseResult, MissingAllow); err != nil {
+		return nil, err
+	}
+	warnCaseCollisions(parseResult.Assets)
+	version.Missing = parseResult.MissingAssets
+	key, err := signingKey()
+	if err != nil {
+		return nil, err
+	}
 
-    // Store the project file and assets in Docker
+    // Store the project file and assets
     // Use project filename (without extension) as project ID
     versionDir := fmt.Sprintf("v%03d", version.Number)
     projectBaseName := strings.TrimSuffix(filepath.Base(aepxFilePath), filepath.Ext(aepxFilePath))
     projectID := sanitizeProjectName(projectBaseName)
     dockerVersionDir := filepath.Join(docker.StoragePath, projectID, versionDir)
 
-    if err := docker.CreateDirectory(dockerVersionDir); err != nil {
-        return nil, fmt.Errorf("failed to create version directory in Docker: %w", err)
+    if err := store.MakeDir(dockerVersionDir); err != nil {
+        return nil, fmt.Errorf("failed to create version directory in %s: %w", store.Name(), err)
     }
 
     // Copy .aepx file
     dockerProjectPath := filepath.Join(dockerVersionDir, filepath.Base(aepxFilePath))
-    if err := docker.CopyToContainer(aepxFilePath, dockerProjectPath); err != nil {
-        return nil, fmt.Errorf("failed to copy project file to Docker: %w", err)
+    if err := ui.RunStep("Copying project file", func() error { return store.PutFile(aepxFilePath, dockerProjectPath) }); err != nil {
+        return nil, fmt.Errorf("failed to copy project file to %s: %w", store.Name(), err)
     }
     version.DockerPath = dockerProjectPath
 
     // Create shared assets directory at project level (not per version)
     // Use the same projectID from above
     sharedAssetsDir := filepath.Join(docker.StoragePath, projectID, "assets")
-    if err := docker.CreateDirectory(sharedAssetsDir); err != nil {
-        return nil, fmt.Errorf("failed to create shared assets directory in Docker: %w", err)
+    if err := store.MakeDir(sharedAssetsDir); err != nil {
+        return nil, fmt.Errorf("failed to create shared assets directory in %s: %w", store.Name(), err)
     }
 
-    // Copy assets (only if they don't already exist in shared pool)
-    for _, asset := range parseResult.Assets {
-        sharedAssetPath := filepath.Join(sharedAssetsDir, asset.Filename)
-        
-        // Check if asset already exists
-        if !docker.PathExistsInContainer(sharedAssetPath) {
-            // Copy new asset to shared pool
-            if err := docker.CopyToContainer(asset.Path, sharedAssetPath); err != nil {
-                fmt.Println(ui.Warning(fmt.Sprintf("Failed to copy asset %s: %v", asset.Filename, err)))
-                continue
-            }
-            fmt.Println(ui.Success(fmt.Sprintf("Copied new asset: %s", asset.Filename)))
-        } else {
-            fmt.Println(ui.Success(fmt.Sprintf("Reusing existing asset: %s", asset.Filename)))
-        }
-        
-        // Reference shared asset (not version-specific)
-        version.Assets = append(version.Assets, AssetInfo{
-            OriginalPath: asset.Path,
-            RelativePath: asset.RelativePath,
-            Filename:     asset.Filename,
-            Extension:    asset.Extension,
-            Size:         asset.Size,
-            DockerPath:   sharedAssetPath, // Point to shared location
-        })
-    }
+    // Copy assets into the content-addressed shared pool (reusing identical files)
+    version.Assets = proj.storeAssets(store, sharedAssetsDir, parseResult.Assets, nil)
+	if !proj.Generic && assets.IsAEPX(aepxFilePath) {
+		recordInterpretations(aepxFilePath, parseResult.Assets, version.Assets)
+		version.AppVersion = readAppVersion(aepxFilePath)
+		version.Comps = parseResult.Comps
+	}
 
 	version.AssetCount = len(version.Assets)
 	version.TotalSize = parseResult.TotalSize
 
 	// Convert AssetInfo to AssetInfoInput for tracking
-	currentAssetsInput := make([]tracking.AssetInfoInput, len(version.Assets))
-	for i, asset := range version.Assets {
-		currentAssetsInput[i] = tracking.AssetInfoInput{
-			Filename:   asset.Filename,
-			Extension:  asset.Extension,
-			Size:       asset.Size,
-			DockerPath: asset.DockerPath,
-		}
+	currentAssetsInput := trackingInputs(version.Assets)
+
+	if err := proj.signVersion(&version, aepxFilePath, key); err != nil {
+		return nil, err
 	}
 
 	// Create asset tracking for initial version (no previous version to compare)
 	track := tracking.CreateTracking(version.Number, version.Message, currentAssetsInput, []tracking.AssetInfoInput{})
-	if err := tracking.SaveTracking(version.Number, dockerVersionDir, track); err != nil {
+	track.SetProjectFile(version.DockerPath, version.Size, version.FileHash)
+	if err := tracking.SaveTracking(store, dockerVersionDir, track); err != nil {
 		fmt.Println(ui.Warning(fmt.Sprintf("Failed to save asset tracking: %v", err)))
 	}
-
+	if err := proj.writeVersionManifest(&version); err != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to save version manifest: %v", err)))
+	}
 	proj.Versions = append(proj.Versions, version)
 
 	// Save config
 	if err := proj.Save(); err != nil {
 		return nil, fmt.Errorf("failed to save config: %w", err)
 	}
+	proj.publishMetadata()
 
 	return proj, nil
 }
 
+// parseOptions returns the parser options for this project, combining project-level
+// path mappings with the user's global ones (project mappings take precedence)
+func (p *Project) parseOptions() assets.ParseOptions {
+	opts := assets.ParseOptions{ResolveSymlinks: p.ResolveSymlinks, Rules: p.AssetRules, Generic: p.Generic}
+	opts.PathMappings = append(opts.PathMappings, p.PathMappings...)
+	if s, err := settings.Load(); err == nil {
+		opts.PathMappings = append(opts.PathMappings, s.PathMappings...)
+		// Global excludes apply on top of the project's rules
+		opts.Rules.ExcludeExtensions = append(append([]string{}, p.AssetRules.ExcludeExtensions...), s.ExcludeExtensions...)
+		opts.Rules.ExcludeFolders = append(append([]string{}, p.AssetRules.ExcludeFolders...), s.ExcludeFolders...)
+	}
+
+	// Hashes from the last commit pick the right file when a search finds several with one name
+	opts.SearchPaths = p.SearchPaths
+	if len(p.SearchPaths) > 0 && len(p.Versions) > 0 {
+		opts.KnownHashes = make(map[string]string)
+		for _, a := range p.Versions[len(p.Versions)-1].Assets {
+			if a.Hash != "" {
+				opts.KnownHashes[a.OriginalPath] = a.Hash
+			}
+		}
+	}
+	return opts
+}
+
+// commitParseOptions returns the parser options for a commit, which downloads assets
+// that are online-only in a synced folder rather than storing their placeholders
+func (p *Project) commitParseOptions() assets.ParseOptions {
+	opts := p.parseOptions()
+	opts.HydrateCloud = true
+	return opts
+}
+
+// commitAuthor returns the author recorded on new commits: the project's own author
+// setting, or the global one from settings.yaml
+func (p *Project) commitAuthor() string {
+	if p.Author != "" {
+		return p.Author
+	}
+	if s, err := settings.Load(); err == nil {
+		return s.Author.String()
+	}
+	return ""
+}
+
+// checkMissingAssets applies a missing-asset policy: strict fails the commit, otherwise
+// each missing asset is listed so it never goes unnoticed. Online-only files that
+// couldn't be downloaded are named as such, since they exist but aren't on this disk.
+func checkMissingAssets(result *assets.ParseResult, policy string) error {
+	if len(result.Hydrated) > 0 {
+		fmt.Println(ui.Info(fmt.Sprintf("Downloaded %d online-only asset(s) from cloud storage", len(result.Hydrated))))
+	}
+	missing := result.MissingAssets
+	if len(missing) == 0 {
+		return nil
+	}
+	for _, path := range missing {
+		if provider, ok := result.Placeholders[path]; ok {
+			fmt.Println(ui.Warning(fmt.Sprintf("Missing asset: %s is an online-only %s placeholder; make it available offline and commit again", path, provider)))
+			continue
+		}
+		fmt.Println(ui.Warning(fmt.Sprintf("Missing asset: %s", path)))
+	}
+	if policy == MissingStrict {
+		return fmt.Errorf("%d asset(s) missing; fix the paths or commit with --allow-missing", len(missing))
+	}
+	fmt.Println(ui.Warning(fmt.Sprintf("Committing without %d missing asset(s); they are recorded on the version", len(missing))))
+	return nil
+}
+
+// warnCaseCollisions warns about assets whose paths differ only by case. Each is stored
+// under its own content hash and a pull puts them in separate folders, but the project
+// can't be opened as is on macOS or Windows, where both names are one file.
+func warnCaseCollisions(parsed []assets.Asset) {
+	for _, group := range assets.CaseCollisions(parsed) {
+		fmt.Println(ui.Warning(fmt.Sprintf("Assets differ only by case and collide on macOS and Windows: %s", strings.Join(group, ", "))))
+	}
+}
+
+// projectID returns the project's directory name in Docker storage, derived from the
+// project file name (same logic as Initialize/Commit)
+func (p *Project) projectID() string {
+	projectBaseName := strings.TrimSuffix(filepath.Base(p.ProjectPath), filepath.Ext(p.ProjectPath))
+	return sanitizeProjectName(projectBaseName)
+}
+
+// assetObjectPath returns the collision-free location of an asset in the shared pool.
+// Assets are keyed by content hash so two different files with the same name never
+// overwrite each other, and identical files stored under different names are kept once.
+func assetObjectPath(sharedAssetsDir string, hash string, ext string) string {
+	return filepath.Join(sharedAssetsDir, hash[:2], hash+strings.ToLower(ext))
+}
+
+// parseProjectFile parses a project file (.aepx, .nk) for asset references, showing
+// it as a step since large projects take a while
+func parseProjectFile(path string, opts assets.ParseOptions) (*assets.ParseResult, error) {
+	step := ui.StartStep(fmt.Sprintf("Parsing %s", filepath.Base(path)))
+	result, err := assets.ParseProjectFile(path, opts)
+	if err != nil {
+		step.Fail()
+		return nil, err
+	}
+	step.Done()
+	return result, nil
+}
+
+// storeObject copies a local file to objectPath in the pool unless it is already there.
+// Returns true when existing content was reused. The copy is shown on progress, if set.
+func storeObject(store storage.Storage, localPath string, objectPath string, progress *ui.Progress) (bool, error) {
+	exists, err := store.Exists(objectPath)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return true, nil
+	}
+	if err := store.MakeDir(filepath.Dir(objectPath)); err != nil {
+		return false, err
+	}
+	// The store puts the object in place whole, so an interrupted copy is never mistaken for a stored object
+	if progress == nil {
+		if err := store.PutFile(localPath, objectPath); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	// Streamed straight to the store, so the progress follows the upload itself
+	err = store.Put(progress.Reader(filepath.Base(localPath), info.Size(), f), info.Size(), objectPath)
+	progress.EndFile()
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// storeAssets copies parsed assets into the shared pool, skipping content that is
+// already stored, and returns the version's asset entries. Oversized footage gets a
+// low-resolution proxy when the project's proxy settings ask for one. Completed
+// transfers are recorded in journal (if not nil) so an interrupted commit can resume.
+func (p *Project) storeAssets(store storage.Storage, sharedAssetsDir string, parsed []assets.Asset, journal *storage.Journal) []AssetInfo {
+	stored := []AssetInfo{}
+	proxiesEnabled := p.Proxy.ThresholdMB > 0
+	if proxiesEnabled && !proxy.IsFFmpegInstalled() {
+		fmt.Println(ui.Warning("Proxy generation is enabled but ffmpeg was not found; storing originals only"))
+		proxiesEnabled = false
+	}
+
+	// Unchanged files (same size and mtime as last commit) skip hashing and storage checks.
+	// Staged copies are hashed directly and leave the cache alone, so it isn't pruned of
+	// the project's real files.
+	cache := storage.LoadHashCache()
+	cacheUsed := false
+	defer func() {
+		if !cacheUsed {
+			return
+		}
+		if err := cache.Save(); err != nil {
+			fmt.Println(ui.Warning(fmt.Sprintf("Failed to save hash cache: %v", err)))
+		}
+	}()
+	var previous *Version
+	if len(p.Versions) > 0 {
+		previous = &p.Versions[len(p.Versions)-1]
+	}
+	unchanged := 0
+	probeEnabled := media.IsFFprobeInstalled()
+
+	var totalSize int64
+	for _, asset := range parsed {
+		totalSize += asset.Size
+	}
+	progress := ui.NewProgress(totalSize)
+
+	for _, asset := range parsed {
+		progress.Next(asset.Size)
+		// content is the file actually read: the asset itself, or its staged copy
+		content := asset
+		var hash string
+		var cached bool
+		var err error
+		if asset.Source != "" {
+			content.Path = asset.Source
+			hash, err = storage.HashFile(asset.Source)
+		} else if asset.Hash != "" {
+			// Staged without a copy because the project already stored it; the original is
+			// only read if that content has to be stored again
+			hash = asset.Hash
+			if prev := p.storedAsset(hash); prev == nil || !p.storageUpToDate(prev, proxiesEnabled) {
+				err = checkUnchanged(asset.Path, hash)
+			}
+		} else {
+			cacheUsed = true
+			hash, cached, err = cache.Hash(asset.Path)
+		}
+		if err != nil {
+			fmt.Println(ui.Warning(fmt.Sprintf("Failed to hash asset %s: %v", asset.Filename, err)))
+			continue
+		}
+		slog.Debug("asset hashed", "file", asset.Path, "hash", hash, "cached", cached, "size", asset.Size)
+		info := AssetInfo{
+			OriginalPath: asset.Path,
+			RelativePath: asset.RelativePath,
+			Filename:     asset.Filename,
+			Extension:    asset.Extension,
+			Size:         asset.Size,
+			Hash:         hash,
+			LinkPaths:    asset.LinkPaths,
+			FoundIn:      asset.FoundIn,
+			Cloud:        asset.Cloud,
+		}
+		if asset.FoundIn != "" {
+			fmt.Println(ui.Info(fmt.Sprintf("Found missing asset %s under %s", asset.Filename, asset.FoundIn)))
+		}
+
+		var prev *AssetInfo
+		if asset.Hash != "" {
+			prev = p.storedAsset(hash)
+		} else if cached && previous != nil {
+			prev = previous.findAssetByHash(hash)
+		}
+		if prev != nil && p.storageUpToDate(prev, proxiesEnabled) {
+			info.DockerPath = prev.DockerPath
+			info.Proxy = prev.Proxy
+			info.ProxyOnly = prev.ProxyOnly
+			info.Chunked = prev.Chunked
+			info.Media = prev.Media
+			stored = append(stored, info)
+			unchanged++
+			continue
+		}
+
+		// Record technical metadata so `show --detail` works without the original file
+		if probeEnabled && media.IsProbeable(asset.Extension) {
+			if mediaInfo, err := media.Probe(content.Path); err == nil {
+				info.Media = mediaInfo
+			}
+		}
+
+		// Transcode oversized footage to a proxy before deciding what to store
+		if proxiesEnabled && proxy.IsVideo(asset.Extension) && asset.Size >= p.Proxy.ThresholdMB*1024*1024 {
+			if entry, ok := journal.Get("proxy:" + hash); ok {
+				// Transcoded and stored before the interruption
+				info.Proxy = &ProxyInfo{DockerPath: entry.Path, Hash: entry.Hash, Size: entry.Size}
+				fmt.Println(ui.Success(fmt.Sprintf("Proxy already stored: %s", proxy.ProxyFilename(asset.Filename, asset.Extension))))
+				if p.Proxy.Mode == proxy.ModeProxyOnly {
+					info.ProxyOnly = true
+					info.DockerPath = entry.Path
+					stored = append(stored, info)
+					continue
+				}
+			} else if proxyInfo, err := storeProxy(store, sharedAssetsDir, content, p.Proxy.Height); err != nil {
+				fmt.Println(ui.Warning(fmt.Sprintf("Failed to create proxy for %s: %v", asset.Filename, err)))
+			} else {
+				info.Proxy = proxyInfo
+				journal.Record("proxy:"+hash, storage.JournalEntry{Path: proxyInfo.DockerPath, Hash: proxyInfo.Hash, Size: proxyInfo.Size})
+				fmt.Println(ui.Success(fmt.Sprintf("Stored proxy: %s (%.2f MB)", proxy.ProxyFilename(asset.Filename, asset.Extension), float64(proxyInfo.Size)/(1024*1024))))
+				if p.Proxy.Mode == proxy.ModeProxyOnly {
+					// Keep only the proxy; the original is referenced by its hash
+					info.ProxyOnly = true
+					info.DockerPath = proxyInfo.DockerPath
+					stored = append(stored, info)
+					continue
+				}
+			}
+		}
+
+		// Large files are stored as content-defined chunks so re-exports only add changed chunks
+		if p.ChunkThresholdMB > 0 && asset.Size >= p.ChunkThresholdMB*1024*1024 {
+			manifestPath, err := storeChunked(store, sharedAssetsDir, content, hash)
+			if err != nil {
+				fmt.Println(ui.Warning(fmt.Sprintf("Failed to chunk asset %s, storing whole file: %v", asset.Filename, err)))
+			} else {
+				info.DockerPath = manifestPath
+				info.Chunked = true
+				journal.Record("object:"+hash, storage.JournalEntry{Path: manifestPath, Hash: hash, Size: asset.Size})
+				stored = append(stored, info)
+				continue
+			}
+		}
+
+		objectPath := assetObjectPath(sharedAssetsDir, hash, asset.Extension)
+		if entry, ok := journal.Get("object:" + hash); ok && entry.Path == objectPath {
+			fmt.Println(ui.Success(fmt.Sprintf("Already copied: %s", asset.Filename)))
+			info.DockerPath = objectPath
+			stored = append(stored, info)
+			continue
+		}
+		start := time.Now()
+		reused, err := storeObject(store, content.Path, objectPath, progress)
+		if err != nil {
+			slog.Error("asset copy failed", "file", asset.Path, "object", objectPath, "err", err)
+			fmt.Println(ui.Warning(fmt.Sprintf("Failed to copy asset %s: %v", asset.Filename, err)))
+			continue
+		}
+		slog.Info("asset stored", "file", asset.Filename, "object", objectPath, "reused", reused, "took", logging.Since(start))
+		journal.Record("object:"+hash, storage.JournalEntry{Path: objectPath, Hash: hash, Size: asset.Size})
+		if reused {
+			fmt.Println(ui.Success(fmt.Sprintf("Reusing existing asset: %s", asset.Filename)))
+		} else {
+			fmt.Println(ui.Success(fmt.Sprintf("Copied new asset: %s (%.2f MB)", asset.Filename, float64(asset.Size)/(1024*1024))))
+		}
+
+		info.DockerPath = objectPath // Point to shared location
+		stored = append(stored, info)
+	}
+
+	if unchanged > 0 {
+		fmt.Println(ui.Info(fmt.Sprintf("Skipped %d unchanged asset(s) since last commit", unchanged)))
+	}
+	return stored
+}
+
+// storageUpToDate reports whether a previously stored asset still matches the project's
+// proxy and chunking settings, so an unchanged file can reuse it without re-storing
+func (p *Project) storageUpToDate(prev *AssetInfo, proxiesEnabled bool) bool {
+	if prev.DockerPath == "" || prev.Purged {
+		return false
+	}
+	wantsProxy := proxiesEnabled && proxy.IsVideo(prev.Extension) && prev.Size >= p.Proxy.ThresholdMB*1024*1024
+	if wantsProxy != (prev.Proxy != nil) || prev.ProxyOnly != (wantsProxy && p.Proxy.Mode == proxy.ModeProxyOnly) {
+		return false
+	}
+	if prev.ProxyOnly {
+		return true
+	}
+	wantsChunks := p.ChunkThresholdMB > 0 && prev.Size >= p.ChunkThresholdMB*1024*1024
+	return wantsChunks == prev.Chunked
+}
+
+// storeChunked stores an asset in the project's chunk store and returns the path of
+// its chunk manifest, which takes the place of the whole-file object in the pool
+func storeChunked(store storage.Storage, sharedAssetsDir string, asset assets.Asset, hash string) (string, error) {
+	manifestPath := filepath.Join(sharedAssetsDir, hash[:2], hash+".chunks.json")
+	exists, err := store.Exists(manifestPath)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		fmt.Println(ui.Success(fmt.Sprintf("Reusing existing asset: %s", asset.Filename)))
+		return manifestPath, nil
+	}
+
+	chunksDir := filepath.Join(filepath.Dir(sharedAssetsDir), "chunks")
+	result, err := chunks.Store(store, asset.Path, chunksDir)
+	if err != nil {
+		return "", err
+	}
+	if err := chunks.SaveManifest(store, result.Manifest, manifestPath); err != nil {
+		return "", err
+	}
+
+	fmt.Println(ui.Success(fmt.Sprintf("Chunked asset: %s (%d/%d chunks new, %.2f of %.2f MB stored)",
+		asset.Filename, result.NewChunks, result.TotalChunks,
+		float64(result.NewBytes)/(1024*1024), float64(asset.Size)/(1024*1024))))
+	return manifestPath, nil
+}
+
+// storeProxy transcodes an asset to a proxy in a temp directory and stores it in the pool
+func storeProxy(store storage.Storage, sharedAssetsDir string, asset assets.Asset, height int) (*ProxyInfo, error) {
+	tmpDir, err := storage.MkdirTemp("proxy-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	proxyPath := filepath.Join(tmpDir, proxy.ProxyFilename(asset.Filename, asset.Extension))
+	if err := proxy.Generate(asset.Path, proxyPath, height); err != nil {
+		return nil, err
+	}
+
+	hash, err := storage.HashFile(proxyPath)
+	if err != nil {
+		return nil, err
+	}
+	size, err := storage.GetFileSize(proxyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	objectPath := assetObjectPath(sharedAssetsDir, hash, proxy.ProxyExt)
+	if _, err := storeObject(store, proxyPath, objectPath, nil); err != nil {
+		return nil, err
+	}
+	return &ProxyInfo{DockerPath: objectPath, Hash: hash, Size: size}, nil
+}
+
+// recordInterpretations gives each stored asset the footage interpretation (frame rate,
+// alpha, color profile) the .aepx sets for it, so a change to those settings shows up
+// even when the file itself is unchanged
+func recordInterpretations(aepxFilePath string, parsed []assets.Asset, stored []AssetInfo) {
+	interpretations, err := aepx.ExtractInterpretations(aepxFilePath)
+	if err != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to read footage interpretation: %v", err)))
+		return
+	}
+	byPath := make(map[string]aepx.Interpretation)
+	for _, asset := range parsed {
+		if interp, ok := interpretations[asset.Reference]; ok {
+			byPath[asset.Path] = interp
+		}
+	}
+	for i := range stored {
+		stored[i].Interpretation = byPath[stored[i].OriginalPath]
+	}
+}
+
+// readAppVersion reads which After Effects release saved an .aepx file, warning
+// rather than failing when it can't be read
+func readAppVersion(aepxFilePath string) *aepx.AppVersion {
+	v, err := aepx.ExtractAppVersion(aepxFilePath)
+	if err != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to read the After Effects version: %v", err)))
+	}
+	return v
+}
+
+// readComps summarizes the compositions of an .aepx, warning rather than failing when
+// they can't be read
+func readComps(aepxFilePath string) []aepx.CompSummary {
+	comps, err := aepx.ExtractComps(aepxFilePath)
+	if err != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to read compositions: %v", err)))
+		return nil
+	}
+	return aepx.Summarize(comps)
+}
+
+// trackingInputs converts version assets into the tracking package's input type
+func trackingInputs(assetInfos []AssetInfo) []tracking.AssetInfoInput {
+	inputs := make([]tracking.AssetInfoInput, len(assetInfos))
+	for i, asset := range assetInfos {
+		inputs[i] = tracking.AssetInfoInput{
+			Filename:       asset.Filename,
+			RelativePath:   asset.RelativePath,
+			Extension:      asset.Extension,
+			Size:           asset.Size,
+			Hash:           asset.Hash,
+			DockerPath:     asset.DockerPath,
+			Interpretation: asset.Interpretation,
+		}
+	}
+	return inputs
+}
+
 // Load loads the project from config.json in current directory
 func Load() (*Project, error) {
 	configPath := storage.GetConfigPath()
@@ -192,6 +760,7 @@ func LoadFromPath(configPath string) (*Project, error) {
 	if err := json.Unmarshal(data, &proj); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	proj.loaded(configPath, data)
 
 	return &proj, nil
 }
@@ -219,6 +788,19 @@ func sanitizeProjectName(name string) string {
 func (p *Project) Save() error {
 	configPath := storage.GetConfigPath()
 
+	// An edit saved outside a locked write, e.g. a new label, waits for one in progress
+	// and doesn't overwrite what it saved
+	if !p.writing {
+		release, err := waitWriteLock()
+		if err != nil {
+			return err
+		}
+		defer release()
+		if err := p.checkNotChanged(); err != nil {
+			return err
+		}
+	}
+
 	data, err := json.MarshalIndent(p, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
@@ -227,15 +809,26 @@ func (p *Project) Save() error {
 	if err := os.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
+	p.loaded(configPath, data)
 
 	return nil
 }
 
-// Delete removes the project from Docker storage and local filesystem
+// Delete removes the project from storage and local filesystem
 func (p *Project) Delete() error {
-	// Ensure Docker is ready
-	if err := docker.EnsureDockerReady(); err != nil {
-		return fmt.Errorf("Docker not available: %w", err)
+	if err := p.CheckWritable(); err != nil {
+		return err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Ensure storage is ready
+	store, err := p.readyStore()
+	if err != nil {
+		return fmt.Errorf("%s not available: %w", p.StorageName(), err)
 	}
 
 	// Get project ID from project name (same logic as Initialize/Commit)
@@ -243,13 +836,17 @@ func (p *Project) Delete() error {
 	projectID := sanitizeProjectName(projectBaseName)
 	dockerProjectDir := filepath.Join(docker.StoragePath, projectID)
 
-	// Check if project directory exists in Docker
-	if !docker.PathExistsInContainer(dockerProjectDir) {
-		// Project doesn't exist in Docker, but continue to delete local files
+	// Check if project directory exists in storage
+	exists, err := store.Exists(dockerProjectDir)
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %w", store.Name(), err)
+	}
+	if !exists {
+		// Project doesn't exist in storage, but continue to delete local files
 	} else {
-		// Delete project directory from Docker (includes all versions and assets)
-		if err := docker.DeleteDirectory(dockerProjectDir); err != nil {
-			return fmt.Errorf("failed to delete project from Docker: %w", err)
+		// Delete project directory from storage (includes all versions and assets)
+		if err := store.Remove(dockerProjectDir); err != nil {
+			return fmt.Errorf("failed to delete project from %s: %w", store.Name(), err)
 		}
 	}
 
@@ -266,6 +863,10 @@ func (p *Project) Delete() error {
 
 // DeleteProjectByName deletes a project by its name and Docker path
 func DeleteProjectByName(projectName string, dockerPath string) error {
+	if err := CheckVaultWritable(); err != nil {
+		return err
+	}
+
 	// Ensure Docker is ready
 	if err := docker.EnsureDockerReady(); err != nil {
 		return fmt.Errorf("Docker not available: %w", err)
@@ -273,7 +874,7 @@ func DeleteProjectByName(projectName string, dockerPath string) error {
 
 	// Delete project directory from Docker (includes all versions and assets)
 	if !docker.PathExistsInContainer(dockerPath) {
-		return fmt.Errorf("project directory not found in Docker: %s", dockerPath)
+		return errs.New(errs.ProjectNotFound, "project directory not found in Docker: %s", dockerPath)
 	}
 
 	if err := docker.DeleteDirectory(dockerPath); err != nil {
@@ -384,8 +985,24 @@ func (p *Project) Commit(message string) (*Version, error) {
 
 // CommitWithPath creates a new version of the project using the provided .aepx file path
 func (p *Project) CommitWithPath(message string, aepxFilePath string) (*Version, error) {
+	return p.CommitWithOptions(message, aepxFilePath, CommitOptions{})
+}
+
+// CommitWithOptions creates a new version like CommitWithPath, applying the given options
+func (p *Project) CommitWithOptions(message string, aepxFilePath string, opts CommitOptions) (*Version, error) {
+	if err := p.CheckWritable(); err != nil {
+		return nil, err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// Get next version number
-	nextVersion := len(p.Versions)
+	nextVersion := p.nextVersionNumber()
+	start := time.Now()
+	slog.Info("commit started", "file", aepxFilePath, "version", nextVersion)
 
 	// Get current file size
 	fileSize, err := storage.GetFileSize(aepxFilePath)
@@ -402,134 +1019,163 @@ func (p *Project) CommitWithPath(message string, aepxFilePath string) (*Version,
 		Assets:     []AssetInfo{},
 		AssetCount: 0,
 		TotalSize:  fileSize,
+		Author:     p.commitAuthor(),
+		Meta:       opts.Meta,
+	}
+	if !opts.Timestamp.IsZero() {
+		version.Timestamp = opts.Timestamp
 	}
+	if abs, err := filepath.Abs(aepxFilePath); err == nil {
+		version.FilePath = abs // Where the project is being worked on, for 'vervids list'
+	}
+
+	// Hooks see the file the artist committed, not its staged copy
+	hookFile := aepxFilePath
+	if opts.staged != nil {
+		hookFile = opts.staged.ProjectPath
+	}
+	if err := p.runHook(HookPreCommit, hookFile, nextVersion); err != nil {
+		return nil, refused(err)
+	}
+
+	var parseResult *assets.ParseResult
+	if opts.staged != nil {
+		// Staged offline: parsed and checked for missing assets back then
+		version.Timestamp = opts.staged.Timestamp
+		version.FilePath = opts.staged.ProjectPath
+		version.Author = opts.staged.Author
+		version.Meta = opts.staged.Meta
+		parseResult = opts.staged.parseResult()
+	} else {
+		// Parse .aepx file for assets
+		parseResult, err = parseProjectFile(aepxFilePath, p.commitParseOptions())
+		if err != nil {
+			return nil, refused(fmt.Errorf("failed to parse .aepx file: %w", err))
+		}
+		if len(parseResult.ExcludedAssets) > 0 {
+			fmt.Println(ui.Info(fmt.Sprintf("Skipped %d file(s) excluded by asset rules", len(parseResult.ExcludedAssets))))
+		}
 
-    // Parse .aepx file for assets
-	parseResult, err := assets.ParseAEPX(aepxFilePath, "")
+		policy := opts.MissingAssets
+		if policy == "" {
+			policy = p.MissingAssets
+		}
+		if err := checkMissingAssets(parseResult, policy); err != nil {
+			return nil, refused(err)
+		}
+		warnCaseCollisions(parseResult.Assets)
+	}
+	version.Missing = parseResult.MissingAssets
+	if err := p.checkQuota(fileSize, parseResult.Assets, opts.ForceQuota); err != nil {
+		return nil, err
+	}
+	key, err := signingKey()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse .aepx file: %w", err)
+		return nil, err
 	}
 
-    // Ensure Docker is ready
-    if err := docker.EnsureDockerReady(); err != nil {
+    // Ensure storage is ready
+    store, err := p.readyStore()
+    if err != nil {
         return nil, err
     }
 
-    // Store the file and assets in Docker
-    // Use project filename (without extension) as project ID
+    // Refuse to interleave versions with another copy of this project's config
+    if err := p.checkPublished(); err != nil {
+        return nil, err
+    }
+
+    // Store the file and assets
+    // Use the project's ID, not the committed file's name, so a file saved under
+    // another name (e.g. an imported project_v12.aepx) lands in the same storage
     versionDir := fmt.Sprintf("v%03d", version.Number)
-    projectBaseName := strings.TrimSuffix(filepath.Base(aepxFilePath), filepath.Ext(aepxFilePath))
-    projectID := sanitizeProjectName(projectBaseName)
+    projectID := p.projectID()
     dockerVersionDir := filepath.Join(docker.StoragePath, projectID, versionDir)
 
-    if err := docker.CreateDirectory(dockerVersionDir); err != nil {
-        return nil, fmt.Errorf("failed to create version directory in Docker: %w", err)
+    if err := store.MakeDir(dockerVersionDir); err != nil {
+        return nil, fmt.Errorf("failed to create version directory in %s: %w", store.Name(), err)
     }
 
     // Copy .aepx file
     dockerProjectPath := filepath.Join(dockerVersionDir, filepath.Base(aepxFilePath))
-    if err := docker.CopyToContainer(aepxFilePath, dockerProjectPath); err != nil {
-        return nil, fmt.Errorf("failed to copy project file to Docker: %w", err)
+    if err := ui.RunStep("Copying project file", func() error { return store.PutFile(aepxFilePath, dockerProjectPath) }); err != nil {
+        return nil, fmt.Errorf("failed to copy project file to %s: %w", store.Name(), err)
     }
     version.DockerPath = dockerProjectPath
 
     // Use shared assets directory at project level
     // Use the same projectID from above
     sharedAssetsDir := filepath.Join(docker.StoragePath, projectID, "assets")
-    if err := docker.CreateDirectory(sharedAssetsDir); err != nil {
+    if err := store.MakeDir(sharedAssetsDir); err != nil {
         return nil, fmt.Errorf("failed to ensure shared assets directory exists: %w", err)
     }
 
-    // Get all previously used assets from all previous versions
-    previousAssetsMap := make(map[string]string) // filename -> docker path
-    previousAssetsSet := make(map[string]bool)   // filename -> exists in previous version
-    for _, prevVersion := range p.Versions {
-        for _, prevAsset := range prevVersion.Assets {
-            previousAssetsMap[prevAsset.Filename] = prevAsset.DockerPath
-            previousAssetsSet[prevAsset.Filename] = true
-        }
-    }
-
-    // Copy assets that weren't in previous version or don't exist in Docker
-    for _, asset := range parseResult.Assets {
-        sharedAssetPath := filepath.Join(sharedAssetsDir, asset.Filename)
-        
-        // Check if this asset was in the previous version
-        wasInPreviousVersion := previousAssetsSet[asset.Filename]
-        
-        // Check if asset already exists in Docker
-        existsInDocker := docker.PathExistsInContainer(sharedAssetPath)
-        
-        // Copy asset if:
-        // 1. It wasn't in the previous version (new asset), OR
-        // 2. It doesn't exist in Docker (missing or was deleted)
-        if !wasInPreviousVersion || !existsInDocker {
-            // Copy asset to Docker
-            if err := docker.CopyToContainer(asset.Path, sharedAssetPath); err != nil {
-                fmt.Println(ui.Warning(fmt.Sprintf("Failed to copy asset %s: %v", asset.Filename, err)))
-                continue
-            }
-            if !wasInPreviousVersion {
-                fmt.Println(ui.Success(fmt.Sprintf("Copied new asset: %s (%.2f MB)", asset.Filename, float64(asset.Size)/(1024*1024))))
-            } else {
-                fmt.Println(ui.Success(fmt.Sprintf("Copied asset: %s (was missing in Docker)", asset.Filename)))
-            }
-        } else {
-            // Asset exists in Docker and was in previous version - reuse it
-            if existingPath := previousAssetsMap[asset.Filename]; existingPath != "" {
-                sharedAssetPath = existingPath
-            }
-            fmt.Println(ui.Success(fmt.Sprintf("Reusing existing asset: %s", asset.Filename)))
-        }
-        
-        // Reference shared asset
-        version.Assets = append(version.Assets, AssetInfo{
-            OriginalPath: asset.Path,
-            RelativePath: asset.RelativePath,
-            Filename:     asset.Filename,
-            Extension:    asset.Extension,
-            Size:         asset.Size,
-            DockerPath:   sharedAssetPath, // Point to shared location
-        })
+    // Copy new or changed assets; identical content is reused from the shared pool.
+    // A journal left by an interrupted commit of the same file lets finished copies be skipped.
+    journal := storage.OpenJournal(storage.GetCommitJournalPath(), fmt.Sprintf("%s#v%d", aepxFilePath, version.Number))
+    if journal.Resumed() {
+        fmt.Println(ui.Info(fmt.Sprintf("Resuming interrupted commit: %d transfer(s) already done", len(journal.Completed))))
     }
+    version.Assets = p.storeAssets(store, sharedAssetsDir, parseResult.Assets, journal)
+	if !p.Generic && assets.IsAEPX(aepxFilePath) {
+		recordInterpretations(aepxFilePath, parseResult.Assets, version.Assets)
+	}
 
 	version.AssetCount = len(version.Assets)
 	version.TotalSize = parseResult.TotalSize
 
 	// Convert current AssetInfo to AssetInfoInput for tracking
-	currentAssetsInput := make([]tracking.AssetInfoInput, len(version.Assets))
-	for i, asset := range version.Assets {
-		currentAssetsInput[i] = tracking.AssetInfoInput{
-			Filename:   asset.Filename,
-			Extension:  asset.Extension,
-			Size:       asset.Size,
-			DockerPath: asset.DockerPath,
-		}
-	}
+	currentAssetsInput := trackingInputs(version.Assets)
 
 	// Get previous version's assets for comparison
 	previousAssetsInput := make([]tracking.AssetInfoInput, 0)
 	if len(p.Versions) > 0 {
-		previousAssets := p.Versions[len(p.Versions)-1].Assets
-		previousAssetsInput = make([]tracking.AssetInfoInput, len(previousAssets))
-		for i, asset := range previousAssets {
-			previousAssetsInput[i] = tracking.AssetInfoInput{
-				Filename:   asset.Filename,
-				Extension:  asset.Extension,
-				Size:       asset.Size,
-				DockerPath: asset.DockerPath,
-			}
+		previousAssetsInput = trackingInputs(p.Versions[len(p.Versions)-1].Assets)
+	}
+
+	// Keep the artists' marker comments and comp notes with the version; only .aepx
+	// files have comps to read them from
+	isAEPX := !p.Generic && assets.IsAEPX(aepxFilePath)
+	if isAEPX {
+		if markers, err := aepx.ExtractMarkers(aepxFilePath); err != nil {
+			fmt.Println(ui.Warning(fmt.Sprintf("Failed to read markers: %v", err)))
+		} else {
+			version.Markers = markers
+		}
+		version.AppVersion = readAppVersion(aepxFilePath)
+		version.Comps = parseResult.Comps
+	}
+
+	// Summarize layer changes per comp against the previous version's XML
+	if isAEPX && len(p.Versions) > 0 && assets.IsAEPX(p.Versions[len(p.Versions)-1].DockerPath) {
+		changes, err := p.layerChangesSince(p.Versions[len(p.Versions)-1].Number, aepxFilePath)
+		if err != nil {
+			fmt.Println(ui.Warning(fmt.Sprintf("Failed to compare layers with previous version: %v", err)))
+		} else {
+			version.LayerChanges = changes
 		}
 	}
 
+	// Hash and sign before recording; a failed signature leaves the journal for a retry
+	if err := p.signVersion(&version, aepxFilePath, key); err != nil {
+		return nil, err
+	}
+
 	// Create asset tracking comparing with previous version
 	track := tracking.CreateTracking(version.Number, version.Message, currentAssetsInput, previousAssetsInput)
-	if err := tracking.SaveTracking(version.Number, dockerVersionDir, track); err != nil {
+	track.SetProjectFile(version.DockerPath, version.Size, version.FileHash)
+	if err := tracking.SaveTracking(store, dockerVersionDir, track); err != nil {
 		fmt.Println(ui.Warning(fmt.Sprintf("Failed to save asset tracking: %v", err)))
 	}
+	if err := p.writeVersionManifest(&version); err != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to save version manifest: %v", err)))
+	}
 
 	// Update project path to the latest committed file
 	p.ProjectPath = aepxFilePath
+	if opts.staged != nil {
+		p.ProjectPath = opts.staged.ProjectPath
+	}
 
 	// Add version to project
 	p.Versions = append(p.Versions, version)
@@ -538,117 +1184,181 @@ func (p *Project) CommitWithPath(message string, aepxFilePath string) (*Version,
 	if err := p.Save(); err != nil {
 		return nil, fmt.Errorf("failed to save config: %w", err)
 	}
+	journal.Remove()
+	p.publishMetadata()
+	if err := p.runHook(HookPostCommit, hookFile, version.Number); err != nil {
+		fmt.Println(ui.Warning(err.Error()))
+	}
+	slog.Info("commit finished", "version", version.Number, "assets", version.AssetCount, "took", logging.Since(start))
 
 	return &version, nil
 }
 
-// GetVersion returns a specific version by number
-func (p *Project) GetVersion(number int) (*Version, error) {
-	if number < 0 || number >= len(p.Versions) {
-		return nil, fmt.Errorf("version %d does not exist", number)
+// FindVersion returns the version carrying a number. Numbers aren't positions: they
+// stay put when versions before them are pruned or deleted.
+func (p *Project) FindVersion(number int) (*Version, error) {
+	for i := range p.Versions {
+		if p.Versions[i].Number == number {
+			return &p.Versions[i], nil
+		}
 	}
-	return &p.Versions[number], nil
+	return nil, errs.New(errs.VersionNotFound, "version %d does not exist", number)
 }
 
-// GetLatestVersion returns the most recent version
-func (p *Project) GetLatestVersion() *Version {
-	if len(p.Versions) == 0 {
-		return nil
+// nextVersionNumber returns the number for a new version: one past the highest, so
+// numbers freed by removing versions are never reused
+func (p *Project) nextVersionNumber() int {
+	next := 0
+	for _, v := range p.Versions {
+		if v.Number >= next {
+			next = v.Number + 1
+		}
 	}
-	return &p.Versions[len(p.Versions)-1]
+	return next
 }
 
-// ProjectInfo represents basic info about a project found in Docker
-type ProjectInfo struct {
-	Name       string
-	DockerPath string
-}
+// asOfLayouts are the timestamp forms accepted by ParseAsOf, in local time
+var asOfLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"}
 
-// GetAllProjects scans Docker storage and returns all projects
-func GetAllProjects() ([]ProjectInfo, error) {
-	if err := docker.EnsureDockerReady(); err != nil {
-		return nil, err
+// ParseAsOf parses a point in time such as "2024-03-01 18:00", in local time unless
+// given as RFC 3339 with a zone. A date alone means the end of that day.
+func ParseAsOf(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
 	}
-
-	// List all directories that contain version folders (v000, v001, etc.)
-	// This finds actual projects, not just top-level folders
-	output, err := docker.ExecInContainer("sh", "-c", fmt.Sprintf(
-		"find %s -type d -name 'v[0-9][0-9][0-9]' -mindepth 2 -maxdepth 2 | sed 's|/v[0-9][0-9][0-9]$||' | sort -u",
-		docker.StoragePath))
-	if err != nil {
-		return []ProjectInfo{}, nil // No projects found, return empty
+	for _, layout := range asOfLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
 	}
+	return time.Time{}, fmt.Errorf("can't read '%s' as a time; use e.g. \"2024-03-01 18:00\" or 2024-03-01", value)
+}
 
-	var projects []ProjectInfo
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	seen := make(map[string]bool)
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
+// VersionAsOf returns the version that was the latest at t: the newest committed at or
+// before it. Backdated versions count from the time they are dated.
+func (p *Project) VersionAsOf(t time.Time) (*Version, error) {
+	var found *Version
+	for i := range p.Versions {
+		v := &p.Versions[i]
+		if v.Timestamp.After(t) {
 			continue
 		}
-		// Get the project directory (the parent of the version folder)
-		projectPath := line
-		// Extract project name: could be direct child of /vervids or nested
-		relPath := strings.TrimPrefix(projectPath, docker.StoragePath+"/")
-		parts := strings.Split(relPath, "/")
-		var projectName string
-		if len(parts) == 1 {
-			projectName = parts[0]
-		} else {
-			// Use the last part as project name
-			projectName = parts[len(parts)-1]
-		}
-		
-		// Try to find config.json to get actual project name
-		// Search in current directory and common project locations
-		home := os.Getenv("HOME")
-		searchDirs := []string{
-			".",
-			filepath.Join(home, "Documents"),
-			filepath.Join(home, "Desktop"),
-			filepath.Join(home, "Projects"),
-		}
-		
-		foundName := projectName // default
-		for _, baseDir := range searchDirs {
-			// Check if there's a directory matching the project name
-			if entries, err := os.ReadDir(baseDir); err == nil {
-				for _, entry := range entries {
-					if entry.IsDir() {
-						configPath := filepath.Join(baseDir, entry.Name(), storage.VerVidsDir, storage.ConfigFile)
-						if data, err := os.ReadFile(configPath); err == nil {
-							var proj Project
-							if json.Unmarshal(data, &proj) == nil {
-								// Check if this config's docker path matches
-								configProjectID := sanitizeProjectName(strings.TrimSuffix(proj.ProjectName, filepath.Ext(proj.ProjectName)))
-								if configProjectID == projectName || strings.Contains(projectName, configProjectID) || strings.Contains(configProjectID, projectName) {
-									foundName = strings.TrimSuffix(proj.ProjectName, filepath.Ext(proj.ProjectName))
-									break
-								}
-							}
-						}
-					}
-				}
-				if foundName != projectName {
-					break
-				}
+		if found == nil || !v.Timestamp.Before(found.Timestamp) {
+			found = v
+		}
+	}
+	if found == nil {
+		if len(p.Versions) == 0 {
+			return nil, errs.New(errs.VersionNotFound, "the project has no versions")
+		}
+		first := p.Versions[0].Timestamp
+		for _, v := range p.Versions {
+			if v.Timestamp.Before(first) {
+				first = v.Timestamp
 			}
 		}
-		projectName = foundName
-		
-		// Use full path as unique key to avoid duplicates
-		if projectName != "" && !seen[projectPath] {
-			seen[projectPath] = true
-			projects = append(projects, ProjectInfo{
-				Name:       projectName,
-				DockerPath: projectPath,
-			})
+		return nil, errs.New(errs.VersionNotFound, "no version was committed at or before %s; the first is from %s",
+			t.Format("2006-01-02 15:04"), first.Format("2006-01-02 15:04"))
+	}
+	return found, nil
+}
+
+// IsNewAsset reports whether content with the given hash was first stored by the given
+// version, rather than reused from an earlier one
+func (p *Project) IsNewAsset(number int, hash string) bool {
+	for i := 0; i < number && i < len(p.Versions); i++ {
+		if p.Versions[i].findAssetByHash(hash) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// findAsset returns the version's asset stored for path, matching the original path
+// or a recorded symlink first and falling back to the filename
+func (v *Version) findAsset(path string, filename string) *AssetInfo {
+	for i := range v.Assets {
+		if v.Assets[i].OriginalPath == path || containsString(v.Assets[i].LinkPaths, path) {
+			return &v.Assets[i]
+		}
+	}
+	for i := range v.Assets {
+		if v.Assets[i].Filename == filename {
+			return &v.Assets[i]
+		}
+	}
+	return nil
+}
+
+// findAssetByHash returns the version's asset with the given content hash, or nil
+func (v *Version) findAssetByHash(hash string) *AssetInfo {
+	for i := range v.Assets {
+		if v.Assets[i].Hash == hash {
+			return &v.Assets[i]
+		}
+	}
+	return nil
+}
+
+// structuredAssetPath returns the asset's path relative to the original project folder,
+// made safe to place under the pull's assets directory. Parent-directory segments and
+// drive letters are dropped so footage kept outside the project folder can't escape it.
+func structuredAssetPath(asset *AssetInfo) string {
+	rel := asset.RelativePath
+	if rel == "" || filepath.IsAbs(rel) {
+		rel = asset.OriginalPath
+	}
+	rel = strings.ReplaceAll(rel, `\`, "/")
+
+	parts := []string{}
+	for _, part := range strings.Split(rel, "/") {
+		if part == "" || part == "." || part == ".." || strings.HasSuffix(part, ":") {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return asset.Filename
+	}
+	return filepath.Join(parts...)
+}
+
+// collisionDirName returns the subfolder used on pull for an asset whose filename is
+// already taken by a different file
+func collisionDirName(asset *AssetInfo, dockerPath string) string {
+	if asset != nil && len(asset.Hash) >= 8 {
+		return asset.Hash[:8]
+	}
+	return sanitizeProjectName(strings.TrimPrefix(filepath.Dir(dockerPath), docker.StoragePath+"/"))
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
 		}
 	}
+	return false
+}
+
+// GetLatestVersion returns the most recent version
+func (p *Project) GetLatestVersion() *Version {
+	if len(p.Versions) == 0 {
+		return nil
+	}
+	return &p.Versions[len(p.Versions)-1]
+}
 
-	return projects, nil
+// ProjectInfo represents basic info about a project found in Docker
+type ProjectInfo struct {
+	Name       string
+	DockerPath string
+	ConfigPath string // Set when the project was found by its config.json, as remote projects are
 }
 
 // FindProjectConfig searches for a config.json file that matches a project name
@@ -680,13 +1390,18 @@ func FindProjectConfig(projectName string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("config not found for project: %s", projectName)
+	return "", errs.New(errs.ProjectNotFound, "config not found for project: %s", projectName)
 }
 
 // RemoveVersion removes a version by number from the project and compacts the slice.
 func (p *Project) RemoveVersion(number int) error {
-    if number < 0 || number >= len(p.Versions) {
-        return fmt.Errorf("version %d does not exist", number)
+    unlock, err := p.lockWrites()
+    if err != nil {
+        return err
+    }
+    defer unlock()
+    if _, err := p.FindVersion(number); err != nil {
+        return err
     }
     // Remove without re-numbering historical versions (keep numbers stable)
     filtered := make([]Version, 0, len(p.Versions))
@@ -699,11 +1414,53 @@ func (p *Project) RemoveVersion(number int) error {
     return p.Save()
 }
 
-// PruneMissingDockerVersions removes versions whose Docker-backed files are missing.
-// Returns the number of versions removed.
+// MissingStorage returns the versions and pins whose stored files are gone from the
+// project's storage, without changing anything. A failed check is returned as an
+// error rather than counted as missing.
+func (p *Project) MissingStorage() ([]Version, []PinnedAsset, error) {
+	store, err := p.readyStore()
+	if err != nil {
+		return nil, nil, err
+	}
+	var versions []Version
+	for _, v := range p.Versions {
+		if v.DockerPath == "" {
+			continue
+		}
+		exists, err := store.Exists(v.DockerPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check version %d in %s: %w", v.Number, store.Name(), err)
+		}
+		if !exists {
+			versions = append(versions, v)
+		}
+	}
+	var pins []PinnedAsset
+	for _, pin := range p.PinnedAssets {
+		exists, err := store.Exists(pin.DockerPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check pinned %s in %s: %w", pin.Filename, store.Name(), err)
+		}
+		if !exists {
+			pins = append(pins, pin)
+		}
+	}
+	return versions, pins, nil
+}
+
+// PruneMissingDockerVersions removes versions whose stored files are missing, in Docker
+// or the project's remote. Returns the number of versions removed.
 func (p *Project) PruneMissingDockerVersions() (int, error) {
-    // Ensure Docker ready (in case we need to exec)
-    if err := docker.EnsureDockerReady(); err != nil {
+    if err := p.CheckWritable(); err != nil {
+        return 0, err
+    }
+    unlock, err := p.lockWrites()
+    if err != nil {
+        return 0, err
+    }
+    defer unlock()
+    store, err := p.readyStore()
+    if err != nil {
         return 0, err
     }
     removed := 0
@@ -714,7 +1471,12 @@ func (p *Project) PruneMissingDockerVersions() (int, error) {
             kept = append(kept, v)
             continue
         }
-        if docker.PathExistsInContainer(v.DockerPath) {
+        // Only versions storage says are gone are dropped; a failed check stops the prune
+        exists, err := store.Exists(v.DockerPath)
+        if err != nil {
+            return 0, fmt.Errorf("failed to check version %d in %s: %w", v.Number, store.Name(), err)
+        }
+        if exists {
             kept = append(kept, v)
             continue
         }
@@ -729,17 +1491,190 @@ func (p *Project) PruneMissingDockerVersions() (int, error) {
     return removed, nil
 }
 
-// RestoreVersion restores a specific version from Docker storage to local filesystem
+// TrackingIssue describes a version whose asset-tracking.json is missing or disagrees with config.json
+type TrackingIssue struct {
+	Version int
+	Problem string
+}
+
+// CheckTracking compares each stored version's asset-tracking.json with the assets
+// recorded for it in config.json. Versions whose storage is missing are skipped.
+func (p *Project) CheckTracking() ([]TrackingIssue, error) {
+	store, err := p.readyStore()
+	if err != nil {
+		return nil, err
+	}
+	issues := []TrackingIssue{}
+	for _, v := range p.Versions {
+		if v.DockerPath == "" {
+			continue
+		}
+		exists, err := store.Exists(v.DockerPath)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		track, err := tracking.LoadTracking(store, filepath.Dir(v.DockerPath))
+		if err != nil {
+			issues = append(issues, TrackingIssue{Version: v.Number, Problem: "asset-tracking.json is missing or unreadable"})
+			continue
+		}
+		if problem := trackingMismatch(&v, track); problem != "" {
+			issues = append(issues, TrackingIssue{Version: v.Number, Problem: problem})
+		}
+	}
+	return issues, nil
+}
+
+// trackingMismatch returns why a tracking file disagrees with its version, or ""
+func trackingMismatch(v *Version, track *tracking.AssetTracking) string {
+	if track.Version != v.Number {
+		return fmt.Sprintf("tracking is for v%d", track.Version)
+	}
+	tracked := make(map[string]int)
+	for _, a := range track.Assets {
+		if a.Present {
+			tracked[a.Filename+"|"+a.Hash]++
+		}
+	}
+	if countPresent(track) != len(v.Assets) {
+		return fmt.Sprintf("tracking lists %d present asset(s), config has %d", countPresent(track), len(v.Assets))
+	}
+	for _, a := range v.Assets {
+		key := a.Filename + "|" + a.Hash
+		if tracked[key] == 0 {
+			return fmt.Sprintf("asset %s is not in tracking", a.Filename)
+		}
+		tracked[key]--
+	}
+	return ""
+}
+
+// countPresent counts the tracked assets present in the version
+func countPresent(track *tracking.AssetTracking) int {
+	n := 0
+	for _, a := range track.Assets {
+		if a.Present {
+			n++
+		}
+	}
+	return n
+}
+
+// RegenerateTracking rebuilds a version's asset-tracking.json from config.json,
+// comparing against the version before it
+func (p *Project) RegenerateTracking(versionNum int) error {
+	version, err := p.FindVersion(versionNum)
+	if err != nil {
+		return err
+	}
+	if version.DockerPath == "" {
+		return fmt.Errorf("version %d has no Docker path", versionNum)
+	}
+
+	previousAssetsInput := make([]tracking.AssetInfoInput, 0)
+	for i := range p.Versions {
+		if p.Versions[i].Number == versionNum && i > 0 {
+			previousAssetsInput = trackingInputs(p.Versions[i-1].Assets)
+		}
+	}
+
+	store, err := p.readyStore()
+	if err != nil {
+		return err
+	}
+	track := tracking.CreateTracking(version.Number, version.Message, trackingInputs(version.Assets), previousAssetsInput)
+	track.Timestamp = version.Timestamp.Format(time.RFC3339)
+	track.SetProjectFile(version.DockerPath, version.Size, version.FileHash)
+	return tracking.SaveTracking(store, filepath.Dir(version.DockerPath), track)
+}
+
+// RestoreOptions controls how a version is written out on pull
+type RestoreOptions struct {
+	PreserveStructure bool   // Restore assets under assets/<original relative path> instead of a flat folder
+	ProjectFile       string // Write the project file here instead of into the output directory under its own name
+}
+
+// LoadVersionTracking loads the asset tracking stored next to a version's .aepx
+func (p *Project) LoadVersionTracking(versionNum int) (*tracking.AssetTracking, error) {
+	version, err := p.FindVersion(versionNum)
+	if err != nil {
+		return nil, err
+	}
+	if version.DockerPath == "" {
+		return nil, fmt.Errorf("version %d has no Docker path", versionNum)
+	}
+	store, err := p.readyStore()
+	if err != nil {
+		return nil, fmt.Errorf("%s not available: %w", p.StorageName(), err)
+	}
+	return tracking.LoadTracking(store, filepath.Dir(version.DockerPath))
+}
+
+// layerChangesSince counts per-comp layer changes between a stored version and an .aepx on disk
+func (p *Project) layerChangesSince(versionNum int, aepxFilePath string) ([]aepx.LayerChanges, error) {
+	previousPath, cleanup, err := p.FetchVersionFile(versionNum)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	oldComps, err := aepx.ExtractComps(previousPath)
+	if err != nil {
+		return nil, err
+	}
+	newComps, err := aepx.ExtractComps(aepxFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return aepx.DiffLayers(oldComps, newComps), nil
+}
+
+// FetchVersionFile copies a version's .aepx out of storage into a temporary directory
+// for inspection. The returned cleanup function removes it.
+func (p *Project) FetchVersionFile(versionNum int) (string, func(), error) {
+	version, err := p.FindVersion(versionNum)
+	if err != nil {
+		return "", nil, err
+	}
+	if version.DockerPath == "" {
+		return "", nil, fmt.Errorf("version %d has no Docker path", versionNum)
+	}
+	store, err := p.readyStore()
+	if err != nil {
+		return "", nil, fmt.Errorf("%s not available: %w", p.StorageName(), err)
+	}
+
+	tmpDir, err := storage.MkdirTemp("version-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	localPath := filepath.Join(tmpDir, filepath.Base(version.DockerPath))
+	if err := store.GetFile(version.DockerPath, localPath); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to copy version %d from %s: %w", versionNum, store.Name(), err)
+	}
+	return localPath, cleanup, nil
+}
+
+// RestoreVersion restores a specific version from storage to local filesystem
 // It copies the .aepx file and updates asset paths if assets don't exist at their original locations
 // Returns the path to the restored .aepx file
-func (p *Project) RestoreVersion(versionNum int, outputDir string) (string, error) {
-	// Ensure Docker is ready
-	if err := docker.EnsureDockerReady(); err != nil {
-		return "", fmt.Errorf("Docker not available: %w", err)
+func (p *Project) RestoreVersion(versionNum int, outputDir string, opts RestoreOptions) (string, error) {
+	start := time.Now()
+	slog.Info("pull started", "version", versionNum, "output", outputDir)
+	// Ensure storage is ready
+	store, err := p.readyStore()
+	if err != nil {
+		return "", fmt.Errorf("%s not available: %w", p.StorageName(), err)
 	}
 
 	// Get the version
-	version, err := p.GetVersion(versionNum)
+	version, err := p.FindVersion(versionNum)
 	if err != nil {
 		return "", err
 	}
@@ -759,23 +1694,34 @@ func (p *Project) RestoreVersion(versionNum int, outputDir string) (string, erro
 
 	// Copy .aepx file to final location first (we'll check assets relative to this location)
 	restoredAepxPath := filepath.Join(outputDir, filepath.Base(version.FilePath))
-	if err := docker.CopyFromContainer(version.DockerPath, restoredAepxPath); err != nil {
-		return "", fmt.Errorf("failed to copy .aepx file from Docker: %w", err)
+	if opts.ProjectFile != "" {
+		restoredAepxPath = opts.ProjectFile
+	}
+	if err := ui.RunStep("Copying project file", func() error { return store.GetFile(version.DockerPath, restoredAepxPath) }); err != nil {
+		return "", fmt.Errorf("failed to copy .aepx file from %s: %w", store.Name(), err)
 	}
 
 	// Parse the .aepx file to find asset references (using the final location)
-	parseResult, err := assets.ParseAEPX(restoredAepxPath, "")
+	parseResult, err := parseProjectFile(restoredAepxPath, p.parseOptions())
 	if err != nil {
 		// Clean up the file if parsing fails
 		os.Remove(restoredAepxPath)
 		return "", fmt.Errorf("failed to parse .aepx file: %w", err)
 	}
 
+	// Map to track path replacements
+	pathMap := make(map[string]string)
+
 	// Check if all assets exist at their original paths
 	allAssetsExist := true
 	assetsNeedingDocker := []assets.Asset{}
 
 	for _, asset := range parseResult.Assets {
+		// Assets found through a path mapping or search path exist on this machine elsewhere
+		if (asset.Mapped || asset.FoundIn != "") && asset.Reference != "" {
+			pathMap[asset.Reference] = asset.Path
+		}
+
 		// Asset.Path from ParseAEPX is already resolved to absolute path
 		// based on the project directory, so we can check it directly
 		assetPath := filepath.Clean(asset.Path)
@@ -788,52 +1734,94 @@ func (p *Project) RestoreVersion(versionNum int, outputDir string) (string, erro
 		}
 	}
 
+	// Assets the parser couldn't find on this machine have to come from Docker
+	for _, missingPath := range parseResult.MissingAssets {
+		allAssetsExist = false
+		assetsNeedingDocker = append(assetsNeedingDocker, assets.Asset{
+			Path:      missingPath,
+			Filename:  filepath.Base(missingPath),
+			Extension: filepath.Ext(missingPath),
+			Reference: parseResult.MissingReferences[missingPath],
+		})
+	}
+
+	// References written on another OS are rewritten for this one, through the path
+	// mappings for absolute paths, so the project opens without relinking
+	converted, unmapped := 0, 0
+	mappings := p.parseOptions().PathMappings
+	for _, reference := range parseResult.References {
+		if _, ok := pathMap[reference]; ok {
+			continue
+		}
+		if native, ok := assets.NativeReference(reference, mappings); ok {
+			pathMap[reference] = native
+			converted++
+		} else if assets.IsForeignPath(reference) {
+			unmapped++
+		}
+	}
+	if converted > 0 {
+		fmt.Println(ui.Info(fmt.Sprintf("Converted %d path(s) written on another OS", converted)))
+	}
+	if unmapped > 0 {
+		fmt.Println(ui.Warning(fmt.Sprintf("%d path(s) from another OS have no path mapping; add one with 'vervids pathmap add'", unmapped)))
+	}
+
 	// If all assets exist locally, remove the copied .aepx file and return original path
-	if allAssetsExist && len(parseResult.Assets) > 0 {
+	if allAssetsExist && len(parseResult.Assets) > 0 && len(pathMap) == 0 && opts.ProjectFile == "" {
 		os.Remove(restoredAepxPath)
 		// Return the original file path from the version
 		return version.FilePath, nil
 	}
 
-	// Some assets need Docker - update .aepx file with new paths
-	// Create assets directory in output directory
+	// Some assets need Docker - plan every copy and check it can succeed before writing anything
 	assetsDir := filepath.Join(outputDir, "assets")
+	plan, err := p.planRestore(store, version, assetsDir, assetsNeedingDocker, opts)
+	if err != nil {
+		os.Remove(restoredAepxPath)
+		return "", err
+	}
+	if err := plan.validate(outputDir); err != nil {
+		os.Remove(restoredAepxPath)
+		return "", err
+	}
+	plan.print(assetsDir)
+
+	// Anything written from here on is removed again if a copy fails
+	created := []string{restoredAepxPath}
+	if _, err := os.Stat(assetsDir); os.IsNotExist(err) {
+		created = append(created, assetsDir)
+	}
 	if err := os.MkdirAll(assetsDir, 0755); err != nil {
+		os.Remove(restoredAepxPath)
 		return "", fmt.Errorf("failed to create assets directory: %w", err)
 	}
 
-	// Map to track path replacements
-	pathMap := make(map[string]string)
-	finalProjectDir := filepath.Dir(restoredAepxPath)
-
-	// Copy assets that don't exist locally
-	for _, asset := range assetsNeedingDocker {
-		// Find the asset in version.Assets to get Docker path
-		var dockerAssetPath string
-		for _, vAsset := range version.Assets {
-			if vAsset.Filename == asset.Filename {
-				dockerAssetPath = vAsset.DockerPath
-				break
-			}
-		}
+	// Files finished by an interrupted pull of the same version are kept rather than copied again
+	journal := storage.OpenJournal(filepath.Join(outputDir, PullJournalFile), fmt.Sprintf("%s#v%d", p.projectID(), versionNum))
+	if journal.Resumed() {
+		fmt.Println(ui.Info(fmt.Sprintf("Resuming interrupted pull: %d file(s) already restored", len(journal.Completed))))
+	}
 
-		if dockerAssetPath == "" {
-			// Asset not found in version metadata, try to find it in shared assets
-			projectBaseName := strings.TrimSuffix(filepath.Base(p.ProjectPath), filepath.Ext(p.ProjectPath))
-			projectID := sanitizeProjectName(projectBaseName)
-			dockerAssetPath = filepath.Join(docker.StoragePath, projectID, "assets", asset.Filename)
-		}
+	var totalSize int64
+	for _, step := range plan.steps {
+		totalSize += step.size
+	}
+	progress := ui.NewProgress(totalSize)
 
-		// Check if asset exists in Docker
-		if !docker.PathExistsInContainer(dockerAssetPath) {
-			fmt.Println(ui.Warning(fmt.Sprintf("Asset %s not found in Docker storage, skipping", asset.Filename)))
-			continue
+	finalProjectDir := filepath.Dir(restoredAepxPath)
+	for _, step := range plan.steps {
+		progress.Next(step.size)
+		localAssetPath, done := step.resumed(journal)
+		if !done {
+			localAssetPath, err = step.run(store, &created, progress)
+			if err != nil {
+				cleanupRestore(created)
+				return "", fmt.Errorf("failed to restore %s, removed partially restored files: %w", step.asset.Filename, err)
+			}
+			step.record(journal)
 		}
-
-		// Copy asset from Docker to local assets directory
-		localAssetPath := filepath.Join(assetsDir, asset.Filename)
-		if err := docker.CopyFromContainer(dockerAssetPath, localAssetPath); err != nil {
-			fmt.Println(ui.Warning(fmt.Sprintf("Failed to copy asset %s from Docker: %v", asset.Filename, err)))
+		if step.linkOnly {
 			continue
 		}
 
@@ -845,18 +1833,25 @@ func (p *Project) RestoreVersion(versionNum int, outputDir string) (string, erro
 		}
 
 		// Add to path map for updating .aepx file
-		// Use the original path from the parsed asset
-		pathMap[asset.Path] = relAssetPath
-		fmt.Println(ui.Success(fmt.Sprintf("Restored asset: %s -> %s", asset.Filename, relAssetPath)))
+		// Use the path as written in the .aepx so Windows/macOS notation is matched verbatim
+		originalRef := step.asset.Path
+		if step.asset.Reference != "" {
+			originalRef = step.asset.Reference
+		}
+		pathMap[originalRef] = relAssetPath
+		fmt.Println(ui.Success(fmt.Sprintf("Restored asset: %s -> %s", step.asset.Filename, relAssetPath)))
 	}
 
 	// Update .aepx file with new asset paths
 	if len(pathMap) > 0 {
 		if err := assets.UpdateAssetPaths(restoredAepxPath, pathMap); err != nil {
+			cleanupRestore(created)
 			return "", fmt.Errorf("failed to update asset paths in .aepx file: %w", err)
 		}
 		fmt.Println(ui.Success(fmt.Sprintf("Updated %d asset path(s) in .aepx file", len(pathMap))))
 	}
+	journal.Remove()
+	slog.Info("pull finished", "version", versionNum, "files", len(plan.steps), "took", logging.Since(start))
 
 	return restoredAepxPath, nil
 }
@@ -0,0 +1,57 @@
+package project
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// QuotaWarnPercent is how full a project's quota gets before commits warn about it
+const QuotaWarnPercent = 80
+
+// Quota returns the project's storage quota in bytes, or 0 if it has none
+func (p *Project) Quota() int64 {
+	return p.QuotaGB * 1024 * 1024 * 1024
+}
+
+// OverQuota reports whether the project stores more than its quota allows
+func (p *Project) OverQuota() bool {
+	return p.QuotaGB > 0 && p.Stats().StoredSize > p.Quota()
+}
+
+// checkQuota estimates the storage the project will use after a commit and warns once
+// it passes QuotaWarnPercent of the quota. Past the quota the commit is refused with a
+// StorageFull error unless force is set. Assets whose path and size match the latest
+// version are assumed to be reused; anything else counts as new, so the estimate errs
+// on the high side.
+func (p *Project) checkQuota(fileSize int64, parsed []assets.Asset, force bool) error {
+	quota := p.Quota()
+	if quota == 0 {
+		return nil
+	}
+	added := fileSize
+	latest := p.GetLatestVersion()
+	for _, a := range parsed {
+		if latest != nil {
+			if prev := latest.findAsset(a.Path, a.Filename); prev != nil && prev.Size == a.Size && !prev.Purged {
+				continue
+			}
+		}
+		added += a.Size
+	}
+
+	projected := p.Stats().StoredSize + added
+	percent := float64(projected) / float64(quota) * 100
+	gb := func(n int64) float64 { return float64(n) / (1024 * 1024 * 1024) }
+	switch {
+	case projected > quota && !force:
+		return errs.New(errs.StorageFull, "commit would bring the project to about %.1f GB, over its %d GB quota", gb(projected), p.QuotaGB)
+	case projected > quota:
+		fmt.Println(ui.Warning(fmt.Sprintf("Committing past the quota: about %.1f GB of %d GB (%.0f%%)", gb(projected), p.QuotaGB, percent)))
+	case percent >= QuotaWarnPercent:
+		fmt.Println(ui.Warning(fmt.Sprintf("Project storage at about %.0f%% of its %d GB quota after this commit", percent, p.QuotaGB)))
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package project
+
+import (
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/settings"
+)
+
+// CheckVaultWritable returns a ReadOnly error when the global read_only setting makes
+// every project on this machine read-only, e.g. to browse a shared backend safely
+func CheckVaultWritable() error {
+	if s, err := settings.Load(); err == nil && s.ReadOnly {
+		return errs.New(errs.ReadOnly, "the vault is read-only: commit, delete, prune and other writes are blocked (unset read_only in %s to allow them)", settings.GetSettingsPath())
+	}
+	return nil
+}
+
+// CheckWritable returns a ReadOnly error when the project is archived read-only, or the
+// whole vault is
+func (p *Project) CheckWritable() error {
+	if p.ReadOnly {
+		return errs.New(errs.ReadOnly, "project '%s' is read-only: commit, delete, prune and other writes are blocked (run 'vervids config set read_only false' to allow them)", p.ProjectName)
+	}
+	return CheckVaultWritable()
+}
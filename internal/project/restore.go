@@ -0,0 +1,467 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/chunks"
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/proxy"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// PullJournalFile is written into the output directory while a pull is in progress
+const PullJournalFile = ".vervids-pull.json"
+
+// restoreStep is one asset to bring back on pull
+type restoreStep struct {
+	asset      assets.Asset
+	stored     *AssetInfo // The version's record of the asset
+	dockerPath string
+	localPath  string
+	linkTarget string // Relative symlink to create at linkPath once localPath is restored
+	linkPath   string
+	linkOnly   bool // Target still exists locally; only the symlink is recreated
+	size       int64
+}
+
+// restorePlan lists every asset a pull will restore
+type restorePlan struct {
+	store   storage.Storage // Where the version's objects are kept
+	steps   []*restoreStep
+	skipped []string // Assets with nothing stored for them, e.g. missing at commit
+}
+
+// planRestore works out where each asset comes from and where it will be written,
+// without touching the filesystem
+func (p *Project) planRestore(store storage.Storage, version *Version, assetsDir string, needed []assets.Asset, opts RestoreOptions) (*restorePlan, error) {
+	plan := &restorePlan{store: store}
+	restoredFiles := make(map[string]string) // case-folded local path -> Docker path it will be restored from
+
+	for _, asset := range needed {
+		// Find the asset in version.Assets to get Docker path
+		vAsset := version.findAsset(asset.Path, asset.Filename)
+		step := &restoreStep{asset: asset, stored: vAsset}
+
+		// A symlinked asset whose target still exists locally only needs its link recreated
+		isLink := vAsset != nil && containsString(vAsset.LinkPaths, asset.Path)
+		if isLink && filepath.IsAbs(asset.Path) {
+			if _, err := os.Stat(vAsset.OriginalPath); err == nil {
+				step.linkOnly = true
+				step.linkTarget = vAsset.OriginalPath
+				step.linkPath = asset.Path
+				plan.steps = append(plan.steps, step)
+				continue
+			}
+		}
+
+		if vAsset == nil {
+			// Not in version metadata; older projects kept assets by filename in the shared folder
+			legacyPath := filepath.Join(docker.StoragePath, p.projectID(), "assets", asset.Filename)
+			exists, err := store.Exists(legacyPath)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				plan.skipped = append(plan.skipped, asset.Filename)
+				continue
+			}
+			vAsset = &AssetInfo{Filename: asset.Filename, Extension: asset.Extension, DockerPath: legacyPath}
+			step.stored = vAsset
+		}
+		if vAsset.DockerPath == "" || vAsset.Purged {
+			plan.skipped = append(plan.skipped, asset.Filename)
+			continue
+		}
+		step.dockerPath = vAsset.DockerPath
+		step.size = vAsset.Size
+
+		// Copy asset from Docker to local assets directory (symlinks restore their target's file)
+		targetFilename := asset.Filename
+		if isLink {
+			targetFilename = vAsset.Filename
+		}
+		if vAsset.ProxyOnly {
+			// Only the proxy was stored; restore it under its own name and point the project at it
+			targetFilename = proxy.ProxyFilename(vAsset.Filename, vAsset.Extension)
+			if vAsset.Proxy != nil {
+				step.size = vAsset.Proxy.Size
+			}
+		}
+		localAssetPath := filepath.Join(assetsDir, targetFilename)
+		if opts.PreserveStructure {
+			// Mirror the asset's location relative to the original project file
+			localAssetPath = filepath.Join(assetsDir, structuredAssetPath(vAsset))
+		}
+
+		// Different files with the same name go into a hash-named subfolder, keeping their filename.
+		// Names are compared ignoring case so bg.PNG and bg.png land in the same places on
+		// every OS, not overwriting each other on macOS and Windows only.
+		if prev, used := restoredFiles[strings.ToLower(localAssetPath)]; used && prev != step.dockerPath {
+			localAssetPath = filepath.Join(assetsDir, collisionDirName(vAsset, step.dockerPath), targetFilename)
+		}
+		restoredFiles[strings.ToLower(localAssetPath)] = step.dockerPath
+		step.localPath = localAssetPath
+
+		// Recreate the link next to its target so the project keeps referencing the link name
+		if isLink && asset.Filename != targetFilename {
+			step.linkTarget = targetFilename
+			step.linkPath = filepath.Join(filepath.Dir(localAssetPath), asset.Filename)
+		}
+		plan.steps = append(plan.steps, step)
+	}
+	return plan, nil
+}
+
+// validate checks that every object the plan needs is in storage and that the
+// destination has room for all of it
+func (plan *restorePlan) validate(outputDir string) error {
+	missing := []string{}
+	var total int64
+	for _, step := range plan.steps {
+		if step.linkOnly {
+			continue
+		}
+		exists, err := plan.store.Exists(step.dockerPath)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, step.asset.Filename)
+		}
+		total += step.size
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%d stored asset(s) are missing in %s: %s (run 'vervids fsck')", len(missing), plan.store.Name(), strings.Join(missing, ", "))
+	}
+
+	if free, err := storage.FreeSpace(outputDir); err == nil && uint64(total) > free {
+		return errs.New(errs.StorageFull, "not enough disk space in %s: need %.2f MB, %.2f MB available",
+			outputDir, float64(total)/(1024*1024), float64(free)/(1024*1024))
+	}
+	return nil
+}
+
+// print shows what will be restored and where
+func (plan *restorePlan) print(assetsDir string) {
+	var total int64
+	copies := 0
+	for _, step := range plan.steps {
+		if !step.linkOnly {
+			total += step.size
+			copies++
+		}
+	}
+	fmt.Println(ui.Info(fmt.Sprintf("Restore plan: %d file(s), %.2f MB into %s", copies, float64(total)/(1024*1024), assetsDir)))
+	for _, step := range plan.steps {
+		if step.linkOnly {
+			fmt.Printf(ui.Text("  🔗 %s -> %s\n"), step.linkPath, step.linkTarget)
+			continue
+		}
+		rel, err := filepath.Rel(assetsDir, step.localPath)
+		if err != nil {
+			rel = step.localPath
+		}
+		fmt.Printf("  %s (%.2f MB) -> assets/%s\n", step.asset.Filename, float64(step.size)/(1024*1024), filepath.ToSlash(rel))
+	}
+	for _, name := range plan.skipped {
+		fmt.Println(ui.Warning(fmt.Sprintf("Asset %s not found in %s storage, skipping", name, plan.store.Name())))
+	}
+}
+
+// run performs the step, copying from store and appending everything it creates to
+// created so a failed pull can be rolled back. Returns the path the project should
+// reference. The copy is shown on progress.
+func (step *restoreStep) run(store storage.Storage, created *[]string, progress *ui.Progress) (string, error) {
+	if step.linkOnly {
+		if err := mkdirAllTracked(filepath.Dir(step.linkPath), created); err != nil {
+			return "", err
+		}
+		if err := os.Symlink(step.linkTarget, step.linkPath); err != nil {
+			return "", err
+		}
+		*created = append(*created, step.linkPath)
+		fmt.Println(ui.Success(fmt.Sprintf("Recreated link: %s -> %s", step.linkPath, step.linkTarget)))
+		return step.linkPath, nil
+	}
+
+	if step.stored.ProxyOnly {
+		fmt.Println(ui.Warning(fmt.Sprintf("Only a proxy is stored for %s; restoring %s", step.asset.Filename, filepath.Base(step.localPath))))
+	}
+	if err := mkdirAllTracked(filepath.Dir(step.localPath), created); err != nil {
+		return "", err
+	}
+	*created = append(*created, step.localPath)
+
+	if step.stored.Chunked {
+		// Reassemble chunked assets from the chunk store
+		manifest, err := chunks.LoadManifest(store, step.dockerPath)
+		if err == nil {
+			err = chunks.Restore(store, manifest, step.localPath)
+		}
+		if err != nil {
+			return "", err
+		}
+	} else if err := downloadObject(store, step.dockerPath, step.localPath, step.size, progress); err != nil {
+		return "", err
+	}
+
+	if step.linkPath == "" {
+		return step.localPath, nil
+	}
+	os.Remove(step.linkPath)
+	if err := os.Symlink(step.linkTarget, step.linkPath); err != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to recreate link %s: %v", step.asset.Filename, err)))
+		return step.localPath, nil
+	}
+	*created = append(*created, step.linkPath)
+	return step.linkPath, nil
+}
+
+// downloadObject streams a stored object to localPath, reporting progress
+func downloadObject(store storage.Storage, dockerPath string, localPath string, size int64, progress *ui.Progress) error {
+	f, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+	err = store.Get(dockerPath, progress.Writer(filepath.Base(localPath), size, f))
+	progress.EndFile()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// resumed reports whether an interrupted pull already restored this step, per the
+// journal, and returns the path the project should reference
+func (step *restoreStep) resumed(journal *storage.Journal) (string, bool) {
+	if step.linkOnly {
+		return "", false
+	}
+	entry, ok := journal.Get(step.localPath)
+	if !ok || entry.Path != step.dockerPath {
+		return "", false
+	}
+	if info, err := os.Stat(step.localPath); err != nil || info.Size() != entry.Size {
+		return "", false
+	}
+	if step.linkPath == "" {
+		return step.localPath, true
+	}
+	if _, err := os.Lstat(step.linkPath); err != nil {
+		return "", false
+	}
+	return step.linkPath, true
+}
+
+// record notes a finished step in the pull journal
+func (step *restoreStep) record(journal *storage.Journal) {
+	if step.linkOnly {
+		return
+	}
+	if info, err := os.Stat(step.localPath); err == nil {
+		journal.Record(step.localPath, storage.JournalEntry{Path: step.dockerPath, Hash: step.stored.Hash, Size: info.Size()})
+	}
+}
+
+// mkdirAllTracked creates dir and any missing parents, recording the ones it created
+func mkdirAllTracked(dir string, created *[]string) error {
+	missing := []string{}
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil || filepath.Dir(d) == d {
+			break
+		}
+		missing = append(missing, d)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		*created = append(*created, missing[i])
+	}
+	return nil
+}
+
+// cleanupRestore removes files and directories created by a failed pull, newest first
+func cleanupRestore(created []string) {
+	for i := len(created) - 1; i >= 0; i-- {
+		os.Remove(created[i])
+	}
+}
+
+// AssetRestore describes an asset brought back from a past version into a project file
+type AssetRestore struct {
+	Asset     *AssetInfo // The version's record of the asset
+	LocalPath string     // Where the stored content was written
+	Reference string     // The project file's reference before the restore
+	NewPath   string     // The reference now, relative to the project file
+}
+
+// RestoreAsset copies one asset as stored in a past version next to aepxFilePath, under
+// assets/vNNN/, and points the project file's reference to that asset at the copy.
+// Everything else in the project file is left as it is. The asset is matched by
+// filename or relative path and must still be referenced by the project file.
+func (p *Project) RestoreAsset(versionNum int, name string, aepxFilePath string) (*AssetRestore, error) {
+	version, err := p.FindVersion(versionNum)
+	if err != nil {
+		return nil, err
+	}
+	var stored *AssetInfo
+	for i := range version.Assets {
+		if assetMatchesName(&version.Assets[i], name) {
+			stored = &version.Assets[i]
+			break
+		}
+	}
+	if stored == nil {
+		return nil, fmt.Errorf("version %d has no asset named '%s'", versionNum, name)
+	}
+	if stored.DockerPath == "" {
+		return nil, fmt.Errorf("%s was missing when version %d was committed; nothing is stored for it", stored.Filename, versionNum)
+	}
+
+	reference, err := p.assetReference(aepxFilePath, stored, name)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := p.readyStore()
+	if err != nil {
+		return nil, fmt.Errorf("%s not available: %w", p.StorageName(), err)
+	}
+
+	projectDir := filepath.Dir(aepxFilePath)
+	assetsDir := filepath.Join(projectDir, "assets", fmt.Sprintf("v%03d", versionNum))
+	needed := []assets.Asset{{Path: stored.OriginalPath, Filename: stored.Filename, Extension: stored.Extension}}
+	plan, err := p.planRestore(store, version, assetsDir, needed, RestoreOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.steps) == 0 {
+		return nil, fmt.Errorf("%s is not in %s storage", stored.Filename, store.Name())
+	}
+	if err := plan.validate(projectDir); err != nil {
+		return nil, err
+	}
+
+	step := plan.steps[0]
+	created := []string{}
+	progress := ui.NewProgress(step.size)
+	progress.Next(step.size)
+	localPath, err := step.run(store, &created, progress)
+	if err != nil {
+		cleanupRestore(created)
+		return nil, fmt.Errorf("failed to restore %s, removed partially restored files: %w", stored.Filename, err)
+	}
+
+	newPath, err := filepath.Rel(projectDir, localPath)
+	if err != nil {
+		newPath = localPath
+	}
+	if err := assets.UpdateAssetPaths(aepxFilePath, map[string]string{reference: newPath}); err != nil {
+		cleanupRestore(created)
+		return nil, fmt.Errorf("failed to update asset path in .aepx file: %w", err)
+	}
+	return &AssetRestore{Asset: stored, LocalPath: localPath, Reference: reference, NewPath: newPath}, nil
+}
+
+// assetReference returns the path as written in the project file for the asset, which
+// may have moved or been renamed since the version was committed
+func (p *Project) assetReference(aepxFilePath string, stored *AssetInfo, name string) (string, error) {
+	parseResult, err := parseProjectFile(aepxFilePath, p.parseOptions())
+	if err != nil {
+		return "", fmt.Errorf("failed to parse .aepx file: %w", err)
+	}
+
+	references := make(map[string]string) // path -> reference as written
+	for _, a := range parseResult.Assets {
+		references[a.Path] = a.Reference
+	}
+	for _, missing := range parseResult.MissingAssets {
+		references[missing] = parseResult.MissingReferences[missing]
+	}
+
+	matches := func(path string) bool {
+		return strings.EqualFold(filepath.Base(path), stored.Filename) ||
+			strings.EqualFold(filepath.ToSlash(path), filepath.ToSlash(stored.OriginalPath)) ||
+			strings.HasSuffix(strings.ToLower(filepath.ToSlash(path)), "/"+strings.ToLower(strings.ReplaceAll(name, "\\", "/")))
+	}
+	candidates := make([]string, 0, len(references))
+	for path := range references {
+		candidates = append(candidates, path)
+	}
+	sort.Strings(candidates)
+	for _, path := range candidates {
+		if matches(path) {
+			if references[path] != "" {
+				return references[path], nil
+			}
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s does not reference %s; import it in After Effects first", filepath.Base(aepxFilePath), stored.Filename)
+}
+
+// BackupsDir is the directory inside .vervids holding working files replaced by restores
+const BackupsDir = "backups"
+
+// RestoreRecord is one restore of the working project file to a version
+type RestoreRecord struct {
+	Version   int       `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	FilePath  string    `json:"file_path"`        // The working file that was overwritten
+	Backup    string    `json:"backup,omitempty"` // Copy of the working file as it was, if it existed
+}
+
+// RestoreInPlace overwrites the working project file at target with a version, as
+// 'vervids restore --in-place'. The file is first backed up under .vervids/backups.
+// Assets missing on this machine are restored next to it as on pull, and references
+// are rewritten to local locations. The restore is recorded in the project's history.
+func (p *Project) RestoreInPlace(versionNum int, target string, opts RestoreOptions) (*RestoreRecord, error) {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+	record := &RestoreRecord{Version: versionNum, Timestamp: time.Now(), FilePath: target}
+
+	// Restored next to the working file under a temporary name, so relative asset paths
+	// come out right and the working file is only replaced once everything is in place
+	opts.ProjectFile = filepath.Join(filepath.Dir(target), ".vervids-restore-"+filepath.Base(target))
+	restored, err := p.RestoreVersion(versionNum, filepath.Dir(target), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(target); err == nil {
+		backupDir, err := filepath.Abs(filepath.Join(storage.VerVidsDir, BackupsDir))
+		if err != nil {
+			os.Remove(restored)
+			return nil, err
+		}
+		ext := filepath.Ext(target)
+		name := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(filepath.Base(target), ext), record.Timestamp.Format("20060102-150405"), ext)
+		record.Backup = filepath.Join(backupDir, name)
+		if err := storage.CopyFile(target, record.Backup); err != nil {
+			os.Remove(restored)
+			return nil, fmt.Errorf("failed to back up %s: %w", filepath.Base(target), err)
+		}
+	}
+	if err := os.Rename(restored, target); err != nil {
+		os.Remove(restored)
+		return nil, fmt.Errorf("failed to replace %s: %w", filepath.Base(target), err)
+	}
+
+	p.Restores = append(p.Restores, *record)
+	if err := p.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+	return record, nil
+}
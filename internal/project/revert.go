@@ -0,0 +1,241 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/merge"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/tracking"
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// RevertResult describes a version created by RevertVersion
+type RevertResult struct {
+	Version *Version
+	Kept    []string // Assets the reverted version changed that later versions changed again
+}
+
+// RevertVersion records a new version that undoes what the given version changed
+// relative to its parent, keeping everything committed since (like git revert).
+// Project file edits are undone line by line; if later versions changed the same
+// lines, nothing is recorded and a Conflict error is returned. Assets come from
+// storage, so none of the files need to be on this machine.
+func (p *Project) RevertVersion(versionNum int, message string) (*RevertResult, error) {
+	if err := p.CheckWritable(); err != nil {
+		return nil, err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Numbers have gaps after a prune, so the parent is whatever came before
+	target, err := p.FindVersion(versionNum)
+	if err != nil {
+		return nil, err
+	}
+	i := versionIndex(p.Versions, target)
+	if i == 0 {
+		return nil, fmt.Errorf("version %d is the first and has no parent to revert to", versionNum)
+	}
+	parent := &p.Versions[i-1]
+	if p.Generic {
+		return nil, fmt.Errorf("generic projects are snapshotted whole and can't be reverted line by line; pull version %d and commit it instead", parent.Number)
+	}
+	latest := p.GetLatestVersion()
+
+	key, err := signingKey()
+	if err != nil {
+		return nil, err
+	}
+	store, err := p.readyStore()
+	if err != nil {
+		return nil, err
+	}
+
+	// Undo the parent -> target edits on top of the latest project file
+	texts := make([][]string, 3)
+	for i, v := range []*Version{parent, target, latest} {
+		path, cleanup, err := p.FetchVersionFile(v.Number)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		cleanup()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read version %d: %w", v.Number, err)
+		}
+		texts[i] = merge.SplitLines(string(data))
+	}
+	merged, conflicts := merge.Revert(texts[0], texts[1], texts[2])
+	if conflicts > 0 {
+		return nil, errs.New(errs.Conflict, "%d change(s) from version %d overlap with later edits to the project file", conflicts, versionNum)
+	}
+
+	tmpDir, err := storage.MkdirTemp("revert-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	revertedPath := filepath.Join(tmpDir, filepath.Base(latest.DockerPath))
+	if err := os.WriteFile(revertedPath, []byte(strings.Join(merged, "")), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write reverted project file: %w", err)
+	}
+
+	result := &RevertResult{}
+	version := Version{
+		Number:    p.nextVersionNumber(),
+		Message:   message,
+		Timestamp: time.Now(),
+		Author:    p.commitAuthor(),
+	}
+	version.Assets, result.Kept = revertAssets(parent.Assets, target.Assets, latest.Assets)
+	version.Missing = revertStrings(parent.Missing, target.Missing, latest.Missing)
+	version.AssetCount = len(version.Assets)
+	for _, a := range version.Assets {
+		version.TotalSize += a.Size
+	}
+	if info, err := os.Stat(revertedPath); err == nil {
+		version.Size = info.Size()
+	}
+
+	if err := p.checkPublished(); err != nil {
+		return nil, err
+	}
+	dockerVersionDir := filepath.Join(docker.StoragePath, p.projectID(), fmt.Sprintf("v%03d", version.Number))
+	if err := store.MakeDir(dockerVersionDir); err != nil {
+		return nil, fmt.Errorf("failed to create version directory in %s: %w", store.Name(), err)
+	}
+	version.DockerPath = filepath.Join(dockerVersionDir, filepath.Base(revertedPath))
+	if err := ui.RunStep("Copying project file", func() error { return store.PutFile(revertedPath, version.DockerPath) }); err != nil {
+		return nil, fmt.Errorf("failed to copy project file to %s: %w", store.Name(), err)
+	}
+
+	// Markers and layer changes are only read from .aepx files
+	if assets.IsAEPX(revertedPath) {
+		if markers, err := aepx.ExtractMarkers(revertedPath); err != nil {
+			fmt.Println(ui.Warning(fmt.Sprintf("Failed to read markers: %v", err)))
+		} else {
+			version.Markers = markers
+		}
+		version.AppVersion = readAppVersion(revertedPath)
+		version.Comps = readComps(revertedPath)
+		if assets.IsAEPX(latest.DockerPath) {
+			if changes, err := p.layerChangesSince(latest.Number, revertedPath); err != nil {
+				fmt.Println(ui.Warning(fmt.Sprintf("Failed to compare layers with previous version: %v", err)))
+			} else {
+				version.LayerChanges = changes
+			}
+		}
+	}
+
+	if err := p.signVersion(&version, revertedPath, key); err != nil {
+		return nil, err
+	}
+
+	track := tracking.CreateTracking(version.Number, version.Message, trackingInputs(version.Assets), trackingInputs(latest.Assets))
+	track.SetProjectFile(version.DockerPath, version.Size, version.FileHash)
+	if err := tracking.SaveTracking(store, dockerVersionDir, track); err != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to save asset tracking: %v", err)))
+	}
+	if err := p.writeVersionManifest(&version); err != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to save version manifest: %v", err)))
+	}
+	p.Versions = append(p.Versions, version)
+	if err := p.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+	p.publishMetadata()
+	result.Version = &p.Versions[len(p.Versions)-1]
+	return result, nil
+}
+
+// versionIndex returns the position of v, which points into versions
+func versionIndex(versions []Version, v *Version) int {
+	for i := range versions {
+		if &versions[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// revertAssets undoes the parent -> target asset changes in latest: assets the target
+// added are dropped, removed ones come back and changed ones get the parent's content.
+// An asset changed again after the target keeps its latest content and is returned in kept.
+func revertAssets(parent, target, latest []AssetInfo) (result []AssetInfo, kept []string) {
+	byPath := func(list []AssetInfo) map[string]*AssetInfo {
+		index := make(map[string]*AssetInfo)
+		for i := range list {
+			index[list[i].OriginalPath] = &list[i]
+		}
+		return index
+	}
+	parentAssets, targetAssets := byPath(parent), byPath(target)
+
+	for _, a := range latest {
+		t, changedByTarget := targetAssets[a.OriginalPath]
+		old, inParent := parentAssets[a.OriginalPath]
+		switch {
+		case !changedByTarget || (inParent && old.Hash == t.Hash):
+			result = append(result, a)
+		case a.Hash != t.Hash:
+			// Changed again since; the later change wins
+			result = append(result, a)
+			kept = append(kept, a.Filename)
+		case inParent:
+			result = append(result, *old)
+		}
+		// Added by the target and unchanged since: dropped
+	}
+
+	present := byPath(result)
+	for _, old := range parent {
+		if _, inTarget := targetAssets[old.OriginalPath]; inTarget {
+			continue
+		}
+		if _, ok := present[old.OriginalPath]; !ok {
+			result = append(result, old)
+		}
+	}
+	if result == nil {
+		result = []AssetInfo{}
+	}
+	return result, kept
+}
+
+// revertStrings undoes the parent -> target additions and removals in latest
+func revertStrings(parent, target, latest []string) []string {
+	inParent, inTarget := make(map[string]bool), make(map[string]bool)
+	for _, s := range parent {
+		inParent[s] = true
+	}
+	for _, s := range target {
+		inTarget[s] = true
+	}
+
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, s := range latest {
+		if inTarget[s] && !inParent[s] {
+			continue
+		}
+		result = append(result, s)
+		seen[s] = true
+	}
+	for _, s := range parent {
+		if !inTarget[s] && !seen[s] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// prunedProject returns a project whose versions are numbered 0, 2 and 3, v001 having
+// been pruned, each with a Nuke script stored in store
+func prunedProject(t *testing.T) (*Project, *memStore) {
+	t.Helper()
+	inProjectDir(t)
+	store := newMemStore()
+	p := &Project{ProjectName: "comp.nk", ProjectPath: "/work/comp.nk", backend: store}
+	for i, v := range []struct {
+		number  int
+		content string
+	}{
+		{0, "a\nb\nc\nd\n"},
+		{2, "a\nB\nc\nd\n"},
+		{3, "a\nB\nc\nD\n"},
+	} {
+		path := filepath.Join(p.StorageDir(), fmt.Sprintf("v%03d", v.number), "comp.nk")
+		if err := store.Put(bytes.NewReader([]byte(v.content)), int64(len(v.content)), path); err != nil {
+			t.Fatal(err)
+		}
+		p.Versions = append(p.Versions, Version{
+			Number:     v.number,
+			Message:    "edit " + strconv.Itoa(v.number),
+			Timestamp:  time.Date(2024, 3, 1, 9+i, 0, 0, 0, time.UTC),
+			DockerPath: path,
+			Assets:     []AssetInfo{},
+		})
+	}
+	return p, store
+}
+
+func TestRevertVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int
+		want     string // Project file of the new version
+		wantErr  bool
+		wantKind errs.Kind
+	}{
+		{name: "latest, parent by position", version: 3, want: "a\nB\nc\nd\n"},
+		{name: "after a pruned number", version: 2, want: "a\nb\nc\nD\n"},
+		{name: "pruned version", version: 1, wantErr: true, wantKind: errs.VersionNotFound},
+		{name: "past the last", version: 4, wantErr: true, wantKind: errs.VersionNotFound},
+		{name: "first version", version: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, store := prunedProject(t)
+
+			result, err := p.RevertVersion(tt.version, "Revert")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("RevertVersion(%d) succeeded, want an error", tt.version)
+				}
+				if tt.wantKind != errs.Unknown && errs.KindOf(err) != tt.wantKind {
+					t.Errorf("error kind = %v, want %v (%v)", errs.KindOf(err), tt.wantKind, err)
+				}
+				if len(p.Versions) != 3 {
+					t.Errorf("%d versions after a failed revert, want 3", len(p.Versions))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RevertVersion(%d) error = %v", tt.version, err)
+			}
+
+			// The next number follows the highest, not the count of versions
+			if result.Version.Number != 4 || len(p.Versions) != 4 {
+				t.Errorf("new version is v%03d of %d, want v004 of 4", result.Version.Number, len(p.Versions))
+			}
+			if got := store.read(t, result.Version.DockerPath); got != tt.want {
+				t.Errorf("new version's project file = %q, want %q", got, tt.want)
+			}
+			if _, err := os.Stat(storage.GetCommitLockPath()); !os.IsNotExist(err) {
+				t.Errorf("commit lock left behind: %v", err)
+			}
+		})
+	}
+}
+
+func TestRevertVersionWaitsForWrites(t *testing.T) {
+	p, _ := prunedProject(t)
+	// A live process, this one, is writing to the project
+	if err := os.WriteFile(storage.GetCommitLockPath(), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.RevertVersion(3, "Revert")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("RevertVersion() = %v while another write held the lock, want it to wait", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	os.Remove(storage.GetCommitLockPath())
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RevertVersion() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RevertVersion() still waiting after the lock was released")
+	}
+	if len(p.Versions) != 4 {
+		t.Errorf("%d versions after the revert, want 4", len(p.Versions))
+	}
+}
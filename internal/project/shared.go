@@ -0,0 +1,276 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/tracking"
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// sharedConfigFile is the copy of config.json kept next to a project's versions in
+// storage, so another machine using the same storage can fetch the history
+const sharedConfigFile = "project.json"
+
+// PublishMetadata writes the project's config, versions included, to its storage
+// directory for 'vervids fetch --metadata'
+func (p *Project) PublishMetadata() error {
+	store, err := p.store()
+	if err != nil {
+		return err
+	}
+	p.PublishedFrom = publisher()
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	path := filepath.Join(docker.StoragePath, p.projectID(), sharedConfigFile)
+	return store.Put(bytes.NewReader(data), int64(len(data)), path)
+}
+
+// publishMetadata publishes the config after a commit, warning rather than failing:
+// the commit itself is already stored and saved
+func (p *Project) publishMetadata() {
+	if err := p.PublishMetadata(); err != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to publish project metadata: %v", err)))
+	}
+}
+
+// FetchMetadata reads the history of the project stored in dir in store without
+// downloading any project files or assets, for a local project in localDir. It uses
+// the config the last commit published; for projects committed to Docker before
+// configs were published, versions are rebuilt from each version folder's manifest,
+// or from its asset tracking, which lacks metadata and signatures, for versions
+// committed before manifests were written. Labels can't be rebuilt.
+func FetchMetadata(store storage.Storage, dir string, localDir string) (*Project, bool, error) {
+	p, err := readPublished(store, dir)
+	if err != nil {
+		return nil, false, err
+	}
+	if p != nil {
+		p.ProjectPath = filepath.Join(localDir, filepath.Base(p.ProjectPath))
+		for i := range p.Versions {
+			p.Versions[i].FilePath = filepath.Join(localDir, filepath.Base(p.Versions[i].FilePath))
+		}
+		p.SearchPaths = nil // Folders on the machine that committed
+		return p, true, nil
+	}
+
+	if _, ok := store.(docker.Volume); !ok {
+		return nil, false, fmt.Errorf("no history has been published to %s", store.Name())
+	}
+	p, err = rebuildMetadata(dir, localDir)
+	return p, false, err
+}
+
+// FetchStoredMetadata reads the history published to the project's own storage, the
+// Docker volume or its remote, like FetchMetadata
+func (p *Project) FetchStoredMetadata(localDir string) (*Project, bool, error) {
+	store, err := p.readyStore()
+	if err != nil {
+		return nil, false, err
+	}
+	return FetchMetadata(store, p.StorageDir(), localDir)
+}
+
+// readPublished reads the config published in dockerDir, or nil if none was. Storage
+// that can't be read is an error, not an unpublished project: callers rely on the
+// published config to refuse conflicting commits.
+func readPublished(store storage.Storage, dockerDir string) (*Project, error) {
+	var buf bytes.Buffer
+	if err := store.Get(filepath.Join(dockerDir, sharedConfigFile), &buf); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the published %s: %w", sharedConfigFile, err)
+	}
+	var p Project
+	if err := json.Unmarshal(buf.Bytes(), &p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", sharedConfigFile, err)
+	}
+	return &p, nil
+}
+
+// publisher identifies this copy of the project's config by host and directory
+func publisher() string {
+	host, _ := os.Hostname()
+	dir, _ := os.Getwd()
+	return host + ":" + dir
+}
+
+// checkPublished refuses a commit when storage holds versions this config doesn't
+// know, e.g. committed from a copied project folder or another machine's checkout.
+// Both copies would number their next version the same and overwrite each other's
+// files. Projects without a published config aren't checked.
+func (p *Project) checkPublished() error {
+	store, err := p.store()
+	if err != nil {
+		return err
+	}
+	published, err := readPublished(store, p.StorageDir())
+	if err != nil || published == nil {
+		return err
+	}
+	other := "another copy of this project"
+	if published.PublishedFrom != "" && published.PublishedFrom != publisher() {
+		other = fmt.Sprintf("the copy of this project at %s", published.PublishedFrom)
+	}
+	for i, v := range published.Versions {
+		if i >= len(p.Versions) {
+			return errs.New(errs.Conflict,
+				"storage has v%03d-v%03d, committed from %s, which this config doesn't know; run 'vervids fetch %s --metadata' here to catch up before committing, or remove one of the copies' .vervids folder",
+				v.Number, published.Versions[len(published.Versions)-1].Number, other, p.projectID())
+		}
+		if !sameVersion(p.Versions[i], v.Timestamp, v.FileHash) {
+			return errs.New(errs.Conflict,
+				"v%03d in storage, committed from %s, differs from v%03d here; the histories have diverged, so keep one copy's .vervids folder and run 'vervids fetch %s --metadata' in the other",
+				v.Number, other, p.Versions[i].Number, p.projectID())
+		}
+	}
+	return nil
+}
+
+// rebuildMetadata reconstructs a project's versions from the project files, manifests
+// and asset tracking in its version folders
+func rebuildMetadata(dockerDir string, localDir string) (*Project, error) {
+	// Sizes and paths of the files directly in each version folder
+	output, err := docker.ExecInContainer("sh", "-c", fmt.Sprintf(
+		"find %q -mindepth 2 -maxdepth 2 -path '*/v[0-9][0-9][0-9]/*' -type f -exec stat -c '%%s %%n' {} +", dockerDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	versions := make(map[int]*Version)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		sizeField, path, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || filepath.Base(path) == "asset-tracking.json" || filepath.Base(path) == VersionManifestFile {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(path)), "v"))
+		if err != nil {
+			continue
+		}
+		size, _ := strconv.ParseInt(sizeField, 10, 64)
+		versions[number] = &Version{
+			Number:     number,
+			Size:       size,
+			FilePath:   filepath.Join(localDir, filepath.Base(path)),
+			DockerPath: path,
+			Assets:     []AssetInfo{},
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions found in %s", dockerDir)
+	}
+
+	// All tracking files in one read; each records its version number
+	var buf bytes.Buffer
+	script := fmt.Sprintf("cat %q/v[0-9][0-9][0-9]/asset-tracking.json 2>/dev/null; true", dockerDir)
+	if err := docker.StreamFromContainer(nil, &buf, "sh", "-c", script); err != nil {
+		return nil, fmt.Errorf("failed to read asset tracking: %w", err)
+	}
+	poolDir := filepath.Join(dockerDir, "assets")
+	decoder := json.NewDecoder(&buf)
+	for {
+		var track tracking.AssetTracking
+		if err := decoder.Decode(&track); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse asset tracking: %w", err)
+		}
+		v := versions[track.Version]
+		if v == nil {
+			continue
+		}
+		v.Message = track.CommitMessage
+		v.Timestamp, _ = time.Parse(time.RFC3339, track.Timestamp)
+		v.TotalSize = v.Size
+		for _, a := range track.Assets {
+			if !a.Present {
+				if a.Status == "missing" {
+					v.Missing = append(v.Missing, a.Path)
+				}
+				continue
+			}
+			info := AssetInfo{
+				OriginalPath: a.Path,
+				RelativePath: a.RelativePath,
+				Filename:     a.Filename,
+				Extension:    a.Extension,
+				Size:         a.Size,
+				Hash:         a.Hash,
+			}
+			if a.Hash != "" {
+				info.DockerPath = assetObjectPath(poolDir, a.Hash, a.Extension)
+			}
+			v.Assets = append(v.Assets, info)
+			v.TotalSize += a.Size
+		}
+		v.AssetCount = len(v.Assets)
+	}
+
+	// Manifests record each version whole, so they replace what tracking gave
+	manifests, bad, err := readVersionManifests(dockerDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, number := range bad {
+		fmt.Println(ui.Warning(fmt.Sprintf("v%03d: manifest.json is damaged; rebuilt from asset tracking", number)))
+	}
+	for number, m := range manifests {
+		v := versions[number]
+		if v == nil {
+			continue
+		}
+		*v = m.Version
+		v.FilePath = filepath.Join(localDir, filepath.Base(v.DockerPath))
+		if v.Assets == nil {
+			v.Assets = []AssetInfo{}
+		}
+	}
+
+	p := &Project{UseDocker: true, DockerVolume: docker.VolumeName}
+	for _, v := range versions {
+		p.Versions = append(p.Versions, *v)
+	}
+	sort.Slice(p.Versions, func(i, j int) bool { return p.Versions[i].Number < p.Versions[j].Number })
+	latest := p.Versions[len(p.Versions)-1]
+	p.ProjectName = filepath.Base(latest.DockerPath)
+	if m := manifests[latest.Number]; m != nil {
+		p.ProjectName = m.Project
+	}
+	p.ProjectPath = filepath.Join(localDir, p.ProjectName)
+	p.CreatedAt = p.Versions[0].Timestamp
+	return p, nil
+}
+
+// AdoptMetadata takes the history fetched from storage into p: its versions, labels
+// and notes. p's settings for this machine (paths, search paths, mappings) are kept.
+func (p *Project) AdoptMetadata(fetched *Project) {
+	p.Versions = fetched.Versions
+	if len(fetched.Labels) > 0 {
+		p.Labels = fetched.Labels
+	}
+	if fetched.Notes != "" {
+		p.Notes = fetched.Notes
+	}
+	if len(fetched.PinnedAssets) > 0 {
+		p.PinnedAssets = fetched.PinnedAssets
+	}
+}
+
+// StorageDir returns the project's directory in storage
+func (p *Project) StorageDir() string {
+	return filepath.Join(docker.StoragePath, p.projectID())
+}
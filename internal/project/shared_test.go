@@ -0,0 +1,104 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+)
+
+// newTestProject returns a project named promo stored in store, with a version for
+// each of the hashes given
+func newTestProject(store *memStore, hashes ...string) *Project {
+	p := &Project{ProjectName: "promo.aepx", ProjectPath: "/work/promo.aepx", backend: store}
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i, hash := range hashes {
+		p.Versions = append(p.Versions, Version{
+			Number:     i,
+			Message:    "commit " + hash,
+			Timestamp:  start.Add(time.Duration(i) * time.Hour),
+			FilePath:   "/work/promo.aepx",
+			DockerPath: filepath.Join(p.StorageDir(), fmt.Sprintf("v%03d", i), "promo.aepx"),
+			Assets:     []AssetInfo{},
+			FileHash:   hash,
+		})
+	}
+	return p
+}
+
+// publish stores versions as the config another copy of p published
+func publish(t *testing.T, store *memStore, p *Project, from string, versions []Version) {
+	t.Helper()
+	published := *p
+	published.Versions = versions
+	published.PublishedFrom = from
+	data, err := json.Marshal(&published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(bytes.NewReader(data), int64(len(data)), filepath.Join(p.StorageDir(), sharedConfigFile)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadPublished(t *testing.T) {
+	store := newMemStore()
+	p := newTestProject(store, "a")
+
+	got, err := readPublished(store, p.StorageDir())
+	if err != nil || got != nil {
+		t.Fatalf("readPublished() with nothing published = %v, %v; want nil, nil", got, err)
+	}
+
+	publish(t, store, p, "edit-bay:/work", p.Versions)
+	got, err = readPublished(store, p.StorageDir())
+	if err != nil || got == nil || got.PublishedFrom != "edit-bay:/work" {
+		t.Fatalf("readPublished() = %v, %v; want the published config", got, err)
+	}
+
+	store.err = errors.New("connection reset")
+	if got, err := readPublished(store, p.StorageDir()); err == nil {
+		t.Fatalf("readPublished() with storage failing = %v, nil; want an error", got)
+	}
+}
+
+func TestCheckPublished(t *testing.T) {
+	tests := []struct {
+		name      string
+		published []string // Hashes of the published versions; nil publishes nothing
+		storeErr  error
+		wantErr   bool
+		wantKind  errs.Kind
+	}{
+		{name: "nothing published", published: nil},
+		{name: "same history", published: []string{"a", "b"}},
+		{name: "behind this copy", published: []string{"a"}},
+		{name: "ahead of this copy", published: []string{"a", "b", "c"}, wantErr: true, wantKind: errs.Conflict},
+		{name: "diverged", published: []string{"a", "x"}, wantErr: true, wantKind: errs.Conflict},
+		{name: "storage failing", published: []string{"a", "b", "c"}, storeErr: errors.New("access denied"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			p := newTestProject(store, "a", "b")
+			if tt.published != nil {
+				other := newTestProject(store, tt.published...)
+				publish(t, store, p, "render-node:/work", other.Versions)
+			}
+			store.err = tt.storeErr
+
+			err := p.checkPublished()
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("checkPublished() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantKind != errs.Unknown && errs.KindOf(err) != tt.wantKind {
+				t.Errorf("error kind = %v, want %v", errs.KindOf(err), tt.wantKind)
+			}
+		})
+	}
+}
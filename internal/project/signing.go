@@ -0,0 +1,153 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/settings"
+	"github.com/ajeebtech/vervideos/internal/signing"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// TrustedKeysFile lists the public keys whose signatures 'vervids verify-signatures'
+// accepts, inside the project's .vervids directory
+const TrustedKeysFile = "trusted_keys"
+
+// Signature check results
+const (
+	SignatureGood      = "good"      // Valid, by a trusted key (or no trust list was given)
+	SignatureUntrusted = "untrusted" // Valid, but the key isn't in the trust list
+	SignatureBad       = "bad"       // Doesn't match the version's manifest
+	SignatureMissing   = "unsigned"
+)
+
+// SignatureCheck is the result of verifying one version's signature
+type SignatureCheck struct {
+	Version int
+	Status  string
+	Signer  string
+	Err     error // Why a signature is bad, or why the stored project file doesn't match
+}
+
+// Manifest returns the text a version's signature covers: what was committed, by whom
+// and when, and the content hashes of the project file and every asset. Labels, tags
+// and other details that can change after the commit are left out.
+func (v *Version) Manifest(projectName string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "vervids-manifest 1\n")
+	fmt.Fprintf(&b, "project %s\n", projectName)
+	fmt.Fprintf(&b, "version %d\n", v.Number)
+	fmt.Fprintf(&b, "message %q\n", v.Message)
+	fmt.Fprintf(&b, "timestamp %s\n", v.Timestamp.UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "author %q\n", v.Author)
+	fmt.Fprintf(&b, "file %s %d\n", v.FileHash, v.Size)
+	for _, a := range v.Assets {
+		fmt.Fprintf(&b, "asset %s %d %q\n", a.Hash, a.Size, a.OriginalPath)
+	}
+	for _, m := range v.Missing {
+		fmt.Fprintf(&b, "missing %q\n", m)
+	}
+	for _, pair := range MetaPairs(v.Meta) {
+		fmt.Fprintf(&b, "meta %q\n", pair)
+	}
+	return []byte(b.String())
+}
+
+// signingKey returns the key configured to sign new commits, or nil if signing is off.
+// It is loaded before anything is stored so a missing key or tool fails the commit early.
+func signingKey() (*signing.Key, error) {
+	s, err := settings.Load()
+	if err != nil || s.SigningKey == "" {
+		return nil, err
+	}
+	return signing.LoadKey(s.SigningKey)
+}
+
+// signVersion hashes the version's project file and, with a signing key, signs the
+// version's manifest
+func (p *Project) signVersion(v *Version, projectFile string, key *signing.Key) error {
+	hash, err := storage.HashFile(projectFile)
+	if err != nil {
+		return fmt.Errorf("failed to hash project file: %w", err)
+	}
+	v.FileHash = hash
+	if key == nil {
+		return nil
+	}
+	sig, err := key.Sign(v.Manifest(p.ProjectName), v.Author)
+	if err != nil {
+		return fmt.Errorf("failed to sign version: %w", err)
+	}
+	v.Signature = sig
+	return nil
+}
+
+// VerifySignatures checks every version's signature against its manifest. With trusted
+// keys, valid signatures by other keys are reported as untrusted. With checkFiles, each
+// stored project file is also fetched and compared with the hash that was signed.
+func (p *Project) VerifySignatures(trusted []string, checkFiles bool) []SignatureCheck {
+	checks := make([]SignatureCheck, 0, len(p.Versions))
+	for i := range p.Versions {
+		v := &p.Versions[i]
+		check := SignatureCheck{Version: v.Number, Status: SignatureMissing}
+		if v.Signature != nil {
+			check.Signer = v.Signature.Signer
+			check.Status = SignatureGood
+			if err := signing.Verify(v.Signature, v.Manifest(p.ProjectName)); err != nil {
+				check.Status, check.Err = SignatureBad, err
+			} else if len(trusted) > 0 && !isTrusted(v.Signature.PublicKey, trusted) {
+				check.Status = SignatureUntrusted
+			}
+		}
+		if checkFiles && check.Err == nil && v.FileHash != "" {
+			if err := p.checkVersionFile(v); err != nil {
+				check.Err = err
+				if check.Status != SignatureMissing {
+					check.Status = SignatureBad
+				}
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkVersionFile compares the stored project file with the hash recorded at commit
+func (p *Project) checkVersionFile(v *Version) error {
+	path, cleanup, err := p.FetchVersionFile(v.Number)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	hash, err := storage.HashFile(path)
+	if err != nil {
+		return err
+	}
+	if hash != v.FileHash {
+		return fmt.Errorf("stored project file was modified after commit")
+	}
+	return nil
+}
+
+// isTrusted reports whether a public key is in the trusted list
+func isTrusted(publicKey string, trusted []string) bool {
+	for _, t := range trusted {
+		if t == publicKey {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadTrustedKeys reads the project's .vervids/trusted_keys (relative to the project
+// directory), or returns nil if there is none
+func LoadTrustedKeys() ([]string, error) {
+	keys, err := signing.LoadTrustedKeys(filepath.Join(storage.VerVidsDir, TrustedKeysFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return keys, err
+}
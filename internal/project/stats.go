@@ -0,0 +1,114 @@
+package project
+
+// Stats summarizes a project's history and what it takes up in storage
+type Stats struct {
+	Versions      int
+	Oldest        *Version
+	Newest        *Version
+	LogicalSize   int64 // Project files plus every version's assets, as if each were stored in full
+	StoredSize    int64 // Project files plus each stored object once
+	StoredObjects int   // Distinct asset objects (files, proxies, chunk manifests)
+	Missing       int   // Assets missing when the newest version was committed
+	Pinned        int
+}
+
+// Savings returns how many bytes content-addressed storage saves over storing every
+// version's assets in full
+func (s Stats) Savings() int64 {
+	return s.LogicalSize - s.StoredSize
+}
+
+// Stats computes the project's history and storage summary from config.json alone;
+// sizes are those recorded on commit
+func (p *Project) Stats() Stats {
+	stats := Stats{Versions: len(p.Versions), Pinned: len(p.PinnedAssets)}
+	if len(p.Versions) == 0 {
+		return stats
+	}
+	stats.Oldest = &p.Versions[0]
+	stats.Newest = p.GetLatestVersion()
+	stats.Missing = len(stats.Newest.Missing)
+
+	stored := make(map[string]bool)
+	store := func(dockerPath string, size int64) {
+		if dockerPath != "" && !stored[dockerPath] {
+			stored[dockerPath] = true
+			stats.StoredSize += size
+		}
+	}
+	for _, v := range p.Versions {
+		stats.LogicalSize += v.Size
+		stats.StoredSize += v.Size
+		for _, a := range v.Assets {
+			stats.LogicalSize += a.Size
+			if a.Purged {
+				continue
+			}
+			if !a.ProxyOnly {
+				store(a.DockerPath, a.Size)
+			}
+			if a.Proxy != nil {
+				store(a.Proxy.DockerPath, a.Proxy.Size)
+			}
+		}
+	}
+	stats.StoredObjects = len(stored)
+	return stats
+}
+
+// CommitStorage is what one version added to storage
+type CommitStorage struct {
+	LogicalSize int64 // Project file plus the version's assets, as if stored in full
+	StoredSize  int64 // Project file plus objects no earlier version had stored
+	ReusedSize  int64 // Assets whose objects were already stored, by earlier versions or the same one
+}
+
+// CommitStorage computes what committing a version added to storage, counted like
+// Stats: an asset is reused when its object was already stored in the pool
+func (p *Project) CommitStorage(number int) (CommitStorage, error) {
+	v, err := p.FindVersion(number)
+	if err != nil {
+		return CommitStorage{}, err
+	}
+
+	stored := make(map[string]bool)
+	for _, earlier := range p.Versions[:number] {
+		for _, a := range earlier.Assets {
+			if a.Purged {
+				continue
+			}
+			if !a.ProxyOnly {
+				stored[a.DockerPath] = true
+			}
+			if a.Proxy != nil {
+				stored[a.Proxy.DockerPath] = true
+			}
+		}
+	}
+
+	cs := CommitStorage{LogicalSize: v.Size, StoredSize: v.Size}
+	store := func(dockerPath string, size int64) {
+		if dockerPath == "" {
+			return
+		}
+		if stored[dockerPath] {
+			cs.ReusedSize += size
+			return
+		}
+		stored[dockerPath] = true
+		cs.StoredSize += size
+	}
+	for _, a := range v.Assets {
+		cs.LogicalSize += a.Size
+		if a.Purged {
+			continue
+		}
+		if !a.ProxyOnly {
+			store(a.DockerPath, a.Size)
+		}
+		if a.Proxy != nil {
+			store(a.Proxy.DockerPath, a.Proxy.Size)
+		}
+	}
+	return cs, nil
+}
@@ -0,0 +1,48 @@
+package project
+
+import (
+	"fmt"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// WorkingStatus compares a project file on disk, and the assets it references, with
+// the latest version
+type WorkingStatus struct {
+	Version     *Version // The latest version
+	File        string
+	FileChanged bool           // The project file differs from the one committed
+	Assets      *PendingCommit // Asset changes since the version
+}
+
+// Clean reports whether the file and its assets are as committed. Missing assets
+// don't count: they can't be committed either way.
+func (s *WorkingStatus) Clean() bool {
+	return !s.FileChanged && s.Assets.Count("new") == 0 && s.Assets.Count("modified") == 0 && len(s.Assets.Removed) == 0
+}
+
+// Status compares aepxFilePath with the latest version without touching storage:
+// the project file by size and hash, and its assets by size and hash against the
+// checksums recorded when the version was committed
+func (p *Project) Status(aepxFilePath string) (*WorkingStatus, error) {
+	latest := p.GetLatestVersion()
+	if latest == nil {
+		return nil, fmt.Errorf("no versions committed yet")
+	}
+	pending, err := p.pendingChanges(aepxFilePath, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &WorkingStatus{Version: latest, File: aepxFilePath, Assets: pending}
+	if pending.ProjectFileSize != latest.Size {
+		status.FileChanged = true
+	} else if latest.FileHash != "" {
+		hash, _, err := storage.LoadWorkingHashCache().Hash(aepxFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash project file: %w", err)
+		}
+		status.FileChanged = hash != latest.FileHash
+	}
+	return status, nil
+}
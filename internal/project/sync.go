@@ -0,0 +1,369 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// Ways to settle a divergence between this config's history and storage's
+const (
+	ResolveRenumber   = "renumber"   // Keep storage's versions where they are; this copy's follow them
+	ResolveInterleave = "interleave" // Order both copies' versions by commit time
+	ResolveAbort      = "abort"      // Change nothing; the commit or push is refused
+)
+
+// Divergence describes how the history published to storage by another copy of the
+// project has moved on from this config's
+type Divergence struct {
+	Base   int       // Versions both histories start with
+	Local  []Version // Versions after Base that only this config has
+	Remote []Version // Versions after Base that only storage's history has
+	From   string    // Host and directory of the copy that published storage's history
+
+	published *Project
+}
+
+// CheckDivergence compares the config with the history published to storage, and
+// returns nil when storage has nothing this config doesn't know. Versions this config
+// has under another number, e.g. after another copy interleaved them, count as known.
+func (p *Project) CheckDivergence() (*Divergence, error) {
+	store, err := p.store()
+	if err != nil {
+		return nil, err
+	}
+	published, err := readPublished(store, p.StorageDir())
+	if err != nil || published == nil {
+		return nil, err
+	}
+
+	base := 0
+	for base < len(published.Versions) && base < len(p.Versions) &&
+		sameVersion(p.Versions[base], published.Versions[base].Timestamp, published.Versions[base].FileHash) {
+		base++
+	}
+	if base == len(published.Versions) {
+		return nil, nil
+	}
+
+	d := &Divergence{Base: base, Remote: published.Versions[base:], From: published.PublishedFrom, published: published}
+	for _, v := range p.Versions[base:] {
+		if !hasVersion(d.Remote, v) {
+			d.Local = append(d.Local, v)
+		}
+	}
+	return d, nil
+}
+
+// hasVersion reports whether versions includes v, under any number
+func hasVersion(versions []Version, v Version) bool {
+	for _, other := range versions {
+		if sameVersion(other, v.Timestamp, v.FileHash) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveDivergence adopts storage's history and fits this config's own versions into
+// it: after storage's with ResolveRenumber, or ordered by commit time with
+// ResolveInterleave. Version folders in storage are moved to match, their tracking and
+// manifests rewritten, and the merged history is published, so other copies see it on
+// their next commit. Fails without changing anything when a version's folder was
+// overwritten by the other copy and its project file can't be found locally.
+func (p *Project) ResolveDivergence(d *Divergence, strategy string) error {
+	if strategy != ResolveRenumber && strategy != ResolveInterleave {
+		return fmt.Errorf("unknown resolution '%s'; use %s or %s", strategy, ResolveRenumber, ResolveInterleave)
+	}
+	if err := p.CheckWritable(); err != nil {
+		return err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	store, err := p.readyStore()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range d.Remote {
+		problem, err := folderProblem(store, v)
+		if err != nil {
+			return err
+		}
+		if problem != "" {
+			return errs.New(errs.Conflict, "v%03d from %s can't be kept: %s; keep one copy's .vervids folder and run 'vervids fetch %s --metadata' in the other",
+				v.Number, d.From, problem, p.projectID())
+		}
+	}
+	for _, v := range d.Local {
+		problem, err := folderProblem(store, v)
+		if err != nil {
+			return err
+		}
+		if problem != "" && localProjectFile(v) == "" {
+			return errs.New(errs.Conflict, "v%03d here was overwritten in storage by the other copy and %s no longer has the content committed; keep one copy's .vervids folder and run 'vervids fetch %s --metadata' in the other",
+				v.Number, v.FilePath, p.projectID())
+		}
+	}
+
+	merged := append(append([]Version{}, d.Remote...), d.Local...)
+	if strategy == ResolveInterleave {
+		sort.SliceStable(merged, func(i, j int) bool { return merged[i].Timestamp.Before(merged[j].Timestamp) })
+	}
+	before := p.Versions
+	p.AdoptMetadata(d.published)
+	p.Versions = append(append([]Version{}, before[:d.Base]...), merged...)
+
+	return p.renumberFrom(store, d.Base, before, localVersionSet(d.Local))
+}
+
+// InterleaveSince orders the versions from position base on by commit time, e.g. once
+// older staged commits have been pushed after a divergence resolved by interleaving
+func (p *Project) InterleaveSince(base int) error {
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if base >= len(p.Versions) {
+		return nil
+	}
+	store, err := p.readyStore()
+	if err != nil {
+		return err
+	}
+	before := append([]Version{}, p.Versions...)
+	tail := p.Versions[base:]
+	sort.SliceStable(tail, func(i, j int) bool { return tail[i].Timestamp.Before(tail[j].Timestamp) })
+	return p.renumberFrom(store, base, before, nil)
+}
+
+// localVersionSet keys the versions whose folder may be replaced by the file they were
+// committed from, when the other copy overwrote it
+func localVersionSet(versions []Version) map[string]bool {
+	set := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		set[versionKey(v)] = true
+	}
+	return set
+}
+
+// versionKey identifies a version across renumbering
+func versionKey(v Version) string {
+	return v.Timestamp.UTC().String() + "\x00" + v.FileHash
+}
+
+// renumberFrom gives the versions from position base on the numbers of their
+// positions, moving their folders in storage. Folders are downloaded before any is
+// replaced, since versions can swap places. before is the history as it was, for
+// remapping restore records and spotting versions whose predecessor changed; local
+// marks versions that can be re-uploaded from their committed file.
+func (p *Project) renumberFrom(store storage.Storage, base int, before []Version, local map[string]bool) error {
+	oldNumber := make(map[string]int, len(before))
+	previous := make(map[string]string, len(before))
+	for i, v := range before {
+		oldNumber[versionKey(v)] = v.Number
+		if i > 0 {
+			previous[versionKey(v)] = versionKey(before[i-1])
+		}
+	}
+
+	tmp, err := storage.MkdirTemp("renumber-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// Fetch every folder that moves, or that the other copy overwrote
+	moving := map[int]string{}
+	for i := base; i < len(p.Versions); i++ {
+		v := &p.Versions[i]
+		problem, err := folderProblem(store, *v)
+		if err != nil {
+			return fmt.Errorf("failed to check v%03d: %w", v.Number, err)
+		}
+		held := problem == ""
+		if v.Number == i && held {
+			continue
+		}
+		localDir := filepath.Join(tmp, fmt.Sprintf("%d", i))
+		if held {
+			if err := downloadFolder(store, filepath.Dir(v.DockerPath), localDir); err != nil {
+				return fmt.Errorf("failed to fetch v%03d: %w", v.Number, err)
+			}
+		} else if file := localProjectFile(*v); file != "" && local[versionKey(*v)] {
+			if err := storage.CopyFile(file, filepath.Join(localDir, filepath.Base(v.DockerPath))); err != nil {
+				return err
+			}
+		} else {
+			return errs.New(errs.Conflict, "v%03d's folder in storage no longer holds it", v.Number)
+		}
+		moving[i] = localDir
+	}
+
+	if len(moving) > 0 {
+		err := ui.RunStep(fmt.Sprintf("Renumbering %d version(s)", len(moving)), func() error {
+			return p.storeMoved(store, moving)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// A signature can only be renewed with the key that made it
+	key, _ := signingKey()
+
+	// Versions that moved or follow another version now need their signature,
+	// tracking and manifest rewritten, and their layer changes no longer apply
+	for i := base; i < len(p.Versions); i++ {
+		v := &p.Versions[i]
+		k := versionKey(*v)
+		_, moved := moving[i]
+		newPrevious := ""
+		if i > 0 {
+			newPrevious = versionKey(p.Versions[i-1])
+		}
+		if !moved && previous[k] == newPrevious {
+			continue
+		}
+		if previous[k] != newPrevious {
+			v.LayerChanges = nil
+		}
+		if moved && v.Signature != nil {
+			if key != nil && key.PublicKey == v.Signature.PublicKey {
+				sig, err := key.Sign(v.Manifest(p.ProjectName), v.Signature.Signer)
+				if err != nil {
+					fmt.Println(ui.Warning(fmt.Sprintf("Failed to sign v%03d again, so its signature was removed: %v", i, err)))
+				}
+				v.Signature = sig
+			} else {
+				fmt.Println(ui.Warning(fmt.Sprintf("v%03d's signature by %s covered its old number and was removed", i, v.Signature.Signer)))
+				v.Signature = nil
+			}
+		}
+		if err := p.RegenerateTracking(i); err != nil {
+			fmt.Println(ui.Warning(fmt.Sprintf("Failed to save asset tracking for v%03d: %v", i, err)))
+		}
+		if moved {
+			if err := p.writeVersionManifest(v); err != nil {
+				fmt.Println(ui.Warning(fmt.Sprintf("Failed to save version manifest for v%03d: %v", i, err)))
+			}
+		}
+	}
+
+	// Restore records follow the versions they name
+	newNumber := make(map[int]int, len(p.Versions))
+	for _, v := range p.Versions {
+		if old, ok := oldNumber[versionKey(v)]; ok {
+			newNumber[old] = v.Number
+		}
+	}
+	for i := range p.Restores {
+		if n, ok := newNumber[p.Restores[i].Version]; ok {
+			p.Restores[i].Version = n
+		}
+	}
+
+	if err := p.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	p.publishMetadata()
+	return nil
+}
+
+// storeMoved replaces the folder of each version in moving, keyed by position, with
+// the files fetched for it, and updates the version's number and path to match
+func (p *Project) storeMoved(store storage.Storage, moving map[int]string) error {
+	projectDir := filepath.Join(docker.StoragePath, p.projectID())
+	for i := range p.Versions {
+		localDir, ok := moving[i]
+		if !ok {
+			continue
+		}
+		v := &p.Versions[i]
+		dir := filepath.Join(projectDir, fmt.Sprintf("v%03d", i))
+		if err := store.Remove(dir); err != nil {
+			if exists, checkErr := store.Exists(dir); checkErr != nil || exists {
+				return fmt.Errorf("failed to clear %s: %w", dir, err)
+			}
+		}
+		if err := store.PutDir(localDir, dir); err != nil {
+			return fmt.Errorf("failed to store v%03d as v%03d: %w", v.Number, i, err)
+		}
+		v.Number = i
+		v.DockerPath = filepath.Join(dir, filepath.Base(v.DockerPath))
+	}
+	return nil
+}
+
+// folderProblem returns why the version's folder no longer holds it, e.g. the other
+// copy committed the same number over it, or "" when it does. Folders without a
+// manifest, from before manifests were written, are taken as they are. An error means
+// storage couldn't be read, which says nothing about the folder.
+func folderProblem(store storage.Storage, v Version) (string, error) {
+	if v.DockerPath == "" {
+		return "it has no stored project file", nil
+	}
+	m, err := ReadVersionManifest(store, filepath.Dir(v.DockerPath))
+	if err != nil {
+		return "", err
+	}
+	if m == nil {
+		exists, err := store.Exists(v.DockerPath)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "its project file is gone", nil
+		}
+		return "", nil
+	}
+	if !sameVersion(m.Version, v.Timestamp, v.FileHash) {
+		return fmt.Sprintf("its folder now holds \"%s\" from %s", m.Version.Message, m.Version.Timestamp.Format("2006-01-02 15:04")), nil
+	}
+	return "", nil
+}
+
+// localProjectFile returns the file the version was committed from if it still has
+// the committed content, or "" if not
+func localProjectFile(v Version) string {
+	if v.FilePath == "" || v.FileHash == "" {
+		return ""
+	}
+	if hash, err := storage.HashFile(v.FilePath); err != nil || hash != v.FileHash {
+		return ""
+	}
+	return v.FilePath
+}
+
+// downloadFolder copies every file under dir in store into localDir
+func downloadFolder(store storage.Storage, dir string, localDir string) error {
+	files, err := store.List(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			rel = filepath.Base(file)
+		}
+		target := filepath.Join(localDir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := store.GetFile(file, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
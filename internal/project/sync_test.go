@@ -0,0 +1,253 @@
+package project
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// syncStart is when the first test version was committed
+var syncStart = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+// storeVersion commits a version named name at number as a commit leaves it in store:
+// the project file and its manifest in the version folder. The project file's content
+// is "project <name>". With local, the file it was committed from is kept in the
+// current directory, as on the machine that committed it.
+func storeVersion(t *testing.T, p *Project, store *memStore, name string, number int, hour int, local bool) Version {
+	t.Helper()
+	content := []byte("project " + name)
+	file, err := filepath.Abs("promo-" + name + ".aepx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := storage.HashFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !local {
+		os.Remove(file)
+	}
+	v := Version{
+		Number:     number,
+		Message:    name,
+		Timestamp:  syncStart.Add(time.Duration(hour) * time.Hour),
+		FilePath:   file,
+		DockerPath: filepath.Join(p.StorageDir(), fmt.Sprintf("v%03d", number), "promo.aepx"),
+		Assets:     []AssetInfo{},
+		FileHash:   hash,
+	}
+	if err := store.Put(bytes.NewReader(content), int64(len(content)), v.DockerPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.writeVersionManifest(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// checkStored fails unless the versions are numbered by position, are the ones named,
+// and each version's folder in storage holds its project file and manifest
+func checkStored(t *testing.T, p *Project, store *memStore, names ...string) {
+	t.Helper()
+	if len(p.Versions) != len(names) {
+		t.Fatalf("%d versions, want %d (%v)", len(p.Versions), len(names), names)
+	}
+	for i, v := range p.Versions {
+		if v.Number != i || v.Message != names[i] {
+			t.Errorf("position %d holds v%03d %q, want v%03d %q", i, v.Number, v.Message, i, names[i])
+			continue
+		}
+		if got := store.read(t, v.DockerPath); got != "project "+names[i] {
+			t.Errorf("v%03d's project file holds %q, want %q", i, got, "project "+names[i])
+		}
+		m, err := ReadVersionManifest(store, filepath.Dir(v.DockerPath))
+		if err != nil || m == nil || m.Version.Number != i || m.Version.FileHash != v.FileHash {
+			t.Errorf("v%03d's manifest = %+v, %v; want one describing %q", i, m, err, names[i])
+		}
+	}
+}
+
+// diverged sets up a history A, B shared by two copies of the project, after which
+// this copy committed C as v002 and the other copy then committed X over it and Y
+// after it, publishing A, B, X, Y
+func diverged(t *testing.T) (*Project, *memStore) {
+	t.Helper()
+	inProjectDir(t)
+	store := newMemStore()
+	p := &Project{ProjectName: "promo.aepx", ProjectPath: "/work/promo.aepx", backend: store}
+	a := storeVersion(t, p, store, "A", 0, 0, false)
+	b := storeVersion(t, p, store, "B", 1, 1, false)
+	c := storeVersion(t, p, store, "C", 2, 2, true)
+	x := storeVersion(t, p, store, "X", 2, 3, false)
+	y := storeVersion(t, p, store, "Y", 3, 4, false)
+	p.Versions = []Version{a, b, c}
+	publish(t, store, p, "render-node:/work", []Version{a, b, x, y})
+	return p, store
+}
+
+func TestCheckDivergence(t *testing.T) {
+	p, _ := diverged(t)
+	d, err := p.CheckDivergence()
+	if err != nil || d == nil {
+		t.Fatalf("CheckDivergence() = %v, %v; want a divergence", d, err)
+	}
+	if d.Base != 2 || len(d.Local) != 1 || d.Local[0].Message != "C" || len(d.Remote) != 2 || d.From != "render-node:/work" {
+		t.Errorf("divergence = base %d, local %v, remote %v, from %q", d.Base, d.Local, d.Remote, d.From)
+	}
+}
+
+func TestResolveDivergence(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		setup    func(t *testing.T, p *Project, store *memStore)
+		want     []string // Versions in order after resolving; nil when it must fail
+		wantKind errs.Kind
+	}{
+		{name: "renumber", strategy: ResolveRenumber, want: []string{"A", "B", "X", "Y", "C"}},
+		{name: "interleave", strategy: ResolveInterleave, want: []string{"A", "B", "C", "X", "Y"}},
+		{
+			name:     "local file changed since",
+			strategy: ResolveRenumber,
+			setup: func(t *testing.T, p *Project, store *memStore) {
+				os.WriteFile(p.Versions[2].FilePath, []byte("edited"), 0644)
+			},
+			wantKind: errs.Conflict,
+		},
+		{
+			name:     "other copy's folder overwritten",
+			strategy: ResolveRenumber,
+			setup: func(t *testing.T, p *Project, store *memStore) {
+				storeVersion(t, p, store, "Z", 3, 5, false)
+			},
+			wantKind: errs.Conflict,
+		},
+		{
+			name:     "storage failing",
+			strategy: ResolveRenumber,
+			setup: func(t *testing.T, p *Project, store *memStore) {
+				store.err = errors.New("connection reset")
+			},
+			wantKind: errs.Unknown,
+		},
+		{name: "unknown strategy", strategy: "merge", wantKind: errs.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, store := diverged(t)
+			d, err := p.CheckDivergence()
+			if err != nil || d == nil {
+				t.Fatalf("CheckDivergence() = %v, %v", d, err)
+			}
+			if tt.setup != nil {
+				tt.setup(t, p, store)
+			}
+
+			err = p.ResolveDivergence(d, tt.strategy)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatal("ResolveDivergence() succeeded, want an error")
+				}
+				if errs.KindOf(err) != tt.wantKind {
+					t.Errorf("error kind = %v, want %v (%v)", errs.KindOf(err), tt.wantKind, err)
+				}
+				if len(p.Versions) != 3 || p.Versions[2].Message != "C" {
+					t.Errorf("a failed resolution changed the history to %v", p.Versions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveDivergence() error = %v", err)
+			}
+			store.err = nil
+			checkStored(t, p, store, tt.want...)
+
+			// The merged history is published, and saved here
+			published, err := readPublished(store, p.StorageDir())
+			if err != nil || published == nil || len(published.Versions) != len(tt.want) {
+				t.Errorf("published history = %v, %v; want %d versions", published, err, len(tt.want))
+			}
+			saved, err := Load()
+			if err != nil || len(saved.Versions) != len(tt.want) {
+				t.Errorf("saved config = %v, %v; want %d versions", saved, err, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestRenumberFrom(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    []int  // Positions in A, B, C, D to move to positions 0, 1, ...
+		base     int    // First position renumbered
+		restore  int    // Version a restore record names
+		broken   string // Version whose folder is overwritten before renumbering
+		storeErr bool
+		want     []string // nil when renumbering must fail
+		wantKind errs.Kind
+		wantRest int // Version the restore record names afterwards
+	}{
+		{name: "nothing moves", order: []int{0, 1, 2, 3}, restore: 2, want: []string{"A", "B", "C", "D"}, wantRest: 2},
+		{name: "swap", order: []int{0, 2, 1, 3}, base: 1, restore: 1, want: []string{"A", "C", "B", "D"}, wantRest: 2},
+		{name: "rotate", order: []int{0, 3, 1, 2}, base: 1, restore: 3, want: []string{"A", "D", "B", "C"}, wantRest: 1},
+		{name: "before base untouched", order: []int{0, 1, 3, 2}, base: 2, restore: 0, want: []string{"A", "B", "D", "C"}, wantRest: 0},
+		{name: "overwritten, no local file", order: []int{0, 2, 1, 3}, base: 1, broken: "B", wantKind: errs.Conflict},
+		{name: "storage failing", order: []int{0, 2, 1, 3}, base: 1, storeErr: true, wantKind: errs.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inProjectDir(t)
+			store := newMemStore()
+			p := &Project{ProjectName: "promo.aepx", ProjectPath: "/work/promo.aepx", backend: store}
+			var committed []Version
+			for i, name := range []string{"A", "B", "C", "D"} {
+				committed = append(committed, storeVersion(t, p, store, name, i, i, false))
+			}
+			if tt.broken != "" {
+				// Another copy's version now fills the folder
+				storeVersion(t, p, store, "E", int(tt.broken[0]-'A'), 9, false)
+			}
+			p.Restores = []RestoreRecord{{Version: tt.restore}}
+			for _, i := range tt.order {
+				p.Versions = append(p.Versions, committed[i])
+			}
+			if tt.storeErr {
+				store.err = errors.New("access denied")
+			}
+
+			err := p.renumberFrom(store, tt.base, committed, nil)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatal("renumberFrom() succeeded, want an error")
+				}
+				if errs.KindOf(err) != tt.wantKind {
+					t.Errorf("error kind = %v, want %v (%v)", errs.KindOf(err), tt.wantKind, err)
+				}
+				store.err = nil
+				for i, v := range committed {
+					if got := store.read(t, v.DockerPath); tt.broken == "" && got != "project "+v.Message {
+						t.Errorf("v%03d changed to %q after a failed renumbering", i, got)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renumberFrom() error = %v", err)
+			}
+			checkStored(t, p, store, tt.want...)
+			if got := p.Restores[0].Version; got != tt.wantRest {
+				t.Errorf("restore record names v%03d, want v%03d", got, tt.wantRest)
+			}
+		})
+	}
+}
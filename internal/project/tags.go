@@ -0,0 +1,115 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+)
+
+// Tag is a unique name standing for one version, e.g. "client-review-1"
+type Tag struct {
+	Name    string
+	Version int
+}
+
+// FindTag returns the version carrying the tag (case-insensitive), or nil
+func (p *Project) FindTag(name string) *Version {
+	for i := range p.Versions {
+		for _, t := range p.Versions[i].Tags {
+			if strings.EqualFold(t, name) {
+				return &p.Versions[i]
+			}
+		}
+	}
+	return nil
+}
+
+// SetTag puts a tag on a version. A tag is on one version at a time: one already on
+// another version is moved only with force. Returns the number of the version the
+// tag was moved from, or -1.
+func (p *Project) SetTag(name string, number int, force bool) (int, error) {
+	name = strings.TrimSpace(name)
+	if err := validateTagName(name); err != nil {
+		return -1, err
+	}
+	v, err := p.FindVersion(number)
+	if err != nil {
+		return -1, err
+	}
+
+	from := -1
+	if tagged := p.FindTag(name); tagged != nil {
+		if tagged.Number == number {
+			return -1, nil
+		}
+		if !force {
+			return -1, errs.New(errs.Conflict, "tag '%s' is already on version %d; use --force to move it", name, tagged.Number)
+		}
+		from = tagged.Number
+		tagged.Tags = withoutTag(tagged.Tags, name)
+	}
+	v.Tags = append(v.Tags, name)
+	return from, nil
+}
+
+// RemoveTag deletes a tag and returns the number of the version it was on
+func (p *Project) RemoveTag(name string) (int, error) {
+	v := p.FindTag(name)
+	if v == nil {
+		return -1, fmt.Errorf("no tag named '%s'", name)
+	}
+	v.Tags = withoutTag(v.Tags, name)
+	return v.Number, nil
+}
+
+// SortedTags returns every tag of the project with its version, sorted by name
+func (p *Project) SortedTags() []Tag {
+	tags := []Tag{}
+	for _, v := range p.Versions {
+		for _, t := range v.Tags {
+			tags = append(tags, Tag{Name: t, Version: v.Number})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i].Name) < strings.ToLower(tags[j].Name) })
+	return tags
+}
+
+// ResolveVersion turns a version argument, a number or a tag, into a version number
+func (p *Project) ResolveVersion(arg string) (int, error) {
+	if number, err := strconv.Atoi(arg); err == nil {
+		return number, nil
+	}
+	if v := p.FindTag(arg); v != nil {
+		return v.Number, nil
+	}
+	return -1, errs.New(errs.VersionNotFound, "'%s' is neither a version number nor a tag; see 'vervids tag list'", arg)
+}
+
+// validateTagName rejects names that couldn't be told apart from a version number or
+// typed as one argument
+func validateTagName(name string) error {
+	if name == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return fmt.Errorf("tag name '%s' would be read as a version number", name)
+	}
+	if strings.ContainsAny(name, " \t\r\n") {
+		return fmt.Errorf("tag name '%s' cannot contain spaces", name)
+	}
+	return nil
+}
+
+// withoutTag returns tags without name (case-insensitive), or nil when none are left
+func withoutTag(tags []string, name string) []string {
+	var kept []string
+	for _, t := range tags {
+		if !strings.EqualFold(t, name) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
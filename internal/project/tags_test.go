@@ -0,0 +1,81 @@
+package project
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+)
+
+func TestResolveTagAfterPrune(t *testing.T) {
+	p, _ := prunedProject(t) // Versions 0, 2 and 3
+	if _, err := p.SetTag("client-review", 2, false); err != nil {
+		t.Fatalf("SetTag() error = %v", err)
+	}
+	if _, err := p.SetTag("final", 3, false); err != nil {
+		t.Fatalf("SetTag() error = %v", err)
+	}
+
+	tests := []struct {
+		arg      string
+		want     int
+		wantFile string // Project file the resolved version opens
+		wantKind errs.Kind
+	}{
+		{arg: "client-review", want: 2, wantFile: "a\nB\nc\nd\n"},
+		{arg: "final", want: 3, wantFile: "a\nB\nc\nD\n"},
+		{arg: "FINAL", want: 3, wantFile: "a\nB\nc\nD\n"},
+		{arg: "0", want: 0, wantFile: "a\nb\nc\nd\n"},
+		{arg: "1", want: 1, wantKind: errs.VersionNotFound},
+		{arg: "rough-cut", want: -1, wantKind: errs.VersionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			number, err := p.ResolveVersion(tt.arg)
+			if err != nil {
+				if tt.want != -1 || errs.KindOf(err) != tt.wantKind {
+					t.Fatalf("ResolveVersion(%q) error = %v", tt.arg, err)
+				}
+				return
+			}
+			if number != tt.want {
+				t.Fatalf("ResolveVersion(%q) = %d, want %d", tt.arg, number, tt.want)
+			}
+
+			v, err := p.FindVersion(number)
+			if tt.wantKind != errs.Unknown {
+				if errs.KindOf(err) != tt.wantKind {
+					t.Errorf("FindVersion(%d) error = %v, want %v", number, err, tt.wantKind)
+				}
+				return
+			}
+			if err != nil || v.Number != number {
+				t.Fatalf("FindVersion(%d) = %v, %v", number, v, err)
+			}
+			path, cleanup, err := p.FetchVersionFile(number)
+			if err != nil {
+				t.Fatalf("FetchVersionFile(%d) error = %v", number, err)
+			}
+			defer cleanup()
+			if data, _ := os.ReadFile(path); string(data) != tt.wantFile {
+				t.Errorf("%q opens %q, want %q", tt.arg, data, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestSetTagOnPrunedVersion(t *testing.T) {
+	p, _ := prunedProject(t)
+	if _, err := p.SetTag("gone", 1, false); errs.KindOf(err) != errs.VersionNotFound {
+		t.Errorf("SetTag() on a pruned version error = %v, want version_not_found", err)
+	}
+	if _, err := p.AddLabel("approved", "", []int{3}); err != nil {
+		t.Fatalf("AddLabel() error = %v", err)
+	}
+	if v, _ := p.FindVersion(3); !v.HasLabel("approved") {
+		t.Error("AddLabel() labelled the wrong version")
+	}
+	if err := p.RemoveVersion(1); errs.KindOf(err) != errs.VersionNotFound {
+		t.Errorf("RemoveVersion() of a pruned version error = %v, want version_not_found", err)
+	}
+}
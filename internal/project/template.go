@@ -0,0 +1,137 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateFile describes a project template inside its directory
+const TemplateFile = "template.yaml"
+
+// Template seeds a new project with studio conventions. A template is a directory
+// holding template.yaml, and optionally files/, copied into the project directory,
+// and hooks/, copied into .vervids/hooks.
+type Template struct {
+	Name        string            `yaml:"-"`
+	Dir         string            `yaml:"-"`
+	Description string            `yaml:"description,omitempty"`
+	Folders     []string          `yaml:"folders,omitempty"`  // Created in the project directory, e.g. Footage/Raw
+	Settings    map[string]string `yaml:"settings,omitempty"` // Project settings as for 'vervids config set'; lists comma-separated
+	Notes       string            `yaml:"notes,omitempty"`    // Starting project notes
+}
+
+// LoadTemplate reads the template called name from templatesDir, checking its
+// settings and folders before anything is created
+func LoadTemplate(templatesDir string, name string) (*Template, error) {
+	dir := filepath.Join(templatesDir, name)
+	data, err := os.ReadFile(filepath.Join(dir, TemplateFile))
+	if os.IsNotExist(err) {
+		available := ListTemplates(templatesDir)
+		if len(available) == 0 {
+			return nil, fmt.Errorf("template '%s' not found: no templates in %s", name, templatesDir)
+		}
+		return nil, fmt.Errorf("template '%s' not found in %s (available: %s)", name, templatesDir, strings.Join(available, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	t := &Template{Name: name, Dir: dir}
+	if err := yaml.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, TemplateFile), err)
+	}
+	check := &Project{}
+	for key, value := range t.Settings {
+		if err := check.SetConfig(key, []string{value}); err != nil {
+			return nil, fmt.Errorf("template '%s': %w", name, err)
+		}
+	}
+	for _, folder := range t.Folders {
+		if filepath.IsAbs(folder) || strings.HasPrefix(filepath.Clean(folder), "..") {
+			return nil, fmt.Errorf("template '%s': folder '%s' must be inside the project directory", name, folder)
+		}
+	}
+	return t, nil
+}
+
+// ListTemplates returns the names of the templates in templatesDir, sorted
+func ListTemplates(templatesDir string) []string {
+	entries, err := os.ReadDir(templatesDir)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(templatesDir, e.Name(), TemplateFile)); e.IsDir() && err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply gives p the template's settings and notes
+func (t *Template) apply(p *Project) error {
+	for key, value := range t.Settings {
+		if err := p.SetConfig(key, []string{value}); err != nil {
+			return err
+		}
+	}
+	if t.Notes != "" {
+		p.Notes = t.Notes
+	}
+	return nil
+}
+
+// seed creates the template's folders in dir and copies its files and hooks. Files
+// already in dir are left alone.
+func (t *Template) seed(dir string) error {
+	for _, folder := range t.Folders {
+		if err := os.MkdirAll(filepath.Join(dir, folder), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", folder, err)
+		}
+	}
+	if err := copyTemplateTree(filepath.Join(t.Dir, "files"), dir); err != nil {
+		return err
+	}
+	return copyTemplateTree(filepath.Join(t.Dir, HooksDir), filepath.Join(dir, storage.VerVidsDir, HooksDir))
+}
+
+// copyTemplateTree copies the files under src to dst, keeping their permissions so
+// hook scripts stay executable. A missing src is fine; existing files aren't replaced.
+func copyTemplateTree(src string, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if _, err := os.Stat(target); err == nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err := storage.CopyFile(path, target); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", rel, err)
+		}
+		return os.Chmod(target, info.Mode().Perm())
+	})
+}
@@ -0,0 +1,194 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ObjectUsage is one stored object and the versions that reference it
+type ObjectUsage struct {
+	DockerPath   string
+	Filename     string
+	OriginalPath string
+	Size         int64
+	Versions     []int // Oldest first
+	Proxy        bool  // A low-res proxy rather than the asset itself
+	Chunked      bool  // A chunk manifest; the size is the whole asset's
+	InLatest     bool  // Referenced by the newest version
+	Pinned       bool
+}
+
+// Purgeable reports whether the object can be removed from storage: only old copies
+// can go, never what the newest version or a pin uses. Chunk data is shared between
+// assets, so chunked assets are left alone.
+func (u *ObjectUsage) Purgeable() bool {
+	return !u.InLatest && !u.Pinned && !u.Chunked
+}
+
+// ObjectUsage lists every stored object the project's versions reference, largest
+// first, from config.json alone
+func (p *Project) ObjectUsage() []ObjectUsage {
+	index := make(map[string]*ObjectUsage)
+	order := []string{}
+	latest := len(p.Versions) - 1
+	add := func(v *Version, a *AssetInfo, dockerPath string, size int64, isProxy bool) {
+		if dockerPath == "" {
+			return
+		}
+		u, ok := index[dockerPath]
+		if !ok {
+			u = &ObjectUsage{DockerPath: dockerPath, Filename: a.Filename, OriginalPath: a.OriginalPath, Size: size, Proxy: isProxy, Chunked: a.Chunked && !isProxy}
+			index[dockerPath] = u
+			order = append(order, dockerPath)
+		}
+		if len(u.Versions) == 0 || u.Versions[len(u.Versions)-1] != v.Number {
+			u.Versions = append(u.Versions, v.Number)
+		}
+		if v.Number == p.Versions[latest].Number {
+			u.InLatest = true
+		}
+	}
+	for i := range p.Versions {
+		v := &p.Versions[i]
+		for j := range v.Assets {
+			a := &v.Assets[j]
+			if a.Purged {
+				continue
+			}
+			if !a.ProxyOnly {
+				add(v, a, a.DockerPath, a.Size, false)
+			}
+			if a.Proxy != nil {
+				add(v, a, a.Proxy.DockerPath, a.Proxy.Size, true)
+			}
+		}
+	}
+
+	usage := make([]ObjectUsage, 0, len(order))
+	for _, path := range order {
+		u := index[path]
+		u.Pinned = p.IsPinned(path)
+		usage = append(usage, *u)
+	}
+	sort.SliceStable(usage, func(i, j int) bool { return usage[i].Size > usage[j].Size })
+	return usage
+}
+
+// ExcludeAsset adds a file to the project's asset rules so later commits stop
+// tracking it. Returns false if it was already excluded.
+func (p *Project) ExcludeAsset(path string) bool {
+	if !p.AssetRules.Allows(path) {
+		return false
+	}
+	p.AssetRules.ExcludeFiles = append(p.AssetRules.ExcludeFiles, path)
+	return true
+}
+
+// PurgeObject removes an old stored object from storage and marks the assets that used
+// it as purged, so pulling those versions skips them. The newest version's and pinned
+// objects can't be purged. Returns the number of version assets affected.
+func (p *Project) PurgeObject(dockerPath string) (int, error) {
+	if err := p.CheckWritable(); err != nil {
+		return 0, err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	var target *ObjectUsage
+	usage := p.ObjectUsage()
+	for i := range usage {
+		if usage[i].DockerPath == dockerPath {
+			target = &usage[i]
+			break
+		}
+	}
+	if target == nil {
+		return 0, fmt.Errorf("no stored object at %s", dockerPath)
+	}
+	if !target.Purgeable() {
+		return 0, fmt.Errorf("%s is still in use by the newest version, a pin or shared chunks", target.Filename)
+	}
+
+	store, err := p.readyStore()
+	if err != nil {
+		return 0, err
+	}
+	if err := store.Remove(dockerPath); err != nil {
+		return 0, fmt.Errorf("failed to remove %s from %s: %w", dockerPath, store.Name(), err)
+	}
+	affected := 0
+	for i := range p.Versions {
+		for j := range p.Versions[i].Assets {
+			a := &p.Versions[i].Assets[j]
+			switch {
+			case a.DockerPath == dockerPath:
+				a.Purged = true
+			case a.Proxy != nil && a.Proxy.DockerPath == dockerPath:
+				// Only the proxy went; the original is still stored
+				a.Proxy = nil
+			default:
+				continue
+			}
+			affected++
+		}
+	}
+	return affected, nil
+}
+
+// PruneKeepLast purges from storage the objects used only by versions older than the
+// newest keep, like PruneAutosaves does for autosaves. Project files stay, so every
+// version remains in the history; pinned objects are kept. Returns the number of
+// objects purged and the bytes freed.
+func (p *Project) PruneKeepLast(keep int) (int, int64, error) {
+	if err := p.CheckWritable(); err != nil {
+		return 0, 0, err
+	}
+	unlock, err := p.lockWrites()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+	if keep < 1 {
+		return 0, 0, fmt.Errorf("at least one version must be kept")
+	}
+	if len(p.Versions) <= keep {
+		return 0, 0, nil
+	}
+	prunable := make(map[int]bool)
+	for _, v := range p.Versions[:len(p.Versions)-keep] {
+		prunable[v.Number] = true
+	}
+	return p.purgeObjectsOf(prunable)
+}
+
+// purgeObjectsOf purges every purgeable object that only the versions in prunable use
+// and saves the config. Returns the number of objects purged and the bytes freed.
+func (p *Project) purgeObjectsOf(prunable map[int]bool) (int, int64, error) {
+	purged := 0
+	var freed int64
+	for _, u := range p.ObjectUsage() {
+		if !u.Purgeable() {
+			continue
+		}
+		onlyPrunable := true
+		for _, number := range u.Versions {
+			onlyPrunable = onlyPrunable && prunable[number]
+		}
+		if !onlyPrunable {
+			continue
+		}
+		if _, err := p.PurgeObject(u.DockerPath); err != nil {
+			return purged, freed, err
+		}
+		purged++
+		freed += u.Size
+	}
+	if purged > 0 {
+		if err := p.Save(); err != nil {
+			return purged, freed, fmt.Errorf("failed to save config: %w", err)
+		}
+	}
+	return purged, freed, nil
+}
@@ -0,0 +1,93 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// writeLockTimeout bounds how long a write waits for the one before it, e.g. a long
+// upload, to finish
+var writeLockTimeout = 30 * time.Minute
+
+// waitWriteLock takes the project's write lock, the commit lock in the current
+// directory's .vervids, waiting while another process or goroutine holds it
+func waitWriteLock() (func(), error) {
+	return storage.WaitCommitLock(writeLockTimeout, func(pid int) {
+		fmt.Println(ui.Info(fmt.Sprintf("Waiting for another write to this project to finish (pid %d)...", pid)))
+	})
+}
+
+// lockWrites holds the project's write lock until the returned function is called.
+// Every write to the config or storage takes it, whether it comes from the CLI, the
+// API server, watch mode or the autosave scheduler, so writes run one at a time in the
+// order they get the lock, and a write that comes while another runs waits for it.
+// When the config was saved by another write since p was loaded, p is reloaded, so
+// this write starts from it. A write nested in another of the same project goes ahead.
+func (p *Project) lockWrites() (func(), error) {
+	if p.writing {
+		return func() {}, nil
+	}
+	release, err := waitWriteLock()
+	if err != nil {
+		return nil, err
+	}
+	if p.changedOnDisk() {
+		fresh, err := LoadFromPath(p.loadedFrom)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		fresh.backend = p.backend
+		*p = *fresh
+	}
+	p.writing = true
+	return func() {
+		p.writing = false
+		release()
+	}, nil
+}
+
+// changedOnDisk reports whether another write saved the config p was loaded from,
+// when that is the config in the current directory, since p read or saved it
+func (p *Project) changedOnDisk() bool {
+	if p.loadedFrom == "" {
+		return false
+	}
+	if current, err := filepath.Abs(storage.GetConfigPath()); err != nil || current != p.loadedFrom {
+		return false
+	}
+	data, err := os.ReadFile(p.loadedFrom)
+	return err != nil || configHash(data) != p.loadedHash
+}
+
+// checkNotChanged refuses to save over a config another write saved since p was
+// loaded, which would undo that write
+func (p *Project) checkNotChanged() error {
+	if p.changedOnDisk() {
+		return errs.New(errs.Conflict, "another command changed project '%s' while this one ran; run it again", p.ProjectName)
+	}
+	return nil
+}
+
+// loaded records the config p was read from, or saved to, and its content
+func (p *Project) loaded(configPath string, data []byte) {
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	p.loadedFrom = configPath
+	p.loadedHash = configHash(data)
+}
+
+// configHash fingerprints a config's content
+func configHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
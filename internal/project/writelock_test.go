@@ -0,0 +1,252 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+// savedProject saves a generic project stored in a new memStore and returns it as
+// loaded from its config, like a command would
+func savedProject(t *testing.T) (*Project, *memStore) {
+	t.Helper()
+	inProjectDir(t)
+	store := newMemStore()
+	p := &Project{ProjectName: "brief.txt", Generic: true, Versions: []Version{}}
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return loadProject(t, store), store
+}
+
+// loadProject loads the saved project again, as another command would
+func loadProject(t *testing.T, store *memStore) *Project {
+	t.Helper()
+	p, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.backend = store
+	return p
+}
+
+// holdLock takes the write lock as a running process, this one, until the test ends
+// or release is called
+func holdLock(t *testing.T) (release func()) {
+	t.Helper()
+	if err := os.WriteFile(storage.GetCommitLockPath(), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+	release = func() { os.Remove(storage.GetCommitLockPath()) }
+	t.Cleanup(release)
+	return release
+}
+
+func TestConcurrentCommits(t *testing.T) {
+	_, store := savedProject(t)
+	const commits = 6
+
+	// The CLI, the API and watch mode each load the project and commit at once
+	var wg sync.WaitGroup
+	results := make(chan error, commits)
+	for i := 0; i < commits; i++ {
+		file, err := filepath.Abs(fmt.Sprintf("brief-%d.txt", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(fmt.Sprintf("draft %d", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		p := loadProject(t, store)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := p.CommitWithOptions(fmt.Sprintf("draft %d", i), file, CommitOptions{})
+			results <- err
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+	for err := range results {
+		if err != nil {
+			t.Errorf("CommitWithOptions() error = %v", err)
+		}
+	}
+
+	// Every commit is kept, each under its own number with its own file
+	saved := loadProject(t, store)
+	if len(saved.Versions) != commits {
+		t.Fatalf("%d versions saved, want %d", len(saved.Versions), commits)
+	}
+	var messages []string
+	for i, v := range saved.Versions {
+		if v.Number != i {
+			t.Errorf("position %d holds v%03d, want v%03d", i, v.Number, i)
+		}
+		if got := store.read(t, v.DockerPath); got != v.Message {
+			t.Errorf("v%03d holds %q, want %q", v.Number, got, v.Message)
+		}
+		messages = append(messages, v.Message)
+	}
+	sort.Strings(messages)
+	for i, m := range messages {
+		if want := fmt.Sprintf("draft %d", i); m != want {
+			t.Errorf("commits saved %v, want one of each draft", messages)
+			break
+		}
+	}
+}
+
+func TestLockWritesReloads(t *testing.T) {
+	p, store := savedProject(t)
+
+	// Another command adds a label after p was loaded
+	other := loadProject(t, store)
+	if _, err := other.AddLabel("approved", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := p.lockWrites()
+	if err != nil {
+		t.Fatalf("lockWrites() error = %v", err)
+	}
+	defer unlock()
+	if len(p.Labels) != 1 || p.Labels[0].Name != "approved" {
+		t.Errorf("labels after locking = %v, want the other command's", p.Labels)
+	}
+	if p.backend != store {
+		t.Error("reloading dropped the project's storage")
+	}
+	if err := p.Save(); err != nil {
+		t.Errorf("Save() under the lock error = %v", err)
+	}
+}
+
+func TestLockWritesNested(t *testing.T) {
+	p, _ := savedProject(t)
+	unlock, err := p.lockWrites()
+	if err != nil {
+		t.Fatalf("lockWrites() error = %v", err)
+	}
+	inner, err := p.lockWrites()
+	if err != nil {
+		t.Fatalf("nested lockWrites() error = %v", err)
+	}
+	inner()
+	if _, err := os.Stat(storage.GetCommitLockPath()); err != nil {
+		t.Errorf("the nested write released the outer one's lock: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(storage.GetCommitLockPath()); !os.IsNotExist(err) {
+		t.Errorf("lock left behind: %v", err)
+	}
+}
+
+func TestLockWritesTimesOut(t *testing.T) {
+	p, _ := savedProject(t)
+	holdLock(t)
+	timeout := writeLockTimeout
+	writeLockTimeout = 200 * time.Millisecond
+	defer func() { writeLockTimeout = timeout }()
+
+	_, err := p.lockWrites()
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("pid %d", os.Getpid())) {
+		t.Errorf("lockWrites() error = %v, want one naming the writing process", err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, p *Project, store *memStore)
+		wantKind errs.Kind
+		wantErr  bool
+	}{
+		{name: "unchanged since loading"},
+		{
+			name: "saved again by itself",
+			setup: func(t *testing.T, p *Project, store *memStore) {
+				p.Notes = "first pass"
+				if err := p.Save(); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "changed by another command",
+			setup: func(t *testing.T, p *Project, store *memStore) {
+				other := loadProject(t, store)
+				other.Notes = "client notes"
+				if err := other.Save(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr:  true,
+			wantKind: errs.Conflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, store := savedProject(t)
+			if tt.setup != nil {
+				tt.setup(t, p, store)
+			}
+			before, _ := os.ReadFile(storage.GetConfigPath())
+
+			p.QuotaGB = 50
+			err := p.Save()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Save() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if errs.KindOf(err) != tt.wantKind {
+					t.Errorf("error kind = %v, want %v", errs.KindOf(err), tt.wantKind)
+				}
+				// The other command's save stands
+				if after, _ := os.ReadFile(storage.GetConfigPath()); string(after) != string(before) {
+					t.Error("a refused save changed config.json")
+				}
+				return
+			}
+			if saved := loadProject(t, store); saved.QuotaGB != 50 {
+				t.Errorf("saved quota = %d, want 50", saved.QuotaGB)
+			}
+		})
+	}
+}
+
+func TestSaveWaitsForWrites(t *testing.T) {
+	p, _ := savedProject(t)
+	release := holdLock(t)
+	done := make(chan error, 1)
+	go func() {
+		p.Notes = "delivery specs"
+		done <- p.Save()
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("Save() = %v while another write held the lock, want it to wait", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+	release()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Save() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Save() still waiting after the lock was released")
+	}
+}
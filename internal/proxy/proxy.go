@@ -0,0 +1,55 @@
+package proxy
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	DefaultHeight = 540
+	ProxyExt      = ".mp4"
+
+	ModeBoth      = "both"       // Store the original and the proxy
+	ModeProxyOnly = "proxy_only" // Store only the proxy plus the original's hash
+)
+
+// videoExtensions are the footage types ffmpeg can make proxies for
+var videoExtensions = map[string]bool{
+	".mov": true, ".mp4": true, ".m4v": true, ".avi": true, ".mxf": true,
+	".mkv": true, ".webm": true, ".mpg": true, ".mpeg": true, ".wmv": true,
+}
+
+// IsVideo reports whether a file extension is footage that can be proxied
+func IsVideo(ext string) bool {
+	return videoExtensions[strings.ToLower(ext)]
+}
+
+// IsFFmpegInstalled checks if ffmpeg is available on PATH
+func IsFFmpegInstalled() bool {
+	cmd := exec.Command("ffmpeg", "-version")
+	return cmd.Run() == nil
+}
+
+// Generate transcodes srcPath into a low-resolution H.264 proxy at destPath
+func Generate(srcPath, destPath string, height int) error {
+	if height <= 0 {
+		height = DefaultHeight
+	}
+	cmd := exec.Command("ffmpeg", "-y", "-v", "error",
+		"-i", srcPath,
+		"-vf", fmt.Sprintf("scale=-2:%d", height),
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "28",
+		"-c:a", "aac", "-b:a", "128k",
+		destPath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ProxyFilename returns the name used for an asset's proxy file
+func ProxyFilename(filename string, ext string) string {
+	return strings.TrimSuffix(filename, ext) + "_proxy" + ProxyExt
+}
@@ -0,0 +1,659 @@
+// Package s3 stores versions in an S3-compatible bucket (AWS S3, MinIO and the like)
+// through the REST API, signing each request with AWS Signature Version 4
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+const (
+	// DefaultRegion is used when neither the remote settings nor AWS_REGION name one
+	DefaultRegion = "us-east-1"
+
+	// multipartThreshold is the size above which files are uploaded in parts; a single
+	// PUT is limited to 5 GB
+	multipartThreshold = 100 * 1024 * 1024
+
+	// minPartSize is the smallest part uploaded, grown for files that would otherwise
+	// need more than maxParts parts
+	minPartSize = 64 * 1024 * 1024
+	maxParts    = 10000
+
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+
+	// stallTimeout is how long a connection may go without sending or receiving a byte
+	// before its request fails, so a stalled endpoint can't hang a commit or pull. The
+	// length of a transfer isn't limited, as footage can take hours to move.
+	stallTimeout = 2 * time.Minute
+)
+
+// Config says where a bucket is. Credentials come from the standard AWS environment
+// variables, so they are never written to settings.yaml.
+type Config struct {
+	URL      string `json:"url" yaml:"url"`                               // s3://bucket/prefix
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"` // Server URL for S3-compatible storage such as MinIO, e.g. http://nas:9000; empty for AWS
+	Region   string `json:"region,omitempty" yaml:"region,omitempty"`     // Empty uses AWS_REGION, then DefaultRegion
+}
+
+// Bucket is an S3 bucket, or a prefix inside one, used through the storage.Storage
+// interface. Storage paths under root map to keys under the prefix.
+type Bucket struct {
+	bucket    string
+	prefix    string // Key prefix without slashes at either end; empty for the whole bucket
+	root      string
+	endpoint  *url.URL
+	pathStyle bool // Bucket in the path rather than the host name, as MinIO expects
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	client    *http.Client
+}
+
+// ParseURL splits an s3://bucket/prefix URL
+func ParseURL(raw string) (bucket string, prefix string, err error) {
+	rest, ok := strings.CutPrefix(raw, "s3://")
+	if !ok {
+		return "", "", fmt.Errorf("'%s' is not an s3:// URL", raw)
+	}
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("'%s' has no bucket name", raw)
+	}
+	return bucket, strings.Trim(prefix, "/"), nil
+}
+
+// New returns the bucket described by cfg, storing what lives under root
+func New(cfg Config, root string) (*Bucket, error) {
+	bucket, prefix, err := ParseURL(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bucket{
+		bucket:    bucket,
+		prefix:    prefix,
+		root:      strings.TrimSuffix(root, "/"),
+		region:    firstNonEmpty(cfg.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), DefaultRegion),
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		client:    newHTTPClient(),
+	}
+	if cfg.Endpoint != "" {
+		if b.endpoint, err = url.Parse(cfg.Endpoint); err != nil || b.endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid endpoint '%s' (use e.g. http://localhost:9000)", cfg.Endpoint)
+		}
+		b.pathStyle = true
+	} else {
+		b.endpoint = &url.URL{Scheme: "https", Host: fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, b.region)}
+	}
+	return b, nil
+}
+
+// Name describes the backend by its URL
+func (b *Bucket) Name() string {
+	if b.prefix == "" {
+		return "s3://" + b.bucket
+	}
+	return "s3://" + b.bucket + "/" + b.prefix
+}
+
+// EnsureReady checks that credentials are set and the bucket can be reached
+func (b *Bucket) EnsureReady() error {
+	if b.accessKey == "" || b.secretKey == "" {
+		return errs.New(errs.StorageUnavailable, "no credentials for %s; set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", b.Name())
+	}
+	resp, err := b.do(http.MethodHead, "", nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return errs.New(errs.StorageUnavailable, "bucket '%s' does not exist", b.bucket)
+	case http.StatusForbidden:
+		return errs.New(errs.StorageUnavailable, "access to bucket '%s' denied; check AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", b.bucket)
+	}
+	return errs.New(errs.StorageUnavailable, "bucket '%s' answered %s", b.bucket, resp.Status)
+}
+
+// Exists reports whether an object is at path, or any object under it. Only a 404
+// counts as missing; denied or failed requests are returned as errors.
+func (b *Bucket) Exists(path string) (bool, error) {
+	resp, err := b.do(http.MethodHead, b.key(path), nil, nil, 0)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+	case http.StatusForbidden:
+		return false, errs.New(errs.StorageUnavailable, "access to %s in %s denied", path, b.Name())
+	default:
+		return false, fmt.Errorf("failed to check %s: %s", path, resp.Status)
+	}
+	keys, _, err := b.listPage(b.key(path)+"/", "", 1)
+	if err != nil {
+		return false, err
+	}
+	return len(keys) > 0, nil
+}
+
+// MakeDir does nothing: directories are implied by the keys under them
+func (b *Bucket) MakeDir(path string) error {
+	return nil
+}
+
+// PutFile uploads a local file, in parts when it is large
+func (b *Bucket) PutFile(localPath string, path string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return b.Put(f, info.Size(), path)
+}
+
+// Put uploads size bytes from r as they are read, in parts when there are many. S3
+// needs the size up front, so a reader of unknown size is spooled to a temp file
+// first.
+func (b *Bucket) Put(r io.Reader, size int64, path string) error {
+	if size > multipartThreshold {
+		return b.putMultipart(r, size, b.key(path))
+	}
+	if size >= 0 {
+		return b.putObject(r, size, b.key(path))
+	}
+	tmp, err := storage.CreateTemp("s3-upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to buffer upload: %w", err)
+	}
+	return b.PutFile(tmp.Name(), path)
+}
+
+// PutDir uploads every file under localDir to the same place under dir
+func (b *Bucket) PutDir(localDir string, dir string) error {
+	return filepath.Walk(localDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, file)
+		if err != nil {
+			return err
+		}
+		return b.PutFile(file, dir+"/"+filepath.ToSlash(rel))
+	})
+}
+
+// GetFile downloads the object at path to localPath
+func (b *Bucket) GetFile(path string, localPath string) error {
+	f, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+	err = b.Get(path, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(localPath)
+	}
+	return err
+}
+
+// Get writes the object at path to w
+func (b *Bucket) Get(path string, w io.Writer) error {
+	resp, err := b.do(http.MethodGet, b.key(path), nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, "failed to download "+path)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", path, err)
+	}
+	return nil
+}
+
+// Concat downloads the named objects under dir one after another
+func (b *Bucket) Concat(dir string, names []string, w io.Writer) error {
+	for _, name := range names {
+		if err := b.Get(dir+"/"+filepath.ToSlash(name), w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns the paths of every object under dir
+func (b *Bucket) List(dir string) ([]string, error) {
+	prefix := b.key(dir) + "/"
+	var paths []string
+	token := ""
+	for {
+		keys, next, err := b.listPage(prefix, token, 1000)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			paths = append(paths, b.path(key))
+		}
+		if next == "" {
+			return paths, nil
+		}
+		token = next
+	}
+}
+
+// Remove deletes the object at path and every object under it
+func (b *Bucket) Remove(path string) error {
+	paths, err := b.List(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range append(paths, path) {
+		resp, err := b.do(http.MethodDelete, b.key(p), nil, nil, 0)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+			return responseError(resp, "failed to delete "+p)
+		}
+	}
+	return nil
+}
+
+// key returns the object key for a storage path
+func (b *Bucket) key(path string) string {
+	rel := strings.Trim(strings.TrimPrefix(path, b.root), "/")
+	if b.prefix == "" {
+		return rel
+	}
+	if rel == "" {
+		return b.prefix
+	}
+	return b.prefix + "/" + rel
+}
+
+// path returns the storage path for an object key
+func (b *Bucket) path(key string) string {
+	if b.prefix != "" {
+		key = strings.TrimPrefix(strings.TrimPrefix(key, b.prefix), "/")
+	}
+	return b.root + "/" + key
+}
+
+// putObject uploads size bytes from r in a single request
+func (b *Bucket) putObject(r io.Reader, size int64, key string) error {
+	resp, err := b.do(http.MethodPut, key, nil, r, size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, "failed to upload "+key)
+	}
+	return nil
+}
+
+// putMultipart uploads size bytes from r in parts, read one after another; the object
+// only appears once every part is in and the upload is completed, and a failed upload
+// is aborted
+func (b *Bucket) putMultipart(r io.Reader, size int64, key string) error {
+	resp, err := b.do(http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0)
+	if err != nil {
+		return err
+	}
+	var created struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = decodeResponse(resp, &created, "failed to start upload of "+key)
+	if err != nil {
+		return err
+	}
+
+	partSize := int64(minPartSize)
+	if size/maxParts >= partSize {
+		partSize = size/maxParts + 1
+	}
+	type part struct {
+		Number int    `xml:"PartNumber"`
+		ETag   string `xml:"ETag"`
+	}
+	var parts []part
+	for offset, number := int64(0), 1; offset < size; offset, number = offset+partSize, number+1 {
+		n := partSize
+		if offset+n > size {
+			n = size - offset
+		}
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {created.UploadID}}
+		resp, err := b.do(http.MethodPut, key, query, io.LimitReader(r, n), n)
+		if err == nil && resp.StatusCode != http.StatusOK {
+			err = responseError(resp, fmt.Sprintf("failed to upload part %d of %s", number, key))
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err != nil {
+			b.abortMultipart(key, created.UploadID)
+			return err
+		}
+		parts = append(parts, part{Number: number, ETag: resp.Header.Get("ETag")})
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		b.abortMultipart(key, created.UploadID)
+		return err
+	}
+	resp, err = b.do(http.MethodPost, key, url.Values{"uploadId": {created.UploadID}}, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		b.abortMultipart(key, created.UploadID)
+		return err
+	}
+	// Completion can fail after a 200, with the error in the body
+	var completed struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := decodeResponse(resp, &completed, "failed to complete upload of "+key); err != nil {
+		b.abortMultipart(key, created.UploadID)
+		return err
+	}
+	if completed.XMLName.Local == "Error" {
+		b.abortMultipart(key, created.UploadID)
+		return codeError(completed.Code, fmt.Sprintf("failed to complete upload of %s: %s", key, completed.Message))
+	}
+	return nil
+}
+
+// abortMultipart discards the parts of a failed upload
+func (b *Bucket) abortMultipart(key string, uploadID string) {
+	if resp, err := b.do(http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, 0); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// listPage lists up to max keys under prefix, returning the token for the next page
+// or "" after the last
+func (b *Bucket) listPage(prefix string, token string, max int) ([]string, string, error) {
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "max-keys": {strconv.Itoa(max)}}
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+	resp, err := b.do(http.MethodGet, "", query, nil, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	var result struct {
+		Contents []struct {
+			Key string `xml:"Key"`
+		} `xml:"Contents"`
+		IsTruncated           bool   `xml:"IsTruncated"`
+		NextContinuationToken string `xml:"NextContinuationToken"`
+	}
+	if err := decodeResponse(resp, &result, "failed to list "+prefix); err != nil {
+		return nil, "", err
+	}
+	keys := make([]string, len(result.Contents))
+	for i, c := range result.Contents {
+		keys[i] = c.Key
+	}
+	if !result.IsTruncated {
+		return keys, "", nil
+	}
+	return keys, result.NextContinuationToken, nil
+}
+
+// newHTTPClient returns a client whose requests fail when connecting, the TLS
+// handshake, waiting for a response or any read or write stalls
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &stallConn{Conn: conn}, nil
+	}
+	transport.TLSHandshakeTimeout = 30 * time.Second
+	transport.ResponseHeaderTimeout = stallTimeout
+	return &http.Client{Transport: transport}
+}
+
+// stallConn is a connection whose reads and writes fail after stallTimeout without
+// progress
+type stallConn struct {
+	net.Conn
+}
+
+func (c *stallConn) Read(p []byte) (int, error) {
+	c.Conn.SetReadDeadline(time.Now().Add(stallTimeout))
+	return c.Conn.Read(p)
+}
+
+func (c *stallConn) Write(p []byte) (int, error) {
+	c.Conn.SetWriteDeadline(time.Now().Add(stallTimeout))
+	return c.Conn.Write(p)
+}
+
+// do sends a signed request for key ("" for the bucket itself)
+func (b *Bucket) do(method string, key string, query url.Values, body io.Reader, size int64) (*http.Response, error) {
+	u := *b.endpoint
+	if b.pathStyle {
+		u.Path = "/" + b.bucket
+		if key != "" {
+			u.Path += "/" + key
+		}
+	} else {
+		u.Path = "/" + key
+	}
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	if body != nil && size == 0 {
+		body = http.NoBody // Otherwise an empty body is sent chunked, which S3 rejects
+	}
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	b.sign(req, time.Now())
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, errs.New(errs.StorageUnavailable, "cannot reach %s: %w", b.Name(), err)
+	}
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req. The payload is left unsigned, so
+// uploads can stream without being read twice.
+func (b *Bucket) sign(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	if b.token != "" {
+		req.Header.Set("X-Amz-Security-Token", b.token)
+	}
+
+	names := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if b.token != "" {
+		names = append(names, "x-amz-security-token")
+	}
+	scope := date + "/" + b.region + "/s3/aws4_request"
+	toSign := stringToSign(amzDate, scope, canonicalRequest(req, names, unsignedPayload))
+	signature := hex.EncodeToString(hmacSHA256(signingKey(b.secretKey, date, b.region, "s3"), toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", b.accessKey, scope, strings.Join(names, ";"), signature))
+}
+
+// canonicalRequest returns the SigV4 canonical request for req, covering the named
+// headers (lowercase and sorted) and a payload with the given hash
+func canonicalRequest(req *http.Request, names []string, payloadHash string) string {
+	var headers strings.Builder
+	for _, name := range names {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	return strings.Join([]string{req.Method, req.URL.EscapedPath(), req.URL.RawQuery, headers.String(), strings.Join(names, ";"), payloadHash}, "\n")
+}
+
+// stringToSign returns what SigV4 signs for a canonical request made at amzDate
+func stringToSign(amzDate string, scope string, canonical string) string {
+	return "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex(canonical)
+}
+
+// signingKey derives the SigV4 key for a day, region and service from the secret key
+func signingKey(secretKey string, date string, region string, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// escapePath URI-encodes each segment of a path the way SigV4 expects, keeping slashes
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery encodes query parameters sorted by name, as SigV4 signs them
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, escape(name)+"="+escape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// escape percent-encodes everything but unreserved characters
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// decodeResponse parses an XML response body into v, or returns the error it reports
+func decodeResponse(resp *http.Response, v interface{}, msg string) error {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, msg)
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s: unreadable response: %w", msg, err)
+	}
+	return nil
+}
+
+// responseError describes a failed request from the error document S3 sends
+func responseError(resp *http.Response, msg string) error {
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if xml.Unmarshal(data, &e) != nil || e.Code == "" {
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%s: %w", msg, os.ErrNotExist)
+		}
+		return fmt.Errorf("%s: %s", msg, resp.Status)
+	}
+	return codeError(e.Code, fmt.Sprintf("%s: %s (%s)", msg, e.Message, e.Code))
+}
+
+// codeError categorizes an S3 error code
+func codeError(code string, msg string) error {
+	switch code {
+	case "NoSuchKey":
+		return fmt.Errorf("%s: %w", msg, os.ErrNotExist)
+	case "XMinioStorageFull", "QuotaExceeded":
+		return errs.New(errs.StorageFull, "%s", msg)
+	case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "NoSuchBucket":
+		return errs.New(errs.StorageUnavailable, "%s", msg)
+	}
+	return errors.New(msg)
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
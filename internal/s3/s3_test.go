@@ -0,0 +1,273 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/errs"
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Vectors from the AWS Signature Version 4 test suite and the S3 documentation's
+// GET Object example
+func TestSigV4Vectors(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		query         url.Values
+		headers       map[string]string
+		names         []string
+		secretKey     string
+		amzDate       string
+		region        string
+		service       string
+		wantCanonical string // SHA-256 of the canonical request
+		wantSignature string
+	}{
+		{
+			name:          "get-vanilla",
+			url:           "https://example.amazonaws.com/",
+			headers:       map[string]string{"X-Amz-Date": "20150830T123600Z"},
+			names:         []string{"host", "x-amz-date"},
+			secretKey:     "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+			amzDate:       "20150830T123600Z",
+			region:        "us-east-1",
+			service:       "service",
+			wantCanonical: "bb579772317eb040ac9ed261061d46c1f17a8133879d6129b6e1c25292927e63",
+			wantSignature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-vanilla-query-order-key-case",
+			url:           "https://example.amazonaws.com/",
+			query:         url.Values{"Param2": {"value2"}, "Param1": {"value1"}},
+			headers:       map[string]string{"X-Amz-Date": "20150830T123600Z"},
+			names:         []string{"host", "x-amz-date"},
+			secretKey:     "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+			amzDate:       "20150830T123600Z",
+			region:        "us-east-1",
+			service:       "service",
+			wantCanonical: "816cd5b414d056048ba4f7c5386d6e0533120fb1fcfa93762cf0fc39e2cf19e0",
+			wantSignature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name: "s3-get-object",
+			url:  "https://examplebucket.s3.amazonaws.com/test.txt",
+			headers: map[string]string{
+				"Range":                "bytes=0-9",
+				"X-Amz-Content-Sha256": emptyPayloadHash,
+				"X-Amz-Date":           "20130524T000000Z",
+			},
+			names:         []string{"host", "range", "x-amz-content-sha256", "x-amz-date"},
+			secretKey:     "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
+			amzDate:       "20130524T000000Z",
+			region:        "us-east-1",
+			service:       "s3",
+			wantCanonical: "7344ae5b7ee6c3e7e6b0fe0640412a37625d1fbfff95c48bbb2dc43964946972",
+			wantSignature: "f0e8bdb87c964420e857bd35b5d6ed310bd44f0170aba48dd91039c6036bdb41",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.URL.RawQuery = canonicalQuery(tt.query)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			canonical := canonicalRequest(req, tt.names, emptyPayloadHash)
+			if got := sha256Hex(canonical); got != tt.wantCanonical {
+				t.Errorf("canonical request hash = %s, want %s\n%s", got, tt.wantCanonical, canonical)
+			}
+			date := tt.amzDate[:8]
+			scope := date + "/" + tt.region + "/" + tt.service + "/aws4_request"
+			key := signingKey(tt.secretKey, date, tt.region, tt.service)
+			if got := hex.EncodeToString(hmacSHA256(key, stringToSign(tt.amzDate, scope, canonical))); got != tt.wantSignature {
+				t.Errorf("signature = %s, want %s", got, tt.wantSignature)
+			}
+		})
+	}
+}
+
+func TestSignSessionToken(t *testing.T) {
+	b := &Bucket{region: "us-east-1", accessKey: "AKID", secretKey: "secret", token: "session"}
+	req, err := http.NewRequest(http.MethodGet, "https://bucket.s3.us-east-1.amazonaws.com/key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.sign(req, time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC))
+
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "session" {
+		t.Errorf("X-Amz-Security-Token = %q, want %q", got, "session")
+	}
+	auth := req.Header.Get("Authorization")
+	for _, want := range []string{
+		"Credential=AKID/20240301/us-east-1/s3/aws4_request",
+		"SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token",
+	} {
+		if !strings.Contains(auth, want) {
+			t.Errorf("Authorization %q does not contain %q", auth, want)
+		}
+	}
+}
+
+// newTestBucket returns a bucket whose endpoint is a test server running handler
+func newTestBucket(t *testing.T, handler http.HandlerFunc) (*Bucket, *httptest.Server) {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	b, err := New(Config{URL: "s3://bucket/prefix", Endpoint: server.URL}, "/vervids")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, server
+}
+
+// listing is a ListObjectsV2 response holding keys
+func listing(keys ...string) string {
+	var b strings.Builder
+	b.WriteString("<ListBucketResult>")
+	for _, key := range keys {
+		b.WriteString("<Contents><Key>" + key + "</Key></Contents>")
+	}
+	b.WriteString("<IsTruncated>false</IsTruncated></ListBucketResult>")
+	return b.String()
+}
+
+func TestExists(t *testing.T) {
+	tests := []struct {
+		name       string
+		headStatus int
+		listStatus int
+		listBody   string
+		want       bool
+		wantErr    bool
+		wantKind   errs.Kind
+	}{
+		{name: "object", headStatus: http.StatusOK, want: true},
+		{name: "missing", headStatus: http.StatusNotFound, listStatus: http.StatusOK, listBody: listing(), want: false},
+		{name: "directory", headStatus: http.StatusNotFound, listStatus: http.StatusOK, listBody: listing("prefix/proj/v000/promo.aepx"), want: true},
+		{name: "denied", headStatus: http.StatusForbidden, wantErr: true, wantKind: errs.StorageUnavailable},
+		{name: "server error", headStatus: http.StatusInternalServerError, wantErr: true},
+		{name: "list fails", headStatus: http.StatusNotFound, listStatus: http.StatusServiceUnavailable, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := newTestBucket(t, func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodHead && r.URL.Path == "/bucket/prefix/proj/v000":
+					w.WriteHeader(tt.headStatus)
+				case r.Method == http.MethodGet && r.URL.Path == "/bucket" && r.URL.Query().Get("prefix") == "prefix/proj/v000/":
+					w.WriteHeader(tt.listStatus)
+					io.WriteString(w, tt.listBody)
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			})
+
+			got, err := b.Exists("/vervids/proj/v000")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Exists() = %v, want an error", got)
+				}
+				if tt.wantKind != 0 && errs.KindOf(err) != tt.wantKind {
+					t.Errorf("error kind = %v, want %v", errs.KindOf(err), tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exists() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Exists() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExistsUnreachable(t *testing.T) {
+	b, server := newTestBucket(t, func(w http.ResponseWriter, r *http.Request) {})
+	server.Close()
+	if got, err := b.Exists("/vervids/proj/v000"); err == nil {
+		t.Fatalf("Exists() = %v with the endpoint down, want an error", got)
+	}
+}
+
+// upload is what the test server received for a PUT
+type upload struct {
+	key           string
+	contentLength int64
+	chunked       bool
+	body          []byte
+}
+
+func TestPut(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		size    int64 // -1 for a reader of unknown size
+		status  int
+		wantErr bool
+	}{
+		{name: "known size", data: "footage", size: 7, status: http.StatusOK},
+		{name: "unknown size", data: "footage", size: -1, status: http.StatusOK},
+		{name: "empty", data: "", size: 0, status: http.StatusOK},
+		{name: "rejected", data: "footage", size: 7, status: http.StatusInternalServerError, wantErr: true},
+		{name: "bucket full", data: "footage", size: 7, status: http.StatusInsufficientStorage, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(storage.TempDirEnv, t.TempDir())
+			var mu sync.Mutex
+			var uploads []upload
+			b, _ := newTestBucket(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				body, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				uploads = append(uploads, upload{key: r.URL.Path, contentLength: r.ContentLength, chunked: len(r.TransferEncoding) > 0, body: body})
+				mu.Unlock()
+				w.WriteHeader(tt.status)
+			})
+
+			// A plain io.Reader, so nothing can tell the size but the argument
+			r := io.MultiReader(strings.NewReader(tt.data))
+			err := b.Put(r, tt.size, "/vervids/proj/assets/ab/abcd.mov")
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Put() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(uploads) != 1 {
+				t.Fatalf("got %d uploads, want 1", len(uploads))
+			}
+			got := uploads[0]
+			if got.key != "/bucket/prefix/proj/assets/ab/abcd.mov" {
+				t.Errorf("uploaded to %s", got.key)
+			}
+			if got.chunked || got.contentLength != int64(len(tt.data)) {
+				t.Errorf("Content-Length = %d (chunked %v), want %d", got.contentLength, got.chunked, len(tt.data))
+			}
+			if !bytes.Equal(got.body, []byte(tt.data)) {
+				t.Errorf("body = %q, want %q", got.body, tt.data)
+			}
+		})
+	}
+}
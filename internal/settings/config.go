@@ -0,0 +1,364 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/i18n"
+	"github.com/ajeebtech/vervideos/internal/ui"
+)
+
+// DefaultPort is the API server port used when none is configured
+const DefaultPort = 8080
+
+// configKey describes a user setting that can be read and changed by key
+type configKey struct {
+	description string
+	get         func(s *Settings) string
+	set         func(s *Settings, values []string) error
+}
+
+// configKeys are the user settings editable through `vervids config --global`
+var configKeys = map[string]configKey{
+	"theme": {
+		description: "Color theme: " + strings.Join(ui.ThemeNames(), ", "),
+		get: func(s *Settings) string {
+			if s.Theme == "" {
+				return ui.DefaultTheme
+			}
+			return s.Theme
+		},
+		set: func(s *Settings, values []string) error {
+			value, err := singleValue(values)
+			if err != nil {
+				return err
+			}
+			if _, err := ui.ResolveTheme(value, ui.Theme{}); err != nil && value != "" {
+				return err
+			}
+			s.Theme = value
+			return nil
+		},
+	},
+	"language": {
+		description: "Message language (" + strings.Join(i18n.Languages(), ", ") + "); empty follows the locale",
+		get:         func(s *Settings) string { return s.Language },
+		set: func(s *Settings, values []string) error {
+			value, err := singleValue(values)
+			if err != nil {
+				return err
+			}
+			if value != "" {
+				// Validate, then restore the active language
+				active := i18n.Language()
+				err := i18n.SetLanguage(value)
+				i18n.SetLanguage(active)
+				if err != nil {
+					return err
+				}
+			}
+			s.Language = value
+			return nil
+		},
+	},
+	"log_to_file": {
+		description: "Always append debug logs to ~/.vervids/logs",
+		get:         func(s *Settings) string { return strconv.FormatBool(s.LogToFile) },
+		set: func(s *Settings, values []string) (err error) {
+			s.LogToFile, err = boolValue(values)
+			return err
+		},
+	},
+	"no_update_check": {
+		description: "Don't look up new releases on startup",
+		get:         func(s *Settings) string { return strconv.FormatBool(s.NoUpdateCheck) },
+		set: func(s *Settings, values []string) (err error) {
+			s.NoUpdateCheck, err = boolValue(values)
+			return err
+		},
+	},
+	"port": {
+		description: fmt.Sprintf("Default port for 'vervids serve' (default %d)", DefaultPort),
+		get:         func(s *Settings) string { return strconv.Itoa(s.ServePort()) },
+		set: func(s *Settings, values []string) error {
+			if len(values) == 0 {
+				s.Port = 0
+				return nil
+			}
+			value, err := singleValue(values)
+			if err != nil {
+				return err
+			}
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("invalid port '%s' (expected 1-65535)", value)
+			}
+			s.Port = port
+			return nil
+		},
+	},
+	"temp_dir": {
+		description: "Directory for temporary files (default: the system temp directory)",
+		get:         func(s *Settings) string { return s.TempDir },
+		set: func(s *Settings, values []string) (err error) {
+			s.TempDir, err = dirValue(values)
+			return err
+		},
+	},
+	"cache_dir": {
+		description: "Directory for cached and staged data (default: ~/.vervids/cache)",
+		get:         func(s *Settings) string { return s.CacheDir },
+		set: func(s *Settings, values []string) (err error) {
+			s.CacheDir, err = dirValue(values)
+			return err
+		},
+	},
+	"templates_dir": {
+		description: "Directory of project templates for 'vervids init --template' (default: ~/.vervids/templates)",
+		get:         func(s *Settings) string { return s.TemplatesDir },
+		set: func(s *Settings, values []string) (err error) {
+			s.TemplatesDir, err = dirValue(values)
+			return err
+		},
+	},
+	"ae_version": {
+		description: "After Effects release installed here, e.g. 2024, for when it isn't found in the usual application folder",
+		get:         func(s *Settings) string { return s.AEVersion },
+		set: func(s *Settings, values []string) error {
+			value, err := singleValue(values)
+			if err == nil && value != "" {
+				_, err = aepx.ParseRelease(value)
+			}
+			if err != nil {
+				return err
+			}
+			s.AEVersion = value
+			return nil
+		},
+	},
+	"read_only": {
+		description: "Block commit, delete, prune and other writes in every project, e.g. to browse a shared backend safely",
+		get:         func(s *Settings) string { return strconv.FormatBool(s.ReadOnly) },
+		set: func(s *Settings, values []string) (err error) {
+			s.ReadOnly, err = boolValue(values)
+			return err
+		},
+	},
+	"author_name": {
+		description: "Name recorded on commits (a project's own author setting takes precedence)",
+		get:         func(s *Settings) string { return s.Author.Name },
+		set: func(s *Settings, values []string) error {
+			s.Author.Name = strings.Join(values, " ")
+			return nil
+		},
+	},
+	"author_email": {
+		description: "Email recorded on commits",
+		get:         func(s *Settings) string { return s.Author.Email },
+		set: func(s *Settings, values []string) error {
+			value, err := singleValue(values)
+			if err != nil {
+				return err
+			}
+			s.Author.Email = value
+			return nil
+		},
+	},
+	"signing_key": {
+		description: "SSH or minisign private key that signs new commits (its .pub must sit next to it)",
+		get:         func(s *Settings) string { return s.SigningKey },
+		set: func(s *Settings, values []string) error {
+			value, err := singleValue(values)
+			if err != nil || value == "" {
+				s.SigningKey = ""
+				return err
+			}
+			path, err := filepath.Abs(value)
+			if err != nil {
+				return fmt.Errorf("invalid path '%s': %w", value, err)
+			}
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("cannot use '%s': %w", path, err)
+			}
+			s.SigningKey = path
+			return nil
+		},
+	},
+	"exclude_extensions": {
+		description: "Never track assets with these extensions, in every project",
+		get:         func(s *Settings) string { return strings.Join(s.ExcludeExtensions, ",") },
+		set: func(s *Settings, values []string) error {
+			exts := splitValues(values)
+			for i, e := range exts {
+				exts[i] = assets.NormalizeExtension(e)
+			}
+			s.ExcludeExtensions = exts
+			return nil
+		},
+	},
+	"container_cpus": {
+		description: "CPU limit for the storage container, e.g. 2 (applies when it is recreated)",
+		get:         func(s *Settings) string { return s.Container.CPUs },
+		set: func(s *Settings, values []string) error {
+			value, err := singleValue(values)
+			if err == nil && value != "" {
+				_, err = docker.ParseCPUs(value)
+			}
+			if err != nil {
+				return err
+			}
+			s.Container.CPUs = value
+			return nil
+		},
+	},
+	"container_memory": {
+		description: "Memory limit for the storage container, e.g. 2g (applies when it is recreated)",
+		get:         func(s *Settings) string { return s.Container.Memory },
+		set: func(s *Settings, values []string) error {
+			value, err := singleValue(values)
+			if err == nil && value != "" {
+				_, err = docker.ParseMemory(value)
+			}
+			if err != nil {
+				return err
+			}
+			s.Container.Memory = value
+			return nil
+		},
+	},
+	"container_image": {
+		description: "Storage container image, pinned by digest (alpine@sha256:...); default " + docker.DefaultImage,
+		get:         func(s *Settings) string { return s.Container.Image },
+		set: func(s *Settings, values []string) error {
+			value, err := singleValue(values)
+			if err != nil {
+				return err
+			}
+			s.Container.Image = value
+			return nil
+		},
+	},
+	"container_read_only": {
+		description: "Run the storage container with a read-only root filesystem",
+		get:         func(s *Settings) string { return strconv.FormatBool(s.Container.ReadOnly) },
+		set: func(s *Settings, values []string) (err error) {
+			s.Container.ReadOnly, err = boolValue(values)
+			return err
+		},
+	},
+	"exclude_folders": {
+		description: "Never track assets inside these folders, in every project",
+		get:         func(s *Settings) string { return strings.Join(s.ExcludeFolders, ",") },
+		set: func(s *Settings, values []string) error {
+			s.ExcludeFolders = splitValues(values)
+			return nil
+		},
+	},
+}
+
+// ConfigKeys returns the names of all user settings, sorted
+func ConfigKeys() []string {
+	keys := make([]string, 0, len(configKeys))
+	for k := range configKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IsConfigKey reports whether key is a user setting
+func IsConfigKey(key string) bool {
+	_, ok := configKeys[key]
+	return ok
+}
+
+// ConfigDescription returns the help text for a user setting
+func ConfigDescription(key string) string {
+	return configKeys[key].description
+}
+
+// GetConfig returns the current value of a user setting
+func (s *Settings) GetConfig(key string) (string, error) {
+	k, ok := configKeys[key]
+	if !ok {
+		return "", fmt.Errorf("unknown global setting '%s' (available: %s)", key, strings.Join(ConfigKeys(), ", "))
+	}
+	return k.get(s), nil
+}
+
+// SetConfig changes a user setting; passing no values clears it
+func (s *Settings) SetConfig(key string, values []string) error {
+	k, ok := configKeys[key]
+	if !ok {
+		return fmt.Errorf("unknown global setting '%s' (available: %s)", key, strings.Join(ConfigKeys(), ", "))
+	}
+	return k.set(s, values)
+}
+
+// ServePort returns the configured API server port, or DefaultPort
+func (s *Settings) ServePort() int {
+	if s.Port == 0 {
+		return DefaultPort
+	}
+	return s.Port
+}
+
+// singleValue returns the one value given, or "" for none
+func singleValue(values []string) (string, error) {
+	switch len(values) {
+	case 0:
+		return "", nil
+	case 1:
+		return strings.TrimSpace(values[0]), nil
+	}
+	return "", fmt.Errorf("expected a single value")
+}
+
+// dirValue returns a single directory value as an absolute path, creating it
+func dirValue(values []string) (string, error) {
+	value, err := singleValue(values)
+	if err != nil || value == "" {
+		return "", err
+	}
+	dir, err := filepath.Abs(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid path '%s': %w", value, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("cannot use '%s': %w", dir, err)
+	}
+	return dir, nil
+}
+
+// boolValue parses a single boolean value; no values means false
+func boolValue(values []string) (bool, error) {
+	value, err := singleValue(values)
+	if err != nil || value == "" {
+		return false, err
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value '%s' (expected true or false)", value)
+	}
+	return b, nil
+}
+
+// splitValues flattens comma-separated values and drops empty entries
+func splitValues(values []string) []string {
+	result := []string{}
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/assets"
+	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/s3"
+	"github.com/ajeebtech/vervideos/internal/storage"
+	"github.com/ajeebtech/vervideos/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+const SettingsFile = "settings.yaml"
+
+// Settings holds user-wide preferences stored in ~/.vervids/settings.yaml
+type Settings struct {
+	PathMappings  []assets.PathMapping `yaml:"path_mappings,omitempty"`
+	Theme         string               `yaml:"theme,omitempty"`           // Built-in color theme: dark, light or high-contrast
+	Colors        ui.Theme             `yaml:"colors,omitempty"`          // Custom colors applied over the theme
+	Language      string               `yaml:"language,omitempty"`        // Message language, e.g. "es"; defaults to the locale
+	LogToFile     bool                 `yaml:"log_to_file,omitempty"`     // Always append debug logs to ~/.vervids/logs
+	NoUpdateCheck bool                 `yaml:"no_update_check,omitempty"` // Don't look up new releases on startup
+	Port          int                  `yaml:"port,omitempty"`            // Default port for vervids serve
+	Author        Author               `yaml:"author,omitempty"`
+	SigningKey    string               `yaml:"signing_key,omitempty"`   // SSH or minisign private key that signs new commits
+	TempDir       string               `yaml:"temp_dir,omitempty"`      // Temporary files, instead of the system temp directory
+	CacheDir      string               `yaml:"cache_dir,omitempty"`     // Cached and staged data, instead of ~/.vervids/cache
+	TemplatesDir  string               `yaml:"templates_dir,omitempty"` // Project templates, instead of ~/.vervids/templates
+	AEVersion     string               `yaml:"ae_version,omitempty"`    // After Effects release installed here, when it can't be detected
+	ReadOnly      bool                 `yaml:"read_only,omitempty"`     // Block commit, delete, prune and other writes in every project
+	// Asset rules applied to every project, on top of each project's own rules
+	ExcludeExtensions []string `yaml:"exclude_extensions,omitempty"`
+	ExcludeFolders    []string `yaml:"exclude_folders,omitempty"`
+	// Resource limits and isolation for the storage container
+	Container Container `yaml:"container,omitempty"`
+	// S3-compatible bucket new projects are stored in instead of Docker ('vervids remote add')
+	Remote *s3.Config `yaml:"remote,omitempty"`
+	// Tokens accepted by 'vervids serve --auth' ('vervids token create')
+	APITokens []APIToken `yaml:"api_tokens,omitempty"`
+}
+
+// Container configures how the storage container is created
+type Container struct {
+	CPUs     string `yaml:"cpus,omitempty"`      // CPU limit, e.g. "2"
+	Memory   string `yaml:"memory,omitempty"`    // Memory limit, e.g. "2g"
+	Image    string `yaml:"image,omitempty"`     // Image, ideally pinned by digest (alpine@sha256:...)
+	ReadOnly bool   `yaml:"read_only,omitempty"` // Read-only root filesystem
+}
+
+// Options returns the container settings as Docker container options
+func (c Container) Options() docker.ContainerOptions {
+	return docker.ContainerOptions{CPUs: c.CPUs, Memory: c.Memory, Image: c.Image, ReadOnly: c.ReadOnly}
+}
+
+// Author identifies who made a commit
+type Author struct {
+	Name  string `yaml:"name,omitempty"`
+	Email string `yaml:"email,omitempty"`
+}
+
+// String formats the author as "Name <email>", or whichever part is set
+func (a Author) String() string {
+	switch {
+	case a.Name != "" && a.Email != "":
+		return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+	case a.Name != "":
+		return a.Name
+	}
+	return a.Email
+}
+
+// TemplatesPath returns the directory holding project templates: the configured
+// templates_dir or ~/.vervids/templates
+func (s *Settings) TemplatesPath() string {
+	if s.TemplatesDir != "" {
+		return s.TemplatesDir
+	}
+	return filepath.Join(storage.GetUserDir(), "templates")
+}
+
+// InstalledAEVersion returns the After Effects release to check projects against: the
+// configured ae_version, or the newest one found installed. Nil when neither is known.
+func (s *Settings) InstalledAEVersion() *aepx.AppVersion {
+	if s.AEVersion != "" {
+		if v, err := aepx.ParseRelease(s.AEVersion); err == nil {
+			return &v
+		}
+	}
+	if installed := aepx.InstalledAppVersions(); len(installed) > 0 {
+		return &installed[0]
+	}
+	return nil
+}
+
+// GetSettingsPath returns the path to the user settings file
+func GetSettingsPath() string {
+	return filepath.Join(storage.GetUserDir(), SettingsFile)
+}
+
+// Load reads the user settings file. A missing file yields empty settings.
+func Load() (*Settings, error) {
+	s := &Settings{}
+	data, err := os.ReadFile(GetSettingsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the settings back to the user settings file
+func (s *Settings) Save() error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if err := os.WriteFile(GetSettingsPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package settings
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// apiTokenPrefix starts every API token, so a leaked one is easy to recognize
+const apiTokenPrefix = "vvt_"
+
+// APIToken is a token accepted by 'vervids serve --auth'. Only its hash is kept; the
+// token itself is shown once, when it is created.
+type APIToken struct {
+	Name      string    `yaml:"name"`
+	Hash      string    `yaml:"hash"` // SHA-256 of the token
+	CreatedAt time.Time `yaml:"created_at"`
+}
+
+// CreateAPIToken generates a token named name and returns it. A token already named
+// name is replaced, which rotates it: the old one stops working once saved.
+func (s *Settings) CreateAPIToken(name string) (token string, rotated bool, err error) {
+	if name == "" {
+		return "", false, fmt.Errorf("token name is required")
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", false, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = apiTokenPrefix + hex.EncodeToString(secret)
+	entry := APIToken{Name: name, Hash: hashAPIToken(token), CreatedAt: time.Now()}
+
+	for i := range s.APITokens {
+		if s.APITokens[i].Name == name {
+			s.APITokens[i] = entry
+			return token, true, nil
+		}
+	}
+	s.APITokens = append(s.APITokens, entry)
+	return token, false, nil
+}
+
+// RemoveAPIToken deletes the token named name, reporting whether there was one
+func (s *Settings) RemoveAPIToken(name string) bool {
+	for i := range s.APITokens {
+		if s.APITokens[i].Name == name {
+			s.APITokens = append(s.APITokens[:i], s.APITokens[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ValidAPIToken reports whether token is one of the configured tokens
+func (s *Settings) ValidAPIToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	hash := []byte(hashAPIToken(token))
+	valid := false
+	for _, t := range s.APITokens {
+		if subtle.ConstantTimeCompare(hash, []byte(t.Hash)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,105 @@
+package settings
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidAPIToken(t *testing.T) {
+	s := &Settings{}
+	ci, _, err := s.CreateAPIToken("ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	editor, _, err := s.CreateAPIToken("editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ci, apiTokenPrefix) {
+		t.Errorf("token %q does not start with %q", ci, apiTokenPrefix)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{"first token", ci, true},
+		{"second token", editor, true},
+		{"empty", "", false},
+		{"prefix only", apiTokenPrefix, false},
+		{"truncated", ci[:len(ci)-1], false},
+		{"extra character", ci + "0", false},
+		{"upper case", strings.ToUpper(ci), false},
+		{"surrounding space", " " + ci, false},
+		{"stored hash", hashAPIToken(ci), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.ValidAPIToken(tt.token); got != tt.want {
+				t.Errorf("ValidAPIToken(%q) = %v, want %v", tt.token, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPITokenKeepsOnlyHash(t *testing.T) {
+	s := &Settings{}
+	token, _, err := s.CreateAPIToken("ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.APITokens[0].Hash; got == token || got != hashAPIToken(token) {
+		t.Errorf("stored hash = %q, want the SHA-256 of the token", got)
+	}
+}
+
+func TestRotateAPIToken(t *testing.T) {
+	s := &Settings{}
+	old, rotated, err := s.CreateAPIToken("ci")
+	if err != nil || rotated {
+		t.Fatalf("CreateAPIToken() = rotated %v, error %v", rotated, err)
+	}
+	current, rotated, err := s.CreateAPIToken("ci")
+	if err != nil || !rotated {
+		t.Fatalf("CreateAPIToken() again = rotated %v, error %v; want a rotation", rotated, err)
+	}
+	if len(s.APITokens) != 1 {
+		t.Errorf("%d tokens after rotating, want 1", len(s.APITokens))
+	}
+	if s.ValidAPIToken(old) {
+		t.Error("the rotated-out token is still valid")
+	}
+	if !s.ValidAPIToken(current) {
+		t.Error("the new token is not valid")
+	}
+}
+
+func TestRemoveAPIToken(t *testing.T) {
+	s := &Settings{}
+	ci, _, _ := s.CreateAPIToken("ci")
+	editor, _, _ := s.CreateAPIToken("editor")
+
+	if !s.RemoveAPIToken("ci") {
+		t.Fatal("RemoveAPIToken(\"ci\") = false, want true")
+	}
+	if s.RemoveAPIToken("ci") {
+		t.Error("RemoveAPIToken(\"ci\") twice = true, want false")
+	}
+	if s.ValidAPIToken(ci) {
+		t.Error("a removed token is still valid")
+	}
+	if !s.ValidAPIToken(editor) {
+		t.Error("removing one token invalidated another")
+	}
+}
+
+func TestCreateAPITokenRequiresName(t *testing.T) {
+	s := &Settings{}
+	if _, _, err := s.CreateAPIToken(""); err == nil {
+		t.Error("CreateAPIToken(\"\") succeeded, want an error")
+	}
+	if len(s.APITokens) != 0 {
+		t.Errorf("%d tokens after a failed create, want 0", len(s.APITokens))
+	}
+}
@@ -0,0 +1,203 @@
+// Package signing signs version manifests with the user's SSH or minisign key and
+// verifies those signatures, using the ssh-keygen and minisign tools
+package signing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+const (
+	FormatSSH      = "ssh"
+	FormatMinisign = "minisign"
+
+	// namespace keeps vervids signatures from being valid for other uses of an SSH key
+	namespace = "vervids"
+	// principal is the identity the signer's key is listed under when verifying SSH signatures
+	principal = "signer"
+)
+
+// Signature is a signed version manifest as stored on the version
+type Signature struct {
+	Format    string `json:"format"`     // FormatSSH or FormatMinisign
+	Signer    string `json:"signer"`     // Who signed, from the author settings
+	PublicKey string `json:"public_key"` // The signing key's public half
+	Signature string `json:"signature"`  // Armored SSH signature or .minisig contents
+}
+
+// Key is a private key used to sign new versions
+type Key struct {
+	Path      string
+	Format    string
+	PublicKey string
+}
+
+// LoadKey opens a signing key: an SSH private key (or its .pub, when the key is held by
+// ssh-agent) or a minisign secret key. The public key is read from the file next to it
+// with a .pub extension.
+func LoadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	key := &Key{Path: path, Format: FormatSSH}
+	if bytes.HasPrefix(data, []byte("untrusted comment:")) {
+		key.Format = FormatMinisign
+	}
+
+	publicPaths := []string{path + ".pub"}
+	if key.Format == FormatMinisign {
+		publicPaths = []string{strings.TrimSuffix(path, filepath.Ext(path)) + ".pub", path + ".pub"}
+	} else if strings.HasSuffix(path, ".pub") {
+		publicPaths = []string{path}
+	}
+	for _, p := range publicPaths {
+		if data, err := os.ReadFile(p); err == nil {
+			if key.PublicKey = KeyMaterial(string(data)); key.PublicKey != "" {
+				break
+			}
+		}
+	}
+	if key.PublicKey == "" {
+		return nil, fmt.Errorf("no public key found for %s (expected %s)", path, publicPaths[0])
+	}
+
+	tool := "ssh-keygen"
+	if key.Format == FormatMinisign {
+		tool = "minisign"
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("%s is needed to sign with %s but was not found on PATH", tool, path)
+	}
+	return key, nil
+}
+
+// Sign signs a manifest, prompting for the key's passphrase if it has one
+func (k *Key) Sign(manifest []byte, signer string) (*Signature, error) {
+	dir, err := storage.MkdirTemp("sign-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	manifestPath := filepath.Join(dir, "manifest")
+	if err := os.WriteFile(manifestPath, manifest, 0644); err != nil {
+		return nil, err
+	}
+
+	var cmd *exec.Cmd
+	sigPath := manifestPath + ".sig"
+	if k.Format == FormatMinisign {
+		cmd = exec.Command("minisign", "-S", "-s", k.Path, "-m", manifestPath, "-x", sigPath, "-t", "vervids version signed by "+signer)
+	} else {
+		cmd = exec.Command("ssh-keygen", "-Y", "sign", "-f", k.Path, "-n", namespace, manifestPath)
+	}
+	cmd.Stdin = os.Stdin
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w (output: %s)", cmd.Args[0], err, strings.TrimSpace(string(output)))
+	}
+
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	return &Signature{Format: k.Format, Signer: signer, PublicKey: k.PublicKey, Signature: string(sig)}, nil
+}
+
+// Verify checks that the signature was made over the manifest by the signature's key
+func Verify(sig *Signature, manifest []byte) error {
+	dir, err := storage.MkdirTemp("verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	sigPath := filepath.Join(dir, "manifest.sig")
+	if err := os.WriteFile(sigPath, []byte(sig.Signature), 0644); err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	switch sig.Format {
+	case FormatMinisign:
+		manifestPath := filepath.Join(dir, "manifest")
+		if err := os.WriteFile(manifestPath, manifest, 0644); err != nil {
+			return err
+		}
+		cmd = exec.Command("minisign", "-V", "-q", "-P", sig.PublicKey, "-m", manifestPath, "-x", sigPath)
+	case FormatSSH:
+		allowedPath := filepath.Join(dir, "allowed_signers")
+		if err := os.WriteFile(allowedPath, []byte(principal+" "+sig.PublicKey+"\n"), 0644); err != nil {
+			return err
+		}
+		cmd = exec.Command("ssh-keygen", "-Y", "verify", "-f", allowedPath, "-I", principal, "-n", namespace, "-s", sigPath)
+		cmd.Stdin = bytes.NewReader(manifest)
+	default:
+		return fmt.Errorf("unknown signature format '%s'", sig.Format)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return fmt.Errorf("%s is needed to verify %s signatures: %w", cmd.Args[0], sig.Format, err)
+		}
+		reason, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+		return fmt.Errorf("bad signature: %s", reason)
+	}
+	return nil
+}
+
+// KeyMaterial extracts the key itself from a public key line, ignoring comments and
+// names: "ssh-ed25519 AAAA..." for SSH keys, the base64 key for minisign. Returns ""
+// if the text holds no key.
+func KeyMaterial(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "untrusted comment:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		for i, f := range fields {
+			if isSSHKeyType(f) && i+1 < len(fields) {
+				return f + " " + fields[i+1]
+			}
+			if strings.HasPrefix(f, "RW") && len(f) == 56 {
+				return f
+			}
+		}
+	}
+	return ""
+}
+
+// isSSHKeyType reports whether a field names an SSH public key type
+func isSSHKeyType(field string) bool {
+	for _, prefix := range []string{"ssh-", "ecdsa-sha2-", "sk-"} {
+		if strings.HasPrefix(field, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadTrustedKeys reads a list of trusted public keys, one per line. SSH public key
+// lines (optionally preceded by a name, as in allowed_signers) and minisign .pub files
+// can be pasted in as they are; blank lines and # comments are skipped.
+func LoadTrustedKeys(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keys := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if key := KeyMaterial(scanner.Text()); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys, scanner.Err()
+}
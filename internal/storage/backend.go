@@ -0,0 +1,37 @@
+package storage
+
+import "io"
+
+// Storage is where versions and assets are kept: the local Docker volume, or a remote
+// S3-compatible bucket. Paths are slash-separated and rooted at the same storage root
+// for every backend (/vervids), so the paths recorded in config.json mean the same
+// thing wherever a project is stored.
+type Storage interface {
+	// Name describes the backend for messages, e.g. "Docker" or "s3://bucket/prefix"
+	Name() string
+	// EnsureReady checks the backend can be reached, starting it if it can
+	EnsureReady() error
+	// Exists reports whether a file or a directory with files in it is at path. An
+	// error means the backend couldn't be asked, not that nothing is there.
+	Exists(path string) (bool, error)
+	// MakeDir creates a directory and its parents; a no-op where directories are implied
+	MakeDir(path string) error
+	// PutFile stores a local file at path. The file appears whole or not at all.
+	PutFile(localPath string, path string) error
+	// Put stores the size bytes r holds at path, streaming them where the backend can;
+	// size is -1 when unknown. The file appears whole or not at all.
+	Put(r io.Reader, size int64, path string) error
+	// PutDir merges the contents of a local directory into dir
+	PutDir(localDir string, dir string) error
+	// GetFile copies the file at path to localPath
+	GetFile(path string, localPath string) error
+	// Get writes the file at path to w. A missing file is an error wrapping
+	// os.ErrNotExist; any other error means the backend couldn't be read.
+	Get(path string, w io.Writer) error
+	// Concat writes the files named, relative to dir, to w one after another
+	Concat(dir string, names []string, w io.Writer) error
+	// List returns the paths of every file under dir
+	List(dir string) ([]string, error)
+	// Remove deletes the file or the directory tree at path
+	Remove(path string) error
+}
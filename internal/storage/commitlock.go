@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitLockFile marks a commit or other write to the project in progress and holds
+// the writing process's ID
+const CommitLockFile = "commit.lock"
+
+// lockPoll is how often WaitCommitLock checks whether the lock was released
+const lockPoll = 100 * time.Millisecond
+
+// LockHeldError is LockCommit's error while a running process holds the lock
+type LockHeldError struct {
+	PID int
+}
+
+func (e *LockHeldError) Error() string {
+	return fmt.Sprintf("another write to this project is running (pid %d)", e.PID)
+}
+
+// GetCommitLockPath returns the path to the commit lock in the .vervids directory
+func GetCommitLockPath() string {
+	return filepath.Join(VerVidsDir, CommitLockFile)
+}
+
+// LockCommit marks a commit of the project in the current directory as running, for
+// as long as it runs, so 'vervids clean' leaves its files alone. A lock left by a
+// process that has exited is taken over. Returns the function that removes it.
+func LockCommit() (func(), error) {
+	path := GetCommitLockPath()
+	for attempt := 0; attempt < 2; attempt++ {
+		// The lock appears with the ID already in it, so a process checking it never
+		// reads an empty lock and takes it for one left behind
+		err := linkLock(path)
+		if err == nil {
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if pid := lockHolder(path); pid > 0 {
+			return nil, &LockHeldError{PID: pid}
+		}
+		os.Remove(path) // Left by a commit that exited without cleaning up
+	}
+	return nil, fmt.Errorf("failed to create %s", CommitLockFile)
+}
+
+// linkLock creates the lock at path holding this process's ID, failing with
+// os.ErrExist when it's already there
+func linkLock(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), CommitLockFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", CommitLockFile, err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(strconv.Itoa(os.Getpid()))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", CommitLockFile, err)
+	}
+	if err := os.Link(tmp.Name(), path); err != nil && !errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("failed to create %s: %w", CommitLockFile, err)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// WaitCommitLock takes the commit lock like LockCommit, waiting while a running
+// process holds it, so writes to the project run one after another. waiting is called
+// once, with the holder's ID, if it has to wait. Gives up after timeout.
+func WaitCommitLock(timeout time.Duration, waiting func(pid int)) (func(), error) {
+	deadline := time.Now().Add(timeout)
+	notified := false
+	for {
+		release, err := LockCommit()
+		var held *LockHeldError
+		if !errors.As(err, &held) {
+			return release, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w; gave up waiting after %s", err, timeout)
+		}
+		if !notified && waiting != nil {
+			waiting(held.PID)
+			notified = true
+		}
+		time.Sleep(lockPoll)
+	}
+}
+
+// CommitRunning reports whether a live process holds the commit lock in vervidsDir
+func CommitRunning(vervidsDir string) bool {
+	return lockHolder(filepath.Join(vervidsDir, CommitLockFile)) > 0
+}
+
+// lockHolder returns the ID of the running process holding the lock at path, or 0
+func lockHolder(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 || !processRunning(pid) {
+		return 0
+	}
+	return pid
+}
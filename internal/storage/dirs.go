@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// TempPrefix starts the name of every temporary file and directory vervids creates,
+	// so leftovers from interrupted runs can be found and cleaned
+	TempPrefix = "vervids-"
+
+	// CacheDirName is the default cache directory under ~/.vervids
+	CacheDirName = "cache"
+
+	// TempDirEnv and CacheDirEnv override the configured locations
+	TempDirEnv  = "VERVIDS_TMPDIR"
+	CacheDirEnv = "VERVIDS_CACHE_DIR"
+)
+
+// Directories configured in settings.yaml; empty uses the defaults
+var (
+	tempDir  string
+	cacheDir string
+)
+
+// SetTempDir sets where temporary files go instead of the system temp directory
+func SetTempDir(dir string) {
+	tempDir = dir
+}
+
+// SetCacheDir sets where cached and staged data goes instead of ~/.vervids/cache
+func SetCacheDir(dir string) {
+	cacheDir = dir
+}
+
+// TempDir returns the directory for temporary files: $VERVIDS_TMPDIR, the configured
+// temp_dir, or the system temp directory
+func TempDir() string {
+	dir := os.Getenv(TempDirEnv)
+	if dir == "" {
+		dir = tempDir
+	}
+	if dir == "" {
+		return os.TempDir()
+	}
+	os.MkdirAll(dir, 0755)
+	return dir
+}
+
+// CacheDir returns the directory for cached and staged data: $VERVIDS_CACHE_DIR, the
+// configured cache_dir, or ~/.vervids/cache
+func CacheDir() string {
+	dir := os.Getenv(CacheDirEnv)
+	if dir == "" {
+		dir = cacheDir
+	}
+	if dir == "" {
+		dir = filepath.Join(GetUserDir(), CacheDirName)
+	}
+	os.MkdirAll(dir, 0755)
+	return dir
+}
+
+// MkdirTemp creates a temporary directory in TempDir named TempPrefix+pattern
+func MkdirTemp(pattern string) (string, error) {
+	return os.MkdirTemp(TempDir(), TempPrefix+pattern)
+}
+
+// CreateTemp creates a temporary file in TempDir named TempPrefix+pattern
+func CreateTemp(pattern string) (*os.File, error) {
+	return os.CreateTemp(TempDir(), TempPrefix+pattern)
+}
+
+// TempPath returns the path of a named temporary file in TempDir
+func TempPath(name string) string {
+	return filepath.Join(TempDir(), TempPrefix+name)
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const HashCacheFile = "hash-cache.json"
+
+// WorkingHashCacheFile caches, in the cache directory, the hashes of the project files
+// 'vervids list' checks for uncommitted work
+const WorkingHashCacheFile = "working-files.json"
+
+// HashCacheEntry records a file's hash along with the size and mtime it was computed for
+type HashCacheEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Hash    string    `json:"hash"`
+}
+
+// HashCache remembers asset hashes between commits so unchanged files are not re-read
+type HashCache struct {
+	Entries map[string]HashCacheEntry `json:"entries"`
+	seen    map[string]bool
+	path    string
+}
+
+// GetHashCachePath returns the path to the hash cache in the .vervids directory
+func GetHashCachePath() string {
+	return filepath.Join(VerVidsDir, HashCacheFile)
+}
+
+// LoadHashCache reads the hash cache. A missing or unreadable cache yields an empty one.
+func LoadHashCache() *HashCache {
+	return LoadHashCacheFrom(GetHashCachePath())
+}
+
+// LoadWorkingHashCache reads the cache of project file hashes used by 'vervids list'
+func LoadWorkingHashCache() *HashCache {
+	return LoadHashCacheFrom(filepath.Join(CacheDir(), WorkingHashCacheFile))
+}
+
+// LoadHashCacheFrom reads a hash cache from path, where Save writes it back
+func LoadHashCacheFrom(path string) *HashCache {
+	cache := &HashCache{Entries: map[string]HashCacheEntry{}, seen: map[string]bool{}, path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Entries == nil {
+		cache.Entries = map[string]HashCacheEntry{}
+	}
+	return cache
+}
+
+// Hash returns the SHA-256 of the file at path, reusing the cached hash when the
+// file's size and mtime are unchanged. cached reports whether the file was skipped.
+func (c *HashCache) Hash(path string) (hash string, cached bool, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false, err
+	}
+	c.seen[path] = true
+
+	if entry, ok := c.Entries[path]; ok && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+		return entry.Hash, true, nil
+	}
+
+	hash, err = HashFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	c.Entries[path] = HashCacheEntry{Size: info.Size(), ModTime: info.ModTime(), Hash: hash}
+	return hash, false, nil
+}
+
+// Save writes the cache, dropping entries for files not looked up since it was loaded
+func (c *HashCache) Save() error {
+	for path := range c.Entries {
+		if !c.seen[path] {
+			delete(c.Entries, path)
+		}
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal hash cache: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+	return nil
+}
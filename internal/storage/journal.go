@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const CommitJournalFile = "commit-journal.json"
+
+// JournalEntry records one completed transfer
+type JournalEntry struct {
+	Path string `json:"path"`           // Where the transferred data ended up (or came from)
+	Hash string `json:"hash,omitempty"` // Content hash, when known
+	Size int64  `json:"size"`
+}
+
+// Journal tracks the transfers of a long pull or commit so an interrupted run can
+// resume instead of starting over. It is written after every completed transfer.
+type Journal struct {
+	Key       string                  `json:"key"` // Identifies the operation, e.g. the .aepx being committed
+	Started   time.Time               `json:"started"`
+	Completed map[string]JournalEntry `json:"completed"`
+	path      string
+	resumed   bool
+}
+
+// GetCommitJournalPath returns the path to the commit journal in the .vervids directory
+func GetCommitJournalPath() string {
+	return filepath.Join(VerVidsDir, CommitJournalFile)
+}
+
+// OpenJournal loads the journal at path if it belongs to the same operation (key),
+// otherwise starts a new one. Nothing is written until the first Record.
+func OpenJournal(path string, key string) *Journal {
+	j := &Journal{Key: key, Started: time.Now(), Completed: map[string]JournalEntry{}, path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return j
+	}
+	var existing Journal
+	if err := json.Unmarshal(data, &existing); err != nil || existing.Key != key || len(existing.Completed) == 0 {
+		return j
+	}
+	existing.path = path
+	existing.resumed = true
+	return &existing
+}
+
+// ReadJournal returns the journal left at path by an unfinished operation, or nil if
+// there is none
+func ReadJournal(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("failed to parse journal: %w", err)
+	}
+	j.path = path
+	return j, nil
+}
+
+// Resumed reports whether the journal was left behind by an interrupted run
+func (j *Journal) Resumed() bool {
+	return j != nil && j.resumed
+}
+
+// Get returns the completed transfer recorded under key
+func (j *Journal) Get(key string) (JournalEntry, bool) {
+	if j == nil {
+		return JournalEntry{}, false
+	}
+	entry, ok := j.Completed[key]
+	return entry, ok
+}
+
+// Record marks a transfer complete and saves the journal
+func (j *Journal) Record(key string, entry JournalEntry) error {
+	if j == nil {
+		return nil
+	}
+	j.Completed[key] = entry
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal: %w", err)
+	}
+	if err := os.WriteFile(j.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the journal once the operation has finished
+func (j *Journal) Remove() {
+	if j != nil {
+		os.Remove(j.path)
+	}
+}
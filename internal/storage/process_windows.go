@@ -0,0 +1,16 @@
+//go:build windows
+
+package storage
+
+import "os"
+
+// processRunning reports whether a process with the given ID exists; on Windows
+// finding a process opens it, which fails once it has exited
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
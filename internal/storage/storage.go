@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -128,6 +130,21 @@ func CopyFile(src, dst string) error {
 	return destFile.Sync()
 }
 
+// HashFile returns the hex-encoded SHA-256 of a file's contents
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // GetFileSize returns the size of a file in bytes
 func GetFileSize(path string) (int64, error) {
 	info, err := os.Stat(path)
@@ -137,16 +154,21 @@ func GetFileSize(path string) (int64, error) {
 	return info.Size(), nil
 }
 
-// GetContextPath returns the path to the current project context file
-func GetContextPath() string {
+// GetUserDir returns the per-user ~/.vervids directory, creating it if needed
+func GetUserDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		// Fallback to current directory
-		return filepath.Join(".vervids", ContextFile)
+		return VerVidsDir
 	}
-	contextDir := filepath.Join(home, ".vervids")
-	os.MkdirAll(contextDir, 0755)
-	return filepath.Join(contextDir, ContextFile)
+	userDir := filepath.Join(home, VerVidsDir)
+	os.MkdirAll(userDir, 0755)
+	return userDir
+}
+
+// GetContextPath returns the path to the current project context file
+func GetContextPath() string {
+	return filepath.Join(GetUserDir(), ContextFile)
 }
 
 // SaveContext saves the current project context
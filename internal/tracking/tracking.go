@@ -1,97 +1,120 @@
 package tracking
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/ajeebtech/vervideos/internal/docker"
+	"github.com/ajeebtech/vervideos/internal/aepx"
+	"github.com/ajeebtech/vervideos/internal/storage"
 )
 
 // AssetInfoInput represents asset info for tracking (to avoid import cycle)
 type AssetInfoInput struct {
-	Filename     string
-	Extension    string
-	Size         int64
-	DockerPath   string
+	Filename       string
+	RelativePath   string
+	Extension      string
+	Size           int64
+	Hash           string
+	DockerPath     string
+	Interpretation aepx.Interpretation
 }
 
 // AssetStatus represents the status of an asset in a commit
 type AssetStatus struct {
-	Filename     string `json:"filename"`
-	Path         string `json:"path"`
-	Extension    string `json:"extension"`
-	Size         int64  `json:"size"`
-	Status       string `json:"status"` // "present", "missing", "removed", "new"
-	Present      bool   `json:"present"`
-	InPrevious   bool   `json:"in_previous"`
+	Filename              string   `json:"filename"`
+	Path                  string   `json:"path"`
+	RelativePath          string   `json:"relative_path,omitempty"`
+	Extension             string   `json:"extension"`
+	Size                  int64    `json:"size"`
+	Hash                  string   `json:"hash,omitempty"`
+	Status                string   `json:"status"` // "present", "modified", "reinterpreted", "missing", "removed", "new", "renamed"
+	Present               bool     `json:"present"`
+	InPrevious            bool     `json:"in_previous"`
+	PreviousPath          string   `json:"previous_path,omitempty"`          // Where a renamed/moved asset used to live
+	PreviousHash          string   `json:"previous_hash,omitempty"`          // Hash in the previous version, if it was there
+	PreviousSize          int64    `json:"previous_size,omitempty"`          // Size in the previous version, if it was there
+	SizeDelta             int64    `json:"size_delta"`                       // Bytes gained (or lost) since the previous version
+	InterpretationChanged []string `json:"interpretation_changed,omitempty"` // Footage settings changed while the file stayed the same
 }
 
 // AssetTracking represents the complete asset tracking for a commit
 type AssetTracking struct {
-	Version       int           `json:"version"`
-	CommitMessage string        `json:"commit_message"`
-	Timestamp     string        `json:"timestamp"`
-	Assets        []AssetStatus `json:"assets"`
-	TotalAssets   int           `json:"total_assets"`
-	PresentAssets int           `json:"present_assets"`
-	MissingAssets int           `json:"missing_assets"`
-	NewAssets     int           `json:"new_assets"`
-	RemovedAssets int           `json:"removed_assets"`
+	Version             int           `json:"version"`
+	CommitMessage       string        `json:"commit_message"`
+	Timestamp           string        `json:"timestamp"`
+	Assets              []AssetStatus `json:"assets"`
+	TotalAssets         int           `json:"total_assets"`
+	PresentAssets       int           `json:"present_assets"`
+	MissingAssets       int           `json:"missing_assets"`
+	NewAssets           int           `json:"new_assets"`
+	RemovedAssets       int           `json:"removed_assets"`
+	RenamedAssets       int           `json:"renamed_assets"`
+	ModifiedAssets      int           `json:"modified_assets"`
+	ReinterpretedAssets int           `json:"reinterpreted_assets,omitempty"`
+	ProjectFile         string        `json:"project_file,omitempty"`      // Stored path of the version's project file
+	ProjectFileSize     int64         `json:"project_file_size,omitempty"` // Size of the project file as committed
+	ProjectFileHash     string        `json:"project_file_hash,omitempty"` // SHA-256 of the project file as committed
 }
 
-// SaveTracking saves asset tracking JSON to Docker
-func SaveTracking(version int, versionDir string, tracking *AssetTracking) error {
+// SetProjectFile records the committed project file alongside the assets, so the
+// tracking alone tells whether a working copy differs from the version
+func (t *AssetTracking) SetProjectFile(path string, size int64, hash string) {
+	t.ProjectFile = path
+	t.ProjectFileSize = size
+	t.ProjectFileHash = hash
+}
+
+// SaveTracking saves asset tracking JSON to the version folder in storage
+func SaveTracking(store storage.Storage, versionDir string, tracking *AssetTracking) error {
 	// Create tracking JSON file
 	jsonData, err := json.MarshalIndent(tracking, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal tracking data: %w", err)
 	}
 
-	// Save to local temp file first
-	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("asset-tracking-v%03d.json", version))
-	if err := os.WriteFile(tmpFile, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write tracking file: %w", err)
-	}
-	defer os.Remove(tmpFile) // Clean up temp file
-
-	// Copy to Docker
 	dockerPath := filepath.Join(versionDir, "asset-tracking.json")
-	if err := docker.CopyToContainer(tmpFile, dockerPath); err != nil {
-		return fmt.Errorf("failed to copy tracking file to Docker: %w", err)
+	if err := store.Put(bytes.NewReader(jsonData), int64(len(jsonData)), dockerPath); err != nil {
+		return fmt.Errorf("failed to copy tracking file to %s: %w", store.Name(), err)
 	}
 
 	return nil
 }
 
-// LoadTracking loads asset tracking JSON from Docker
-func LoadTracking(versionDir string) (*AssetTracking, error) {
+// LoadTracking loads asset tracking JSON from the version folder in storage
+func LoadTracking(store storage.Storage, versionDir string) (*AssetTracking, error) {
 	dockerPath := filepath.Join(versionDir, "asset-tracking.json")
-	
-	// Copy from Docker to temp file
-	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("tracking-%d.json", os.Getpid()))
-	defer os.Remove(tmpFile)
-	
-	if err := docker.CopyFromContainer(dockerPath, tmpFile); err != nil {
-		return nil, fmt.Errorf("failed to copy tracking file from Docker: %w", err)
-	}
 
-	data, err := os.ReadFile(tmpFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read tracking file: %w", err)
+	var buf bytes.Buffer
+	if err := store.Get(dockerPath, &buf); err != nil {
+		return nil, fmt.Errorf("failed to copy tracking file from %s: %w", store.Name(), err)
 	}
 
 	var tracking AssetTracking
-	if err := json.Unmarshal(data, &tracking); err != nil {
+	if err := json.Unmarshal(buf.Bytes(), &tracking); err != nil {
 		return nil, fmt.Errorf("failed to parse tracking file: %w", err)
 	}
 
 	return &tracking, nil
 }
 
-// CreateTracking creates asset tracking by comparing current assets with previous version
+// assetKey identifies an asset across versions by its location relative to the project,
+// falling back to the filename for versions recorded before relative paths were tracked
+func assetKey(asset AssetInfoInput) string {
+	if asset.RelativePath != "" {
+		return strings.ReplaceAll(asset.RelativePath, "\\", "/")
+	}
+	return asset.Filename
+}
+
+// CreateTracking creates asset tracking by comparing current assets with previous version.
+// An asset kept at the same location whose size or hash changed is reported as "modified",
+// and one whose content is the same but whose footage interpretation changed as "reinterpreted".
+// An asset that disappeared from one location while identical content (same hash) appeared
+// at another is reported once as "renamed" rather than as a removal plus an addition.
 func CreateTracking(version int, commitMessage string, currentAssets []AssetInfoInput, previousAssets []AssetInfoInput) *AssetTracking {
 	tracking := &AssetTracking{
 		Version:       version,
@@ -102,27 +125,71 @@ func CreateTracking(version int, commitMessage string, currentAssets []AssetInfo
 
 	// Create map of previous assets for quick lookup
 	previousMap := make(map[string]bool)
+	previousByKey := make(map[string]AssetInfoInput)
 	for _, asset := range previousAssets {
-		previousMap[asset.Filename] = true
+		previousMap[assetKey(asset)] = true
+		previousByKey[assetKey(asset)] = asset
 	}
 
-	// Process current assets
 	currentMap := make(map[string]bool)
 	for _, asset := range currentAssets {
-		currentMap[asset.Filename] = true
+		currentMap[assetKey(asset)] = true
+	}
+
+	// Previous assets that are gone from their location, by hash, as rename candidates
+	vanishedByHash := make(map[string][]AssetInfoInput)
+	for _, asset := range previousAssets {
+		if !currentMap[assetKey(asset)] && asset.Hash != "" {
+			vanishedByHash[asset.Hash] = append(vanishedByHash[asset.Hash], asset)
+		}
+	}
+	renamedFrom := make(map[string]bool) // previous keys consumed by a rename
+
+	// Process current assets
+	for _, asset := range currentAssets {
+		key := assetKey(asset)
 		status := AssetStatus{
-			Filename:   asset.Filename,
-			Path:       asset.DockerPath,
-			Extension:  asset.Extension,
-			Size:       asset.Size,
-			Present:    true,
-			InPrevious: previousMap[asset.Filename],
+			Filename:     asset.Filename,
+			Path:         asset.DockerPath,
+			RelativePath: asset.RelativePath,
+			Extension:    asset.Extension,
+			Size:         asset.Size,
+			Hash:         asset.Hash,
+			Present:      true,
+			InPrevious:   previousMap[key],
 		}
 
-		if previousMap[asset.Filename] {
-			status.Status = "present"
+		if previousMap[key] {
+			previous := previousByKey[key]
+			status.PreviousHash = previous.Hash
+			status.PreviousSize = previous.Size
+			status.SizeDelta = asset.Size - previous.Size
+			if previous.Size != asset.Size || (previous.Hash != "" && asset.Hash != "" && previous.Hash != asset.Hash) {
+				// Same location, different content
+				status.Status = "modified"
+				tracking.ModifiedAssets++
+			} else if changed := aepx.InterpretationChanges(previous.Interpretation, asset.Interpretation); len(changed) > 0 {
+				// Same file, interpreted differently (frame rate, alpha, color profile)
+				status.Status = "reinterpreted"
+				status.InterpretationChanged = changed
+				tracking.ReinterpretedAssets++
+			} else {
+				status.Status = "present"
+			}
+		} else if candidates := vanishedByHash[asset.Hash]; asset.Hash != "" && len(candidates) > 0 {
+			// Same content at a new name/location
+			previous := candidates[0]
+			vanishedByHash[asset.Hash] = candidates[1:]
+			renamedFrom[assetKey(previous)] = true
+			status.Status = "renamed"
+			status.InPrevious = true
+			status.PreviousPath = assetKey(previous)
+			status.PreviousHash = previous.Hash
+			status.PreviousSize = previous.Size
+			tracking.RenamedAssets++
 		} else {
 			status.Status = "new"
+			status.SizeDelta = asset.Size
 			tracking.NewAssets++
 		}
 		tracking.Assets = append(tracking.Assets, status)
@@ -131,15 +198,21 @@ func CreateTracking(version int, commitMessage string, currentAssets []AssetInfo
 
 	// Find removed assets (in previous but not in current)
 	for _, asset := range previousAssets {
-		if !currentMap[asset.Filename] {
+		key := assetKey(asset)
+		if !currentMap[key] && !renamedFrom[key] {
 			tracking.Assets = append(tracking.Assets, AssetStatus{
-				Filename:   asset.Filename,
-				Path:       asset.DockerPath,
-				Extension:  asset.Extension,
-				Size:       asset.Size,
-				Status:     "removed",
-				Present:    false,
-				InPrevious: true,
+				Filename:     asset.Filename,
+				Path:         asset.DockerPath,
+				RelativePath: asset.RelativePath,
+				Extension:    asset.Extension,
+				Size:         asset.Size,
+				Hash:         asset.Hash,
+				Status:       "removed",
+				Present:      false,
+				InPrevious:   true,
+				PreviousHash: asset.Hash,
+				PreviousSize: asset.Size,
+				SizeDelta:    -asset.Size,
 			})
 			tracking.RemovedAssets++
 		}
@@ -150,4 +223,3 @@ func CreateTracking(version int, commitMessage string, currentAssets []AssetInfo
 
 	return tracking
 }
-
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"strings"
+)
+
+// sparkTicks are the block characters used to draw sparklines, lowest to highest
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a single line of block characters scaled to the largest value
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if max > 0 {
+			idx = int(v / max * float64(len(sparkTicks)-1))
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(sparkTicks) {
+			idx = len(sparkTicks) - 1
+		}
+		sb.WriteRune(sparkTicks[idx])
+	}
+	return sb.String()
+}
+
+// Bar renders a horizontal bar of the given width scaled against max
+func Bar(value, max float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	filled := 0
+	if max > 0 {
+		filled = int(value / max * float64(width))
+	}
+	if value > 0 && filled == 0 {
+		filled = 1 // Always show something for non-empty values
+	}
+	if filled > width {
+		filled = width
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
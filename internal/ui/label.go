@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LabelColorNames are the named label colors, in the order new labels are given them
+var LabelColorNames = []string{"blue", "green", "orange", "purple", "red", "teal", "yellow", "pink", "gray"}
+
+// labelColors maps label color names to ANSI 256 colors
+var labelColors = map[string]string{
+	"red":    "196",
+	"orange": "208",
+	"yellow": "220",
+	"green":  "34",
+	"teal":   "37",
+	"blue":   "33",
+	"purple": "135",
+	"pink":   "205",
+	"gray":   "245",
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ValidateLabelColor checks that color is a named label color, an ANSI 256 number or
+// a hex value, and returns it normalized
+func ValidateLabelColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if _, ok := labelColors[color]; ok || hexColor.MatchString(color) {
+		return color, nil
+	}
+	var n int
+	if _, err := fmt.Sscanf(color, "%d", &n); err == nil && fmt.Sprint(n) == color && n >= 0 && n <= 255 {
+		return color, nil
+	}
+	return "", fmt.Errorf("unknown color '%s' (use %s, an ANSI number 0-255 or #rrggbb)", color, strings.Join(LabelColorNames, ", "))
+}
+
+// Label renders a label name as a colored chip, or as [name] in plain mode
+func Label(name string, color string) string {
+	if plain {
+		return "[" + name + "]"
+	}
+	if c, ok := labelColors[color]; ok {
+		color = c
+	}
+	return lipgloss.NewStyle().
+		Background(lipgloss.Color(color)).
+		Foreground(lipgloss.Color("231")).
+		Bold(true).
+		Padding(0, 1).
+		Render(name)
+}
@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+)
+
+// pickerRows is the most choices a picker shows at once
+const pickerRows = 10
+
+// PickItem is one choice in a picker: the label the query is matched against, and a
+// detail line shown dimmed below it
+type PickItem struct {
+	Label  string
+	Detail string
+}
+
+// ReadKey reads one key press from a terminal in raw mode. Special keys are named
+// ("up", "down", "left", "right", "tab", "enter", "esc", "backspace", "ctrl+c",
+// "ctrl+u"); anything else is returned as the text typed.
+func ReadKey(r io.Reader) (string, error) {
+	buf := make([]byte, 16)
+	n, err := r.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	switch key := string(buf[:n]); key {
+	case "\x1b[A", "\x1bOA":
+		return "up", nil
+	case "\x1b[B", "\x1bOB":
+		return "down", nil
+	case "\x1b[C", "\x1bOC":
+		return "right", nil
+	case "\x1b[D", "\x1bOD":
+		return "left", nil
+	case "\t":
+		return "tab", nil
+	case "\r", "\n":
+		return "enter", nil
+	case "\x1b":
+		return "esc", nil
+	case "\x7f", "\b":
+		return "backspace", nil
+	case "\x03":
+		return "ctrl+c", nil
+	case "\x15":
+		return "ctrl+u", nil
+	default:
+		return key, nil
+	}
+}
+
+// FuzzyMatch reports whether every character of query appears in text in order,
+// ignoring case, and scores the match: consecutive characters and characters at the
+// start of words score higher, so "mpr" ranks "My Promo Reel" above "campaign_pr".
+func FuzzyMatch(query string, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	score, qi, last := 0, 0, -1
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		if last == ti-1 {
+			score += 3 // Consecutive
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 2 // Word start
+		}
+		last = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score - len(t)/10, true
+}
+
+// FuzzyFilter returns the indexes of the items matching query, best match first
+func FuzzyFilter(query string, items []PickItem) []int {
+	type match struct{ index, score int }
+	matches := []match{}
+	for i, item := range items {
+		if score, ok := FuzzyMatch(query, item.Label); ok {
+			matches = append(matches, match{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	indexes := make([]int, len(matches))
+	for i, m := range matches {
+		indexes[i] = m.index
+	}
+	return indexes
+}
+
+// Pick shows a fuzzy-search picker below prompt, starting from query, and returns the
+// index of the chosen item. ok is false when the user cancels with esc or ctrl+c.
+// Stdin and stdout must be a terminal.
+func Pick(prompt string, query string, items []PickItem) (index int, ok bool, err error) {
+	state, err := term.MakeRaw(os.Stdin.Fd())
+	if err != nil {
+		return -1, false, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	defer term.Restore(os.Stdin.Fd(), state)
+
+	cursor := 0
+	drawn := 0
+	for {
+		matches := FuzzyFilter(query, items)
+		if cursor >= len(matches) {
+			cursor = len(matches) - 1
+		}
+		if cursor < 0 {
+			cursor = 0
+		}
+		drawn = drawPicker(drawn, prompt, query, items, matches, cursor)
+
+		key, err := ReadKey(os.Stdin)
+		if err != nil {
+			clearPicker(drawn)
+			return -1, false, err
+		}
+		switch key {
+		case "esc", "ctrl+c":
+			clearPicker(drawn)
+			return -1, false, nil
+		case "enter":
+			if len(matches) > 0 {
+				clearPicker(drawn)
+				return matches[cursor], true, nil
+			}
+		case "up":
+			cursor--
+		case "down", "tab":
+			cursor++
+		case "backspace":
+			if r := []rune(query); len(r) > 0 {
+				query = string(r[:len(r)-1])
+			}
+		case "ctrl+u":
+			query = ""
+		case "left", "right":
+		default:
+			if printable(key) {
+				query += key
+			}
+		}
+	}
+}
+
+// drawPicker redraws the picker over the previous drawing of drawn lines and returns
+// the number of lines drawn now
+func drawPicker(drawn int, prompt string, query string, items []PickItem, matches []int, cursor int) int {
+	width := TerminalWidth()
+	if width <= 0 {
+		width = 80
+	}
+	lines := []string{InfoStyle.Render(prompt+" ") + query + "▏"}
+
+	start := 0
+	if cursor >= pickerRows {
+		start = cursor - pickerRows + 1
+	}
+	for i := start; i < len(matches) && i < start+pickerRows; i++ {
+		item := items[matches[i]]
+		label := "  " + Truncate(item.Label, width-2)
+		if i == cursor {
+			label = AccentStyle.Bold(true).Render("▸ " + Truncate(item.Label, width-2))
+		}
+		lines = append(lines, label)
+		if item.Detail != "" {
+			lines = append(lines, lipgloss.NewStyle().Faint(!plain).Render("    "+Truncate(item.Detail, width-4)))
+		}
+	}
+	if len(matches) == 0 {
+		lines = append(lines, "  "+Text("No matches"))
+	}
+	lines = append(lines, InfoStyle.Render(Truncate(fmt.Sprintf("%d/%d  ↑/↓ move  enter select  esc cancel", len(matches), len(items)), width)))
+
+	clearPicker(drawn)
+	fmt.Print(strings.Join(lines, "\r\n"))
+	return len(lines)
+}
+
+// clearPicker erases a picker drawing of drawn lines, leaving the cursor where it began
+func clearPicker(drawn int) {
+	if drawn == 0 {
+		return
+	}
+	if drawn > 1 {
+		fmt.Printf("\033[%dA", drawn-1)
+	}
+	fmt.Print("\r\033[J")
+}
+
+func printable(key string) bool {
+	for _, r := range key {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return key != ""
+}
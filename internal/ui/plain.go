@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// plain disables colors, styling and emoji in all output
+var plain bool
+
+// PlainRequested reports whether the environment asks for plain output: NO_COLOR is set
+// (https://no-color.org) or stdout is not a terminal, e.g. CI logs or a file
+func PlainRequested() bool {
+	return os.Getenv("NO_COLOR") != "" || !isTerminal()
+}
+
+// SetPlain switches plain output on or off. In plain mode the shared styles render
+// text unchanged and message helpers use ASCII prefixes instead of emoji.
+func SetPlain(on bool) {
+	plain = on
+	if on {
+		SuccessStyle = lipgloss.NewStyle()
+		ErrorStyle = lipgloss.NewStyle()
+		WarningStyle = lipgloss.NewStyle()
+		InfoStyle = lipgloss.NewStyle()
+		AccentStyle = lipgloss.NewStyle()
+	}
+}
+
+// Plain reports whether plain output is on
+func Plain() bool {
+	return plain
+}
+
+// Text returns s with emoji removed in plain mode, and unchanged otherwise
+func Text(s string) string {
+	if !plain {
+		return s
+	}
+	removed := false
+	stripped := strings.Map(func(r rune) rune {
+		if isEmoji(r) {
+			removed = true
+			return -1
+		}
+		return r
+	}, s)
+	if !removed {
+		return s
+	}
+	// Drop the space an emoji prefix leaves behind, keeping the line's indentation
+	trimmed := strings.TrimLeft(s, " ")
+	indent := s[:len(s)-len(trimmed)]
+	return indent + strings.TrimLeft(strings.TrimPrefix(stripped, indent), " ")
+}
+
+// isEmoji reports whether r is a pictograph or symbol used as an emoji in messages
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // Pictographs, emoticons, transport, supplemental symbols
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Miscellaneous symbols and dingbats (✓, ⚠, ❌)
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // Technical symbols (⏳, ⏱)
+		return true
+	case r == 0xFE0F || r == 0x200D: // Variation selector and zero-width joiner
+		return true
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// progressRedraw limits how often the progress line is redrawn
+const progressRedraw = 100 * time.Millisecond
+
+// Progress draws a single-line progress display for a set of file transfers: the current
+// file's bar and percentage, plus bytes, percentage and ETA for the whole set. The bars
+// are bubbles/progress bars in the theme's accent color. It only draws when stdout is a
+// terminal; otherwise it just counts.
+type Progress struct {
+	total       int64 // Bytes in the whole set
+	done        int64 // Bytes of the items before the current one
+	itemSize    int64
+	transferred int64 // Bytes actually copied, used for the transfer rate
+	file        string
+	fileSize    int64
+	fileDone    int64
+	start       time.Time
+	lastDraw    time.Time
+	out         io.Writer
+	enabled     bool
+	fileBar     progress.Model
+	totalBar    progress.Model
+}
+
+// NewProgress starts tracking a transfer set of total bytes
+func NewProgress(total int64) *Progress {
+	return &Progress{
+		total:    total,
+		start:    time.Now(),
+		out:      os.Stdout,
+		enabled:  isTerminal() && !plain,
+		fileBar:  NewProgressBar(25),
+		totalBar: NewProgressBar(17),
+	}
+}
+
+// NewProgressBar returns a bar, with its percentage, width cells wide in the theme's
+// accent color, to be drawn with ViewAs
+func NewProgressBar(width int) progress.Model {
+	opts := []progress.Option{progress.WithWidth(width)}
+	if color, ok := AccentStyle.GetForeground().(lipgloss.Color); ok {
+		opts = append(opts, progress.WithSolidFill(string(color)))
+	}
+	return progress.New(opts...)
+}
+
+// Reader wraps r, the contents of the named file, so reading it advances the progress
+func (p *Progress) Reader(name string, size int64, r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+	p.startFile(name, size)
+	return &progressReader{r: r, p: p}
+}
+
+// Writer wraps w, the destination of the named file, so writing it advances the progress
+func (p *Progress) Writer(name string, size int64, w io.Writer) io.Writer {
+	if p == nil {
+		return w
+	}
+	p.startFile(name, size)
+	return &progressWriter{w: w, p: p}
+}
+
+// Next moves on to the next item of the set, counting the previous one as done whether
+// it was copied or skipped (e.g. content already stored)
+func (p *Progress) Next(size int64) {
+	if p == nil {
+		return
+	}
+	p.EndFile()
+	p.done += p.itemSize
+	p.itemSize = size
+}
+
+// EndFile clears the progress line once the current file is finished, so regular
+// output can follow
+func (p *Progress) EndFile() {
+	if p == nil || p.file == "" {
+		return
+	}
+	p.file = ""
+	if p.enabled {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+}
+
+func (p *Progress) startFile(name string, size int64) {
+	p.EndFile()
+	p.file = name
+	p.fileSize = size
+	p.fileDone = 0
+	p.lastDraw = time.Time{}
+}
+
+func (p *Progress) add(n int) {
+	p.fileDone += int64(n)
+	p.transferred += int64(n)
+	if !p.enabled || time.Since(p.lastDraw) < progressRedraw {
+		return
+	}
+	p.lastDraw = time.Now()
+	p.draw()
+}
+
+func (p *Progress) draw() {
+	name := p.file
+	if len([]rune(name)) > 24 {
+		name = string([]rune(name)[:23]) + "…"
+	}
+	line := fmt.Sprintf("%-24s %s  %s / %s", name, p.fileBar.ViewAs(percent(p.fileDone, p.fileSize)/100),
+		formatBytes(p.fileDone), formatBytes(p.fileSize))
+	overall := fmt.Sprintf(" of %s", formatBytes(p.total))
+	if eta, ok := p.eta(); ok {
+		overall += fmt.Sprintf("  ETA %s", eta)
+	}
+	fmt.Fprint(p.out, "\r\033[K"+line+InfoStyle.Render("  total ")+p.totalBar.ViewAs(percent(p.overall(), p.total)/100)+InfoStyle.Render(overall))
+}
+
+// overall returns the bytes done across the set, counting the current item up to its size
+func (p *Progress) overall() int64 {
+	current := p.fileDone
+	if current > p.itemSize {
+		current = p.itemSize
+	}
+	return p.done + current
+}
+
+// eta estimates the remaining time from the rate of bytes actually copied
+func (p *Progress) eta() (string, bool) {
+	elapsed := time.Since(p.start)
+	if p.transferred == 0 || elapsed < time.Second {
+		return "", false
+	}
+	rate := float64(p.transferred) / elapsed.Seconds()
+	remaining := time.Duration(float64(p.total-p.overall())/rate) * time.Second
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining.Round(time.Second).String(), true
+}
+
+func percent(done, total int64) float64 {
+	if total <= 0 {
+		return 100
+	}
+	pct := float64(done) / float64(total) * 100
+	if pct > 100 {
+		pct = 100
+	}
+	return pct
+}
+
+func formatBytes(n int64) string {
+	if n >= 1024*1024*1024 {
+		return fmt.Sprintf("%.2f GB", float64(n)/(1024*1024*1024))
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+}
+
+type progressReader struct {
+	r io.Reader
+	p *Progress
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	pr.p.add(n)
+	return n, err
+}
+
+type progressWriter struct {
+	w io.Writer
+	p *Progress
+}
+
+func (pw *progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	pw.p.add(n)
+	return n, err
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are drawn in turn while a step is running
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Step shows a long-running operation: a spinner with the elapsed time while it runs,
+// then a single line with the outcome. Nothing else should print until it finishes.
+type Step struct {
+	label   string
+	start   time.Time
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	enabled bool
+}
+
+// StartStep starts showing label as the current operation. Outside a terminal the
+// label is printed once instead of animated.
+func StartStep(label string) *Step {
+	s := &Step{label: label, start: time.Now(), stop: make(chan struct{}), enabled: isTerminal() && !plain}
+	if !s.enabled {
+		fmt.Println(Info("... " + label))
+		return s
+	}
+	s.wg.Add(1)
+	go s.spin()
+	return s
+}
+
+func (s *Step) spin() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		fmt.Printf("\r\033[K%s %s %s", InfoStyle.Render(spinnerFrames[frame%len(spinnerFrames)]), s.label, s.elapsed())
+		select {
+		case <-s.stop:
+			fmt.Print("\r\033[K")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Done stops the spinner and reports the step as finished
+func (s *Step) Done() {
+	s.finish()
+	fmt.Println(Success(s.label) + " " + s.elapsed())
+}
+
+// Fail stops the spinner and reports the step as failed
+func (s *Step) Fail() {
+	s.finish()
+	if plain {
+		fmt.Println("[failed] " + s.label + " " + s.elapsed())
+		return
+	}
+	fmt.Println(ErrorStyle.Render("✗ "+s.label) + " " + s.elapsed())
+}
+
+func (s *Step) finish() {
+	if s.enabled {
+		close(s.stop)
+		s.wg.Wait()
+	}
+}
+
+func (s *Step) elapsed() string {
+	return fmt.Sprintf("(%.1fs)", time.Since(s.start).Seconds())
+}
+
+// RunStep runs fn as a step labelled label and returns its error
+func RunStep(label string, fn func() error) error {
+	s := StartStep(label)
+	if err := fn(); err != nil {
+		s.Fail()
+		return err
+	}
+	s.Done()
+	return nil
+}
+
+// isTerminal reports whether stdout is an interactive terminal
+func isTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/charmbracelet/x/term"
+)
+
+// columnGap is the space between table columns
+const columnGap = 2
+
+// Table renders rows under headers with aligned columns. Cells are kept on one line,
+// and when the table is wider than the terminal the flex column (e.g. a commit message
+// or filename) is truncated to fit. Pass flex < 0 to never truncate.
+func Table(headers []string, rows [][]string, flex int) string {
+	cleaned := make([][]string, len(rows))
+	for i, row := range rows {
+		cleaned[i] = make([]string, len(row))
+		for j, cell := range row {
+			cleaned[i][j] = strings.Join(strings.Fields(cell), " ")
+		}
+	}
+
+	if width := TerminalWidth(); width > 0 && flex >= 0 && flex < len(headers) {
+		used := 0
+		for col := range headers {
+			if col != flex {
+				used += columnWidth(headers, cleaned, col) + columnGap
+			}
+		}
+		if available := width - used - 1; available >= len(headers[flex]) {
+			for _, row := range cleaned {
+				if flex < len(row) {
+					row[flex] = Truncate(row[flex], available)
+				}
+			}
+		}
+	}
+
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderTop(false).
+		BorderBottom(false).
+		BorderLeft(false).
+		BorderRight(false).
+		BorderColumn(false).
+		BorderStyle(InfoStyle).
+		Headers(headers...).
+		Rows(cleaned...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			style := lipgloss.NewStyle()
+			if col < len(headers)-1 {
+				style = style.PaddingRight(columnGap)
+			}
+			if row == table.HeaderRow {
+				return style.Inherit(InfoStyle)
+			}
+			return style
+		})
+	return t.Render()
+}
+
+// Truncate shortens s to at most width cells, marking the cut with an ellipsis
+func Truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return "…"
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// TerminalWidth returns the width of the terminal stdout is attached to, falling back to
+// $COLUMNS. Returns 0 when unknown, e.g. when output is piped.
+func TerminalWidth() int {
+	if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 0
+}
+
+func columnWidth(headers []string, rows [][]string, col int) int {
+	width := lipgloss.Width(headers[col])
+	for _, row := range rows {
+		if col < len(row) && lipgloss.Width(row[col]) > width {
+			width = lipgloss.Width(row[col])
+		}
+	}
+	return width
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the palette used for styled output. Colors are ANSI 256 numbers ("42") or
+// hex values ("#2e7d32"); empty fields keep the base theme's color.
+type Theme struct {
+	Success string `yaml:"success,omitempty"`
+	Error   string `yaml:"error,omitempty"`
+	Warning string `yaml:"warning,omitempty"`
+	Info    string `yaml:"info,omitempty"`
+	Accent  string `yaml:"accent,omitempty"` // Header box and highlights
+}
+
+// DefaultTheme is the theme used when none is configured
+const DefaultTheme = "dark"
+
+// Themes are the built-in palettes
+var Themes = map[string]Theme{
+	"dark":          {Success: "42", Error: "196", Warning: "214", Info: "39", Accent: "62"},
+	"light":         {Success: "28", Error: "160", Warning: "130", Info: "25", Accent: "55"},
+	"high-contrast": {Success: "46", Error: "9", Warning: "11", Info: "14", Accent: "15"},
+}
+
+// AccentStyle colors headers and other highlights
+var AccentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+
+// ThemeNames returns the names of the built-in themes, sorted
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveTheme returns the named built-in theme with any custom colors applied on top
+func ResolveTheme(name string, custom Theme) (Theme, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+	theme, ok := Themes[strings.ToLower(name)]
+	if !ok {
+		return Themes[DefaultTheme], fmt.Errorf("unknown theme '%s' (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	for _, c := range []struct {
+		dst *string
+		src string
+	}{
+		{&theme.Success, custom.Success},
+		{&theme.Error, custom.Error},
+		{&theme.Warning, custom.Warning},
+		{&theme.Info, custom.Info},
+		{&theme.Accent, custom.Accent},
+	} {
+		if c.src != "" {
+			*c.dst = c.src
+		}
+	}
+	return theme, nil
+}
+
+// ApplyTheme switches the shared styles to the theme's colors. It has no effect in plain mode.
+func ApplyTheme(t Theme) {
+	if plain {
+		return
+	}
+	SuccessStyle = SuccessStyle.Foreground(lipgloss.Color(t.Success))
+	ErrorStyle = ErrorStyle.Foreground(lipgloss.Color(t.Error))
+	WarningStyle = WarningStyle.Foreground(lipgloss.Color(t.Warning))
+	InfoStyle = InfoStyle.Foreground(lipgloss.Color(t.Info))
+	AccentStyle = AccentStyle.Foreground(lipgloss.Color(t.Accent))
+}
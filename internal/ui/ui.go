@@ -23,17 +23,26 @@ var (
 
 // Helper functions for styled output
 func Success(msg string) string {
+	if plain {
+		return "[ok] " + Text(msg)
+	}
 	return SuccessStyle.Render("✓ " + msg)
 }
 
 func Error(msg string) string {
+	if plain {
+		return "[error] " + Text(msg)
+	}
 	return ErrorStyle.Render("❌ " + msg)
 }
 
 func Warning(msg string) string {
+	if plain {
+		return "[warning] " + Text(msg)
+	}
 	return WarningStyle.Render("⚠️  " + msg)
 }
 
 func Info(msg string) string {
-	return InfoStyle.Render(msg)
+	return InfoStyle.Render(Text(msg))
 }
@@ -0,0 +1,181 @@
+package update
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajeebtech/vervideos/internal/storage"
+)
+
+const (
+	StateFile = "update-check.json"
+
+	// ReleaseURL returns the latest published release
+	ReleaseURL = "https://api.github.com/repos/ajeebtech/vervideos/releases/latest"
+
+	// CheckInterval is the minimum time between release lookups
+	CheckInterval = 24 * time.Hour
+
+	// DisableEnv turns the check off when set, e.g. VERVIDS_NO_UPDATE_CHECK=1
+	DisableEnv = "VERVIDS_NO_UPDATE_CHECK"
+
+	requestTimeout = 5 * time.Second
+)
+
+// done is closed when a lookup started by Start finishes
+var done chan struct{}
+
+// State is the result of the last release lookup, kept in ~/.vervids/update-check.json
+type State struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest,omitempty"`
+}
+
+// GetStatePath returns the path to the update check state file
+func GetStatePath() string {
+	return filepath.Join(storage.GetUserDir(), StateFile)
+}
+
+// LoadState reads the last lookup. A missing or unreadable file yields an empty state.
+func LoadState() State {
+	var s State
+	data, err := os.ReadFile(GetStatePath())
+	if err != nil {
+		return s
+	}
+	json.Unmarshal(data, &s)
+	return s
+}
+
+func (s State) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(GetStatePath(), data, 0644)
+}
+
+// Enabled reports whether update checks should run for this build: not for dev
+// builds, and not when DisableEnv is set
+func Enabled(current string) bool {
+	if os.Getenv(DisableEnv) != "" {
+		return false
+	}
+	_, ok := parseVersion(current)
+	return ok
+}
+
+// Start looks up the latest release in the background when the last lookup is older
+// than CheckInterval. The result is saved for Notice, so a new release shows up on the
+// next run rather than delaying this one.
+func Start() {
+	state := LoadState()
+	if time.Since(state.CheckedAt) < CheckInterval {
+		return
+	}
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		latest, err := fetchLatest()
+		if err != nil {
+			slog.Debug("update check failed", "err", err)
+		} else {
+			state.Latest = latest
+		}
+		// Record failed lookups too, so an offline machine doesn't retry every run
+		state.CheckedAt = time.Now()
+		if err := state.save(); err != nil {
+			slog.Debug("update check not saved", "err", err)
+		}
+	}()
+}
+
+// Wait gives a lookup started by Start up to timeout to finish, so quick commands
+// still record a result before the process exits
+func Wait(timeout time.Duration) {
+	if done == nil {
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// Notice returns the newer release recorded by the last lookup, or "" when current
+// is up to date or the lookup hasn't finished
+func Notice(current string) string {
+	latest := LoadState().Latest
+	if latest == "" || !Newer(latest, current) {
+		return ""
+	}
+	return latest
+}
+
+func fetchLatest() (string, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	req, err := http.NewRequest(http.MethodGet, ReleaseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("release lookup returned %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to parse release: %w", err)
+	}
+	return release.TagName, nil
+}
+
+// Newer reports whether version a is newer than b. Both are semantic versions with an
+// optional "v" prefix; git describe suffixes like "-8-g81e2737" are ignored.
+func Newer(a, b string) bool {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		return false
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return va[i] > vb[i]
+		}
+	}
+	return false
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}